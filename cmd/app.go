@@ -0,0 +1,269 @@
+// cmd/app.go
+
+package cmd
+
+import (
+	"context"
+	"io"
+	"maps"
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/peiman/ckeletin-go/internal/exitcode"
+	"github.com/peiman/ckeletin-go/internal/logger"
+	"github.com/peiman/ckeletin-go/internal/startup"
+	"github.com/peiman/ckeletin-go/pkg/termcaps"
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+)
+
+// App is one instance of the CLI: a command tree of its own, copied from the
+// built-in commands, with its own streams, base config and logger. Nothing an
+// invocation sets up is shared with other Apps, so tests and embedders can
+// run Apps in parallel in one process.
+type App struct {
+	root   *cobra.Command
+	config *viper.Viper
+	logger *zerolog.Logger
+}
+
+// AppOption configures an App
+type AppOption func(*App)
+
+// WithIO sets the standard input, output and error of the App's commands
+func WithIO(in io.Reader, out, errOut io.Writer) AppOption {
+	return func(a *App) {
+		a.root.SetIn(in)
+		a.root.SetOut(out)
+		a.root.SetErr(errOut)
+	}
+}
+
+// WithConfig sets the base config of the App's invocations. Its values
+// replace the registered defaults; the config file, environment and flags
+// still override them. Invocations read v but never change it.
+func WithConfig(v *viper.Viper) AppOption {
+	return func(a *App) { a.config = v }
+}
+
+// WithLogger sets the logger the App's invocations log with instead of one
+// writing to their stderr. Its level is still set by app.log_level.
+func WithLogger(l zerolog.Logger) AppOption {
+	return func(a *App) { a.logger = &l }
+}
+
+// NewApp returns an App with a copy of the built-in command tree, so commands
+// added to it and flags parsed by it do not affect other Apps
+func NewApp(opts ...AppOption) *App {
+	a := &App{root: cloneCommand(RootCmd)}
+	for _, opt := range opts {
+		opt(a)
+	}
+	return a
+}
+
+// Root returns the root command of the App, e.g. to add commands or
+// persistent flags
+func (a *App) Root() *cobra.Command {
+	return a.root
+}
+
+// Execute runs the App with args, or the arguments the process was started
+// with when args is nil, and returns the command error. Signals are not
+// handled; cancel ctx to interrupt the command. An App runs one invocation
+// at a time.
+func (a *App) Execute(ctx context.Context, args []string) error {
+	return a.execute(ctx, args, time.Now())
+}
+
+// Main runs the App with the arguments of the process like the binary does:
+// until the command finishes or is interrupted by a signal
+func (a *App) Main() error {
+	ctx, stop := notifyShutdown(context.Background(), a.root.ErrOrStderr())
+	defer stop()
+	return a.execute(ctx, nil, initStart)
+}
+
+// Run executes the App like Execute, prints any error to its stderr and
+// returns the exit code
+func (a *App) Run(ctx context.Context, args []string) int {
+	err := a.Execute(ctx, args)
+	PrintError(a.root.ErrOrStderr(), err)
+	return exitcode.Code(err)
+}
+
+// execute runs the App with args, measuring the startup from started
+func (a *App) execute(ctx context.Context, args []string, started time.Time) error {
+	root := a.root
+	inv := newInvocation(a, started)
+	// --version prints the short form; the version command shows full build details.
+	root.Version = Version
+	inv.markStartup("init")
+	if args == nil {
+		args = processArgs()
+	}
+	// A nil slice would make cobra parse os.Args
+	root.SetArgs(append([]string{}, args...))
+	// scaffold:plugins
+	if unknownCommand(root, args) {
+		registerPlugins(root, a.baseLogger(root.ErrOrStderr()))
+	}
+	inv.markStartup("plugins")
+	// scaffold:end
+	markUsageErrors(root)
+
+	// Replaces the contexts and flags an earlier run left on the commands
+	resetFlags(root)
+	ctx = withInvocation(withArgs(ctx, args), inv)
+	setContexts(root, ctx)
+
+	start := time.Now()
+	cmd, err := root.ExecuteContextC(ctx)
+	err = addHints(root, cmd, classifyError(root, cmd, timeoutError(cmd, err)))
+	for _, record := range afterExecute {
+		record(cmd, start, err)
+	}
+	return err
+}
+
+// baseLogger returns the logger of the App before the level of an
+// invocation is known: the injected one or one writing to errOut
+func (a *App) baseLogger(errOut io.Writer) zerolog.Logger {
+	if a.logger != nil {
+		return *a.logger
+	}
+	return logger.New(errOut, zerolog.InfoLevel.String(), termcaps.UnicodeAuto)
+}
+
+// newLogger returns the logger of an invocation writing to errOut at level
+func (a *App) newLogger(errOut io.Writer, level string, unicode termcaps.UnicodeMode) zerolog.Logger {
+	if a.logger != nil {
+		return logger.WithLevel(*a.logger, level)
+	}
+	return logger.New(errOut, level, unicode)
+}
+
+// invocation is the state of one run of an App, carried by the context of
+// its commands
+type invocation struct {
+	app *App
+	// startup measures the phases of starting the command
+	startup *startup.Timer
+	// stopProfiling ends the profiles started for the invocation, if any
+	stopProfiling func() error
+	// profilePaths are the files written by the profiles of the invocation
+	profilePaths []string
+	// cancelTimeout releases the deadline of the command
+	cancelTimeout context.CancelFunc
+	// notice is the update notice for the command
+	notice string
+	// noticeDone is closed when the background release check finished
+	noticeDone chan struct{}
+}
+
+// newInvocation returns the state of a run of a starting at started
+func newInvocation(a *App, started time.Time) *invocation {
+	return &invocation{app: a, startup: startup.NewTimer(started), cancelTimeout: func() {}}
+}
+
+// markStartup ends the startup phase name
+func (inv *invocation) markStartup(name string) {
+	inv.startup.Mark(name)
+}
+
+type invocationKey struct{}
+
+// withInvocation returns a copy of ctx carrying inv
+func withInvocation(ctx context.Context, inv *invocation) context.Context {
+	return context.WithValue(ctx, invocationKey{}, inv)
+}
+
+// invocationFrom returns the invocation ctx belongs to. Commands run
+// directly, e.g. in tests, get a new one of an App without options.
+func invocationFrom(ctx context.Context) *invocation {
+	if ctx != nil {
+		if inv, ok := ctx.Value(invocationKey{}).(*invocation); ok {
+			return inv
+		}
+	}
+	return newInvocation(&App{}, time.Now())
+}
+
+// cloneMu serializes copying the built-in commands, as cobra and pflag
+// update their lookup caches while the command tree is read
+var cloneMu sync.Mutex
+
+// cloneCommand returns a copy of c and its subcommands with flags of their
+// own, set to their defaults, and no streams, context or arguments
+func cloneCommand(c *cobra.Command) *cobra.Command {
+	cloneMu.Lock()
+	defer cloneMu.Unlock()
+	return cloneTree(c)
+}
+
+func cloneTree(c *cobra.Command) *cobra.Command {
+	clone := *c
+	clone.ResetCommands()
+	clone.ResetFlags()
+	clone.Annotations = maps.Clone(c.Annotations)
+	clone.SetIn(nil)
+	clone.SetOut(nil)
+	clone.SetErr(nil)
+	clone.SetArgs(nil)
+	clone.SetContext(nil)
+
+	c.PersistentFlags().VisitAll(func(f *pflag.Flag) {
+		clone.PersistentFlags().AddFlag(cloneFlag(f))
+	})
+	c.Flags().VisitAll(func(f *pflag.Flag) {
+		// Flags() also holds the persistent flags of c and, once c ran, of its parents
+		if c.PersistentFlags().Lookup(f.Name) == nil && !inherited(c, f) {
+			clone.Flags().AddFlag(cloneFlag(f))
+		}
+	})
+	for _, sub := range c.Commands() {
+		// Cobra adds its own commands to the copy when it runs
+		switch sub.Name() {
+		case "help", cobra.ShellCompRequestCmd:
+			continue
+		}
+		clone.AddCommand(cloneTree(sub))
+	}
+	return &clone
+}
+
+// inherited reports whether f is a persistent flag of a parent of c
+func inherited(c *cobra.Command, f *pflag.Flag) bool {
+	for p := c.Parent(); p != nil; p = p.Parent() {
+		if p.PersistentFlags().Lookup(f.Name) == f {
+			return true
+		}
+	}
+	return false
+}
+
+// cloneFlag returns a copy of f with a value of its own at its default
+func cloneFlag(f *pflag.Flag) *pflag.Flag {
+	nf := *f
+	nf.Changed = false
+	nf.Annotations = maps.Clone(f.Annotations)
+	if f.Value.Type() == "stringSlice" {
+		fs := pflag.NewFlagSet("", pflag.ContinueOnError)
+		fs.StringSlice(f.Name, sliceDefault(f.DefValue), "")
+		nf.Value = fs.Lookup(f.Name).Value
+		return &nf
+	}
+	t := reflect.TypeOf(f.Value)
+	if t.Kind() != reflect.Pointer || t.Elem().Kind() == reflect.Struct {
+		log.Fatal().Str("flag", f.Name).Str("type", f.Value.Type()).Msg("Failed to copy flag of unsupported type")
+	}
+	nf.Value = reflect.New(t.Elem()).Interface().(pflag.Value)
+	if err := nf.Value.Set(f.DefValue); err != nil {
+		log.Fatal().Err(err).Msgf("Failed to set the default of '%s'", f.Name)
+	}
+	return &nf
+}
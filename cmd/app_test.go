@@ -0,0 +1,89 @@
+// cmd/app_test.go
+
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/rs/zerolog"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+func TestNewApp_Independent(t *testing.T) {
+	a, b := NewApp(), NewApp()
+	if a.Root() == RootCmd || a.Root() == b.Root() {
+		t.Fatal("NewApp() shares the root command")
+	}
+
+	a.Root().AddCommand(&cobra.Command{Use: "hello"})
+	for _, root := range []*cobra.Command{b.Root(), RootCmd} {
+		if c, _, err := root.Find([]string{"hello"}); err == nil && c.Name() == "hello" {
+			t.Error("Command added to one App is in another root command")
+		}
+	}
+
+	ping, _, err := a.Root().Find([]string{"ping"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := ping.Flags().Set("message", "Changed"); err != nil {
+		t.Fatal(err)
+	}
+	for _, root := range []*cobra.Command{b.Root(), RootCmd} {
+		other, _, _ := root.Find([]string{"ping"})
+		if f := other.Flags().Lookup("message"); f.Changed || f.Value.String() != "Pong" {
+			t.Errorf("Flag set in one App is %q in another", f.Value.String())
+		}
+	}
+}
+
+func TestApp_WithConfig(t *testing.T) {
+	isolateExecute(t)
+	base := viper.New()
+	base.Set("app.ping.output_message", "From base")
+	var stdout, stderr bytes.Buffer
+	a := NewApp(WithIO(strings.NewReader(""), &stdout, &stderr), WithConfig(base))
+
+	if err := a.Execute(context.Background(), []string{"ping"}); err != nil {
+		t.Fatalf("Execute() error = %v\n%s", err, &stderr)
+	}
+	if stdout.String() != "From base\n" {
+		t.Errorf("stdout = %q, want the message of the base config", stdout.String())
+	}
+
+	stdout.Reset()
+	if err := a.Execute(context.Background(), []string{"ping", "--message", "From flag"}); err != nil {
+		t.Fatalf("Execute() error = %v\n%s", err, &stderr)
+	}
+	if stdout.String() != "From flag\n" || base.GetString("app.ping.output_message") != "From base" {
+		t.Errorf("stdout = %q, base = %q, want the flag to win without changing the base", stdout.String(), base.GetString("app.ping.output_message"))
+	}
+}
+
+func TestApp_WithLogger(t *testing.T) {
+	isolateExecute(t)
+	var stdout, stderr, logged bytes.Buffer
+	a := NewApp(WithIO(strings.NewReader(""), &stdout, &stderr), WithLogger(zerolog.New(&logged)))
+
+	if err := a.Execute(context.Background(), []string{"ping", "--log-level", "debug"}); err != nil {
+		t.Fatalf("Execute() error = %v\n%s", err, &stderr)
+	}
+	if !strings.Contains(logged.String(), "Starting runPing execution") {
+		t.Errorf("Injected logger got %q, want the debug logs", logged.String())
+	}
+	if strings.Contains(stderr.String(), "Starting runPing execution") {
+		t.Errorf("stderr = %q, want the logs in the injected logger only", stderr.String())
+	}
+
+	logged.Reset()
+	if err := a.Execute(context.Background(), []string{"ping", "--log-level", "error"}); err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	if logged.Len() != 0 {
+		t.Errorf("Injected logger got %q at level error", logged.String())
+	}
+}
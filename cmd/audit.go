@@ -0,0 +1,157 @@
+// cmd/audit.go
+
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/peiman/ckeletin-go/internal/audit"
+	"github.com/peiman/ckeletin-go/internal/config"
+	"github.com/peiman/ckeletin-go/internal/exitcode"
+	"github.com/peiman/ckeletin-go/internal/runctx"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+var auditCmd = &cobra.Command{
+	Use:         "audit",
+	Short:       "Inspect the command audit log",
+	Annotations: map[string]string{docsAnnotation: "audit-command"},
+	Long: `The audit log records every invocation with its arguments, the user, start and
+end time, and exit code. It is off unless app.audit.enabled is true.
+Values of secret flags (tokens, passwords, keys) are masked before they are written.`,
+}
+
+var auditShowCmd = &cobra.Command{
+	Use:   "show",
+	Short: "Show the most recent audit log entries",
+	Args:  cobra.NoArgs,
+	RunE:  runAuditShow,
+}
+
+func init() {
+	initAuditConfig()
+	auditShowCmd.Flags().Int("limit", 20, "Number of most recent entries to show (0 for all)")
+	auditCmd.AddCommand(auditShowCmd)
+	RootCmd.AddCommand(auditCmd)
+	afterExecute = append(afterExecute, func(cmd *cobra.Command, start time.Time, err error) {
+		if cmd != nil {
+			recordAudit(cmd, runContext(cmd).Args, start, err)
+		}
+	})
+}
+
+func initAuditConfig() {
+	config.Register(
+		config.Option{Key: "app.audit.enabled", Default: false, Description: "Record every invocation in the audit log"},
+		config.Option{Key: "app.audit.path", Default: "", Description: "Audit log file (default is audit.jsonl in the state directory)", Path: config.PathStateDir},
+	)
+}
+
+// auditLog returns the audit log configured in rc, by default in the XDG state directory
+func auditLog(rc *runctx.RunContext) (*audit.Log, error) {
+	if path := rc.Config.GetString("app.audit.path"); path != "" {
+		return &audit.Log{Path: path}, nil
+	}
+	path, err := rc.Dirs.StateFile(binaryName, "audit.jsonl")
+	if err != nil {
+		return nil, err
+	}
+	return &audit.Log{Path: path}, nil
+}
+
+// recordAudit appends the invocation of cmd to the audit log when it is enabled.
+// Failures are logged and never change the command result.
+func recordAudit(cmd *cobra.Command, args []string, start time.Time, runErr error) {
+	if cmd == nil || isCompletionRequest(cmd) {
+		return
+	}
+	rc := runContext(cmd)
+	if !rc.Config.GetBool("app.audit.enabled") {
+		return
+	}
+
+	l, err := auditLog(rc)
+	if err != nil {
+		rc.Logger.Warn().Err(err).Msg("Failed to open audit log")
+		return
+	}
+
+	fs := pflag.NewFlagSet(cmd.Name(), pflag.ContinueOnError)
+	fs.AddFlagSet(cmd.Flags())
+	fs.AddFlagSet(cmd.InheritedFlags())
+
+	e := audit.Entry{
+		Start:    start.UTC(),
+		End:      time.Now().UTC(),
+		User:     audit.CurrentUser(),
+		Command:  commandName(cmd),
+		Args:     audit.MaskArgs(fs, args),
+		ExitCode: exitcode.Code(runErr),
+	}
+	if err := l.Append(e); err != nil {
+		rc.Logger.Warn().Err(err).Msg("Failed to write audit log")
+	}
+}
+
+func runAuditShow(cmd *cobra.Command, args []string) error {
+	limit, _ := cmd.Flags().GetInt("limit")
+	if limit < 0 {
+		return fmt.Errorf("invalid limit %d: must not be negative", limit)
+	}
+
+	rc := runContext(cmd)
+	l, err := auditLog(rc)
+	if err != nil {
+		return err
+	}
+	entries, err := l.Entries()
+	if err != nil {
+		return err
+	}
+	if limit > 0 && len(entries) > limit {
+		entries = entries[len(entries)-limit:]
+	}
+
+	if entries == nil {
+		entries = []audit.Entry{}
+	}
+	return renderOutput(cmd, auditEntries{Entries: entries, Enabled: rc.Config.GetBool("app.audit.enabled")})
+}
+
+// auditEntries is the result of audit show
+type auditEntries struct {
+	Entries []audit.Entry
+	// Enabled tells the text form why there are no entries
+	Enabled bool
+}
+
+// MarshalJSON encodes the entries only, as a list
+func (r auditEntries) MarshalJSON() ([]byte, error) {
+	return json.Marshal(r.Entries)
+}
+
+// WriteText prints one line per entry
+func (r auditEntries) WriteText(w io.Writer) error {
+	entries := r.Entries
+	if len(entries) == 0 {
+		msg := "No audit entries recorded."
+		if !r.Enabled {
+			msg += " Set app.audit.enabled to true to start recording."
+		}
+		_, err := fmt.Fprintln(w, msg)
+		return err
+	}
+	for _, e := range entries {
+		if _, err := fmt.Fprintf(w, "%s  %-12s  exit %-3d  %8s  %s %s\n",
+			e.Start.Local().Format(time.DateTime), e.User, e.ExitCode,
+			e.End.Sub(e.Start).Round(time.Millisecond), binaryName, strings.Join(e.Args, " ")); err != nil {
+			return err
+		}
+	}
+	return nil
+}
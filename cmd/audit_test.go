@@ -0,0 +1,144 @@
+// cmd/audit_test.go
+
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/peiman/ckeletin-go/internal/audit"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// setupAuditTest enables the audit log in a temp file and returns a command
+// with a secret flag and the config enabling it
+func setupAuditTest(t *testing.T) (*cobra.Command, map[string]interface{}) {
+	t.Helper()
+	values := map[string]interface{}{
+		"app.audit.enabled": true,
+		"app.audit.path":    filepath.Join(t.TempDir(), "audit.jsonl"),
+	}
+
+	root := &cobra.Command{Use: "root"}
+	root.PersistentFlags().String("log-level", "info", "")
+	deploy := &cobra.Command{Use: "deploy"}
+	deploy.Flags().String("token", "", "")
+	root.AddCommand(deploy)
+	useConfig(t, deploy, values)
+	return deploy, values
+}
+
+func executeAuditShow(t *testing.T, values map[string]interface{}, args ...string) string {
+	t.Helper()
+	out := new(bytes.Buffer)
+	auditShowCmd.SetOut(out)
+	auditShowCmd.Flags().VisitAll(func(f *pflag.Flag) { _ = f.Value.Set(f.DefValue) })
+	if err := auditShowCmd.ParseFlags(args); err != nil {
+		t.Fatalf("ParseFlags() error = %v", err)
+	}
+	useConfig(t, auditShowCmd, values)
+	if err := runAuditShow(auditShowCmd, nil); err != nil {
+		t.Fatalf("runAuditShow() error = %v", err)
+	}
+	return out.String()
+}
+
+func TestRecordAudit(t *testing.T) {
+	deploy, _ := setupAuditTest(t)
+	start := time.Now()
+
+	recordAudit(deploy, []string{"--log-level", "debug", "deploy", "--token", "s3cret"}, start, nil)
+	recordAudit(deploy, []string{"deploy", "--token=s3cret"}, start, errors.New("boom"))
+
+	l, err := auditLog(runContext(deploy))
+	if err != nil {
+		t.Fatalf("auditLog() error = %v", err)
+	}
+	entries, err := l.Entries()
+	if err != nil {
+		t.Fatalf("Entries() error = %v", err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("Expected 2 entries, got %d", len(entries))
+	}
+	if got := strings.Join(entries[0].Args, " "); got != "--log-level debug deploy --token "+audit.Masked {
+		t.Errorf("Args = %q, want the token masked", got)
+	}
+	if entries[0].Command != "deploy" || entries[0].ExitCode != 0 || entries[0].User == "" {
+		t.Errorf("Unexpected entry %+v", entries[0])
+	}
+	if entries[1].ExitCode != 1 || entries[1].Args[1] != "--token="+audit.Masked {
+		t.Errorf("Unexpected failed entry %+v", entries[1])
+	}
+}
+
+func TestRecordAudit_Disabled(t *testing.T) {
+	deploy, values := setupAuditTest(t)
+	values["app.audit.enabled"] = false
+	rc := useConfig(t, deploy, values)
+
+	recordAudit(deploy, []string{"deploy"}, time.Now(), nil)
+	l, _ := auditLog(rc)
+	if entries, _ := l.Entries(); len(entries) != 0 {
+		t.Errorf("Expected nothing recorded while disabled, got %d entries", len(entries))
+	}
+}
+
+func TestAuditShow(t *testing.T) {
+	deploy, values := setupAuditTest(t)
+	if out := executeAuditShow(t, values); !strings.Contains(out, "No audit entries recorded.") {
+		t.Errorf("Unexpected output for empty log %q", out)
+	}
+
+	for _, arg := range []string{"one", "two", "three"} {
+		recordAudit(deploy, []string{"deploy", arg}, time.Now(), nil)
+	}
+
+	out := executeAuditShow(t, values, "--limit", "2")
+	if strings.Contains(out, "deploy one") || !strings.Contains(out, "deploy two") {
+		t.Errorf("Expected only the 2 most recent entries, got %q", out)
+	}
+	if lines := strings.Count(out, "\n"); lines != 2 {
+		t.Errorf("Expected 2 lines, got %d: %q", lines, out)
+	}
+
+	values["app.output"] = "json"
+	var entries []audit.Entry
+	if err := json.Unmarshal([]byte(executeAuditShow(t, values, "--limit", "0")), &entries); err != nil {
+		t.Fatalf("Invalid JSON output: %v", err)
+	}
+	if len(entries) != 3 {
+		t.Errorf("Expected 3 entries with --limit 0, got %d", len(entries))
+	}
+}
+
+func TestRecordAudit_ExecuteWithArgs(t *testing.T) {
+	isolateExecute(t)
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	t.Setenv("APP_AUDIT_ENABLED", "true")
+	t.Setenv("APP_AUDIT_PATH", path)
+
+	var stdout, stderr bytes.Buffer
+	args := []string{"ping", "--message", "Audited", "--log-level", "error"}
+	if code, err := ExecuteWithArgs(context.Background(), args, strings.NewReader(""), &stdout, &stderr); code != 0 {
+		t.Fatalf("ExecuteWithArgs() = %d, %v\n%s", code, err, &stderr)
+	}
+
+	entries, err := (&audit.Log{Path: path}).Entries()
+	if err != nil {
+		t.Fatalf("Entries() error = %v", err)
+	}
+	if len(entries) != 1 {
+		t.Fatalf("Expected 1 entry, got %d", len(entries))
+	}
+	if got := strings.Join(entries[0].Args, " "); got != strings.Join(args, " ") {
+		t.Errorf("Args = %q, want the arguments given to ExecuteWithArgs, not those of the process", got)
+	}
+}
@@ -3,7 +3,12 @@
 package cmd
 
 import (
+	"github.com/peiman/ckeletin-go/internal/config"
+	"github.com/peiman/ckeletin-go/internal/runctx"
+	"github.com/peiman/ckeletin-go/internal/ui"
+	"github.com/rs/zerolog"
 	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
 )
 
 // completionCmd generates shell completion scripts.
@@ -31,3 +36,30 @@ PowerShell:
 func init() {
 	RootCmd.AddCommand(completionCmd)
 }
+
+// isCompletionRequest reports whether cmd is the hidden command the shell runs
+// on every tab press to ask for completions
+func isCompletionRequest(cmd *cobra.Command) bool {
+	if cmd == nil {
+		return false
+	}
+	switch cmd.Name() {
+	case cobra.ShellCompRequestCmd, cobra.ShellCompNoDescRequestCmd:
+		return true
+	}
+	return false
+}
+
+// startCompletion prepares a completion request. Completions are computed from
+// the command tree and the files they list, not from the config, so loading and
+// validating the config and setting up the logger are skipped to keep tab
+// presses fast: cmd gets a RunContext with the defaults. Logging is disabled,
+// as log lines on stderr would end up in the middle of the command line being
+// completed.
+func startCompletion(cmd *cobra.Command) {
+	cfg := viper.New()
+	config.ApplyDefaults(cfg)
+	cfg.Set("app.log_level", zerolog.Disabled.String())
+	rc := newRunContext(cmd, cfg, ui.Settings{})
+	cmd.SetContext(runctx.With(rc.Logger.WithContext(cmd.Context()), rc))
+}
@@ -0,0 +1,52 @@
+// cmd/completion_test.go
+
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+)
+
+func TestCompletionSkipsConfig(t *testing.T) {
+	isolateExecute(t)
+	// An invalid config fails every command that loads it
+	t.Setenv("APP_CONFIG_DIR", t.TempDir()+"/missing")
+	run := func(args ...string) (int, string, string) {
+		var stdout, stderr bytes.Buffer
+		code, _ := ExecuteWithArgs(context.Background(), args, strings.NewReader(""), &stdout, &stderr)
+		return code, stdout.String(), stderr.String()
+	}
+
+	if code, _, _ := run("ping"); code == 0 {
+		t.Fatal("ping succeeded with an invalid config dir, want a config error")
+	}
+	code, out, errOut := run(cobra.ShellCompRequestCmd, "pi")
+	if code != 0 || !strings.Contains(out, "ping") {
+		t.Errorf("__complete pi = %d, %q, %q, want the ping command", code, out, errOut)
+	}
+	if strings.Contains(errOut, `"level"`) {
+		t.Errorf("Completion wrote log lines: %q", errOut)
+	}
+}
+
+func TestIsCompletionRequest(t *testing.T) {
+	tests := []struct {
+		cmd  *cobra.Command
+		want bool
+	}{
+		{nil, false},
+		{&cobra.Command{Use: "ping"}, false},
+		{&cobra.Command{Use: "completion"}, false},
+		{&cobra.Command{Use: cobra.ShellCompRequestCmd}, true},
+		{&cobra.Command{Use: cobra.ShellCompNoDescRequestCmd}, true},
+	}
+	for _, tt := range tests {
+		if got := isCompletionRequest(tt.cmd); got != tt.want {
+			t.Errorf("isCompletionRequest(%v) = %v, want %v", tt.cmd, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,165 @@
+// cmd/config.go
+
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"strings"
+
+	"github.com/peiman/ckeletin-go/internal/config"
+	"github.com/peiman/ckeletin-go/internal/exitcode"
+	"github.com/peiman/ckeletin-go/internal/runctx"
+	"github.com/peiman/ckeletin-go/internal/xdg"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// configMigrations upgrade config files written for older versions of the app.
+// When a release renames or restructures keys, append a migration to the next
+// config version, e.g.
+//
+//	{Version: 2, Description: "rename app.log_level to app.log.level", Apply: func(s map[string]interface{}) error {
+//		config.RenameKey(s, "app.log_level", "app.log.level")
+//		return nil
+//	}},
+var configMigrations = config.Migrations{}
+
+var configCmd = &cobra.Command{
+	Use:         "config",
+	Short:       "Manage the config file",
+	Annotations: map[string]string{docsAnnotation: "config-migrate-command"},
+}
+
+var configMigrateCmd = &cobra.Command{
+	Use:   "migrate",
+	Short: "Upgrade the config file to the current format",
+	Long: `Upgrades a config file written for an older version of the app: keys that
+were renamed or restructured since are moved to their new place, and
+app.config_version is set to the current version.
+- The file is backed up to the backups directory in the state directory first.
+- Use --dry-run to list the migrations without changing the file.`,
+	Args: cobra.NoArgs,
+	RunE: runConfigMigrate,
+}
+
+func init() {
+	config.Register(config.Option{Key: "app.ignore_config_version", Default: false, Description: "Use a config file written for a newer version of the app"})
+	RootCmd.PersistentFlags().Bool("ignore-version", false, "Use a config file written for a newer version of the app, with a warning")
+	bindFlags(RootCmd.PersistentFlags(), map[string]string{
+		"ignore-version": "app.ignore_config_version",
+	})
+
+	addExamples(configMigrateCmd,
+		example{Desc: "List the migrations the config file needs", Line: "config migrate --dry-run"},
+		example{Desc: "Back up and upgrade the config file", Line: "config migrate"},
+	)
+	configCmd.AddCommand(configMigrateCmd)
+	RootCmd.AddCommand(configCmd)
+}
+
+func runConfigMigrate(cmd *cobra.Command, args []string) error {
+	out := cmd.OutOrStdout()
+	rc := runContext(cmd)
+	path, err := configFilePath(rc.Config)
+	if err != nil {
+		return err
+	}
+	if _, err := os.Stat(path); errors.Is(err, fs.ErrNotExist) {
+		fmt.Fprintf(out, "No config file at %s, nothing to migrate.\n", path)
+		return nil
+	}
+
+	v := viper.New()
+	v.SetConfigFile(path)
+	if err := v.ReadInConfig(); err != nil {
+		return &exitcode.ConfigError{Err: fmt.Errorf("failed to read config file: %w", err)}
+	}
+	settings := v.AllSettings()
+	from, err := config.SettingsVersion(settings)
+	if err != nil {
+		return &exitcode.ConfigError{Err: err}
+	}
+	latest := configMigrations.Latest()
+	switch {
+	case from > latest:
+		return &exitcode.ConfigError{Err: fmt.Errorf("%s has config version %d, newer than the version %d this binary supports", path, from, latest)}
+	case from == latest:
+		fmt.Fprintf(out, "%s is up to date (config version %d).\n", path, latest)
+		return nil
+	}
+
+	pending := configMigrations.Pending(from)
+	if dryRun(cmd) {
+		steps := make([]string, 0, len(pending))
+		for _, m := range pending {
+			steps = append(steps, m.Description)
+		}
+		return printPlanned(cmd, "migrate %s from config version %d to %d: %s", path, from, latest, strings.Join(steps, "; "))
+	}
+
+	backup, err := rc.Dirs.Backup(binaryName, path)
+	if err != nil {
+		return fmt.Errorf("failed to back up the config file: %w", err)
+	}
+	applied, err := configMigrations.Apply(settings)
+	if err != nil {
+		return &exitcode.ConfigError{Err: fmt.Errorf("%w; the config file was not changed", err)}
+	}
+	migrated := viper.New()
+	if err := migrated.MergeConfigMap(settings); err != nil {
+		return fmt.Errorf("failed to migrate config: %w", err)
+	}
+	if err := migrated.WriteConfigAs(path); err != nil {
+		return fmt.Errorf("failed to write config file: %w", err)
+	}
+	rc.Logger.Info().Str("config_file", path).Int("from", from).Int("to", latest).Str("backup", backup).Msg("Config file migrated")
+
+	fmt.Fprintf(out, "Migrated %s from config version %d to %d:\n", path, from, latest)
+	for _, m := range applied {
+		fmt.Fprintf(out, "  %d: %s\n", m.Version, m.Description)
+	}
+	fmt.Fprintf(out, "Backup: %s\n", backup)
+	return nil
+}
+
+// mergeIncludes merges the files included by the config file cfg loaded into
+// cfg, with ${xdg:...} in their paths in dirs
+func mergeIncludes(cfg *viper.Viper, dirs xdg.Dirs) error {
+	return config.MergeIncludes(cfg, config.Placeholders(dirs, binaryName))
+}
+
+// checkConfigVersion compares the format of the loaded config file with the
+// binary. A newer file is an error unless app.ignore_config_version is set,
+// as this binary could misread its settings; see warnConfigVersion.
+func checkConfigVersion(cfg *viper.Viper) error {
+	version, err := config.ParseVersion(cfg.Get(config.VersionKey))
+	if err != nil {
+		return err
+	}
+	latest := configMigrations.Latest()
+	if version > latest && !cfg.GetBool("app.ignore_config_version") {
+		return fmt.Errorf("%s has config version %d, but %s %s supports up to version %d; upgrade %s or pass --ignore-version to use it anyway",
+			cfg.ConfigFileUsed(), version, binaryName, Version, latest, binaryName)
+	}
+	return nil
+}
+
+// warnConfigVersion warns when the config file of rc has another format than
+// the binary. The settings of an older file may be ignored until it is
+// migrated, and those of a newer one may be misread.
+func warnConfigVersion(rc *runctx.RunContext) {
+	// The version was parsed when the config was loaded
+	version, _ := config.ParseVersion(rc.Config.Get(config.VersionKey))
+	latest := configMigrations.Latest()
+	switch {
+	case version < latest:
+		rc.Logger.Warn().Int("config_version", version).Int("latest", latest).
+			Msgf("The config file has an older format, run '%s config migrate' to upgrade it", binaryName)
+	case version > latest:
+		rc.Logger.Warn().Int("config_version", version).Int("latest", latest).
+			Msg("The config file was written for a newer version; settings may be misread")
+	}
+}
@@ -0,0 +1,172 @@
+// cmd/config_test.go
+
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/peiman/ckeletin-go/internal/config"
+	"github.com/peiman/ckeletin-go/internal/exitcode"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// setupConfigMigrateTest writes content to a temp config file and replaces the migrations
+func setupConfigMigrateTest(t *testing.T, content string, migrations config.Migrations) string {
+	t.Helper()
+	dir := t.TempDir()
+	t.Setenv("XDG_STATE_HOME", filepath.Join(dir, "state"))
+	path := filepath.Join(dir, "config.yaml")
+	if content != "" {
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	orig := configMigrations
+	configMigrations = migrations
+	t.Cleanup(func() { configMigrations = orig })
+	return path
+}
+
+var testMigrations = config.Migrations{
+	{Version: 3, Description: "rename app.greeting to app.ping.output_message", Apply: func(s map[string]interface{}) error {
+		config.RenameKey(s, "app.greeting", "app.ping.output_message")
+		return nil
+	}},
+	{Version: 2, Description: "drop app.legacy", Apply: func(s map[string]interface{}) error {
+		config.DeleteValue(s, "app.legacy")
+		return nil
+	}},
+}
+
+// runConfigMigrateTest runs config migrate on the config file at path with
+// values set in the config
+func runConfigMigrateTest(t *testing.T, path string, values map[string]interface{}) (string, error) {
+	t.Helper()
+	out := new(bytes.Buffer)
+	cmd := &cobra.Command{}
+	cmd.SetOut(out)
+	useConfig(t, cmd, values).Config.SetConfigFile(path)
+	err := runConfigMigrate(cmd, nil)
+	return out.String(), err
+}
+
+func TestRunConfigMigrate(t *testing.T) {
+	path := setupConfigMigrateTest(t, "app:\n  greeting: Hi\n  legacy: true\n  log_level: debug\n", testMigrations)
+
+	out, err := runConfigMigrateTest(t, path, nil)
+	if err != nil {
+		t.Fatalf("runConfigMigrate() error = %v", err)
+	}
+	for _, want := range []string{"from config version 1 to 3", "2: drop app.legacy", "3: rename app.greeting", "Backup: "} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output = %q, want it to contain %q", out, want)
+		}
+	}
+
+	v := viper.New()
+	v.SetConfigFile(path)
+	if err := v.ReadInConfig(); err != nil {
+		t.Fatal(err)
+	}
+	if got := v.GetString("app.ping.output_message"); got != "Hi" {
+		t.Errorf("app.ping.output_message = %q, want Hi", got)
+	}
+	if v.IsSet("app.greeting") || v.IsSet("app.legacy") {
+		t.Error("Expected the old keys to be removed")
+	}
+	if v.GetInt(config.VersionKey) != 3 || v.GetString("app.log_level") != "debug" {
+		t.Errorf("Unexpected migrated config %v", v.AllSettings())
+	}
+
+	backup := strings.TrimSpace(out[strings.Index(out, "Backup: ")+len("Backup: "):])
+	if data, err := os.ReadFile(backup); err != nil || !strings.Contains(string(data), "greeting: Hi") {
+		t.Errorf("backup %s = %q, %v, want the original file", backup, data, err)
+	}
+
+	out, err = runConfigMigrateTest(t, path, nil)
+	if err != nil || !strings.Contains(out, "is up to date (config version 3)") {
+		t.Errorf("second run = %q, %v, want up to date", out, err)
+	}
+}
+
+func TestRunConfigMigrate_DryRun(t *testing.T) {
+	content := "app:\n  greeting: Hi\n"
+	path := setupConfigMigrateTest(t, content, testMigrations)
+
+	out, err := runConfigMigrateTest(t, path, dryRunConfig)
+	if err != nil || !strings.Contains(out, "Would migrate "+path+" from config version 1 to 3: drop app.legacy; rename") {
+		t.Errorf("runConfigMigrate() = %q, %v", out, err)
+	}
+	if data, _ := os.ReadFile(path); string(data) != content {
+		t.Errorf("config file changed in a dry run: %q", data)
+	}
+}
+
+func TestRunConfigMigrate_Errors(t *testing.T) {
+	path := setupConfigMigrateTest(t, "", testMigrations)
+	if out, err := runConfigMigrateTest(t, path, nil); err != nil || !strings.Contains(out, "nothing to migrate") {
+		t.Errorf("without config file = %q, %v", out, err)
+	}
+
+	path = setupConfigMigrateTest(t, "app:\n  config_version: 7\n", testMigrations)
+	_, err := runConfigMigrateTest(t, path, nil)
+	var cfgErr *exitcode.ConfigError
+	if !errors.As(err, &cfgErr) || !strings.Contains(err.Error(), "newer than the version 3") {
+		t.Errorf("newer config error = %v, want ConfigError", err)
+	}
+
+	failing := config.Migrations{{Version: 2, Description: "fail", Apply: func(map[string]interface{}) error { return errors.New("boom") }}}
+	path = setupConfigMigrateTest(t, "app:\n  log_level: info\n", failing)
+	if _, err := runConfigMigrateTest(t, path, nil); err == nil || !strings.Contains(err.Error(), "boom") {
+		t.Errorf("failing migration error = %v", err)
+	}
+	if data, _ := os.ReadFile(path); string(data) != "app:\n  log_level: info\n" {
+		t.Errorf("config file changed by a failed migration: %q", data)
+	}
+}
+
+func TestCheckConfigVersion(t *testing.T) {
+	setupConfigMigrateTest(t, "", testMigrations)
+	v := viper.New()
+	v.Set(config.VersionKey, 2)
+	if err := checkConfigVersion(v); err != nil {
+		t.Errorf("checkConfigVersion() error = %v", err)
+	}
+	v.Set(config.VersionKey, "latest")
+	if err := checkConfigVersion(v); err == nil {
+		t.Error("Expected an error for an invalid config version")
+	}
+
+	v.Set(config.VersionKey, 4)
+	if err := checkConfigVersion(v); err == nil || !strings.Contains(err.Error(), "config version 4") || !strings.Contains(err.Error(), "--ignore-version") {
+		t.Errorf("checkConfigVersion() for a newer config error = %v", err)
+	}
+	v.Set("app.ignore_config_version", true)
+	if err := checkConfigVersion(v); err != nil {
+		t.Errorf("checkConfigVersion() with app.ignore_config_version error = %v", err)
+	}
+}
+
+func TestWriteConfigValues_NewFileVersion(t *testing.T) {
+	path := setupConfigMigrateTest(t, "", testMigrations)
+	cfg := viper.New()
+	cfg.SetConfigFile(path)
+	if _, err := writeConfigValues(cfg, map[string]interface{}{"app.ping.output_message": "Hi"}); err != nil {
+		t.Fatal(err)
+	}
+	v := viper.New()
+	v.SetConfigFile(path)
+	if err := v.ReadInConfig(); err != nil {
+		t.Fatal(err)
+	}
+	if got := v.GetInt(config.VersionKey); got != 3 {
+		t.Errorf("config_version of a new file = %d, want 3", got)
+	}
+}
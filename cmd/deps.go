@@ -0,0 +1,134 @@
+// cmd/deps.go
+
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os/exec"
+	"text/tabwriter"
+
+	"github.com/peiman/ckeletin-go/internal/config"
+	"github.com/peiman/ckeletin-go/internal/deps"
+	"github.com/peiman/ckeletin-go/internal/exitcode"
+	"github.com/peiman/ckeletin-go/internal/update"
+	"github.com/spf13/cobra"
+)
+
+// goListUpdates returns the output of `go list -m -u -json all`, can be replaced in tests
+var goListUpdates = func(ctx context.Context, stderr io.Writer) ([]byte, error) {
+	var out bytes.Buffer
+	c := exec.CommandContext(ctx, "go", "list", "-m", "-u", "-json", "all")
+	c.Stdout = &out
+	c.Stderr = stderr
+	if err := c.Run(); err != nil {
+		return nil, fmt.Errorf("go list failed: %w", err)
+	}
+	return out.Bytes(), nil
+}
+
+var depsCmd = &cobra.Command{
+	Use:         "deps",
+	Short:       "Inspect the project's Go module dependencies",
+	Annotations: map[string]string{docsAnnotation: "deps-command"},
+}
+
+var depsOutdatedCmd = &cobra.Command{
+	Use:   "outdated",
+	Short: "List dependencies with newer versions",
+	Long: `Lists the direct dependencies of the module in the current directory that have
+newer versions, using 'go list -m -u'. Each update is marked as major, minor
+or patch; major updates are likely to need code changes.
+- Use --all to include indirect dependencies.
+- Use --max-updates to exit with status 4 when more updates than that are pending,
+  e.g. in CI to keep updates from piling up.
+- Use --output json or yaml (or --format) for machine-readable output.`,
+	Args: cobra.NoArgs,
+	RunE: runDepsOutdated,
+}
+
+func init() {
+	initDepsConfig()
+	depsOutdatedCmd.Flags().Bool("all", false, "Include indirect dependencies")
+	depsOutdatedCmd.Flags().String("format", "", "Output format, overrides --output (text, json, yaml)")
+	configFlags(depsOutdatedCmd, "app.deps")
+
+	depsCmd.AddCommand(depsOutdatedCmd)
+	RootCmd.AddCommand(depsCmd)
+}
+
+func initDepsConfig() {
+	config.Register(config.Option{Key: "app.deps.max_updates", Flag: "max-updates", Default: 0, Description: "Fail deps outdated when more updates than this are available (0 disables)"})
+}
+
+func runDepsOutdated(cmd *cobra.Command, args []string) error {
+	all, _ := cmd.Flags().GetBool("all")
+	rc := runContext(cmd)
+	maxUpdates := rc.Config.GetInt("app.deps.max_updates")
+	if cmd.Flags().Changed("max-updates") {
+		maxUpdates, _ = cmd.Flags().GetInt("max-updates")
+	}
+	// Validate the format before the slow go list run
+	if _, err := outputFormat(cmd, ""); err != nil {
+		return err
+	}
+	if maxUpdates < 0 {
+		return fmt.Errorf("invalid max-updates %d: must not be negative", maxUpdates)
+	}
+
+	data, err := goListUpdates(cmd.Context(), cmd.ErrOrStderr())
+	if err != nil {
+		return err
+	}
+	updates, err := deps.Outdated(bytes.NewReader(data), all)
+	if err != nil {
+		return err
+	}
+
+	// An empty list rather than null in JSON and YAML output
+	report := outdatedReport{Updates: append([]deps.Update{}, updates...), Counts: deps.CountByKind(updates)}
+	if err := renderOutput(cmd, report); err != nil {
+		return err
+	}
+	rc.Logger.Debug().Int("updates", len(updates)).Msg("Dependency updates listed")
+
+	if maxUpdates > 0 && len(updates) > maxUpdates {
+		return &exitcode.CheckFailure{Err: fmt.Errorf("%d dependency updates available, more than the allowed %d", len(updates), maxUpdates)}
+	}
+	return nil
+}
+
+// outdatedReport is the result of deps outdated
+type outdatedReport struct {
+	Updates []deps.Update  `json:"updates"`
+	Counts  map[string]int `json:"counts"`
+}
+
+// WriteText prints the updates as a table followed by a summary
+func (r outdatedReport) WriteText(w io.Writer) error {
+	if len(r.Updates) == 0 {
+		_, err := fmt.Fprintln(w, "All dependencies are up to date")
+		return err
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "MODULE\tCURRENT\tLATEST\tUPDATE")
+	for _, u := range r.Updates {
+		kind := u.Kind
+		if kind == update.Major {
+			kind = "MAJOR"
+		}
+		if u.Indirect {
+			kind += " (indirect)"
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", u.Path, u.Current, u.Latest, kind)
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+	_, err := fmt.Fprintf(w, "\n%d updates available: %d major, %d minor, %d patch\n",
+		len(r.Updates), r.Counts[update.Major], r.Counts[update.Minor], r.Counts[update.Patch])
+	return err
+}
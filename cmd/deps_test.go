@@ -0,0 +1,81 @@
+// cmd/deps_test.go
+
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/peiman/ckeletin-go/internal/exitcode"
+)
+
+const depsListOutput = `{"Path": "example.com/app", "Main": true}
+{"Path": "github.com/spf13/cobra", "Version": "v1.8.1", "Update": {"Version": "v1.9.0"}}
+{"Path": "github.com/muesli/termenv", "Version": "v0.15.2", "Update": {"Version": "v1.0.0"}, "Indirect": true}
+`
+
+func TestRunDepsOutdated(t *testing.T) {
+	origList := goListUpdates
+	defer func() { goListUpdates = origList }()
+	goListUpdates = func(context.Context, io.Writer) ([]byte, error) {
+		return []byte(depsListOutput), nil
+	}
+
+	tests := []struct {
+		name     string
+		flags    map[string]string
+		config   map[string]interface{}
+		wantCode int
+		want     []string
+	}{
+		{name: "Direct only", want: []string{"github.com/spf13/cobra  v1.8.1", "1 updates available: 0 major, 1 minor, 0 patch"}},
+		{name: "All", flags: map[string]string{"all": "true"}, want: []string{"MAJOR (indirect)", "2 updates available: 1 major"}},
+		{name: "JSON", flags: map[string]string{"format": "json"}, want: []string{`"kind": "minor"`, `"counts": {`}},
+		{name: "Too many updates", flags: map[string]string{"all": "true", "max-updates": "1"}, wantCode: exitcode.CheckFailed},
+		{name: "Limit from config", config: map[string]interface{}{"app.deps.max_updates": 5}, want: []string{"1 updates available"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out := new(bytes.Buffer)
+			depsOutdatedCmd.SetOut(out)
+			useConfig(t, depsOutdatedCmd, tt.config)
+			defer func() {
+				depsOutdatedCmd.SetOut(nil)
+				for _, name := range []string{"all", "max-updates", "format"} {
+					f := depsOutdatedCmd.Flags().Lookup(name)
+					_ = f.Value.Set(f.DefValue)
+					f.Changed = false
+				}
+			}()
+			for name, value := range tt.flags {
+				if err := depsOutdatedCmd.Flags().Set(name, value); err != nil {
+					t.Fatalf("Failed to set flag: %v", err)
+				}
+			}
+
+			err := runDepsOutdated(depsOutdatedCmd, nil)
+			if code := exitcode.Code(err); code != tt.wantCode {
+				t.Fatalf("exit code = %d (%v), want %d", code, err, tt.wantCode)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(out.String(), want) {
+					t.Errorf("Output missing %q:\n%s", want, out.String())
+				}
+			}
+		})
+	}
+}
+
+func TestOutdatedReport_UpToDate(t *testing.T) {
+	var buf bytes.Buffer
+	if err := (outdatedReport{}).WriteText(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buf.String(), "up to date") {
+		t.Errorf("Unexpected output %q", buf.String())
+	}
+}
@@ -0,0 +1,154 @@
+// cmd/dev.go
+
+package cmd
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+
+	"github.com/peiman/ckeletin-go/internal/ui"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// devCmd groups commands that help while developing the CLI itself.
+var devCmd = &cobra.Command{
+	Use:         "dev",
+	Short:       "Developer utilities",
+	Annotations: map[string]string{docsAnnotation: "dev-config-watch-command"},
+	Long:        `Commands that help while developing and debugging the CLI itself.`,
+}
+
+var devConfigCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Inspect the effective configuration",
+}
+
+var devConfigWatchCmd = &cobra.Command{
+	Use:   "watch",
+	Short: "Print a live diff of the configuration whenever it changes",
+	Long: `Watches the config file in use and prints every key that was added, removed,
+or changed each time the file is saved. Values reflect the effective
+configuration (defaults, file, environment variables and flags combined).
+Press Ctrl-C to stop.`,
+	RunE: runDevConfigWatch,
+}
+
+func init() {
+	devConfigCmd.AddCommand(devConfigWatchCmd)
+	devCmd.AddCommand(devConfigCmd)
+	RootCmd.AddCommand(devCmd)
+}
+
+func runDevConfigWatch(cmd *cobra.Command, args []string) error {
+	cfg := runContext(cmd).Config
+	path := cfg.ConfigFileUsed()
+	if path == "" {
+		return fmt.Errorf("no config file in use, pass --config to choose one to watch")
+	}
+
+	return watchConfig(cmd, cfg, path)
+}
+
+// watchConfig prints diffs of cfg to the output of cmd whenever its config
+// file at path changes, until the context of cmd is cancelled. Events and
+// reloads are handled on the calling goroutine, one at a time.
+func watchConfig(cmd *cobra.Command, cfg *viper.Viper, path string) error {
+	watcher, err := watchFile(path)
+	if err != nil {
+		return err
+	}
+	defer watcher.Close()
+
+	prev := configSnapshot(cfg)
+	if _, err := fmt.Fprintf(cmd.OutOrStdout(), "Watching %s for changes (Ctrl-C to stop)\n", path); err != nil {
+		return fmt.Errorf("failed to write output: %w", err)
+	}
+
+	watcher.run(cmd.Context(), runContext(cmd).Logger, func() {
+		prev = printConfigChanges(cmd, path, prev)
+	})
+	return nil
+}
+
+// printConfigChanges reloads the config file at path for cmd and prints how
+// it differs from prev. It returns the new snapshot, or prev when the file
+// cannot be loaded.
+func printConfigChanges(cmd *cobra.Command, path string, prev map[string]interface{}) map[string]interface{} {
+	out, log := cmd.OutOrStdout(), runContext(cmd).Logger
+	next, err := newConfig(cmd, path)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to reload config")
+		return prev
+	}
+	curr := configSnapshot(next)
+	changes := diffConfig(prev, curr)
+	log.Debug().Str("file", path).Int("changes", len(changes)).Msg("Config file changed")
+	if len(changes) == 0 {
+		return curr
+	}
+
+	fmt.Fprintf(out, "%s changed:\n", path)
+	for _, c := range changes {
+		if err := ui.PrintColoredMessage(out, c.String(), c.color()); err != nil {
+			log.Error().Err(err).Str("key", c.Key).Msg("Failed to print config change")
+		}
+	}
+	return curr
+}
+
+// configSnapshot captures every effective value of cfg by its flattened key.
+func configSnapshot(cfg *viper.Viper) map[string]interface{} {
+	snapshot := make(map[string]interface{})
+	for _, key := range cfg.AllKeys() {
+		snapshot[key] = cfg.Get(key)
+	}
+	return snapshot
+}
+
+type configChange struct {
+	Key string
+	Old interface{}
+	New interface{}
+}
+
+func (c configChange) String() string {
+	switch {
+	case c.Old == nil:
+		return fmt.Sprintf("+ %s: %v", c.Key, c.New)
+	case c.New == nil:
+		return fmt.Sprintf("- %s: %v", c.Key, c.Old)
+	default:
+		return fmt.Sprintf("~ %s: %v -> %v", c.Key, c.Old, c.New)
+	}
+}
+
+func (c configChange) color() string {
+	switch {
+	case c.Old == nil:
+		return "green"
+	case c.New == nil:
+		return "red"
+	default:
+		return "yellow"
+	}
+}
+
+// diffConfig returns the changes between two snapshots, sorted by key.
+func diffConfig(prev, curr map[string]interface{}) []configChange {
+	var changes []configChange
+	for key, newVal := range curr {
+		oldVal, ok := prev[key]
+		if !ok || !reflect.DeepEqual(oldVal, newVal) {
+			changes = append(changes, configChange{Key: key, Old: oldVal, New: newVal})
+		}
+	}
+	for key, oldVal := range prev {
+		if _, ok := curr[key]; !ok {
+			changes = append(changes, configChange{Key: key, Old: oldVal})
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Key < changes[j].Key })
+	return changes
+}
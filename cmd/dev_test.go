@@ -0,0 +1,183 @@
+// cmd/dev_test.go
+
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// syncBuffer is a bytes.Buffer safe for use from the watcher goroutine.
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (s *syncBuffer) Write(p []byte) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.buf.Write(p)
+}
+
+func (s *syncBuffer) String() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.buf.String()
+}
+
+func TestDiffConfig(t *testing.T) {
+	prev := map[string]interface{}{
+		"app.log_level":           "info",
+		"app.ping.output_message": "Pong",
+		"app.ping.ui":             false,
+	}
+	curr := map[string]interface{}{
+		"app.log_level":         "debug",
+		"app.ping.output_color": "cyan",
+		"app.ping.ui":           false,
+	}
+
+	got := diffConfig(prev, curr)
+	want := []string{
+		"~ app.log_level: info -> debug",
+		"+ app.ping.output_color: cyan",
+		"- app.ping.output_message: Pong",
+	}
+
+	if len(got) != len(want) {
+		t.Fatalf("diffConfig() returned %d changes, want %d: %v", len(got), len(want), got)
+	}
+	for i, c := range got {
+		if c.String() != want[i] {
+			t.Errorf("change %d = %q, want %q", i, c.String(), want[i])
+		}
+	}
+}
+
+func TestDiffConfig_NoChanges(t *testing.T) {
+	snapshot := map[string]interface{}{"app.log_level": "info"}
+	if got := diffConfig(snapshot, snapshot); len(got) != 0 {
+		t.Errorf("diffConfig() = %v, want no changes", got)
+	}
+}
+
+func TestRunDevConfigWatch_NoConfigFile(t *testing.T) {
+	cmd := &cobra.Command{}
+	cmd.SetContext(context.Background())
+
+	err := runDevConfigWatch(cmd, nil)
+	if err == nil || !strings.Contains(err.Error(), "no config file in use") {
+		t.Errorf("Expected 'no config file in use' error, got %v", err)
+	}
+}
+
+// startWatch loads the config file at path with content and watches it until
+// the test ends
+func startWatch(t *testing.T, path, content string) *syncBuffer {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+	cfg := viper.New()
+	if err := initConfig(cfg, nil, path); err != nil {
+		t.Fatalf("initConfig() error = %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	out := &syncBuffer{}
+	cmd := &cobra.Command{Use: "watch"}
+	cmd.SetOut(out)
+	cmd.SetContext(ctx)
+	done := make(chan error, 1)
+	go func() { done <- watchConfig(cmd, cfg, path) }()
+	t.Cleanup(func() {
+		cancel()
+		if err := <-done; err != nil {
+			t.Errorf("watchConfig() error = %v", err)
+		}
+	})
+	// Give the watcher time to start before modifying the file.
+	time.Sleep(100 * time.Millisecond)
+	return out
+}
+
+// waitForOutput waits up to 3s for out to contain want
+func waitForOutput(out *syncBuffer, want string) bool {
+	deadline := time.Now().Add(3 * time.Second)
+	for !strings.Contains(out.String(), want) && time.Now().Before(deadline) {
+		time.Sleep(20 * time.Millisecond)
+	}
+	return strings.Contains(out.String(), want)
+}
+
+func TestWatchConfig_PrintsChanges(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	out := startWatch(t, path, "app:\n  log_level: info\n")
+
+	if err := os.WriteFile(path, []byte("app:\n  log_level: debug\n"), 0o600); err != nil {
+		t.Fatalf("Failed to update config: %v", err)
+	}
+	if !waitForOutput(out, "app.log_level: info -> debug") {
+		t.Errorf("Expected change to be printed, got %q", out.String())
+	}
+	if strings.Contains(out.String(), "- app.log_level") {
+		t.Errorf("Expected the truncate+write of one save to produce a single diff, got %q", out.String())
+	}
+}
+
+func TestWatchConfig_Placeholders(t *testing.T) {
+	t.Setenv("WATCH_GREETING", "hello")
+	t.Setenv("WATCH_OTHER", "bye")
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	out := startWatch(t, path, "app:\n  ping:\n    output_message: ${env:WATCH_GREETING}\n")
+
+	if err := os.WriteFile(path, []byte("app:\n  ping:\n    output_message: ${env:WATCH_OTHER}\n"), 0o600); err != nil {
+		t.Fatalf("Failed to update config: %v", err)
+	}
+	if !waitForOutput(out, "app.ping.output_message: hello -> bye") {
+		t.Errorf("Expected the edited placeholder to be expanded again, got %q", out.String())
+	}
+}
+
+func TestWatchConfig_Paths(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.yaml")
+	t.Setenv("XDG_STATE_HOME", dir)
+	out := startWatch(t, path, "app:\n  audit:\n    path: first.jsonl\n")
+
+	if err := os.WriteFile(path, []byte("app:\n  audit:\n    path: second.jsonl\n"), 0o600); err != nil {
+		t.Fatalf("Failed to update config: %v", err)
+	}
+	if !waitForOutput(out, "second.jsonl") {
+		t.Fatalf("Expected the edited path to take effect, got %q", out.String())
+	}
+	want := filepath.Join(dir, binaryName, "second.jsonl")
+	if !strings.Contains(out.String(), want) {
+		t.Errorf("Expected the edited path resolved to %s, got %q", want, out.String())
+	}
+}
+
+func TestWatchConfig_QuickSaves(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	out := startWatch(t, path, "app:\n  log_level: info\n")
+
+	// The second save arrives while the first one is being reloaded
+	for _, level := range []string{"debug", "warn"} {
+		if err := os.WriteFile(path, []byte("app:\n  log_level: "+level+"\n"), 0o600); err != nil {
+			t.Fatalf("Failed to update config: %v", err)
+		}
+		time.Sleep(configDebounce + 10*time.Millisecond)
+	}
+	if !waitForOutput(out, "app.log_level: debug -> warn") {
+		t.Errorf("Expected both saves to be printed, got %q", out.String())
+	}
+}
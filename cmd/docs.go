@@ -0,0 +1,217 @@
+// cmd/docs.go
+
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/peiman/ckeletin-go/internal/config"
+	"github.com/spf13/cobra"
+	"github.com/spf13/cobra/doc"
+	"github.com/spf13/viper"
+)
+
+// markdownFormat is accepted by docs commands in addition to the output formats
+const markdownFormat = "markdown"
+
+var docsCmd = &cobra.Command{
+	Use:         "docs",
+	Short:       "Print reference documentation",
+	Annotations: map[string]string{docsAnnotation: "docs-command"},
+}
+
+var docsEnvCmd = &cobra.Command{
+	Use:   "env",
+	Short: "List the environment variables for every config option",
+	Long: `Lists the environment variable of every registered config option with its
+type, default, description, and where the current value comes from: the
+environment, the config file, or the default.
+
+Variables are the config key in upper case with dots replaced by
+underscores, e.g. app.log_level is APP_LOG_LEVEL.
+- Use --format markdown for a table to paste into documentation; it leaves
+  out the source, which depends on the machine.
+- Use --output json or yaml (or --format) for machine-readable output.`,
+	Args: cobra.NoArgs,
+	RunE: runDocsEnv,
+}
+
+var docsManCmd = &cobra.Command{
+	Use:   "man DIR",
+	Short: "Write a man page for every command",
+	Long: `Writes a man page in section 1 for every command that is not hidden to DIR,
+creating it if needed, e.g. to ship with packages.`,
+	Args: cobra.ExactArgs(1),
+	RunE: runDocsMan,
+}
+
+var docsMarkdownCmd = &cobra.Command{
+	Use:   "markdown DIR",
+	Short: "Write a Markdown page for every command",
+	Long: `Writes a Markdown page for every command that is not hidden to DIR, creating
+it if needed, with links between parent and subcommands, e.g. for a docs site.`,
+	Args: cobra.ExactArgs(1),
+	RunE: runDocsMarkdown,
+}
+
+func init() {
+	docsEnvCmd.Flags().String("format", "", "Output format, overrides --output (text, json, yaml, markdown)")
+	addExamples(docsEnvCmd,
+		example{Desc: "List the environment variables with their type, default and source", Line: "docs env"},
+		example{Desc: "Print a Markdown table for the documentation", Line: "docs env --format markdown"},
+	)
+	addExamples(docsManCmd, example{Desc: "Write the man pages to ./man", Line: "docs man man"})
+	addExamples(docsMarkdownCmd, example{Desc: "Write the command reference to ./docs/cli", Line: "docs markdown docs/cli"})
+	docsCmd.AddCommand(docsEnvCmd, docsManCmd, docsMarkdownCmd)
+	RootCmd.AddCommand(docsCmd)
+}
+
+// envVar documents the environment variable of a config option
+type envVar struct {
+	Name        string `json:"name"`
+	Key         string `json:"key"`
+	Type        string `json:"type"`
+	Default     string `json:"default"`
+	Source      string `json:"source"`
+	Description string `json:"description,omitempty"`
+}
+
+// envVarList is the result of docs env
+type envVarList []envVar
+
+func runDocsEnv(cmd *cobra.Command, args []string) error {
+	vars := envVars(runContext(cmd).Config)
+	if format, _ := cmd.Flags().GetString("format"); format == markdownFormat {
+		var buf bytes.Buffer
+		if err := vars.WriteMarkdown(&buf); err != nil {
+			return err
+		}
+		return writeLong(cmd, buf.Bytes())
+	}
+	return renderOutput(cmd, vars)
+}
+
+func runDocsMan(cmd *cobra.Command, args []string) error {
+	header := &doc.GenManHeader{
+		Title:   strings.ToUpper(binaryName),
+		Section: "1",
+		Source:  binaryName + " " + Version,
+	}
+	return writeDocs(cmd, args[0], "man pages", func(root *cobra.Command, dir string) error {
+		return doc.GenManTree(root, header, dir)
+	})
+}
+
+func runDocsMarkdown(cmd *cobra.Command, args []string) error {
+	return writeDocs(cmd, args[0], "Markdown pages", doc.GenMarkdownTree)
+}
+
+// writeDocs generates the docs of all commands into dir with gen
+func writeDocs(cmd *cobra.Command, dir, what string, gen func(root *cobra.Command, dir string) error) error {
+	if dryRun(cmd) {
+		return printPlanned(cmd, "write %s to %s", what, dir)
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("failed to create %s: %w", dir, err)
+	}
+	root := cmd.Root()
+	// Without the generation date the pages only change when the commands do
+	root.DisableAutoGenTag = true
+	if err := gen(root, dir); err != nil {
+		return fmt.Errorf("failed to write %s: %w", what, err)
+	}
+	fmt.Fprintf(cmd.OutOrStdout(), "Wrote %s to %s\n", what, dir)
+	return nil
+}
+
+// envVars returns the environment variables of all registered options and
+// where their values in cfg come from
+func envVars(cfg *viper.Viper) envVarList {
+	opts := config.Options()
+	vars := make(envVarList, 0, len(opts))
+	for _, opt := range opts {
+		name := strings.ToUpper(envKeyReplacer.Replace(opt.Key))
+		source := "default"
+		if os.Getenv(name) != "" {
+			source = "env"
+		} else if cfg.InConfig(opt.Key) {
+			source = "config"
+		}
+		vars = append(vars, envVar{
+			Name:        name,
+			Key:         opt.Key,
+			Type:        optionType(opt),
+			Default:     formatDefault(opt.Default),
+			Source:      source,
+			Description: opt.Description,
+		})
+	}
+	return vars
+}
+
+// optionType returns the declared type of opt or the type of its default
+func optionType(opt config.Option) string {
+	if opt.Type != "" {
+		return string(opt.Type)
+	}
+	switch opt.Default.(type) {
+	case bool:
+		return "bool"
+	case int, int64:
+		return "int"
+	case float64:
+		return "float"
+	case time.Duration:
+		return string(config.TypeDuration)
+	case []string:
+		return "list"
+	}
+	return "string"
+}
+
+// formatDefault returns a default value the way it is written in a config file
+func formatDefault(v interface{}) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case []string:
+		return strings.Join(v, ",")
+	}
+	return fmt.Sprint(v)
+}
+
+// WriteText prints a table of the variables
+func (l envVarList) WriteText(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "VARIABLE\tTYPE\tDEFAULT\tSOURCE")
+	for _, v := range l {
+		def := v.Default
+		if def == "" {
+			def = "-"
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", v.Name, v.Type, def, v.Source)
+	}
+	return tw.Flush()
+}
+
+// WriteMarkdown prints the variables as a Markdown table
+func (l envVarList) WriteMarkdown(w io.Writer) error {
+	var b strings.Builder
+	b.WriteString("| Variable | Type | Default | Description |\n")
+	b.WriteString("|----------|------|---------|-------------|\n")
+	for _, v := range l {
+		def := ""
+		if v.Default != "" {
+			def = "`" + v.Default + "`"
+		}
+		fmt.Fprintf(&b, "| `%s` | %s | %s | %s |\n", v.Name, v.Type, def, strings.ReplaceAll(v.Description, "|", `\|`))
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
@@ -0,0 +1,125 @@
+// cmd/docs_test.go
+
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+)
+
+// runDocsEnvTest runs docs env in format with the config file at configFile
+func runDocsEnvTest(t *testing.T, format, configFile string) string {
+	t.Helper()
+	out := new(bytes.Buffer)
+	cmd := &cobra.Command{}
+	cmd.Flags().String("format", format, "")
+	_ = cmd.Flags().Set("format", format)
+	cmd.SetOut(out)
+	cfg, err := newConfig(cmd, configFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	attachConfig(t, cmd, cfg)
+	if err := runDocsEnv(cmd, nil); err != nil {
+		t.Fatalf("runDocsEnv() error = %v", err)
+	}
+	return out.String()
+}
+
+func TestRunDocsEnv(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte("app:\n  ping:\n    output_color: red\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("APP_PING_COUNT", "3")
+
+	var vars []envVar
+	if err := json.Unmarshal([]byte(runDocsEnvTest(t, "json", path)), &vars); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	byName := map[string]envVar{}
+	for _, v := range vars {
+		byName[v.Name] = v
+	}
+	want := map[string]envVar{
+		"APP_PING_COUNT":        {Key: "app.ping.count", Type: "int", Default: "1", Source: "env"},
+		"APP_PING_OUTPUT_COLOR": {Key: "app.ping.output_color", Type: "string", Default: "white", Source: "config"},
+		"APP_PING_INTERVAL":     {Key: "app.ping.interval", Type: "duration", Default: "1s", Source: "default"},
+		"APP_FETCH_MAX_SIZE":    {Key: "app.fetch.max_size", Type: "size", Default: "0", Source: "default"},
+		"APP_UI_TIMING":         {Key: "app.ui.timing", Type: "bool", Default: "false", Source: "default"},
+	}
+	for name, w := range want {
+		got := byName[name]
+		if got.Key != w.Key || got.Type != w.Type || got.Default != w.Default || got.Source != w.Source || got.Description == "" {
+			t.Errorf("%s = %+v, want %+v with a description", name, got, w)
+		}
+	}
+
+	text := runDocsEnvTest(t, "text", path)
+	if !strings.HasPrefix(text, "VARIABLE") || !strings.Contains(text, "APP_PING_COUNT") {
+		t.Errorf("text output = %q", text)
+	}
+
+	md := runDocsEnvTest(t, markdownFormat, path)
+	if !strings.HasPrefix(md, "| Variable | Type | Default | Description |") || !strings.Contains(md, "| `APP_PING_INTERVAL` | duration | `1s` | ") {
+		t.Errorf("markdown output = %q", md)
+	}
+	if strings.Contains(md, "| env |") {
+		t.Error("markdown output should not depend on the environment")
+	}
+}
+
+func TestRunDocsManAndMarkdown(t *testing.T) {
+	root := &cobra.Command{Use: binaryName}
+	child := &cobra.Command{Use: "child", Short: "A child", Run: func(*cobra.Command, []string) {}}
+	addExamples(child, example{Desc: "Run the child", Line: "child"})
+	t.Cleanup(func() { delete(commandExamples, child) })
+	root.AddCommand(child)
+
+	for _, tt := range []struct {
+		run  func(*cobra.Command, []string) error
+		file string
+	}{
+		{runDocsMan, binaryName + "-child.1"},
+		{runDocsMarkdown, binaryName + "_child.md"},
+	} {
+		dir := filepath.Join(t.TempDir(), "out")
+		out := new(bytes.Buffer)
+		child.SetOut(out)
+		child.SetContext(context.Background())
+		if err := tt.run(child, []string{dir}); err != nil {
+			t.Fatalf("error = %v", err)
+		}
+		data, err := os.ReadFile(filepath.Join(dir, tt.file))
+		if err != nil {
+			t.Fatalf("Expected %s: %v", tt.file, err)
+		}
+		if !strings.Contains(string(data), binaryName+" child") || !strings.Contains(string(data), "# Run the child") {
+			t.Errorf("%s does not contain the example:\n%s", tt.file, data)
+		}
+		if strings.Contains(string(data), "Auto generated") {
+			t.Errorf("%s contains the generation date", tt.file)
+		}
+		if !strings.Contains(out.String(), "Wrote ") {
+			t.Errorf("output = %q", out.String())
+		}
+	}
+
+	dir := filepath.Join(t.TempDir(), "dry")
+	out := new(bytes.Buffer)
+	child.SetOut(out)
+	useConfig(t, child, dryRunConfig)
+	if err := runDocsMan(child, []string{dir}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(dir); !os.IsNotExist(err) {
+		t.Error("docs man wrote files in a dry run")
+	}
+}
@@ -0,0 +1,32 @@
+// cmd/dryrun.go
+
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+// dryRun reports whether cmd should only show what it would change
+// (--dry-run or app.dry_run)
+func dryRun(cmd *cobra.Command) bool {
+	return runContext(cmd).DryRun
+}
+
+// printPlanned prints an action that was skipped because of --dry-run
+func printPlanned(cmd *cobra.Command, format string, args ...interface{}) error {
+	if _, err := fmt.Fprintf(runContext(cmd).Printer.Out, "Would "+format+" (dry run)\n", args...); err != nil {
+		return fmt.Errorf("failed to write output: %w", err)
+	}
+	return nil
+}
+
+// printPlannedConfig prints a config change that was skipped because of --dry-run
+func printPlannedConfig(cmd *cobra.Command, key string, value interface{}) error {
+	path, err := configFilePath(runContext(cmd).Config)
+	if err != nil {
+		return err
+	}
+	return printPlanned(cmd, "set %s to %v in %s", key, value, path)
+}
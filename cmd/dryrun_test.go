@@ -0,0 +1,29 @@
+// cmd/dryrun_test.go
+
+package cmd
+
+import (
+	"strings"
+	"testing"
+)
+
+// dryRunConfig turns on dry-run mode, see useConfig
+var dryRunConfig = map[string]interface{}{"app.dry_run": true}
+
+func TestDryRun_Update(t *testing.T) {
+	setupUpdateTest(t, "v1.2.0")
+	Version = "1.0.0"
+	origPath := executablePath
+	defer func() { executablePath = origPath }()
+	executablePath = func() (string, error) { return "/usr/local/bin/mycli", nil }
+	out, err := executeUpdate(t, map[string]interface{}{
+		"app.dry_run":           true,
+		"app.update.public_key": "RWQAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAA",
+	})
+	if err != nil {
+		t.Fatalf("runUpdate() error = %v", err)
+	}
+	if !strings.Contains(out, "Would replace /usr/local/bin/mycli with 1.2.0 (dry run)") {
+		t.Errorf("Unexpected dry run output %q", out)
+	}
+}
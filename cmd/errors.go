@@ -0,0 +1,182 @@
+// cmd/errors.go
+
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/peiman/ckeletin-go/internal/exitcode"
+	"github.com/peiman/ckeletin-go/internal/runctx"
+	"github.com/peiman/ckeletin-go/internal/ui"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// usageArgsAnnotation marks commands whose argument validation already reports usage errors
+const usageArgsAnnotation = "exitcode_usage_args"
+
+// markUsageErrors makes flag parsing and argument validation errors of root and
+// its subcommands usage errors, so they exit with exitcode.Usage. Unknown flags
+// get suggestions of similar flags.
+func markUsageErrors(root *cobra.Command) {
+	root.SetFlagErrorFunc(unknownFlagError)
+
+	var walk func(c *cobra.Command)
+	walk = func(c *cobra.Command) {
+		if c.Args != nil && c.Annotations[usageArgsAnnotation] == "" {
+			validate := c.Args
+			c.Args = func(cmd *cobra.Command, args []string) error {
+				if err := validate(cmd, args); err != nil {
+					return &exitcode.UsageError{Err: err}
+				}
+				return nil
+			}
+			if c.Annotations == nil {
+				c.Annotations = map[string]string{}
+			}
+			c.Annotations[usageArgsAnnotation] = "true"
+		}
+		for _, sub := range c.Commands() {
+			walk(sub)
+		}
+	}
+	walk(root)
+}
+
+// classifyError marks errors that cobra returns before running any command, such
+// as an unknown command, as usage errors. Other errors are returned unchanged.
+func classifyError(root, executed *cobra.Command, err error) error {
+	if err != nil && executed == root && !root.Runnable() && exitcode.Code(err) == exitcode.Failure {
+		return &exitcode.UsageError{Err: err}
+	}
+	return err
+}
+
+// docsBaseURL is the base of links to the README sections
+const docsBaseURL = "https://github.com/peiman/ckeletin-go#"
+
+// docsAnnotation names the README section documenting a command and its subcommands
+const docsAnnotation = "docs"
+
+// hintedError adds presentation details to a command error
+type hintedError struct {
+	err         error
+	suggestions []string
+	hint        string
+	docs        string
+	// ui are the display settings of the command that failed
+	ui ui.Settings
+}
+
+func (e *hintedError) Error() string { return e.err.Error() }
+func (e *hintedError) Unwrap() error { return e.err }
+
+// unknownFlagError suggests similarly named flags of cmd for an unknown long flag
+func unknownFlagError(cmd *cobra.Command, err error) error {
+	usageErr := &exitcode.UsageError{Err: err}
+	name, ok := strings.CutPrefix(err.Error(), "unknown flag: --")
+	if !ok {
+		return usageErr
+	}
+
+	var suggestions []string
+	cmd.Flags().VisitAll(func(f *pflag.Flag) {
+		if !f.Hidden && (strings.HasPrefix(f.Name, name) || levenshtein(f.Name, name) <= 2) {
+			suggestions = append(suggestions, "--"+f.Name)
+		}
+	})
+	return &hintedError{err: usageErr, suggestions: suggestions}
+}
+
+// addHints attaches suggestions, a usage hint and a docs link to err
+func addHints(root, executed *cobra.Command, err error) error {
+	if err == nil || executed == nil || exitcode.Code(err) == exitcode.Canceled {
+		return err
+	}
+	h := &hintedError{err: err}
+	if !errors.As(err, &h) {
+		err = h
+	}
+	if rc, ok := runctx.From(executed.Context()); ok {
+		h.ui = rc.UI
+	}
+
+	var configErr *exitcode.ConfigError
+	switch {
+	case errors.As(err, &configErr):
+		h.docs = "configuration"
+	case executed == root:
+		h.docs = "commands"
+	default:
+		h.docs = docsSection(executed)
+	}
+
+	if exitcode.Code(err) == exitcode.Usage {
+		var name string
+		if _, scanErr := fmt.Sscanf(err.Error(), "unknown command %q", &name); scanErr == nil && executed == root {
+			h.suggestions = root.SuggestionsFor(name)
+		}
+		h.hint = fmt.Sprintf("Run '%s --help' for usage.", executed.CommandPath())
+	}
+	return err
+}
+
+// docsSection returns the README section of cmd or its closest documented ancestor
+func docsSection(cmd *cobra.Command) string {
+	for c := cmd; c != nil; c = c.Parent() {
+		if section := c.Annotations[docsAnnotation]; section != "" {
+			return section
+		}
+	}
+	return ""
+}
+
+// PrintError presents err on w: a message with suggestions, a usage hint and a
+// docs link where known. Nothing is printed for interrupted commands, which
+// already announced the interrupt.
+func PrintError(w io.Writer, err error) {
+	if err == nil || exitcode.Code(err) == exitcode.Canceled {
+		return
+	}
+
+	// Cobra appends its own suggestions to unknown command errors; they are shown separately.
+	msg, _, _ := strings.Cut(err.Error(), "\n\nDid you mean this?")
+	box := ui.ErrorBox{Message: msg}
+	var settings ui.Settings
+	var h *hintedError
+	if errors.As(err, &h) {
+		settings = h.ui
+		box.Suggestions = h.suggestions
+		box.Hint = h.hint
+		if h.docs != "" {
+			box.DocsURL = docsBaseURL + h.docs
+		}
+	}
+	if printErr := ui.PrintErrorBox(w, settings, box); printErr != nil {
+		fmt.Fprintf(w, "Error: %v\n", err)
+	}
+}
+
+// levenshtein returns the edit distance between a and b
+func levenshtein(a, b string) int {
+	prev := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		curr := make([]int, len(b)+1)
+		curr[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev = curr
+	}
+	return prev[len(b)]
+}
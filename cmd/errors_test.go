@@ -0,0 +1,124 @@
+// cmd/errors_test.go
+
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/peiman/ckeletin-go/internal/exitcode"
+	"github.com/spf13/cobra"
+)
+
+func TestUsageErrors(t *testing.T) {
+	newRoot := func() *cobra.Command {
+		root := &cobra.Command{Use: "test", SilenceErrors: true, SilenceUsage: true}
+		root.SetOut(io.Discard)
+		root.AddCommand(
+			&cobra.Command{Use: "none", Args: cobra.NoArgs, RunE: func(*cobra.Command, []string) error { return nil }},
+			&cobra.Command{Use: "fail", RunE: func(*cobra.Command, []string) error { return fmt.Errorf("boom") }},
+			&cobra.Command{Use: "cancel", RunE: func(c *cobra.Command, _ []string) error { return context.Canceled }},
+		)
+		return root
+	}
+
+	tests := []struct {
+		name string
+		args []string
+		want int
+	}{
+		{"Success", []string{"none"}, exitcode.OK},
+		{"Unknown command", []string{"nope"}, exitcode.Usage},
+		{"Unknown flag", []string{"none", "--nope"}, exitcode.Usage},
+		{"Unexpected argument", []string{"none", "extra"}, exitcode.Usage},
+		{"Command failure", []string{"fail"}, exitcode.Failure},
+		{"Canceled", []string{"cancel"}, exitcode.Canceled},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := newRoot()
+			markUsageErrors(root)
+			// Marking twice must not wrap the validators again.
+			markUsageErrors(root)
+			root.SetArgs(tt.args)
+
+			executed, err := root.ExecuteC()
+			err = classifyError(root, executed, err)
+			if got := exitcode.Code(err); got != tt.want {
+				t.Errorf("exit code = %d (err %v), want %d", got, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestPrintError_Hints(t *testing.T) {
+	root := &cobra.Command{Use: "test", SilenceErrors: true, SilenceUsage: true}
+	root.SetOut(io.Discard)
+	ping := &cobra.Command{
+		Use:         "ping",
+		Annotations: map[string]string{docsAnnotation: "ping-command"},
+		RunE:        func(*cobra.Command, []string) error { return nil },
+	}
+	ping.Flags().String("message", "", "")
+	root.AddCommand(ping)
+	markUsageErrors(root)
+
+	tests := []struct {
+		name string
+		args []string
+		want []string
+	}{
+		{"Unknown command", []string{"pnig"}, []string{
+			"Error: unknown command \"pnig\" for \"test\"\n",
+			"Did you mean this? ping\n",
+			"Run 'test --help' for usage.\n",
+			"Docs: " + docsBaseURL + "commands\n",
+		}},
+		{"Unknown flag", []string{"ping", "--mesage", "x"}, []string{
+			"Error: unknown flag: --mesage\n",
+			"Did you mean this? --message\n",
+			"Run 'test ping --help' for usage.\n",
+			"Docs: " + docsBaseURL + "ping-command\n",
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root.SetArgs(tt.args)
+			executed, err := root.ExecuteC()
+			err = addHints(root, executed, classifyError(root, executed, err))
+			if exitcode.Code(err) != exitcode.Usage {
+				t.Errorf("Expected a usage error, got %v", err)
+			}
+
+			var buf bytes.Buffer
+			PrintError(&buf, err)
+			if got := buf.String(); got != strings.Join(tt.want, "") {
+				t.Errorf("PrintError() = %q, want %q", got, strings.Join(tt.want, ""))
+			}
+		})
+	}
+}
+
+func TestPrintError_Canceled(t *testing.T) {
+	var buf bytes.Buffer
+	PrintError(&buf, fmt.Errorf("ping: %w", context.Canceled))
+	PrintError(&buf, nil)
+	if buf.Len() != 0 {
+		t.Errorf("Expected no output, got %q", buf.String())
+	}
+}
+
+func TestLevenshtein(t *testing.T) {
+	if d := levenshtein("message", "mesage"); d != 1 {
+		t.Errorf("levenshtein() = %d, want 1", d)
+	}
+	if d := levenshtein("", "abc"); d != 3 {
+		t.Errorf("levenshtein() = %d, want 3", d)
+	}
+}
@@ -0,0 +1,38 @@
+// cmd/examples.go
+
+package cmd
+
+import (
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// example is a command line shown under Examples in the help, man pages and
+// Markdown docs of a command
+type example struct {
+	// Desc says what the example does
+	Desc string
+	// Line is the command line without the binary name. It is split on spaces
+	// when the examples test runs it, so it cannot use shell syntax.
+	Line string
+	// NoRun says why the examples test does not run the example, e.g. because
+	// it needs the network; empty means it runs and must succeed
+	NoRun string
+}
+
+// commandExamples are the examples of each command, for the examples test
+var commandExamples = map[*cobra.Command][]example{}
+
+// addExamples adds examples to cmd and renders them into cmd.Example
+func addExamples(cmd *cobra.Command, examples ...example) {
+	commandExamples[cmd] = append(commandExamples[cmd], examples...)
+	var b strings.Builder
+	for i, e := range commandExamples[cmd] {
+		if i > 0 {
+			b.WriteString("\n\n")
+		}
+		b.WriteString("  # " + e.Desc + "\n  " + binaryName + " " + e.Line)
+	}
+	cmd.Example = b.String()
+}
@@ -0,0 +1,63 @@
+// cmd/examples_test.go
+
+package cmd
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/peiman/ckeletin-go/internal/exitcode"
+	"github.com/peiman/ckeletin-go/pkg/clitest"
+	"github.com/spf13/cobra"
+)
+
+// TestMain lets tests start the test binary as the CLI with clitest
+func TestMain(m *testing.M) {
+	clitest.Main(m, func() int {
+		err := Execute()
+		PrintError(os.Stderr, err)
+		return exitcode.Code(err)
+	})
+}
+
+func TestAddExamples(t *testing.T) {
+	c := &cobra.Command{Use: "test"}
+	t.Cleanup(func() { delete(commandExamples, c) })
+
+	addExamples(c, example{Desc: "First", Line: "test --a"})
+	addExamples(c, example{Desc: "Second", Line: "test --b", NoRun: "reason"})
+
+	want := "  # First\n  " + binaryName + " test --a\n\n  # Second\n  " + binaryName + " test --b"
+	if c.Example != want {
+		t.Errorf("Example = %q, want %q", c.Example, want)
+	}
+	if len(commandExamples[c]) != 2 {
+		t.Errorf("commandExamples = %v, want both examples", commandExamples[c])
+	}
+}
+
+// TestExamples runs every example that can run offline in a clitest sandbox:
+// an empty working directory, home and XDG directories, and no APP_
+// environment variables. Each must exit with status 0.
+func TestExamples(t *testing.T) {
+	if testing.Short() {
+		t.Skip("runs the CLI in subprocesses")
+	}
+	for c, examples := range commandExamples {
+		if c.Root() != RootCmd {
+			continue
+		}
+		for _, e := range examples {
+			if e.NoRun != "" {
+				continue
+			}
+			t.Run(e.Line, func(t *testing.T) {
+				h := clitest.New(t)
+				// The sandbox may run as root in containers
+				h.Setenv("APP_ROOT_GUARD", "off")
+				h.RunExpect(strings.Fields(e.Line), 0)
+			})
+		}
+	}
+}
@@ -0,0 +1,102 @@
+// cmd/execute.go
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/peiman/ckeletin-go/internal/exitcode"
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+)
+
+// bindFlags binds the flags of fs to config keys, given as flag name to key:
+// every invocation binds them into its config, see bindConfigFlags. The
+// bindings are fixed at init, so a failure is a bug and exits.
+func bindFlags(fs *pflag.FlagSet, keys map[string]string) {
+	for name, key := range keys {
+		if err := fs.SetAnnotation(name, configKeyAnnotation, []string{key}); err != nil {
+			log.Fatal().Err(err).Str("flag", name).Msg("Failed to bind flag")
+		}
+	}
+}
+
+// bindConfigFlags binds the flags of cmd and its parents that are bound to a
+// config key into v, so a flag overrides the config file and environment
+// when it is set. Of flags bound to the same key, the one closest to cmd wins.
+func bindConfigFlags(v *viper.Viper, cmd *cobra.Command) error {
+	bound := map[string]bool{}
+	var err error
+	bind := func(f *pflag.Flag) {
+		keys := f.Annotations[configKeyAnnotation]
+		if err != nil || len(keys) != 1 || bound[keys[0]] {
+			return
+		}
+		bound[keys[0]] = true
+		if bindErr := v.BindPFlag(keys[0], f); bindErr != nil {
+			err = fmt.Errorf("failed to bind flag %s: %w", f.Name, bindErr)
+		}
+	}
+	for c := cmd; c != nil; c = c.Parent() {
+		c.Flags().VisitAll(bind)
+		c.PersistentFlags().VisitAll(bind)
+	}
+	return err
+}
+
+// ExecuteWithArgs runs the CLI with args in this process like the binary
+// would, with stdin, stdout and stderr in place of the process's, and returns
+// the exit code and the error, which has been printed to stderr already.
+// Every call runs a new App, so tests can run many invocations in one
+// process, also in parallel, without compiling the binary. Signals are not
+// handled; cancel ctx to interrupt the command.
+func ExecuteWithArgs(ctx context.Context, args []string, stdin io.Reader, stdout, stderr io.Writer) (int, error) {
+	a := NewApp(WithIO(stdin, stdout, stderr))
+	// A nil slice would make the App parse os.Args
+	err := a.Execute(ctx, append([]string{}, args...))
+	PrintError(stderr, err)
+	return exitcode.Code(err), err
+}
+
+// resetFlags sets the flags of c and its subcommands back to their defaults
+func resetFlags(c *cobra.Command) {
+	reset := func(f *pflag.Flag) {
+		if !f.Changed {
+			return
+		}
+		if s, ok := f.Value.(pflag.SliceValue); ok {
+			_ = s.Replace(sliceDefault(f.DefValue))
+		} else {
+			_ = f.Value.Set(f.DefValue)
+		}
+		f.Changed = false
+	}
+	c.Flags().VisitAll(reset)
+	c.PersistentFlags().VisitAll(reset)
+	for _, sub := range c.Commands() {
+		resetFlags(sub)
+	}
+}
+
+// setContexts sets the context of c and its subcommands to ctx. Cobra only
+// passes the root context on to commands without one.
+func setContexts(c *cobra.Command, ctx context.Context) {
+	c.SetContext(ctx)
+	for _, sub := range c.Commands() {
+		setContexts(sub, ctx)
+	}
+}
+
+// sliceDefault parses the default of a slice flag, e.g. "[a,b]"
+func sliceDefault(def string) []string {
+	def = strings.TrimSuffix(strings.TrimPrefix(def, "["), "]")
+	if def == "" {
+		return nil
+	}
+	return strings.Split(def, ",")
+}
@@ -0,0 +1,167 @@
+// cmd/execute_test.go
+
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/peiman/ckeletin-go/pkg/prompt"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// isolateExecute gives ExecuteWithArgs an empty home and state, like a new user
+func isolateExecute(t *testing.T) {
+	t.Helper()
+	dir := t.TempDir()
+	t.Setenv("HOME", dir)
+	t.Setenv("XDG_STATE_HOME", dir)
+	t.Setenv("XDG_CACHE_HOME", dir)
+	t.Setenv("XDG_DATA_HOME", dir)
+	t.Setenv("XDG_RUNTIME_DIR", dir)
+	t.Setenv("APP_ROOT_GUARD", "off")
+	t.Setenv("APP_UPDATE_NOTIFY", "false")
+	for _, name := range ciEnvVars {
+		t.Setenv(name, "")
+	}
+}
+
+func TestExecuteWithArgs(t *testing.T) {
+	isolateExecute(t)
+	run := func(args ...string) (int, string, string) {
+		var stdout, stderr bytes.Buffer
+		code, _ := ExecuteWithArgs(context.Background(), args, strings.NewReader(""), &stdout, &stderr)
+		return code, stdout.String(), stderr.String()
+	}
+
+	if code, out, errOut := run("ping", "--message", "Hello", "--log-level", "error"); code != 0 || !strings.Contains(out, "Hello") {
+		t.Fatalf("ping --message Hello = %d, %q, %q", code, out, errOut)
+	}
+	// Flags and config of the first run must not leak into the second
+	if code, out, _ := run("ping"); code != 0 || !strings.Contains(out, "Pong") || strings.Contains(out, "Hello") {
+		t.Errorf("ping = %d, %q, want the default message", code, out)
+	}
+
+	code, _, errOut := run("ping", "--nope")
+	if code != 2 || !strings.Contains(errOut, "unknown flag: --nope") {
+		t.Errorf("ping --nope = %d, %q, want a usage error", code, errOut)
+	}
+}
+
+func TestExecuteWithArgs_Parallel(t *testing.T) {
+	isolateExecute(t)
+	dir := t.TempDir()
+	for i := 0; i < 8; i++ {
+		message := fmt.Sprintf("Hello %d", i)
+		level := []string{"debug", "error"}[i%2]
+		// Each invocation has its own config directory, logger and settings
+		configDir := filepath.Join(dir, fmt.Sprint(i))
+		if err := os.Mkdir(configDir, 0o700); err != nil {
+			t.Fatal(err)
+		}
+		t.Run(message, func(t *testing.T) {
+			t.Parallel()
+			var stdout, stderr bytes.Buffer
+			args := []string{"ping", "--message", message, "--log-level", level, "--config-dir", configDir, "--count", "3", "--interval", "1ms"}
+			if code, err := ExecuteWithArgs(context.Background(), args, strings.NewReader(""), &stdout, &stderr); code != 0 {
+				t.Fatalf("ExecuteWithArgs() = %d, %v\n%s", code, err, &stderr)
+			}
+			if out := stdout.String(); !strings.HasPrefix(out, strings.Repeat(message+"\n", 3)+"\n--- ping statistics ---") {
+				t.Errorf("stdout = %q, want %q three times and the statistics", out, message)
+			}
+			logged := stderr.String()
+			if strings.Contains(logged, "Starting runPing execution") != (level == "debug") {
+				t.Errorf("stderr at level %s = %q, want the debug logs only at debug level", level, logged)
+			}
+			for j := 0; j < 8; j++ {
+				if other := fmt.Sprintf("Hello %d", j); other != message && strings.Contains(logged, other) {
+					t.Errorf("stderr = %q, want only the logs of this invocation", logged)
+				}
+			}
+		})
+	}
+}
+
+func TestExecuteWithArgs_Stdin(t *testing.T) {
+	isolateExecute(t)
+	ask := &cobra.Command{
+		Use: "ask",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			name, err := prompt.Input(cmd.Context(), "Name?", "")
+			if err != nil {
+				return err
+			}
+			_, err = fmt.Fprintf(cmd.OutOrStdout(), "Hello %s\n", name)
+			return err
+		},
+	}
+	RootCmd.AddCommand(ask)
+	t.Cleanup(func() { RootCmd.RemoveCommand(ask) })
+
+	var stdout, stderr bytes.Buffer
+	code, err := ExecuteWithArgs(context.Background(), []string{"ask"}, strings.NewReader("Ada\n"), &stdout, &stderr)
+	if code != 0 || err != nil {
+		t.Fatalf("ExecuteWithArgs() = %d, %v\n%s", code, err, &stderr)
+	}
+	if stdout.String() != "Hello Ada\n" {
+		t.Errorf("stdout = %q, want the answer read from stdin", stdout.String())
+	}
+	if !strings.Contains(stderr.String(), "Name?") {
+		t.Errorf("stderr = %q, want the question", stderr.String())
+	}
+}
+
+func TestSliceDefault(t *testing.T) {
+	tests := map[string][]string{"[]": nil, "[a]": {"a"}, "[a,b]": {"a", "b"}}
+	for def, want := range tests {
+		if got := sliceDefault(def); fmt.Sprint(got) != fmt.Sprint(want) || len(got) != len(want) {
+			t.Errorf("sliceDefault(%q) = %q, want %q", def, got, want)
+		}
+	}
+}
+
+func TestBindConfigFlags(t *testing.T) {
+	root := &cobra.Command{Use: "root"}
+	root.PersistentFlags().String("bind-message", "", "")
+	bindFlags(root.PersistentFlags(), map[string]string{"bind-message": "test.bind.message"})
+	sub := &cobra.Command{Use: "sub"}
+	sub.Flags().Int("bind-count", 0, "")
+	sub.Flags().String("sub-message", "", "")
+	bindFlags(sub.Flags(), map[string]string{"bind-count": "test.bind.count", "sub-message": "test.bind.message"})
+	root.AddCommand(sub)
+
+	if err := root.PersistentFlags().Parse([]string{"--bind-message", "root"}); err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if err := sub.Flags().Parse([]string{"--bind-count", "2"}); err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	cfg := viper.New()
+	if err := bindConfigFlags(cfg, sub); err != nil {
+		t.Fatalf("bindConfigFlags() error = %v", err)
+	}
+	if got := cfg.GetInt("test.bind.count"); got != 2 {
+		t.Errorf("test.bind.count = %d, want the flag value", got)
+	}
+	// The flag of sub is closer than the one of root, even though only root's was set
+	if got := cfg.GetString("test.bind.message"); got != "" {
+		t.Errorf("test.bind.message = %q, want the value of the flag of sub", got)
+	}
+
+	other := viper.New()
+	if err := bindConfigFlags(other, root); err != nil {
+		t.Fatalf("bindConfigFlags() error = %v", err)
+	}
+	if got := other.GetString("test.bind.message"); got != "root" {
+		t.Errorf("test.bind.message = %q, want the flag value", got)
+	}
+	if other.IsSet("test.bind.count") {
+		t.Error("Expected the flags of subcommands not to be bound")
+	}
+}
@@ -0,0 +1,225 @@
+// cmd/fetch.go
+
+package cmd
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/peiman/ckeletin-go/internal/config"
+	"github.com/peiman/ckeletin-go/internal/exitcode"
+	"github.com/peiman/ckeletin-go/internal/ui"
+	"github.com/peiman/ckeletin-go/pkg/httpclient"
+	"github.com/peiman/ckeletin-go/pkg/prompt"
+	"github.com/peiman/ckeletin-go/pkg/retry"
+	"github.com/rs/zerolog"
+	"github.com/spf13/cobra"
+)
+
+var (
+	// fetchRetryDelay is the base delay between attempts; it doubles on every retry.
+	fetchRetryDelay = 500 * time.Millisecond
+	// confirm asks before destructive actions, can be replaced in tests
+	confirm = prompt.Confirm
+)
+
+var fetchCmd = &cobra.Command{
+	Use:         "fetch URL",
+	Short:       "Download a URL over HTTP",
+	Annotations: map[string]string{docsAnnotation: "fetch-command"},
+	Long: `The fetch command demonstrates an HTTP client with retries and timeouts.
+- Writes the response body to stdout, or to a file with --output-file.
+- Shows download progress on stderr (disable with --progress=false).
+- Verifies the download with --sha256; a file that fails verification is not kept.
+- Asks before overwriting an existing file; use --yes to overwrite without asking.
+- Retries on network errors and 5xx/429 responses with exponential backoff.`,
+	Args: cobra.ExactArgs(1),
+	RunE: runFetch,
+}
+
+func init() {
+	fetchCmd.Flags().StringP("output-file", "O", "", "Write the download to this file instead of stdout")
+	fetchCmd.Flags().String("sha256", "", "Expected SHA-256 checksum (hex) of the download")
+
+	initFetchConfig()
+	configFlags(fetchCmd, "app.fetch")
+	addExamples(fetchCmd,
+		example{Desc: "Download a file and verify its checksum", Line: "fetch https://example.com/file.tar.gz -O file.tar.gz --sha256 <hex digest>", NoRun: "needs the network"},
+		example{Desc: "Print a download on stdout", Line: "fetch https://example.com/data.json", NoRun: "needs the network"},
+	)
+
+	RootCmd.AddCommand(fetchCmd)
+}
+
+func initFetchConfig() {
+	config.Register(
+		config.Option{Key: "app.fetch.timeout", Flag: "request-timeout", Default: 30 * time.Second, Type: config.TypeDuration, Description: "Timeout for each fetch attempt"},
+		config.Option{Key: "app.fetch.retries", Flag: "retries", Default: 3, Description: "Number of fetch retries on transient failures"},
+		config.Option{Key: "app.fetch.progress", Flag: "progress", Default: true, Description: "Show download progress on stderr"},
+		config.Option{Key: "app.fetch.max_size", Flag: "max-size", Default: 0, Type: config.TypeSize, Description: "Largest download fetch accepts, e.g. 100MB or 1GiB (0 for no limit)"},
+	)
+}
+
+type fetchOptions struct {
+	URL        string
+	OutputFile string
+	SHA256     string
+	Timeout    time.Duration
+	Retries    int
+	Progress   bool
+	MaxSize    int64
+}
+
+func runFetch(cmd *cobra.Command, args []string) error {
+	cfg := runContext(cmd).Config
+	opts := fetchOptions{
+		URL:      args[0],
+		Timeout:  cfg.GetDuration("app.fetch.timeout"),
+		Retries:  cfg.GetInt("app.fetch.retries"),
+		Progress: cfg.GetBool("app.fetch.progress"),
+		MaxSize:  config.GetSize(cfg, "app.fetch.max_size"),
+	}
+	if cmd.Flags().Changed("request-timeout") {
+		opts.Timeout, _ = cmd.Flags().GetDuration("request-timeout")
+	}
+	if cmd.Flags().Changed("retries") {
+		opts.Retries, _ = cmd.Flags().GetInt("retries")
+	}
+	if cmd.Flags().Changed("progress") {
+		opts.Progress, _ = cmd.Flags().GetBool("progress")
+	}
+	if cmd.Flags().Changed("max-size") {
+		opts.MaxSize = int64(*cmd.Flags().Lookup("max-size").Value.(*config.SizeValue))
+	}
+	opts.OutputFile, _ = cmd.Flags().GetString("output-file")
+	opts.SHA256, _ = cmd.Flags().GetString("sha256")
+
+	runContext(cmd).Logger.Debug().
+		Str("url", opts.URL).
+		Str("output_file", opts.OutputFile).
+		Dur("timeout", opts.Timeout).
+		Int("retries", opts.Retries).
+		Msg("Configuration loaded")
+
+	if opts.Retries < 0 {
+		return fmt.Errorf("invalid retries %d: must not be negative", opts.Retries)
+	}
+	if opts.MaxSize < 0 {
+		return fmt.Errorf("invalid max size %d: must not be negative", opts.MaxSize)
+	}
+
+	if opts.OutputFile != "" && dryRun(cmd) {
+		return printPlanned(cmd, "download %s to %s", opts.URL, opts.OutputFile)
+	}
+	if opts.OutputFile != "" {
+		if _, err := os.Stat(opts.OutputFile); err == nil {
+			ok, err := confirm(cmd.Context(), fmt.Sprintf("Overwrite %s?", opts.OutputFile), false)
+			if err != nil {
+				return fmt.Errorf("not overwriting %s: %w", opts.OutputFile, err)
+			}
+			if !ok {
+				return fmt.Errorf("not overwriting %s", opts.OutputFile)
+			}
+		}
+	}
+
+	return fetch(cmd.Context(), opts, cmd.OutOrStdout(), cmd.ErrOrStderr())
+}
+
+// fetch downloads opts.URL to opts.OutputFile (or stdout) and verifies the checksum if given.
+func fetch(ctx context.Context, opts fetchOptions, stdout, stderr io.Writer) error {
+	resp, err := get(ctx, opts, stderr)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if opts.MaxSize > 0 && resp.ContentLength > opts.MaxSize {
+		return fmt.Errorf("download of %s is %s, larger than the maximum of %s", opts.URL, config.FormatSize(resp.ContentLength), config.FormatSize(opts.MaxSize))
+	}
+
+	dst := stdout
+	var tmp *os.File
+	if opts.OutputFile != "" {
+		// Download next to the destination and rename on success, so a failed or
+		// unverified download never replaces an existing file.
+		tmp, err = os.CreateTemp(filepath.Dir(opts.OutputFile), "."+filepath.Base(opts.OutputFile)+".*")
+		if err != nil {
+			return fmt.Errorf("failed to create output file: %w", err)
+		}
+		defer os.Remove(tmp.Name())
+		defer tmp.Close()
+		dst = tmp
+	}
+
+	var body io.Reader = resp.Body
+	if opts.MaxSize > 0 {
+		// Read one byte past the limit to notice bodies without a Content-Length that exceed it
+		body = io.LimitReader(body, opts.MaxSize+1)
+	}
+	if opts.Progress {
+		progress := ui.NewProgressWriter(stderr, ui.SettingsFrom(ctx), resp.ContentLength)
+		body = io.TeeReader(body, progress)
+		defer progress.Done()
+	}
+
+	hash := sha256.New()
+	written, err := io.Copy(io.MultiWriter(dst, hash), body)
+	if err != nil {
+		return fmt.Errorf("failed to download %s: %w", opts.URL, err)
+	}
+	if opts.MaxSize > 0 && written > opts.MaxSize {
+		return fmt.Errorf("download of %s is larger than the maximum of %s", opts.URL, config.FormatSize(opts.MaxSize))
+	}
+
+	sum := hex.EncodeToString(hash.Sum(nil))
+	zerolog.Ctx(ctx).Info().Str("url", opts.URL).Int64("bytes", written).Str("sha256", sum).Msg("Download complete")
+
+	if opts.SHA256 != "" && !strings.EqualFold(sum, opts.SHA256) {
+		return &exitcode.CheckFailure{Err: fmt.Errorf("checksum mismatch: expected %s, got %s", strings.ToLower(opts.SHA256), sum)}
+	}
+
+	if tmp != nil {
+		if err := tmp.Close(); err != nil {
+			return fmt.Errorf("failed to write output file: %w", err)
+		}
+		if err := os.Rename(tmp.Name(), opts.OutputFile); err != nil {
+			return fmt.Errorf("failed to write output file: %w", err)
+		}
+	}
+	return nil
+}
+
+// get requests opts.URL with the app.http client settings and the fetch timeout
+// and retries; only a 2xx response is returned. With progress enabled, retries
+// are reported on stderr.
+func get(ctx context.Context, opts fetchOptions, stderr io.Writer) (*http.Response, error) {
+	o := httpOptions(contextConfig(ctx))
+	o.Timeout, o.Retries, o.RetryDelay = opts.Timeout, opts.Retries, fetchRetryDelay
+	if opts.Progress {
+		o.OnRetry = func(e retry.Event) {
+			fmt.Fprintf(stderr, "%v, retrying in %s (%d/%d)\n", e.Err, e.Delay.Round(time.Millisecond), e.Retry, e.Retries)
+		}
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, opts.URL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("invalid request: %w", err)
+	}
+	resp, err := httpclient.New(o).Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		resp.Body.Close()
+		return nil, fmt.Errorf("unexpected status: %s", resp.Status)
+	}
+	return resp, nil
+}
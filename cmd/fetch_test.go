@@ -0,0 +1,267 @@
+// cmd/fetch_test.go
+
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/peiman/ckeletin-go/pkg/prompt"
+	"github.com/spf13/cobra"
+)
+
+const fetchBody = "hello from the test server"
+
+func fetchBodySum() string {
+	sum := sha256.Sum256([]byte(fetchBody))
+	return hex.EncodeToString(sum[:])
+}
+
+func TestFetch_ToStdout(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(fetchBody))
+	}))
+	defer srv.Close()
+
+	stdout, stderr := new(bytes.Buffer), new(bytes.Buffer)
+	opts := fetchOptions{URL: srv.URL, Timeout: time.Second, Progress: true}
+	if err := fetch(context.Background(), opts, stdout, stderr); err != nil {
+		t.Fatalf("fetch() error = %v", err)
+	}
+
+	if stdout.String() != fetchBody {
+		t.Errorf("stdout = %q, want %q", stdout.String(), fetchBody)
+	}
+	if !strings.Contains(stderr.String(), "Downloaded") {
+		t.Errorf("Expected progress on stderr, got %q", stderr.String())
+	}
+}
+
+func TestFetch_MaxSize(t *testing.T) {
+	for _, chunked := range []bool{false, true} {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if chunked {
+				// Without a Content-Length the limit is only noticed while reading
+				w.(http.Flusher).Flush()
+			}
+			_, _ = w.Write([]byte(fetchBody))
+		}))
+
+		out := filepath.Join(t.TempDir(), "download")
+		opts := fetchOptions{URL: srv.URL, OutputFile: out, Timeout: time.Second, MaxSize: int64(len(fetchBody)) - 1}
+		err := fetch(context.Background(), opts, new(bytes.Buffer), new(bytes.Buffer))
+		if err == nil || !strings.Contains(err.Error(), "larger than the maximum") {
+			t.Errorf("fetch(chunked=%v) error = %v, want max size error", chunked, err)
+		}
+		if _, err := os.Stat(out); !os.IsNotExist(err) {
+			t.Errorf("fetch(chunked=%v) kept a download over the maximum size", chunked)
+		}
+
+		opts.MaxSize = int64(len(fetchBody))
+		if err := fetch(context.Background(), opts, new(bytes.Buffer), new(bytes.Buffer)); err != nil {
+			t.Errorf("fetch(chunked=%v) at the maximum size error = %v", chunked, err)
+		}
+		srv.Close()
+	}
+}
+
+func TestFetch_ToFileWithChecksum(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(fetchBody))
+	}))
+	defer srv.Close()
+
+	dir := t.TempDir()
+	tests := []struct {
+		name     string
+		sum      string
+		wantErr  bool
+		wantFile bool
+	}{
+		{"Matching checksum", strings.ToUpper(fetchBodySum()), false, true},
+		{"Mismatched checksum", strings.Repeat("0", 64), true, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out := filepath.Join(dir, strings.ReplaceAll(tt.name, " ", "_"))
+			opts := fetchOptions{URL: srv.URL, OutputFile: out, SHA256: tt.sum, Timeout: time.Second}
+
+			err := fetch(context.Background(), opts, new(bytes.Buffer), new(bytes.Buffer))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("fetch() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			data, readErr := os.ReadFile(out)
+			if tt.wantFile && string(data) != fetchBody {
+				t.Errorf("file content = %q, want %q (err %v)", data, fetchBody, readErr)
+			}
+			if !tt.wantFile && readErr == nil {
+				t.Errorf("Expected no file to be written on checksum mismatch")
+			}
+
+			entries, _ := os.ReadDir(dir)
+			for _, e := range entries {
+				if strings.HasPrefix(e.Name(), ".") {
+					t.Errorf("Temporary file %s was left behind", e.Name())
+				}
+			}
+		})
+	}
+}
+
+func TestGet(t *testing.T) {
+	origDelay := fetchRetryDelay
+	fetchRetryDelay = time.Millisecond
+	defer func() { fetchRetryDelay = origDelay }()
+
+	tests := []struct {
+		name         string
+		statuses     []int
+		retries      int
+		wantErr      string
+		wantAttempts int32
+	}{
+		{"Retries server errors", []int{500, 503, 200}, 3, "", 3},
+		{"Gives up after retries", []int{500, 500, 500}, 2, "unexpected status: 500", 3},
+		{"No retry on client error", []int{404, 200}, 3, "unexpected status: 404", 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var attempts int32
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				n := atomic.AddInt32(&attempts, 1)
+				w.WriteHeader(tt.statuses[n-1])
+			}))
+			defer srv.Close()
+
+			stderr := new(bytes.Buffer)
+			resp, err := get(context.Background(), fetchOptions{URL: srv.URL, Timeout: time.Second, Retries: tt.retries, Progress: true}, stderr)
+			if resp != nil {
+				resp.Body.Close()
+			}
+			if tt.wantErr == "" && err != nil || tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Errorf("get() error = %v, want %q", err, tt.wantErr)
+			}
+			if got := atomic.LoadInt32(&attempts); got != tt.wantAttempts {
+				t.Errorf("attempts = %d, want %d", got, tt.wantAttempts)
+			}
+			if got := strings.Count(stderr.String(), "retrying in"); got != int(tt.wantAttempts)-1 {
+				t.Errorf("Reported %d retries on stderr, want %d: %q", got, tt.wantAttempts-1, stderr.String())
+			}
+		})
+	}
+}
+
+func TestRunFetch_InvalidRetries(t *testing.T) {
+	fetchCmd.SetContext(context.Background())
+	if err := fetchCmd.Flags().Set("retries", "-1"); err != nil {
+		t.Fatalf("Failed to set flag: %v", err)
+	}
+	defer func() { _ = fetchCmd.Flags().Set("retries", "3") }()
+
+	err := runFetch(fetchCmd, []string{"http://127.0.0.1:0"})
+	if err == nil || !strings.Contains(err.Error(), "invalid retries") {
+		t.Errorf("Expected 'invalid retries' error, got %v", err)
+	}
+}
+
+func TestRunFetch_ConfirmOverwrite(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(fetchBody))
+	}))
+	defer srv.Close()
+
+	origConfirm := confirm
+	defer func() { confirm = origConfirm }()
+
+	path := filepath.Join(t.TempDir(), "existing.txt")
+	fetchCmd.SetContext(context.Background())
+	if err := fetchCmd.Flags().Set("output-file", path); err != nil {
+		t.Fatalf("Failed to set flag: %v", err)
+	}
+	_ = fetchCmd.Flags().Set("progress", "false")
+	defer func() {
+		_ = fetchCmd.Flags().Set("output-file", "")
+		_ = fetchCmd.Flags().Set("progress", "true")
+	}()
+
+	tests := []struct {
+		name    string
+		answer  bool
+		err     error
+		wantErr string
+		want    string
+	}{
+		{name: "Declined", answer: false, wantErr: "not overwriting", want: "old"},
+		{name: "Prompt unavailable", err: prompt.ErrNoInput, wantErr: "input required", want: "old"},
+		{name: "Confirmed", answer: true, want: fetchBody},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := os.WriteFile(path, []byte("old"), 0o600); err != nil {
+				t.Fatal(err)
+			}
+			var asked string
+			confirm = func(ctx context.Context, question string, def bool) (bool, error) {
+				asked = question
+				return tt.answer, tt.err
+			}
+
+			err := runFetch(fetchCmd, []string{srv.URL})
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("runFetch() error = %v, want %q", err, tt.wantErr)
+				}
+			} else if err != nil {
+				t.Errorf("runFetch() error = %v", err)
+			}
+			if !strings.Contains(asked, path) {
+				t.Errorf("Expected a question about %s, got %q", path, asked)
+			}
+			if got, _ := os.ReadFile(path); string(got) != tt.want {
+				t.Errorf("file = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDryRun_Fetch(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "out.txt")
+
+	c := &cobra.Command{Use: "fetch"}
+	c.Flags().AddFlagSet(fetchCmd.Flags())
+	out := new(bytes.Buffer)
+	c.SetOut(out)
+	useConfig(t, c, dryRunConfig)
+	if err := c.Flags().Set("output-file", path); err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		f := fetchCmd.Flags().Lookup("output-file")
+		_ = f.Value.Set("")
+		f.Changed = false
+	}()
+
+	if err := runFetch(c, []string{"http://127.0.0.1:0/file"}); err != nil {
+		t.Fatalf("runFetch() error = %v", err)
+	}
+	if !strings.Contains(out.String(), "Would download http://127.0.0.1:0/file to "+path) {
+		t.Errorf("Unexpected dry run output %q", out.String())
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("Dry run created %s", path)
+	}
+}
@@ -0,0 +1,101 @@
+// cmd/flags.go
+
+package cmd
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/peiman/ckeletin-go/internal/config"
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+const (
+	// configPrefixAnnotation holds the config prefix a command owns, see configFlags
+	configPrefixAnnotation = "config_prefix"
+	// configKeyAnnotation marks a flag generated from the config option of this key
+	configKeyAnnotation = "config_key"
+)
+
+func init() {
+	cobra.AddTemplateFunc("localFlagUsages", localFlagUsages)
+	RootCmd.SetUsageTemplate(strings.Replace(RootCmd.UsageTemplate(),
+		"{{.LocalFlags.FlagUsages | trimTrailingWhitespaces}}",
+		"{{localFlagUsages . | trimTrailingWhitespaces}}", 1))
+}
+
+// configFlags declares that cmd owns the config options under prefix, e.g.
+// "app.ping". Every registered option there with a Flag gets a flag on cmd of
+// the option's type, with its default and description, bound to its key.
+// Register the options first; help lists these flags under the prefix.
+func configFlags(cmd *cobra.Command, prefix string) {
+	addConfigFlags(cmd, prefix, config.Options())
+}
+
+// addConfigFlags adds the flags of the options in opts under prefix to cmd
+func addConfigFlags(cmd *cobra.Command, prefix string, opts []config.Option) {
+	if cmd.Annotations == nil {
+		cmd.Annotations = map[string]string{}
+	}
+	cmd.Annotations[configPrefixAnnotation] = prefix
+	for _, opt := range opts {
+		if opt.Flag == "" || !strings.HasPrefix(opt.Key, prefix+".") {
+			continue
+		}
+		if err := addConfigFlag(cmd.Flags(), opt); err != nil {
+			log.Fatal().Err(err).Str("key", opt.Key).Msg("Failed to generate flag")
+		}
+	}
+}
+
+// addConfigFlag defines the flag of opt in fs, typed after its Type or default
+func addConfigFlag(fs *pflag.FlagSet, opt config.Option) error {
+	name, usage := opt.Flag, opt.Description
+	if opt.Type == config.TypeSize {
+		size := new(config.SizeValue)
+		if err := size.Set(fmt.Sprint(opt.Default)); err != nil {
+			return fmt.Errorf("invalid default size: %w", err)
+		}
+		fs.Var(size, name, usage)
+	} else {
+		switch def := opt.Default.(type) {
+		case string:
+			fs.String(name, def, usage)
+		case bool:
+			fs.Bool(name, def, usage)
+		case int:
+			fs.Int(name, def, usage)
+		case float64:
+			fs.Float64(name, def, usage)
+		case time.Duration:
+			fs.Duration(name, def, usage)
+		case []string:
+			fs.StringSlice(name, def, usage)
+		default:
+			return fmt.Errorf("no flag type for a default of type %T", opt.Default)
+		}
+	}
+	return fs.SetAnnotation(name, configKeyAnnotation, []string{opt.Key})
+}
+
+// localFlagUsages returns the help of the local flags of cmd, with the flags
+// generated from its config prefix in a group of their own
+func localFlagUsages(cmd *cobra.Command) string {
+	prefix := cmd.Annotations[configPrefixAnnotation]
+	own, generated := pflag.NewFlagSet("", pflag.ContinueOnError), pflag.NewFlagSet("", pflag.ContinueOnError)
+	cmd.LocalFlags().VisitAll(func(f *pflag.Flag) {
+		if _, ok := f.Annotations[configKeyAnnotation]; ok && prefix != "" {
+			generated.AddFlag(f)
+			return
+		}
+		own.AddFlag(f)
+	})
+	usages := own.FlagUsages()
+	if groupUsages := generated.FlagUsages(); groupUsages != "" {
+		usages += fmt.Sprintf("\nSettings (%s.*, also in the config file and environment):\n%s", prefix, groupUsages)
+	}
+	return usages
+}
@@ -0,0 +1,77 @@
+// cmd/flags_test.go
+
+package cmd
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/peiman/ckeletin-go/internal/config"
+	"github.com/spf13/cobra"
+)
+
+func TestConfigFlags(t *testing.T) {
+	opts := []config.Option{
+		{Key: "test.flags.name", Flag: "name", Default: "x", Description: "Name"},
+		{Key: "test.flags.wait", Flag: "wait", Default: time.Second, Type: config.TypeDuration, Description: "Wait"},
+		{Key: "test.flags.limit", Flag: "limit", Default: "1MB", Type: config.TypeSize, Description: "Limit"},
+		{Key: "test.flags.tags", Flag: "tags", Default: []string{"a"}, Description: "Tags"},
+		{Key: "test.flags.hidden", Default: 1, Description: "Config file only"},
+		{Key: "test.flagsother.name", Flag: "other", Default: "y", Description: "Other command"},
+	}
+	cmd := &cobra.Command{Use: "flagtest", Run: func(*cobra.Command, []string) {}}
+	cmd.Flags().Bool("own", false, "Not a setting")
+	addConfigFlags(cmd, "test.flags", opts)
+
+	for name, want := range map[string]string{"name": "x", "wait": "1s", "limit": config.FormatSize(1000000), "tags": "[a]"} {
+		f := cmd.Flags().Lookup(name)
+		if f == nil || f.DefValue != want {
+			t.Errorf("Flag --%s = %+v, want default %q", name, f, want)
+			continue
+		}
+		if key := f.Annotations[configKeyAnnotation]; len(key) != 1 || key[0] != "test.flags."+name {
+			t.Errorf("Flag --%s is not bound to its key, annotations %v", name, f.Annotations)
+		}
+	}
+	if cmd.Flags().Lookup("other") != nil || cmd.Flags().Lookup("hidden") != nil {
+		t.Error("Expected flags only for options of the prefix that name one")
+	}
+
+	out := new(bytes.Buffer)
+	cmd.SetOut(out)
+	cmd.SetUsageTemplate(RootCmd.UsageTemplate())
+	if err := cmd.Usage(); err != nil {
+		t.Fatal(err)
+	}
+	help := out.String()
+	own, settings := strings.Index(help, "--own"), strings.Index(help, "Settings (test.flags.*")
+	if own < 0 || settings < own || strings.Index(help, "--name") < settings {
+		t.Errorf("Expected the generated flags grouped after the others:\n%s", help)
+	}
+}
+
+func TestConfigFlags_Owned(t *testing.T) {
+	prefixes := map[string]bool{}
+	var visit func(*cobra.Command)
+	visit = func(c *cobra.Command) {
+		if prefix, ok := c.Annotations[configPrefixAnnotation]; ok {
+			prefixes[prefix] = true
+		}
+		for _, sub := range c.Commands() {
+			visit(sub)
+		}
+	}
+	visit(RootCmd)
+
+	for _, opt := range config.Options() {
+		if opt.Flag == "" {
+			continue
+		}
+		prefix := opt.Key[:strings.LastIndex(opt.Key, ".")]
+		if !prefixes[prefix] {
+			t.Errorf("Option %s names flag --%s, but no command owns %s", opt.Key, opt.Flag, prefix)
+		}
+	}
+}
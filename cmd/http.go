@@ -0,0 +1,50 @@
+// cmd/http.go
+
+package cmd
+
+import (
+	"fmt"
+	"net/http"
+	"runtime"
+	"time"
+
+	"github.com/peiman/ckeletin-go/internal/config"
+	"github.com/peiman/ckeletin-go/pkg/httpclient"
+	"github.com/spf13/viper"
+)
+
+func init() {
+	initHTTPConfig()
+}
+
+func initHTTPConfig() {
+	config.Register(
+		config.Option{Key: "app.http.timeout", Default: 30 * time.Second, Type: config.TypeDuration, Description: "Timeout for each HTTP attempt, including reading the response"},
+		config.Option{Key: "app.http.retries", Default: 3, Description: "Retries of idempotent HTTP requests after network errors and 5xx/429 responses"},
+		config.Option{Key: "app.http.rate_limit", Default: 0.0, Description: "Maximum HTTP requests per second per client (0 for no limit)"},
+		config.Option{Key: "app.http.proxy", Default: "", Description: "Proxy URL (default uses HTTP_PROXY, HTTPS_PROXY and NO_PROXY)"},
+		config.Option{Key: "app.http.insecure_skip_verify", Default: false, Description: "Skip TLS certificate verification (for testing only)"},
+		config.Option{Key: "app.http.user_agent", Default: "", Description: "User-Agent header (default is <binary>/<version> (<os>/<arch>))"},
+	)
+}
+
+// httpOptions returns the HTTP client settings from the app.http keys in cfg
+func httpOptions(cfg *viper.Viper) httpclient.Options {
+	userAgent := cfg.GetString("app.http.user_agent")
+	if userAgent == "" {
+		userAgent = fmt.Sprintf("%s/%s (%s/%s)", binaryName, Version, runtime.GOOS, runtime.GOARCH)
+	}
+	return httpclient.Options{
+		Timeout:            cfg.GetDuration("app.http.timeout"),
+		Retries:            cfg.GetInt("app.http.retries"),
+		RateLimit:          cfg.GetFloat64("app.http.rate_limit"),
+		Proxy:              cfg.GetString("app.http.proxy"),
+		InsecureSkipVerify: cfg.GetBool("app.http.insecure_skip_verify"),
+		UserAgent:          userAgent,
+	}
+}
+
+// newHTTPClient returns a client configured from app.http in cfg
+func newHTTPClient(cfg *viper.Viper) *http.Client {
+	return httpclient.New(httpOptions(cfg))
+}
@@ -0,0 +1,55 @@
+// cmd/http_test.go
+
+package cmd
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/peiman/ckeletin-go/internal/config"
+	"github.com/spf13/viper"
+)
+
+func TestHTTPOptions(t *testing.T) {
+	cfg := viper.New()
+	config.ApplyDefaults(cfg)
+
+	o := httpOptions(cfg)
+	if o.Timeout != 30*time.Second || o.Retries != 3 || o.Proxy != "" || o.InsecureSkipVerify {
+		t.Errorf("Unexpected defaults %+v", o)
+	}
+	if !strings.HasPrefix(o.UserAgent, binaryName+"/"+Version+" (") {
+		t.Errorf("UserAgent = %q", o.UserAgent)
+	}
+
+	cfg.Set("app.http.timeout", "5s")
+	cfg.Set("app.http.retries", 0)
+	cfg.Set("app.http.proxy", "http://proxy:3128")
+	cfg.Set("app.http.user_agent", "custom/1.0")
+	o = httpOptions(cfg)
+	if o.Timeout != 5*time.Second || o.Retries != 0 || o.Proxy != "http://proxy:3128" || o.UserAgent != "custom/1.0" {
+		t.Errorf("Unexpected options %+v", o)
+	}
+}
+
+func TestNewHTTPClient(t *testing.T) {
+	cfg := viper.New()
+	config.ApplyDefaults(cfg)
+	var agent string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		agent = r.UserAgent()
+	}))
+	defer srv.Close()
+
+	resp, err := newHTTPClient(cfg).Get(srv.URL)
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	resp.Body.Close()
+	if !strings.HasPrefix(agent, binaryName+"/") {
+		t.Errorf("User-Agent = %q", agent)
+	}
+}
@@ -0,0 +1,47 @@
+// cmd/instance.go
+
+package cmd
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/peiman/ckeletin-go/internal/lock"
+	"github.com/peiman/ckeletin-go/internal/xdg"
+	"github.com/spf13/cobra"
+)
+
+// lockPath returns the lock file for name in dirs, can be replaced in tests
+var lockPath = func(dirs xdg.Dirs, name string) (string, error) {
+	return dirs.RuntimeFile(binaryName, name+".lock")
+}
+
+// WithSingleInstance wraps runE so that only one instance of the command named
+// name runs at a time for the current user. A second instance fails right away
+// and reports the PID of the running one.
+func WithSingleInstance(name string, runE func(cmd *cobra.Command, args []string) error) func(cmd *cobra.Command, args []string) error {
+	return func(cmd *cobra.Command, args []string) error {
+		rc := runContext(cmd)
+		path, err := lockPath(rc.Dirs, name)
+		if err != nil {
+			return err
+		}
+		l, err := lock.Acquire(path)
+		var held *lock.HeldError
+		if errors.As(err, &held) {
+			if held.PID != 0 {
+				return fmt.Errorf("another %s %s is already running (pid %d)", binaryName, name, held.PID)
+			}
+			return fmt.Errorf("another %s %s is already running", binaryName, name)
+		}
+		if err != nil {
+			return err
+		}
+		defer func() {
+			if err := l.Release(); err != nil {
+				rc.Logger.Debug().Err(err).Str("lock", path).Msg("Failed to release lock")
+			}
+		}()
+		return runE(cmd, args)
+	}
+}
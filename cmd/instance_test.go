@@ -0,0 +1,53 @@
+// cmd/instance_test.go
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/peiman/ckeletin-go/internal/xdg"
+	"github.com/spf13/cobra"
+)
+
+func TestWithSingleInstance(t *testing.T) {
+	dir := t.TempDir()
+	origLockPath := lockPath
+	defer func() { lockPath = origLockPath }()
+	lockPath = func(_ xdg.Dirs, name string) (string, error) {
+		return filepath.Join(dir, name+".lock"), nil
+	}
+
+	var nested error
+	inner := WithSingleInstance("update", func(cmd *cobra.Command, args []string) error {
+		return nil
+	})
+	outer := WithSingleInstance("update", func(cmd *cobra.Command, args []string) error {
+		nested = inner(cmd, args)
+		return nil
+	})
+
+	if err := outer(&cobra.Command{}, nil); err != nil {
+		t.Fatalf("outer() error = %v", err)
+	}
+	want := fmt.Sprintf("another %s update is already running (pid %d)", binaryName, os.Getpid())
+	if nested == nil || nested.Error() != want {
+		t.Errorf("Nested run error = %v, want %q", nested, want)
+	}
+
+	// The lock is released when the command returns
+	if err := inner(&cobra.Command{}, nil); err != nil {
+		t.Errorf("Run after release error = %v", err)
+	}
+
+	// Other commands use their own lock
+	other := WithSingleInstance("serve", func(cmd *cobra.Command, args []string) error { return nil })
+	outer = WithSingleInstance("update", func(cmd *cobra.Command, args []string) error {
+		return other(cmd, args)
+	})
+	if err := outer(&cobra.Command{}, nil); err != nil {
+		t.Errorf("Unexpected conflict between commands: %v", err)
+	}
+}
@@ -0,0 +1,229 @@
+// cmd/jobs.go
+
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/peiman/ckeletin-go/internal/exitcode"
+	"github.com/peiman/ckeletin-go/internal/jobs"
+	"github.com/peiman/ckeletin-go/internal/xdg"
+	"github.com/spf13/cobra"
+)
+
+// jobsDir returns the directory holding background jobs in dirs, can be replaced in tests
+var jobsDir = func(dirs xdg.Dirs) (string, error) {
+	dir, err := dirs.StateDir(binaryName)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "jobs"), nil
+}
+
+// startJob runs this binary with args as a background job, can be replaced in tests
+var startJob = func(m *jobs.Manager, args []string) (*jobs.Job, error) {
+	exe, err := os.Executable()
+	if err != nil {
+		return nil, fmt.Errorf("failed to locate executable: %w", err)
+	}
+	return m.Start(exe, args)
+}
+
+var jobsCmd = &cobra.Command{
+	Use:         "jobs",
+	Short:       "Manage commands running in the background",
+	Annotations: map[string]string{docsAnnotation: "jobs-command"},
+	Long: `Commands started with --background run detached from the terminal. Their
+status and output are kept in the state directory until removed.`,
+}
+
+var jobsListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List background jobs, most recent first",
+	Args:  cobra.NoArgs,
+	RunE:  runJobsList,
+}
+
+var jobsStatusCmd = &cobra.Command{
+	Use:   "status ID",
+	Short: "Show the status of a background job",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runJobsStatus,
+}
+
+var jobsLogsCmd = &cobra.Command{
+	Use:   "logs ID",
+	Short: "Print the output of a background job",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runJobsLogs,
+}
+
+var jobsCancelCmd = &cobra.Command{
+	Use:   "cancel ID",
+	Short: "Stop a running background job",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runJobsCancel,
+}
+
+func init() {
+	jobsCmd.AddCommand(jobsListCmd, jobsStatusCmd, jobsLogsCmd, jobsCancelCmd)
+	RootCmd.AddCommand(jobsCmd)
+	afterExecute = append(afterExecute, finishJob)
+}
+
+// jobsManager returns the manager of the background jobs of cmd
+func jobsManager(cmd *cobra.Command) (*jobs.Manager, error) {
+	dir, err := jobsDir(runContext(cmd).Dirs)
+	if err != nil {
+		return nil, err
+	}
+	return &jobs.Manager{Dir: dir}, nil
+}
+
+// runInBackground starts the current invocation again as a background job,
+// without the --background flag, and prints its ID
+func runInBackground(cmd *cobra.Command) error {
+	m, err := jobsManager(cmd)
+	if err != nil {
+		return err
+	}
+	job, err := startJob(m, backgroundArgs(os.Args[1:]))
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(cmd.OutOrStdout(), "Started job %s\nFollow it with: %s jobs status %s\n", job.ID, binaryName, job.ID)
+	return err
+}
+
+// backgroundArgs returns args without the --background flag
+func backgroundArgs(args []string) []string {
+	var out []string
+	for i, arg := range args {
+		if arg == "--" {
+			return append(out, args[i:]...)
+		}
+		if arg == "--background" || strings.HasPrefix(arg, "--background=") {
+			continue
+		}
+		out = append(out, arg)
+	}
+	return out
+}
+
+// finishJob records the exit code when this process is a background job
+func finishJob(cmd *cobra.Command, start time.Time, err error) {
+	id := os.Getenv(jobs.EnvID)
+	if id == "" {
+		return
+	}
+	rc := runContext(cmd)
+	dir, mErr := jobsDir(rc.Dirs)
+	if mErr == nil {
+		mErr = (&jobs.Manager{Dir: dir}).Finish(id, exitcode.Code(err))
+	}
+	if mErr != nil {
+		rc.Logger.Warn().Err(mErr).Str("job", id).Msg("Failed to record job result")
+	}
+}
+
+func runJobsList(cmd *cobra.Command, args []string) error {
+	m, err := jobsManager(cmd)
+	if err != nil {
+		return err
+	}
+	list, err := m.List()
+	if err != nil {
+		return err
+	}
+	if list == nil {
+		list = []*jobs.Job{}
+	}
+	return renderOutput(cmd, jobList(list))
+}
+
+func runJobsStatus(cmd *cobra.Command, args []string) error {
+	m, err := jobsManager(cmd)
+	if err != nil {
+		return err
+	}
+	job, err := m.Get(args[0])
+	if err != nil {
+		return err
+	}
+	return renderOutput(cmd, jobStatus{job})
+}
+
+func runJobsLogs(cmd *cobra.Command, args []string) error {
+	m, err := jobsManager(cmd)
+	if err != nil {
+		return err
+	}
+	if _, err := m.Get(args[0]); err != nil {
+		return err
+	}
+	data, err := os.ReadFile(m.LogPath(args[0]))
+	if err != nil {
+		return fmt.Errorf("failed to read job output: %w", err)
+	}
+	return writeLong(cmd, data)
+}
+
+func runJobsCancel(cmd *cobra.Command, args []string) error {
+	m, err := jobsManager(cmd)
+	if err != nil {
+		return err
+	}
+	if dryRun(cmd) {
+		if _, err := m.Get(args[0]); err != nil {
+			return err
+		}
+		return printPlanned(cmd, "cancel job %s", args[0])
+	}
+	if err := m.Cancel(args[0]); err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(cmd.OutOrStdout(), "Canceled job %s\n", args[0])
+	return err
+}
+
+// jobList is the result of jobs list
+type jobList []*jobs.Job
+
+// WriteText prints a table of the jobs
+func (l jobList) WriteText(w io.Writer) error {
+	if len(l) == 0 {
+		_, err := fmt.Fprintln(w, "No background jobs.")
+		return err
+	}
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "ID\tSTATUS\tSTARTED\tCOMMAND")
+	for _, j := range l {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s %s\n", j.ID, j.Status, j.Started.Local().Format(time.DateTime), binaryName, strings.Join(j.Args, " "))
+	}
+	return tw.Flush()
+}
+
+// jobStatus is the result of jobs status
+type jobStatus struct {
+	*jobs.Job
+}
+
+// WriteText prints the job details
+func (s jobStatus) WriteText(w io.Writer) error {
+	fmt.Fprintf(w, "Job:       %s\nCommand:   %s %s\nDirectory: %s\nStatus:    %s\nStarted:   %s\n",
+		s.ID, binaryName, strings.Join(s.Args, " "), s.Dir, s.Status, s.Started.Local().Format(time.DateTime))
+	if s.Finished != nil {
+		fmt.Fprintf(w, "Finished:  %s (%s)\n", s.Finished.Local().Format(time.DateTime), s.Finished.Sub(s.Started).Round(time.Second))
+	}
+	if s.ExitCode != nil {
+		fmt.Fprintf(w, "Exit code: %d\n", *s.ExitCode)
+	}
+	_, err := fmt.Fprintf(w, "Output:    %s jobs logs %s\n", binaryName, s.ID)
+	return err
+}
@@ -0,0 +1,125 @@
+// cmd/jobs_test.go
+
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/peiman/ckeletin-go/internal/exitcode"
+	"github.com/peiman/ckeletin-go/internal/jobs"
+	"github.com/peiman/ckeletin-go/internal/xdg"
+)
+
+// useJobsDir points the jobs commands at a temporary directory
+func useJobsDir(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	orig := jobsDir
+	jobsDir = func(xdg.Dirs) (string, error) { return dir, nil }
+	t.Cleanup(func() { jobsDir = orig })
+	return dir
+}
+
+func TestBackgroundArgs(t *testing.T) {
+	got := backgroundArgs([]string{"dev", "vuln", "--background", "--input=x", "--background=true", "--", "--background"})
+	want := []string{"dev", "vuln", "--input=x", "--", "--background"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("backgroundArgs() = %v, want %v", got, want)
+	}
+}
+
+func TestJobsCommands(t *testing.T) {
+	dir := useJobsDir(t)
+	jobDir := filepath.Join(dir, "abcd1234")
+	if err := os.MkdirAll(jobDir, 0o700); err != nil {
+		t.Fatal(err)
+	}
+	started := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	write := func(name string, v interface{}) {
+		data, _ := json.Marshal(v)
+		if err := os.WriteFile(filepath.Join(jobDir, name), data, 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("job.json", jobs.Job{ID: "abcd1234", Args: []string{"dev", "vuln"}, Dir: "/src", PID: -1, Started: started})
+	write("result.json", map[string]interface{}{"exit_code": 4, "finished": started.Add(90 * time.Second)})
+	if err := os.WriteFile(filepath.Join(jobDir, "output.log"), []byte("GO-2024-0001 found\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	run := func(c func() error) string {
+		t.Helper()
+		out := new(bytes.Buffer)
+		for _, sub := range jobsCmd.Commands() {
+			sub.SetOut(out)
+			defer sub.SetOut(nil)
+		}
+		if err := c(); err != nil {
+			t.Fatalf("error = %v", err)
+		}
+		return out.String()
+	}
+
+	list := run(func() error { return runJobsList(jobsListCmd, nil) })
+	if !strings.Contains(list, "abcd1234  failed") || !strings.Contains(list, binaryName+" dev vuln") {
+		t.Errorf("jobs list = %q", list)
+	}
+	status := run(func() error { return runJobsStatus(jobsStatusCmd, []string{"abcd1234"}) })
+	for _, want := range []string{"Status:    failed", "Exit code: 4", "(1m30s)"} {
+		if !strings.Contains(status, want) {
+			t.Errorf("jobs status missing %q:\n%s", want, status)
+		}
+	}
+	if logs := run(func() error { return runJobsLogs(jobsLogsCmd, []string{"abcd1234"}) }); logs != "GO-2024-0001 found\n" {
+		t.Errorf("jobs logs = %q", logs)
+	}
+	if err := runJobsCancel(jobsCancelCmd, []string{"abcd1234"}); err == nil || !strings.Contains(err.Error(), "not running") {
+		t.Errorf("Expected not running error, got %v", err)
+	}
+	if err := runJobsStatus(jobsStatusCmd, []string{"0badc0de"}); err == nil || !strings.Contains(err.Error(), "not found") {
+		t.Errorf("Expected not found error, got %v", err)
+	}
+	if err := runJobsLogs(jobsLogsCmd, []string{"../.."}); err == nil || !strings.Contains(err.Error(), "invalid job ID") {
+		t.Errorf("Expected invalid job ID error, got %v", err)
+	}
+}
+
+func TestJobsList_Empty(t *testing.T) {
+	useJobsDir(t)
+	out := new(bytes.Buffer)
+	jobsListCmd.SetOut(out)
+	defer jobsListCmd.SetOut(nil)
+	if err := runJobsList(jobsListCmd, nil); err != nil {
+		t.Fatalf("runJobsList() error = %v", err)
+	}
+	if out.String() != "No background jobs.\n" {
+		t.Errorf("Unexpected output %q", out.String())
+	}
+}
+
+func TestFinishJob(t *testing.T) {
+	dir := useJobsDir(t)
+	if err := os.MkdirAll(filepath.Join(dir, "abcd1234"), 0o700); err != nil {
+		t.Fatal(err)
+	}
+
+	finishJob(jobsCmd, time.Now(), errors.New("boom"))
+	if _, err := os.Stat(filepath.Join(dir, "abcd1234", "result.json")); !os.IsNotExist(err) {
+		t.Fatal("Expected no result outside a background job")
+	}
+
+	t.Setenv(jobs.EnvID, "abcd1234")
+	finishJob(jobsCmd, time.Now(), &exitcode.CheckFailure{Err: errors.New("vulnerable")})
+	data, err := os.ReadFile(filepath.Join(dir, "abcd1234", "result.json"))
+	if err != nil || !strings.Contains(string(data), `"exit_code": 4`) {
+		t.Errorf("result.json = %s, %v", data, err)
+	}
+}
@@ -0,0 +1,141 @@
+// cmd/new.go
+
+package cmd
+
+import (
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/peiman/ckeletin-go/internal/scaffold"
+	"github.com/spf13/cobra"
+)
+
+// gitInit initializes a repository in dir, can be replaced in tests
+var gitInit = func(dir string) error {
+	out, err := exec.Command("git", "init", "-q", dir).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("git init failed: %w: %s", err, out)
+	}
+	return nil
+}
+
+var newCmd = &cobra.Command{
+	Use:         "new MODULE_PATH",
+	Short:       "Create a new CLI project from this scaffold",
+	Annotations: map[string]string{docsAnnotation: "new-command"},
+	Long: `Copies the scaffold into a new directory and renames it: the Go module path
+and the binary name are rewritten in every file, which updates imports, the
+config file name, documentation links and the Taskfile build settings.
+- The scaffold is read from the current directory, or from --from.
+- The binary name defaults to the last element of the module path (--name).
+- The project is created in a directory named after the binary (--dir).
+- A git repository is initialized unless --git=false.
+- Optional features are left out with --without and added with --with; their
+  files and the code wiring them into other files are removed.
+
+Features: ` + featureList() + `.`,
+	Args: cobra.ExactArgs(1),
+	RunE: runNew,
+}
+
+func init() {
+	newCmd.Flags().String("name", "", "Binary name of the new CLI (default is the last element of the module path)")
+	newCmd.Flags().String("dir", "", "Directory to create the project in (default is the binary name)")
+	newCmd.Flags().String("from", ".", "Scaffold directory to copy")
+	newCmd.Flags().Bool("git", true, "Initialize a git repository")
+	newCmd.Flags().StringSlice("with", nil, "Optional features to include")
+	newCmd.Flags().StringSlice("without", nil, "Features to leave out")
+	const noRun = "needs the scaffold in the working directory"
+	addExamples(newCmd,
+		example{Desc: "Create ./mycli with the binary mycli", Line: "new github.com/acme/mycli", NoRun: noRun},
+		example{Desc: "Choose the binary name and directory", Line: "new github.com/acme/mycli --name mc --dir ~/src/mycli", NoRun: noRun},
+		example{Desc: "Leave out optional features", Line: "new github.com/acme/mycli --without telemetry,plugins --with scaffolding", NoRun: noRun},
+	)
+	RootCmd.AddCommand(newCmd)
+}
+
+func runNew(cmd *cobra.Command, args []string) error {
+	modulePath := args[0]
+	if err := scaffold.ValidateModulePath(modulePath); err != nil {
+		return err
+	}
+	name, _ := cmd.Flags().GetString("name")
+	if name == "" {
+		name = scaffold.NameFromModule(modulePath)
+	}
+	dir, _ := cmd.Flags().GetString("dir")
+	if dir == "" {
+		dir = name
+	}
+	from, _ := cmd.Flags().GetString("from")
+	initGit, _ := cmd.Flags().GetBool("git")
+	with, _ := cmd.Flags().GetStringSlice("with")
+	without, _ := cmd.Flags().GetStringSlice("without")
+	excluded, err := scaffold.Exclusions(with, without)
+	if err != nil {
+		return err
+	}
+
+	oldModule, err := scaffold.ReadModulePath(from)
+	if err != nil {
+		return err
+	}
+	rename := scaffold.Rename{
+		OldModule: oldModule,
+		NewModule: modulePath,
+		OldName:   scaffold.ReadBinaryName(from, oldModule),
+		NewName:   name,
+	}
+
+	files, err := scaffold.ListFiles(from)
+	if err != nil {
+		return fmt.Errorf("failed to list scaffold files: %w", err)
+	}
+	files = scaffold.ExcludeDir(scaffold.FilterFiles(files, excluded), from, dir)
+	runContext(cmd).Logger.Debug().Str("from", from).Int("files", len(files)).Interface("rename", rename).
+		Interface("excluded", excluded).Msg("Creating project")
+
+	if dryRun(cmd) {
+		action := fmt.Sprintf("create %s (%s) in %s with %d files", name, modulePath, dir, len(files))
+		if initGit {
+			action += " and initialize a git repository"
+		}
+		return printPlanned(cmd, "%s", action)
+	}
+	if err := scaffold.Copy(from, dir, files, rename, excluded); err != nil {
+		return err
+	}
+	if initGit {
+		if err := gitInit(dir); err != nil {
+			return err
+		}
+	}
+
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		abs = dir
+	}
+	_, err = fmt.Fprintf(cmd.OutOrStdout(), `Created %s (%s) in %s
+
+Next steps:
+  cd %s
+  go mod tidy
+  task check
+`, name, modulePath, abs, dir)
+	return err
+}
+
+// featureList describes the features that new can include or leave out
+func featureList() string {
+	var parts []string
+	for _, f := range scaffold.Features {
+		part := f.Name + " (" + f.Description
+		if f.Optional {
+			part += ", off by default"
+		}
+		parts = append(parts, part+")")
+	}
+	return strings.Join(parts, ", ")
+}
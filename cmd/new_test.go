@@ -0,0 +1,287 @@
+// cmd/new_test.go
+
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/peiman/ckeletin-go/internal/scaffold"
+	"github.com/spf13/pflag"
+)
+
+func TestRunNew(t *testing.T) {
+	src := t.TempDir()
+	files := map[string]string{
+		"go.mod":       "module github.com/peiman/ckeletin-go\n\ngo 1.23\n",
+		"main.go":      "package main\n\nimport \"github.com/peiman/ckeletin-go/cmd\"\n",
+		"cmd/root.go":  "package cmd\n\nvar binaryName = \"ckeletin-go\"\n",
+		"Taskfile.yml": "vars:\n  BINARY_NAME: ckeletin-go\n  LDFLAGS: -X github.com/peiman/ckeletin-go/cmd.Version=dev\n",
+	}
+	for name, content := range files {
+		p := filepath.Join(src, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	origGitInit := gitInit
+	defer func() { gitInit = origGitInit }()
+	var gitDir string
+	gitInit = func(dir string) error {
+		gitDir = dir
+		return nil
+	}
+
+	dst := filepath.Join(t.TempDir(), "out")
+	out := new(bytes.Buffer)
+	newCmd.SetOut(out)
+	defer func() {
+		newCmd.SetOut(nil)
+		_ = newCmd.Flags().Set("from", ".")
+		_ = newCmd.Flags().Set("dir", "")
+	}()
+	if err := newCmd.Flags().Set("from", src); err != nil {
+		t.Fatal(err)
+	}
+	if err := newCmd.Flags().Set("dir", dst); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := runNew(newCmd, []string{"github.com/acme/mycli"}); err != nil {
+		t.Fatalf("runNew() error = %v", err)
+	}
+
+	read := func(name string) string {
+		data, err := os.ReadFile(filepath.Join(dst, filepath.FromSlash(name)))
+		if err != nil {
+			t.Fatalf("Missing %s: %v", name, err)
+		}
+		return string(data)
+	}
+	if got := read("go.mod"); !strings.HasPrefix(got, "module github.com/acme/mycli\n") {
+		t.Errorf("go.mod = %q", got)
+	}
+	if got := read("cmd/root.go"); !strings.Contains(got, `binaryName = "mycli"`) {
+		t.Errorf("cmd/root.go = %q", got)
+	}
+	if got := read("Taskfile.yml"); !strings.Contains(got, "BINARY_NAME: mycli") || !strings.Contains(got, "github.com/acme/mycli/cmd.Version") {
+		t.Errorf("Taskfile.yml = %q", got)
+	}
+	if gitDir != dst {
+		t.Errorf("git initialized in %q, want %q", gitDir, dst)
+	}
+	if !strings.Contains(out.String(), "Created mycli (github.com/acme/mycli)") {
+		t.Errorf("Unexpected output %q", out.String())
+	}
+}
+
+func TestRunNew_Without(t *testing.T) {
+	src := t.TempDir()
+	files := map[string]string{
+		"go.mod":                        "module github.com/peiman/ckeletin-go\n",
+		"cmd/root.go":                   "package cmd\n\nfunc Execute() {\n\t// scaffold:telemetry\n\trecordTelemetry()\n\t// scaffold:end\n}\n",
+		"cmd/telemetry.go":              "package cmd\n",
+		"internal/telemetry/client.go":  "package telemetry\n",
+		"internal/scaffold/scaffold.go": "package scaffold\n",
+	}
+	for name, content := range files {
+		p := filepath.Join(src, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	dst := filepath.Join(t.TempDir(), "out")
+	newCmd.SetOut(new(bytes.Buffer))
+	defer func() {
+		newCmd.SetOut(nil)
+		resetNewFlags()
+	}()
+	for name, value := range map[string]string{"from": src, "dir": dst, "git": "false", "without": "telemetry"} {
+		if err := newCmd.Flags().Set(name, value); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := runNew(newCmd, []string{"github.com/acme/mycli"}); err != nil {
+		t.Fatalf("runNew() error = %v", err)
+	}
+	data, err := os.ReadFile(filepath.Join(dst, "cmd", "root.go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "package cmd\n\nfunc Execute() {\n}\n"; string(data) != want {
+		t.Errorf("cmd/root.go = %q, want %q", data, want)
+	}
+	// telemetry was left out, scaffolding is off by default
+	for _, name := range []string{"cmd/telemetry.go", "internal/telemetry", "internal/scaffold"} {
+		if _, err := os.Stat(filepath.Join(dst, filepath.FromSlash(name))); !os.IsNotExist(err) {
+			t.Errorf("Expected %s to be left out, got %v", name, err)
+		}
+	}
+}
+
+// TestRunNew_Generated creates projects from this repository with the default
+// features, each feature left out and each optional one added, and checks that
+// they pass go vet and gofmt
+func TestRunNew_Generated(t *testing.T) {
+	if testing.Short() {
+		t.Skip("builds generated projects")
+	}
+	variants := map[string]map[string]string{"default": {}}
+	var all []string
+	for _, f := range scaffold.Features {
+		if f.Optional {
+			variants["with "+f.Name] = map[string]string{"with": f.Name}
+			continue
+		}
+		variants["without "+f.Name] = map[string]string{"without": f.Name}
+		all = append(all, f.Name)
+	}
+	variants["without all"] = map[string]string{"without": strings.Join(all, ",")}
+
+	newCmd.SetOut(new(bytes.Buffer))
+	defer func() {
+		newCmd.SetOut(nil)
+		resetNewFlags()
+	}()
+	// new shares its flags, so projects are created one by one and checked in parallel
+	dirs := map[string]string{}
+	for name, flags := range variants {
+		resetNewFlags()
+		dirs[name] = filepath.Join(t.TempDir(), "gen")
+		flags["from"], flags["dir"], flags["git"] = "..", dirs[name], "false"
+		for flag, value := range flags {
+			if err := newCmd.Flags().Set(flag, value); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if err := runNew(newCmd, []string{"github.com/acme/gen"}); err != nil {
+			t.Fatalf("runNew() %s error = %v", name, err)
+		}
+	}
+
+	for name, dir := range dirs {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			vet := exec.Command("go", "vet", "./...")
+			vet.Dir = dir
+			if out, err := vet.CombinedOutput(); err != nil {
+				t.Errorf("go vet failed: %v\n%s", err, out)
+			}
+			gofmt := exec.Command("gofmt", "-l", ".")
+			gofmt.Dir = dir
+			if out, err := gofmt.CombinedOutput(); err != nil || len(out) > 0 {
+				t.Errorf("gofmt -l: %v\n%s", err, out)
+			}
+		})
+	}
+}
+
+func TestRunNew_InsideScaffold(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not found")
+	}
+	src := t.TempDir()
+	if err := os.WriteFile(filepath.Join(src, "go.mod"), []byte("module github.com/peiman/ckeletin-go\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := gitInit(src); err != nil {
+		t.Fatal(err)
+	}
+	newCmd.SetOut(new(bytes.Buffer))
+	defer func() {
+		newCmd.SetOut(nil)
+		resetNewFlags()
+	}()
+
+	// The first project is an untracked repository inside the scaffold
+	for _, name := range []string{"one", "two"} {
+		resetNewFlags()
+		for flag, value := range map[string]string{"from": src, "dir": filepath.Join(src, name)} {
+			if err := newCmd.Flags().Set(flag, value); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if err := runNew(newCmd, []string{"github.com/acme/" + name}); err != nil {
+			t.Fatalf("runNew() %s error = %v", name, err)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(src, "two", "one")); !os.IsNotExist(err) {
+		t.Errorf("Expected the first project not to be copied, got %v", err)
+	}
+}
+
+func resetNewFlags() {
+	newCmd.Flags().VisitAll(func(f *pflag.Flag) {
+		if sv, ok := f.Value.(pflag.SliceValue); ok {
+			_ = sv.Replace(nil)
+		} else {
+			_ = f.Value.Set(f.DefValue)
+		}
+		f.Changed = false
+	})
+}
+
+func TestRunNew_Errors(t *testing.T) {
+	if err := runNew(newCmd, []string{"Not A Module"}); err == nil || !strings.Contains(err.Error(), "invalid module path") {
+		t.Errorf("Expected invalid module path error, got %v", err)
+	}
+
+	defer func() { _ = newCmd.Flags().Set("from", ".") }()
+	if err := newCmd.Flags().Set("from", t.TempDir()); err != nil {
+		t.Fatal(err)
+	}
+	if err := runNew(newCmd, []string{"github.com/acme/mycli"}); err == nil || !strings.Contains(err.Error(), "not a Go module") {
+		t.Errorf("Expected missing go.mod error, got %v", err)
+	}
+
+	defer resetNewFlags()
+	if err := newCmd.Flags().Set("without", "tui"); err != nil {
+		t.Fatal(err)
+	}
+	if err := runNew(newCmd, []string{"github.com/acme/mycli"}); err == nil || !strings.Contains(err.Error(), `unknown feature "tui"`) {
+		t.Errorf("Expected unknown feature error, got %v", err)
+	}
+}
+
+func TestDryRun_New(t *testing.T) {
+	src := t.TempDir()
+	if err := os.WriteFile(filepath.Join(src, "go.mod"), []byte("module github.com/peiman/ckeletin-go\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	dst := filepath.Join(t.TempDir(), "out")
+	out := new(bytes.Buffer)
+	newCmd.SetOut(out)
+	useConfig(t, newCmd, dryRunConfig)
+	defer func() {
+		newCmd.SetOut(nil)
+		resetNewFlags()
+	}()
+	for name, value := range map[string]string{"from": src, "dir": dst} {
+		if err := newCmd.Flags().Set(name, value); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := runNew(newCmd, []string{"github.com/acme/mycli"}); err != nil {
+		t.Fatalf("runNew() error = %v", err)
+	}
+	if !strings.Contains(out.String(), "Would create mycli (github.com/acme/mycli) in "+dst+" with 1 files") {
+		t.Errorf("Unexpected dry run output %q", out.String())
+	}
+	if _, err := os.Stat(dst); !os.IsNotExist(err) {
+		t.Errorf("Dry run created %s", dst)
+	}
+}
@@ -0,0 +1,88 @@
+// cmd/notice.go
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/peiman/ckeletin-go/internal/store"
+	"github.com/peiman/ckeletin-go/internal/update"
+	"github.com/spf13/cobra"
+)
+
+const (
+	// noticeInterval is how often the release check may hit the network
+	noticeInterval = 24 * time.Hour
+	// noticeTimeout bounds the background release check
+	noticeTimeout = 2 * time.Second
+)
+
+// ciEnvVars are set by common CI systems; update notices are never shown there
+var ciEnvVars = []string{"CI", "CONTINUOUS_INTEGRATION", "BUILD_NUMBER", "RUN_ID", "GITHUB_ACTIONS", "GITLAB_CI", "JENKINS_URL", "TF_BUILD"}
+
+// startUpdateNotice takes the notice from the release information cached by
+// earlier runs and refreshes the cache in the background. Commands never wait
+// for the check: a refresh cut short by the exit is retried by the next run,
+// and a newer release it finds is announced then.
+func startUpdateNotice(cmd *cobra.Command) {
+	inv := invocationFrom(cmd.Context())
+	inv.notice = ""
+	if !updateNoticeEnabled(cmd) {
+		return
+	}
+
+	rc := runContext(cmd)
+	log := rc.Logger
+	st, err := store.Default(rc.Dirs, binaryName)
+	if err != nil {
+		log.Debug().Err(err).Msg("Update notice disabled: no state directory")
+		return
+	}
+
+	n := &update.Notifier{Updater: newUpdater(rc.Config), Store: st, Interval: noticeInterval, Current: Version}
+	inv.notice = n.Notice()
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		ctx, cancel := context.WithTimeout(cmd.Context(), noticeTimeout)
+		defer cancel()
+		if err := n.Refresh(ctx); err != nil {
+			log.Debug().Err(err).Msg("Update check failed")
+		}
+	}()
+	inv.noticeDone = done
+}
+
+// finishUpdateNotice prints a one-line notice on stderr when a newer release exists
+func finishUpdateNotice(cmd *cobra.Command) {
+	inv := invocationFrom(cmd.Context())
+	msg := inv.notice
+	inv.notice = ""
+	if msg != "" {
+		fmt.Fprintln(cmd.ErrOrStderr(), msg)
+	}
+}
+
+func updateNoticeEnabled(cmd *cobra.Command) bool {
+	cfg := runContext(cmd).Config
+	if !cfg.GetBool("app.update.notify") || !cfg.GetBool("app.update.enabled") {
+		return false
+	}
+	switch cmd.Name() {
+	case "update", "completion", cobra.ShellCompRequestCmd, cobra.ShellCompNoDescRequestCmd:
+		return false
+	}
+	return !runningInCI()
+}
+
+func runningInCI() bool {
+	for _, name := range ciEnvVars {
+		if v, ok := os.LookupEnv(name); ok && v != "" && v != "false" {
+			return true
+		}
+	}
+	return false
+}
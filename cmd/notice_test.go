@@ -0,0 +1,77 @@
+// cmd/notice_test.go
+
+package cmd
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+)
+
+// clearCIEnv hides the CI variables of the environment running the tests
+func clearCIEnv(t *testing.T) {
+	t.Helper()
+	for _, name := range ciEnvVars {
+		t.Setenv(name, "")
+	}
+}
+
+func TestUpdateNoticeEnabled(t *testing.T) {
+	tests := []struct {
+		name    string
+		command string
+		notify  bool
+		ci      bool
+		want    bool
+	}{
+		{"Opted in", "ping", true, false, true},
+		{"Not opted in", "ping", false, false, false},
+		{"Running in CI", "ping", true, true, false},
+		{"Update command", "update", true, false, false},
+		{"Shell completion", cobra.ShellCompRequestCmd, true, false, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clearCIEnv(t)
+			if tt.ci {
+				t.Setenv("CI", "true")
+			}
+			cmd := &cobra.Command{Use: tt.command}
+			useConfig(t, cmd, map[string]interface{}{"app.update.notify": tt.notify})
+
+			if got := updateNoticeEnabled(cmd); got != tt.want {
+				t.Errorf("updateNoticeEnabled() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUpdateNotice_PrintsWhenNewer(t *testing.T) {
+	setupUpdateTest(t, "v2.0.0")
+	Version = "1.0.0"
+	clearCIEnv(t)
+	t.Setenv("XDG_STATE_HOME", t.TempDir())
+	cmd := &cobra.Command{Use: "ping"}
+	stderr := new(bytes.Buffer)
+	cmd.SetErr(stderr)
+	useConfig(t, cmd, map[string]interface{}{"app.update.notify": true})
+	inv := invocationFrom(cmd.Context())
+
+	// The first run only finds the release, without waiting for it
+	startUpdateNotice(cmd)
+	finishUpdateNotice(cmd)
+	if stderr.Len() != 0 {
+		t.Errorf("Expected no notice before the release is cached, got %q", stderr.String())
+	}
+	<-inv.noticeDone
+
+	startUpdateNotice(cmd)
+	finishUpdateNotice(cmd)
+	<-inv.noticeDone
+	if !strings.Contains(stderr.String(), "A new release of mycli is available: 1.0.0 -> 2.0.0.") {
+		t.Errorf("Expected update notice, got %q", stderr.String())
+	}
+}
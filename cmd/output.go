@@ -0,0 +1,52 @@
+// cmd/output.go
+
+package cmd
+
+import (
+	"bytes"
+
+	"github.com/peiman/ckeletin-go/internal/output"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// outputFormat returns the format for the result of cmd. In order of precedence:
+// the command's own --format flag, the global --output flag, the command's
+// format config key (if any, e.g. app.ping.format) and app.output.
+func outputFormat(cmd *cobra.Command, key string) (string, error) {
+	return configOutputFormat(cmd, runContext(cmd).Config, key)
+}
+
+// configOutputFormat is outputFormat with the config keys read from cfg
+func configOutputFormat(cmd *cobra.Command, cfg *viper.Viper, key string) (string, error) {
+	format := cfg.GetString("app.output")
+	if key != "" {
+		if v := cfg.GetString(key); v != "" {
+			format = v
+		}
+	}
+	if f := cmd.Flags().Lookup("output"); f != nil && f.Changed {
+		format = f.Value.String()
+	}
+	if f := cmd.Flags().Lookup("format"); f != nil && f.Changed {
+		format = f.Value.String()
+	}
+	if format == "" {
+		format = output.Text
+	}
+	return format, output.Validate(format)
+}
+
+// renderOutput writes the result v of cmd in the selected output format.
+// Long results are paged on a terminal and can be copied with --copy.
+func renderOutput(cmd *cobra.Command, v interface{}) error {
+	format, err := outputFormat(cmd, "")
+	if err != nil {
+		return err
+	}
+	var buf bytes.Buffer
+	if err := output.Render(&buf, format, v); err != nil {
+		return err
+	}
+	return writeLong(cmd, buf.Bytes())
+}
@@ -0,0 +1,65 @@
+// cmd/output_test.go
+
+package cmd
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+)
+
+func TestOutputFormat(t *testing.T) {
+	tests := []struct {
+		name   string
+		config map[string]interface{}
+		args   []string
+		want   string
+	}{
+		{"Default", nil, nil, "text"},
+		{"Global config", map[string]interface{}{"app.output": "yaml"}, nil, "yaml"},
+		{"Command config beats global config", map[string]interface{}{"app.output": "yaml", "app.test.format": "json"}, nil, "json"},
+		{"Global flag beats config", map[string]interface{}{"app.test.format": "json"}, []string{"--output", "yaml"}, "yaml"},
+		{"Command flag beats global flag", nil, []string{"-o", "yaml", "--format", "json"}, "json"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd := &cobra.Command{Use: "test"}
+			cmd.Flags().StringP("output", "o", "text", "")
+			cmd.Flags().String("format", "", "")
+			if err := cmd.ParseFlags(tt.args); err != nil {
+				t.Fatalf("ParseFlags() error = %v", err)
+			}
+			useConfig(t, cmd, tt.config)
+
+			got, err := outputFormat(cmd, "app.test.format")
+			if err != nil {
+				t.Fatalf("outputFormat() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("outputFormat() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRenderOutput_YAML(t *testing.T) {
+	out := new(bytes.Buffer)
+	cmd := &cobra.Command{}
+	cmd.SetOut(out)
+	rc := useConfig(t, cmd, map[string]interface{}{"app.output": "yaml"})
+
+	if err := renderOutput(cmd, buildInfo{Version: "v1.2.3", Platform: "linux/amd64"}); err != nil {
+		t.Fatalf("renderOutput() error = %v", err)
+	}
+	if !strings.Contains(out.String(), "version: v1.2.3\n") || !strings.Contains(out.String(), "platform: linux/amd64\n") {
+		t.Errorf("Unexpected YAML output %q", out.String())
+	}
+
+	rc.Config.Set("app.output", "xml")
+	if err := renderOutput(cmd, nil); err == nil || !strings.Contains(err.Error(), "invalid format") {
+		t.Errorf("Expected invalid format error, got %v", err)
+	}
+}
@@ -0,0 +1,67 @@
+// cmd/pager.go
+
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/peiman/ckeletin-go/internal/config"
+	"github.com/peiman/ckeletin-go/internal/pager"
+	"github.com/peiman/ckeletin-go/pkg/termcaps"
+	"github.com/spf13/cobra"
+)
+
+var (
+	copyToClipboard = pager.Copy // can be replaced in tests
+	runPager        = pager.Run  // can be replaced in tests
+)
+
+func init() {
+	initPagerConfig()
+}
+
+func initPagerConfig() {
+	config.Register(
+		config.Option{Key: "app.pager.enabled", Default: true, Description: "Page long output on a terminal"},
+		config.Option{Key: "app.pager.command", Default: "", Description: "Pager command (default uses $PAGER, then less -FRX)"},
+	)
+}
+
+// writeLong writes content as the output of cmd. With --copy it is also placed on
+// the clipboard, and on a terminal output taller than the screen is shown in a pager.
+func writeLong(cmd *cobra.Command, content []byte) error {
+	rc := runContext(cmd)
+	if copyOutput, _ := cmd.Flags().GetBool("copy"); copyOutput {
+		if err := copyToClipboard(string(content)); err != nil {
+			return fmt.Errorf("failed to copy output to the clipboard: %w", err)
+		}
+		rc.Logger.Info().Int("bytes", len(content)).Msg("Output copied to the clipboard")
+	}
+
+	out := cmd.OutOrStdout()
+	if command := pagerCommand(cmd); command != "" && out == os.Stdout && rc.UI.Interactive() &&
+		pager.Lines(content) >= termcaps.Stdout().Height {
+		err := runPager(cmd.Context(), command, content, out, cmd.ErrOrStderr())
+		// Once the pager runs it has the content; writing it again would duplicate it
+		if !errors.Is(err, pager.ErrNotStarted) {
+			return err
+		}
+		rc.Logger.Warn().Err(err).Msg("Pager did not start, writing output directly")
+	}
+
+	if _, err := out.Write(content); err != nil {
+		return fmt.Errorf("failed to write output: %w", err)
+	}
+	return nil
+}
+
+// pagerCommand returns the pager for cmd's output, or "" when paging is disabled
+func pagerCommand(cmd *cobra.Command) string {
+	cfg := runContext(cmd).Config
+	if noPager, _ := cmd.Flags().GetBool("no-pager"); noPager || !cfg.GetBool("app.pager.enabled") {
+		return ""
+	}
+	return pager.Command(cfg.GetString("app.pager.command"), os.LookupEnv)
+}
@@ -0,0 +1,89 @@
+// cmd/pager_test.go
+
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+)
+
+func newPagerTestCmd(t *testing.T, args ...string) (*cobra.Command, *bytes.Buffer) {
+	t.Helper()
+	cmd := &cobra.Command{}
+	cmd.Flags().Bool("copy", false, "")
+	cmd.Flags().Bool("no-pager", false, "")
+	if err := cmd.Flags().Parse(args); err != nil {
+		t.Fatalf("Failed to parse flags: %v", err)
+	}
+	out := new(bytes.Buffer)
+	cmd.SetOut(out)
+	return cmd, out
+}
+
+func TestWriteLong_Copy(t *testing.T) {
+	origCopy := copyToClipboard
+	defer func() { copyToClipboard = origCopy }()
+
+	var copied string
+	copyToClipboard = func(text string) error {
+		copied = text
+		return nil
+	}
+
+	cmd, out := newPagerTestCmd(t, "--copy")
+	if err := writeLong(cmd, []byte("result\n")); err != nil {
+		t.Fatalf("writeLong() error = %v", err)
+	}
+	if copied != "result\n" || out.String() != "result\n" {
+		t.Errorf("copied = %q, output = %q; want the result in both", copied, out.String())
+	}
+
+	copyToClipboard = func(string) error { return errors.New("no clipboard") }
+	if err := writeLong(cmd, []byte("result\n")); err == nil || !strings.Contains(err.Error(), "clipboard") {
+		t.Errorf("Expected clipboard error, got %v", err)
+	}
+}
+
+func TestWriteLong_NotATerminal(t *testing.T) {
+	origPager := runPager
+	defer func() { runPager = origPager }()
+	runPager = nil // must not be called for buffered output
+
+	cmd, out := newPagerTestCmd(t)
+	long := strings.Repeat("line\n", 500)
+	if err := writeLong(cmd, []byte(long)); err != nil {
+		t.Fatalf("writeLong() error = %v", err)
+	}
+	if out.String() != long {
+		t.Error("Expected output to be written directly")
+	}
+}
+
+func TestPagerCommand(t *testing.T) {
+	t.Setenv("PAGER", "more")
+	tests := []struct {
+		name   string
+		args   []string
+		config map[string]interface{}
+		want   string
+	}{
+		{"PAGER", nil, nil, "more"},
+		{"Configured command", nil, map[string]interface{}{"app.pager.command": "most"}, "most"},
+		{"Disabled in config", nil, map[string]interface{}{"app.pager.enabled": false}, ""},
+		{"--no-pager", []string{"--no-pager"}, nil, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd, _ := newPagerTestCmd(t, tt.args...)
+			useConfig(t, cmd, tt.config)
+			if got := pagerCommand(cmd); got != tt.want {
+				t.Errorf("pagerCommand() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,192 @@
+// cmd/perf.go
+
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"text/tabwriter"
+
+	"github.com/peiman/ckeletin-go/internal/benchcheck"
+	"github.com/peiman/ckeletin-go/internal/config"
+	"github.com/peiman/ckeletin-go/internal/exitcode"
+	"github.com/spf13/cobra"
+)
+
+// runBenchmarks returns the output of go test -bench for patterns, can be replaced in tests
+var runBenchmarks = func(ctx context.Context, bench string, patterns []string, stderr io.Writer) ([]byte, error) {
+	var out bytes.Buffer
+	args := append([]string{"test", "-run", "^$", "-bench", bench, "-benchmem"}, patterns...)
+	c := exec.CommandContext(ctx, "go", args...)
+	c.Stdout = io.MultiWriter(&out, stderr)
+	c.Stderr = stderr
+	if err := c.Run(); err != nil {
+		if errors.Is(err, exec.ErrNotFound) {
+			return nil, errors.New("go not found, the benchmarks need the Go toolchain")
+		}
+		return nil, fmt.Errorf("benchmarks failed: %w", err)
+	}
+	return out.Bytes(), nil
+}
+
+var devPerfCmd = &cobra.Command{
+	Use:   "perf [packages]",
+	Short: "Compare benchmark results with the committed baseline",
+	Long: `Runs the benchmarks (default on ./...) with 'go test -bench -benchmem' and
+compares them with the baseline file (app.perf.baseline_file, default
+.perfbaseline.yaml). A benchmark regresses when its allocations per operation
+exceed the baseline by more than the tolerance (app.perf.tolerance, default
+0.5 for 50%). Time depends on the machine and its load, so it is shown but
+only compared with --time (app.perf.check_time), on the machine that recorded
+the baseline.
+
+Use --update to write the current results as the new baseline after an
+intended change, and commit the file.
+
+Exits with status 4 when a benchmark regressed.`,
+	RunE: runDevPerf,
+}
+
+func init() {
+	devPerfCmd.Flags().String("input", "", "Read saved 'go test -bench -benchmem' output from this file ('-' for stdin) instead of running the benchmarks")
+	devPerfCmd.Flags().String("bench", ".", "Run only the benchmarks matching this regular expression")
+	devPerfCmd.Flags().Bool("update", false, "Write the results to the baseline file instead of comparing")
+
+	addExamples(devPerfCmd,
+		example{Desc: "Compare all benchmarks with the baseline", Line: "dev perf", NoRun: "runs the benchmarks"},
+		example{Desc: "Also compare the time per operation, on the machine of the baseline", Line: "dev perf --time", NoRun: "runs the benchmarks"},
+		example{Desc: "Record a new baseline after an intended change", Line: "dev perf --update", NoRun: "runs the benchmarks"},
+	)
+	initPerfConfig()
+	configFlags(devPerfCmd, "app.perf")
+	devCmd.AddCommand(devPerfCmd)
+}
+
+func initPerfConfig() {
+	config.Register(
+		config.Option{Key: "app.perf.baseline_file", Flag: "baseline", Default: ".perfbaseline.yaml", Description: "Benchmark baseline compared by dev perf", Path: config.PathWorkDir},
+		config.Option{Key: "app.perf.tolerance", Flag: "tolerance", Default: 0.5, Description: "Allowed allocation growth and slowdown over the benchmark baseline, e.g. 0.5 for 50%"},
+		config.Option{Key: "app.perf.check_time", Flag: "time", Default: false, Description: "Also compare the time per operation with the benchmark baseline, which depends on the machine"},
+	)
+}
+
+func runDevPerf(cmd *cobra.Command, args []string) error {
+	cfg := runContext(cmd).Config
+	baselineFile := cfg.GetString("app.perf.baseline_file")
+	tolerance := cfg.GetFloat64("app.perf.tolerance")
+	checkTime := cfg.GetBool("app.perf.check_time")
+	if tolerance < 0 {
+		return &exitcode.UsageError{Err: fmt.Errorf("invalid tolerance %v: must not be negative", tolerance)}
+	}
+	input, _ := cmd.Flags().GetString("input")
+	bench, _ := cmd.Flags().GetString("bench")
+	update, _ := cmd.Flags().GetBool("update")
+
+	var data []byte
+	var err error
+	switch input {
+	case "":
+		patterns := args
+		if len(patterns) == 0 {
+			patterns = []string{"./..."}
+		}
+		data, err = runBenchmarks(cmd.Context(), bench, patterns, cmd.ErrOrStderr())
+	case "-":
+		data, err = io.ReadAll(cmd.InOrStdin())
+	default:
+		data, err = os.ReadFile(input)
+	}
+	if err != nil {
+		return err
+	}
+
+	results, err := benchcheck.Parse(bytes.NewReader(data), modulePath())
+	if err != nil {
+		return err
+	}
+	if len(results) == 0 {
+		return fmt.Errorf("no benchmark results found")
+	}
+
+	if update {
+		if dryRun(cmd) {
+			return printPlanned(cmd, "write %d benchmark results to %s", len(results), baselineFile)
+		}
+		if err := benchcheck.WriteBaseline(baselineFile, results); err != nil {
+			return err
+		}
+		fmt.Fprintf(cmd.OutOrStdout(), "Wrote %d benchmark results to %s\n", len(results), baselineFile)
+		return nil
+	}
+
+	base, err := benchcheck.LoadBaseline(baselineFile)
+	if err != nil {
+		return &exitcode.ConfigError{Err: err}
+	}
+	report := perfReport{Tolerance: tolerance, CheckTime: checkTime, Benchmarks: benchcheck.Compare(results, base, tolerance, checkTime)}
+	if err := renderOutput(cmd, report); err != nil {
+		return err
+	}
+	if n := report.regressions(); n > 0 {
+		return &exitcode.CheckFailure{Err: fmt.Errorf("%d benchmarks regressed by more than %.0f%%", n, tolerance*100)}
+	}
+	return nil
+}
+
+// modulePath returns the path of the main module, which benchmark names are
+// reported relative to
+func modulePath() string {
+	if bi, ok := readBuildInfo(); ok {
+		return bi.Main.Path
+	}
+	return ""
+}
+
+// perfReport is the result of dev perf
+type perfReport struct {
+	Tolerance  float64                 `json:"tolerance"`
+	CheckTime  bool                    `json:"check_time"`
+	Benchmarks []benchcheck.Comparison `json:"benchmarks"`
+}
+
+func (r perfReport) regressions() int {
+	n := 0
+	for _, c := range r.Benchmarks {
+		if c.Regressed {
+			n++
+		}
+	}
+	return n
+}
+
+// WriteText prints one line per benchmark with its change over the baseline
+func (r perfReport) WriteText(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "BENCHMARK\tNS/OP\tBASELINE\tCHANGE\tALLOCS/OP\tSTATUS")
+	for _, c := range r.Benchmarks {
+		baseline, change, status := "-", "-", "new"
+		if c.Baseline != nil {
+			baseline = fmt.Sprintf("%.0f", c.Baseline.NsPerOp)
+			change = fmt.Sprintf("%+.0f%%", c.Change()*100)
+			status = "ok"
+			if c.Regressed {
+				status = "REGRESSED"
+			}
+		}
+		fmt.Fprintf(tw, "%s\t%.0f\t%s\t%s\t%d\t%s\n", c.Name, c.NsPerOp, baseline, change, c.AllocsPerOp, status)
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+	compared := "allocations"
+	if r.CheckTime {
+		compared = "time and allocations"
+	}
+	_, err := fmt.Fprintf(w, "%d benchmarks, %d regressed (%s, tolerance %.0f%%)\n", len(r.Benchmarks), r.regressions(), compared, r.Tolerance*100)
+	return err
+}
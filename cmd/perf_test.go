@@ -0,0 +1,111 @@
+// cmd/perf_test.go
+
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/peiman/ckeletin-go/internal/exitcode"
+)
+
+const perfOutput = `pkg: github.com/peiman/ckeletin-go/internal/output
+BenchmarkPrinter/json-4   	  200000	      1500 ns/op	     224 B/op	       4 allocs/op
+pkg: github.com/peiman/ckeletin-go/cmd
+BenchmarkInitConfig-4   	    2000	    500000 ns/op	  163368 B/op	    1370 allocs/op
+`
+
+func TestRunDevPerf(t *testing.T) {
+	origRun := runBenchmarks
+	defer func() { runBenchmarks = origRun }()
+	var gotPatterns []string
+	runBenchmarks = func(ctx context.Context, bench string, patterns []string, stderr io.Writer) ([]byte, error) {
+		gotPatterns = patterns
+		return []byte(perfOutput), nil
+	}
+
+	baseline := filepath.Join(t.TempDir(), "baseline.yaml")
+	out := new(bytes.Buffer)
+	devPerfCmd.SetOut(out)
+	defer devPerfCmd.SetOut(nil)
+	rc := useConfig(t, devPerfCmd, map[string]interface{}{"app.perf.baseline_file": baseline})
+	setPerfFlag(t, "update", "true")
+
+	if err := runDevPerf(devPerfCmd, nil); err != nil {
+		t.Fatalf("runDevPerf(--update) error = %v", err)
+	}
+	if len(gotPatterns) != 1 || gotPatterns[0] != "./..." {
+		t.Errorf("patterns = %v, want ./...", gotPatterns)
+	}
+	if !strings.Contains(out.String(), "Wrote 2 benchmark results") {
+		t.Errorf("Unexpected output %q", out.String())
+	}
+
+	setPerfFlag(t, "update", "false")
+	out.Reset()
+	if err := runDevPerf(devPerfCmd, nil); err != nil {
+		t.Fatalf("runDevPerf() error = %v", err)
+	}
+	for _, want := range []string{"internal/output.BenchmarkPrinter/json", "cmd.BenchmarkInitConfig", "2 benchmarks, 0 regressed (allocations, tolerance 50%)"} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("Output missing %q:\n%s", want, out.String())
+		}
+	}
+
+	runBenchmarks = func(ctx context.Context, bench string, patterns []string, stderr io.Writer) ([]byte, error) {
+		return []byte(strings.Replace(perfOutput, "1500 ns/op", "3000 ns/op", 1)), nil
+	}
+	out.Reset()
+	if err := runDevPerf(devPerfCmd, nil); err != nil {
+		t.Errorf("runDevPerf() error = %v, want time not compared by default", err)
+	}
+	if !strings.Contains(out.String(), "+100%") {
+		t.Errorf("Output does not show the slowdown:\n%s", out.String())
+	}
+
+	rc.Config.Set("app.perf.check_time", true)
+	out.Reset()
+	if err := runDevPerf(devPerfCmd, nil); exitcode.Code(err) != exitcode.CheckFailed {
+		t.Errorf("Expected a check failure for a slower benchmark, got %v", err)
+	}
+	if !strings.Contains(out.String(), "+100%") || !strings.Contains(out.String(), "REGRESSED") {
+		t.Errorf("Output does not show the regression:\n%s", out.String())
+	}
+
+	rc.Config.Set("app.perf.tolerance", 1.5)
+	if err := runDevPerf(devPerfCmd, nil); err != nil {
+		t.Errorf("runDevPerf() with a wider tolerance error = %v", err)
+	}
+}
+
+func TestRunDevPerf_Input(t *testing.T) {
+	dir := t.TempDir()
+	input := filepath.Join(dir, "bench.txt")
+	if err := os.WriteFile(input, []byte("PASS\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	useConfig(t, devPerfCmd, map[string]interface{}{"app.perf.baseline_file": filepath.Join(dir, "baseline.yaml")})
+	setPerfFlag(t, "input", input)
+
+	if err := runDevPerf(devPerfCmd, nil); err == nil || !strings.Contains(err.Error(), "no benchmark results") {
+		t.Errorf("runDevPerf() error = %v, want no benchmark results", err)
+	}
+}
+
+// setPerfFlag sets a flag of dev perf until the test ends
+func setPerfFlag(t *testing.T, name, value string) {
+	t.Helper()
+	if err := devPerfCmd.Flags().Set(name, value); err != nil {
+		t.Fatalf("Failed to set flag: %v", err)
+	}
+	t.Cleanup(func() {
+		f := devPerfCmd.Flags().Lookup(name)
+		_ = f.Value.Set(f.DefValue)
+		f.Changed = false
+	})
+}
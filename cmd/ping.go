@@ -3,114 +3,233 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
-	"strings"
+	"io"
+	"time"
 
+	"github.com/peiman/ckeletin-go/internal/config"
+	"github.com/peiman/ckeletin-go/internal/output"
 	"github.com/peiman/ckeletin-go/internal/ui"
-	"github.com/rs/zerolog/log"
+	"github.com/rs/zerolog"
 	"github.com/spf13/cobra"
-	"github.com/spf13/viper"
 )
 
 type UIRunner interface {
-	RunUI(message, col string) error
+	RunUI(ctx context.Context, message, col string) error
 }
 
 var (
-	pingRunner UIRunner = &ui.DefaultUIRunner{} // default UI runner, can be replaced in tests
+	pingRunner  UIRunner = &ui.DefaultUIRunner{Save: savePingConfig} // default UI runner, can be replaced in tests
+	interactive          = ui.Settings.Interactive                   // reports whether the UI may run, can be replaced in tests
 )
 
 var pingCmd = &cobra.Command{
-	Use:   "ping",
-	Short: "Responds with a pong",
+	Use:         "ping",
+	Short:       "Responds with a pong",
+	Annotations: map[string]string{docsAnnotation: "ping-command"},
 	Long: `The ping command demonstrates configuration, logging, and optional Bubble Tea UI.
 - Without arguments, prints "Pong".
 - Use --message and --color to override defaults.
-- Use --ui to launch an interactive Bubble Tea UI.`,
+- Use --ui to launch an interactive Bubble Tea UI. In the UI, press 'c' to cycle
+  colors and 'e' to edit the message; changes can be saved to the config file.
+  Without a terminal or with --non-interactive the message is printed instead.
+- Use --count and --interval to repeat the output periodically; a summary of
+  render times (min/avg/max) is printed when more than one ping is sent.
+- Use --output json or yaml (or --format) for machine-readable output.`,
 	RunE: runPing,
 }
 
 func init() {
-	pingCmd.Flags().String("message", "", "Custom output message")
-	pingCmd.Flags().String("color", "", "Output color")
-	pingCmd.Flags().Bool("ui", false, "Enable UI")
-
-	// Bind flags to Viper
-	if err := viper.BindPFlag("app.ping.output_message", pingCmd.Flags().Lookup("message")); err != nil {
-		log.Fatal().Err(err).Msg("Failed to bind 'message' flag")
-	}
-	if err := viper.BindPFlag("app.ping.output_color", pingCmd.Flags().Lookup("color")); err != nil {
-		log.Fatal().Err(err).Msg("Failed to bind 'color' flag")
-	}
-	if err := viper.BindPFlag("app.ping.ui", pingCmd.Flags().Lookup("ui")); err != nil {
-		log.Fatal().Err(err).Msg("Failed to bind 'ui' flag")
-	}
+	initPingConfig()
+	configFlags(pingCmd, "app.ping")
+	addExamples(pingCmd,
+		example{Desc: "Print the configured message", Line: "ping"},
+		example{Desc: "Print a custom message in green", Line: "ping --message Hello --color green"},
+		example{Desc: "Send three pings 100ms apart and print the statistics as JSON", Line: "ping --count 3 --interval 100ms --format json"},
+	)
 
 	// Add pingCmd to RootCmd
 	RootCmd.AddCommand(pingCmd)
 }
 
 func initPingConfig() {
-	viper.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
-	viper.AutomaticEnv()
-
-	viper.SetDefault("app.ping.output_message", "Pong")
-	viper.SetDefault("app.ping.output_color", "white")
-	viper.SetDefault("app.ping.ui", false)
+	config.Register(
+		config.Option{Key: "app.ping.output_message", Flag: "message", Default: "Pong", Description: "Message printed by ping"},
+		config.Option{Key: "app.ping.output_color", Flag: "color", Default: "white", Description: "Color of the ping message"},
+		config.Option{Key: "app.ping.ui", Flag: "ui", Default: false, Description: "Launch the interactive ping UI"},
+		config.Option{Key: "app.ping.count", Flag: "count", Default: 1, Description: "Number of pings to send"},
+		config.Option{Key: "app.ping.interval", Flag: "interval", Default: time.Second, Type: config.TypeDuration, Description: "Wait time between pings"},
+		config.Option{Key: "app.ping.format", Flag: "format", Default: "", Description: "Output format of ping, overrides --output (text, json, yaml)"},
+	)
 }
 
 func runPing(cmd *cobra.Command, args []string) error {
+	rc := runContext(cmd)
+	log := rc.Logger
+	cfg := rc.Config
 	log.Debug().Msg("Starting runPing execution")
-	initPingConfig()
 
-	// Get values from flags or Viper
-	message := viper.GetString("app.ping.output_message")
+	// Get values from flags or the config snapshot
+	message := cfg.GetString("app.ping.output_message")
 	if cmd.Flags().Changed("message") {
 		message, _ = cmd.Flags().GetString("message")
 	}
 
-	colorStr := viper.GetString("app.ping.output_color")
+	colorStr := cfg.GetString("app.ping.output_color")
 	if cmd.Flags().Changed("color") {
 		colorStr, _ = cmd.Flags().GetString("color")
 	}
 
-	uiFlag := viper.GetBool("app.ping.ui")
+	uiFlag := cfg.GetBool("app.ping.ui")
 	if cmd.Flags().Changed("ui") {
 		uiFlag, _ = cmd.Flags().GetBool("ui")
 	}
 
+	count := cfg.GetInt("app.ping.count")
+	if cmd.Flags().Changed("count") {
+		count, _ = cmd.Flags().GetInt("count")
+	}
+
+	interval := cfg.GetDuration("app.ping.interval")
+	if cmd.Flags().Changed("interval") {
+		interval, _ = cmd.Flags().GetDuration("interval")
+	}
+
+	format, formatErr := outputFormat(cmd, "app.ping.format")
+	printer := rc.Printer
+	printer.Format = format
+
 	log.Debug().
 		Str("message", message).
 		Str("color", colorStr).
 		Bool("ui_enabled", uiFlag).
+		Int("count", count).
+		Dur("interval", interval).
+		Str("format", format).
 		Msg("Configuration loaded")
 
-	writer := cmd.OutOrStdout()
+	if count < 1 {
+		return fmt.Errorf("invalid count %d: must be at least 1", count)
+	}
+	if interval < 0 {
+		return fmt.Errorf("invalid interval %s: must not be negative", interval)
+	}
+	if formatErr != nil {
+		return formatErr
+	}
+
+	writer := printer.Out
 	log.Debug().
 		Str("writer_type", fmt.Sprintf("%T", writer)).
 		Msg("Using writer")
 
+	if uiFlag && !interactive(rc.UI) {
+		log.Warn().Msg("Interactive UI is disabled in non-interactive mode, printing the message instead")
+		uiFlag = false
+	}
+
 	if uiFlag {
 		log.Info().Str("message", message).Str("color", colorStr).Msg("Starting UI")
-		if err := pingRunner.RunUI(message, colorStr); err != nil {
+		if err := pingRunner.RunUI(cmd.Context(), message, colorStr); err != nil {
 			log.Error().Err(err).Msg("Failed to run UI")
 			return err
 		}
 		return nil
 	}
 
-	// Non-UI mode: print the message
-	err := ui.PrintColoredMessage(writer, message, colorStr)
-	if err != nil {
-		log.Error().
-			Err(err).
-			Str("message", message).
-			Str("color", colorStr).
-			Msg("Failed to print colored message")
-		// Wrap the error to provide context
-		return fmt.Errorf("failed to print colored message: %w", err)
+	// Non-UI mode: print the message count times
+	stats := pingStats{Message: message, Color: colorStr}
+	for seq := 1; seq <= count; seq++ {
+		if seq > 1 {
+			select {
+			case <-cmd.Context().Done():
+				log.Debug().Int("sent", seq-1).Msg("Ping interrupted")
+				return cmd.Context().Err()
+			case <-time.After(interval):
+			}
+		}
+
+		// In JSON and YAML mode the message is rendered but only the result is written.
+		out := writer
+		if format != output.Text {
+			out = io.Discard
+		}
+
+		start := time.Now()
+		err := ui.PrintColoredMessage(out, message, colorStr)
+		if err != nil {
+			log.Error().
+				Err(err).
+				Str("message", message).
+				Str("color", colorStr).
+				Msg("Failed to print colored message")
+			// Wrap the error to provide context
+			return fmt.Errorf("failed to print colored message: %w", err)
+		}
+		stats.add(seq, time.Since(start))
+	}
+
+	if format != output.Text {
+		if err := printer.Print(stats); err != nil {
+			return err
+		}
+	} else if count > 1 {
+		if _, err := fmt.Fprintf(writer, "\n--- ping statistics ---\n%s\n", stats.summary()); err != nil {
+			return fmt.Errorf("failed to write statistics: %w", err)
+		}
 	}
 
 	log.Debug().Msg("runPing completed successfully")
 	return nil
 }
+
+// savePingConfig writes the message and color chosen in the UI back to the config file
+// of the invocation ctx belongs to. Only the ping keys are updated; the rest of the
+// file is preserved.
+func savePingConfig(ctx context.Context, message, col string) error {
+	path, err := writeConfigValues(contextConfig(ctx), map[string]interface{}{
+		"app.ping.output_message": message,
+		"app.ping.output_color":   col,
+	})
+	if err != nil {
+		return err
+	}
+	zerolog.Ctx(ctx).Info().Str("config_file", path).Msg("Saved ping settings")
+	return nil
+}
+
+// pingReply records a single ping and how long its output took to render.
+type pingReply struct {
+	Seq        int           `json:"seq"`
+	RenderTime time.Duration `json:"render_time_ns"`
+}
+
+// pingStats aggregates the replies of one ping run.
+type pingStats struct {
+	Message string        `json:"message"`
+	Color   string        `json:"color"`
+	Count   int           `json:"count"`
+	Min     time.Duration `json:"min_ns"`
+	Avg     time.Duration `json:"avg_ns"`
+	Max     time.Duration `json:"max_ns"`
+	Replies []pingReply   `json:"replies"`
+	total   time.Duration
+}
+
+func (s *pingStats) add(seq int, d time.Duration) {
+	s.Replies = append(s.Replies, pingReply{Seq: seq, RenderTime: d})
+	if s.Count == 0 || d < s.Min {
+		s.Min = d
+	}
+	if d > s.Max {
+		s.Max = d
+	}
+	s.Count++
+	s.total += d
+	s.Avg = s.total / time.Duration(s.Count)
+}
+
+func (s *pingStats) summary() string {
+	return fmt.Sprintf("%d pings, render time min/avg/max = %s/%s/%s", s.Count, s.Min, s.Avg, s.Max)
+}
@@ -4,13 +4,21 @@ package cmd
 
 import (
 	"bytes"
+	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/peiman/ckeletin-go/internal/output"
+	"github.com/peiman/ckeletin-go/internal/runctx"
+	"github.com/peiman/ckeletin-go/internal/ui"
 	"github.com/rs/zerolog"
-	"github.com/rs/zerolog/log"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 )
@@ -21,7 +29,7 @@ type mockUIRunner struct {
 	ReturnError       error
 }
 
-func (m *mockUIRunner) RunUI(message, col string) error {
+func (m *mockUIRunner) RunUI(ctx context.Context, message, col string) error {
 	m.CalledWithMessage = message
 	m.CalledWithColor = col
 	return m.ReturnError
@@ -30,25 +38,21 @@ func (m *mockUIRunner) RunUI(message, col string) error {
 type errorWriter struct{}
 
 func (e errorWriter) Write(p []byte) (int, error) {
-	log.Debug().Msg("errorWriter.Write called - generating error")
 	return 0, fmt.Errorf("write error")
 }
 
-// setupTestViper initializes a clean viper instance for testing
-func setupTestViper(ui bool, message, color string) {
-	viper.Reset()
-	viper.SetDefault("app.ping.output_message", message)
-	viper.SetDefault("app.ping.output_color", color)
-	viper.SetDefault("app.ping.ui", ui)
-}
-
 func TestPingCommand(t *testing.T) {
 	// Setup debug logging for tests
 	logBuf := &bytes.Buffer{}
-	log.Logger = zerolog.New(logBuf).With().Timestamp().Logger().Level(zerolog.DebugLevel)
+	log := zerolog.New(logBuf).With().Timestamp().Logger().Level(zerolog.DebugLevel)
 
 	originalRunner := pingRunner
 	defer func() { pingRunner = originalRunner }()
+	originalRoot, originalPing := RootCmd, pingCmd
+	defer func() { RootCmd, pingCmd = originalRoot, originalPing }()
+	originalInteractive := interactive
+	interactive = func(ui.Settings) bool { return true }
+	defer func() { interactive = originalInteractive }()
 
 	tests := []struct {
 		name         string
@@ -58,9 +62,6 @@ func TestPingCommand(t *testing.T) {
 		wantOutput   string
 		mockPrintErr bool
 		writer       io.Writer
-		viperUI      bool
-		viperMsg     string
-		viperColor   string
 	}{
 		{
 			name:       "Default",
@@ -69,9 +70,6 @@ func TestPingCommand(t *testing.T) {
 			wantErr:    false,
 			wantOutput: "Pong\n",
 			writer:     &bytes.Buffer{},
-			viperUI:    false,
-			viperMsg:   "Pong",
-			viperColor: "white",
 		},
 		{
 			name:       "Custom Message and Color",
@@ -80,9 +78,6 @@ func TestPingCommand(t *testing.T) {
 			wantErr:    false,
 			wantOutput: "Hello, Test!\n",
 			writer:     &bytes.Buffer{},
-			viperUI:    false,
-			viperMsg:   "Pong",
-			viperColor: "white",
 		},
 		{
 			name:       "UI Enabled",
@@ -91,9 +86,6 @@ func TestPingCommand(t *testing.T) {
 			wantErr:    false,
 			wantOutput: "",
 			writer:     &bytes.Buffer{},
-			viperUI:    false,
-			viperMsg:   "Pong",
-			viperColor: "white",
 		},
 		{
 			name:       "UI Enabled with Error",
@@ -102,9 +94,6 @@ func TestPingCommand(t *testing.T) {
 			wantErr:    true,
 			wantOutput: "",
 			writer:     &bytes.Buffer{},
-			viperUI:    false,
-			viperMsg:   "Pong",
-			viperColor: "white",
 		},
 		{
 			name:         "PrintColoredMessage Error",
@@ -114,9 +103,6 @@ func TestPingCommand(t *testing.T) {
 			wantOutput:   "",
 			mockPrintErr: true,
 			writer:       &errorWriter{},
-			viperUI:      false,
-			viperMsg:     "Should fail print",
-			viperColor:   "white",
 		},
 	}
 
@@ -125,9 +111,6 @@ func TestPingCommand(t *testing.T) {
 			logBuf.Reset() // Clear the log buffer for each test
 			log.Debug().Str("test_case", tt.name).Msg("Starting test case")
 
-			// Set up a clean viper instance for this test
-			setupTestViper(tt.viperUI, tt.viperMsg, tt.viperColor)
-
 			pingRunner = tt.uiRunner
 
 			// Create a new root command for each test
@@ -142,20 +125,17 @@ func TestPingCommand(t *testing.T) {
 			pingCmd.Flags().String("message", "", "Custom output message")
 			pingCmd.Flags().String("color", "", "Output color")
 			pingCmd.Flags().Bool("ui", false, "Enable UI")
+			pingCmd.Flags().Int("count", 1, "Number of pings to send")
+			pingCmd.Flags().Duration("interval", time.Second, "Wait time between pings")
+			pingCmd.Flags().String("format", "text", "Output format (text, json)")
 
 			RootCmd.AddCommand(pingCmd)
 			RootCmd.SetArgs(append([]string{"ping"}, tt.args...))
 			RootCmd.SetOut(tt.writer)
-			RootCmd.SetErr(tt.writer)
+			RootCmd.SetErr(logBuf)
 			RootCmd.SilenceUsage = true
 			RootCmd.SilenceErrors = true
 
-			log.Debug().
-				Bool("viper_ui", viper.GetBool("app.ping.ui")).
-				Str("viper_msg", viper.GetString("app.ping.output_message")).
-				Str("viper_color", viper.GetString("app.ping.output_color")).
-				Msg("Viper config before execution")
-
 			err := RootCmd.Execute()
 
 			log.Debug().
@@ -180,3 +160,155 @@ func TestPingCommand(t *testing.T) {
 		})
 	}
 }
+
+// executePing runs the ping command with the given args and returns its output.
+func executePing(t *testing.T, args ...string) (string, error) {
+	t.Helper()
+	originalRoot, originalPing := RootCmd, pingCmd
+	t.Cleanup(func() { RootCmd, pingCmd = originalRoot, originalPing })
+	RootCmd = &cobra.Command{Use: binaryName}
+	pingCmd = &cobra.Command{
+		Use:  "ping",
+		RunE: runPing,
+	}
+	pingCmd.Flags().String("message", "", "Custom output message")
+	pingCmd.Flags().String("color", "", "Output color")
+	pingCmd.Flags().Bool("ui", false, "Enable UI")
+	pingCmd.Flags().Int("count", 1, "Number of pings to send")
+	pingCmd.Flags().Duration("interval", time.Second, "Wait time between pings")
+	pingCmd.Flags().String("format", "text", "Output format (text, json)")
+	RootCmd.AddCommand(pingCmd)
+
+	buf := &bytes.Buffer{}
+	RootCmd.SetArgs(append([]string{"ping"}, args...))
+	RootCmd.SetOut(buf)
+	RootCmd.SetErr(io.Discard)
+	RootCmd.SilenceUsage = true
+	RootCmd.SilenceErrors = true
+
+	err := RootCmd.Execute()
+	return buf.String(), err
+}
+
+func TestPingCommand_Repeat(t *testing.T) {
+	output, err := executePing(t, "--count", "3", "--interval", "0s")
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+
+	if got := strings.Count(output, "Pong\n"); got != 3 {
+		t.Errorf("Expected 3 pongs, got %d in %q", got, output)
+	}
+	if !strings.Contains(output, "--- ping statistics ---") {
+		t.Errorf("Expected statistics header, got %q", output)
+	}
+	if !strings.Contains(output, "3 pings, render time min/avg/max = ") {
+		t.Errorf("Expected statistics summary, got %q", output)
+	}
+}
+
+func TestPingCommand_JSONFormat(t *testing.T) {
+	output, err := executePing(t, "--count", "2", "--interval", "0s", "--format", "json", "--message", "Hi")
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+
+	var result pingStats
+	if err := json.Unmarshal([]byte(output), &result); err != nil {
+		t.Fatalf("Output is not valid JSON: %v\n%s", err, output)
+	}
+	if result.Message != "Hi" || result.Count != 2 || len(result.Replies) != 2 {
+		t.Errorf("Unexpected JSON result: %+v", result)
+	}
+	if result.Min > result.Avg || result.Avg > result.Max {
+		t.Errorf("Expected min <= avg <= max, got %+v", result)
+	}
+}
+
+func TestPingCommand_InvalidOptions(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    []string
+		wantErr string
+	}{
+		{"Zero count", []string{"--count", "0"}, "invalid count"},
+		{"Negative interval", []string{"--interval", "-1s"}, "invalid interval"},
+		{"Unknown format", []string{"--format", "xml"}, "invalid format"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := executePing(t, tt.args...)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestPingStats(t *testing.T) {
+	var s pingStats
+	s.add(1, 30*time.Millisecond)
+	s.add(2, 10*time.Millisecond)
+	s.add(3, 20*time.Millisecond)
+
+	if s.Min != 10*time.Millisecond || s.Avg != 20*time.Millisecond || s.Max != 30*time.Millisecond {
+		t.Errorf("Unexpected stats: min=%s avg=%s max=%s", s.Min, s.Avg, s.Max)
+	}
+	want := "3 pings, render time min/avg/max = 10ms/20ms/30ms"
+	if got := s.summary(); got != want {
+		t.Errorf("summary() = %q, want %q", got, want)
+	}
+}
+
+func TestSavePingConfig(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte("app:\n  log_level: debug\n"), 0o600); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+
+	cfg := viper.New()
+	cfg.SetConfigFile(path)
+	if err := cfg.ReadInConfig(); err != nil {
+		t.Fatalf("Failed to read config: %v", err)
+	}
+	rc := runctx.New("test", cfg, output.Printer{}, zerolog.Nop())
+
+	if err := savePingConfig(runctx.With(context.Background(), rc), "Saved", "cyan"); err != nil {
+		t.Fatalf("savePingConfig() error = %v", err)
+	}
+
+	v := viper.New()
+	v.SetConfigFile(path)
+	if err := v.ReadInConfig(); err != nil {
+		t.Fatalf("Failed to re-read config: %v", err)
+	}
+	if got := v.GetString("app.ping.output_message"); got != "Saved" {
+		t.Errorf("output_message = %q, want %q", got, "Saved")
+	}
+	if got := v.GetString("app.ping.output_color"); got != "cyan" {
+		t.Errorf("output_color = %q, want %q", got, "cyan")
+	}
+	if got := v.GetString("app.log_level"); got != "debug" {
+		t.Errorf("Expected existing keys to be preserved, log_level = %q", got)
+	}
+}
+
+func TestPingCommand_UIFallsBackWhenNonInteractive(t *testing.T) {
+	originalRunner, originalInteractive := pingRunner, interactive
+	defer func() { pingRunner, interactive = originalRunner, originalInteractive }()
+	runner := &mockUIRunner{}
+	pingRunner = runner
+	interactive = func(ui.Settings) bool { return false }
+
+	output, err := executePing(t, "--ui")
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	if runner.CalledWithMessage != "" {
+		t.Error("Expected the UI not to run in non-interactive mode")
+	}
+	if output != "Pong\n" {
+		t.Errorf("Expected the message to be printed instead, got %q", output)
+	}
+}
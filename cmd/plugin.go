@@ -0,0 +1,163 @@
+// cmd/plugin.go
+
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/peiman/ckeletin-go/internal/exitcode"
+	"github.com/peiman/ckeletin-go/internal/plugin"
+	"github.com/peiman/ckeletin-go/internal/xdg"
+	"github.com/rs/zerolog"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+)
+
+const (
+	// pluginAnnotation marks commands backed by an external plugin executable
+	pluginAnnotation = "plugin"
+	// pluginShutdownDelay is how long a plugin may take to exit after a shutdown signal
+	pluginShutdownDelay = 10 * time.Second
+)
+
+// pluginEnvKeys are the settings plugins receive. Others, such as endpoints,
+// proxies or credentials, are not passed on.
+var pluginEnvKeys = []string{
+	"app.assume_yes",
+	"app.config_dir",
+	"app.dry_run",
+	"app.log_level",
+	"app.no_input",
+	"app.non_interactive",
+	"app.output",
+}
+
+// unknownCommand reports whether args name a command root does not have, the
+// only case in which the plugin directories and PATH are searched
+func unknownCommand(root *cobra.Command, args []string) bool {
+	_, _, err := root.Find(args)
+	return err != nil
+}
+
+// registerPlugins adds a subcommand for every plugin executable found in the
+// plugins data directory or on PATH. Plugins never shadow built-in commands.
+func registerPlugins(root *cobra.Command, log zerolog.Logger) {
+	pluginDir := ""
+	if dataDir, err := xdg.DataDir(binaryName); err == nil {
+		pluginDir = filepath.Join(dataDir, "plugins")
+	}
+
+	for _, p := range plugin.Discover(binaryName, plugin.SearchDirs(pluginDir)) {
+		if existing, _, err := root.Find([]string{p.Name}); err == nil && existing != root {
+			if existing.Annotations[pluginAnnotation] == "" {
+				log.Debug().Str("plugin", p.Name).Str("path", p.Path).Msg("Plugin shadowed by built-in command")
+			}
+			continue
+		}
+		root.AddCommand(newPluginCmd(p))
+	}
+}
+
+// newPluginCmd creates the subcommand for p. Flag parsing is left to the plugin,
+// except for leading host flags such as --log-level, which are applied to the host
+// before its usual setup runs.
+func newPluginCmd(p plugin.Plugin) *cobra.Command {
+	var hostArgs int
+	return &cobra.Command{
+		Use:                p.Name,
+		Short:              fmt.Sprintf("Plugin provided by %s", p.Path),
+		DisableFlagParsing: true,
+		Annotations:        map[string]string{pluginAnnotation: p.Path, docsAnnotation: "plugins"},
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			n, err := parseHostFlags(cmd.Root().PersistentFlags(), args)
+			if err != nil {
+				return err
+			}
+			hostArgs = n
+			if pre := cmd.Root().PersistentPreRunE; pre != nil {
+				return pre(cmd, args[n:])
+			}
+			return nil
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runPlugin(cmd, p, args[hostArgs:])
+		},
+	}
+}
+
+// parseHostFlags sets the leading args that are host persistent flags on fs and
+// returns how many args were consumed. Parsing stops at the first other argument.
+func parseHostFlags(fs *pflag.FlagSet, args []string) (int, error) {
+	i := 0
+	for i < len(args) {
+		name, value, hasValue := strings.Cut(strings.TrimPrefix(args[i], "--"), "=")
+		if !strings.HasPrefix(args[i], "--") || name == "" {
+			break
+		}
+		f := fs.Lookup(name)
+		if f == nil {
+			break
+		}
+
+		consumed := 1
+		if !hasValue {
+			if f.Value.Type() == "bool" {
+				value = "true"
+			} else if i+1 < len(args) {
+				value = args[i+1]
+				consumed = 2
+			} else {
+				return i, fmt.Errorf("flag needs an argument: --%s", name)
+			}
+		}
+		if err := fs.Set(name, value); err != nil {
+			return i, fmt.Errorf("invalid argument %q for --%s: %w", value, name, err)
+		}
+		i += consumed
+	}
+	return i, nil
+}
+
+// runPlugin executes the plugin with the remaining arguments and the standard streams
+// of the command. The effective configuration is passed through the environment.
+func runPlugin(cmd *cobra.Command, p plugin.Plugin, args []string) error {
+	runContext(cmd).Logger.Debug().Str("plugin", p.Name).Str("path", p.Path).Strs("args", args).Msg("Running plugin")
+
+	c := exec.CommandContext(cmd.Context(), p.Path, args...)
+	c.Stdin = cmd.InOrStdin()
+	c.Stdout = cmd.OutOrStdout()
+	c.Stderr = cmd.ErrOrStderr()
+	c.Env = append(os.Environ(), pluginEnv(runContext(cmd).Config)...)
+	// The plugin gets Ctrl-C from the terminal itself; only kill it if it has not
+	// exited some time after the host was asked to shut down.
+	c.Cancel = func() error { return nil }
+	c.WaitDelay = pluginShutdownDelay
+
+	if err := c.Run(); err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			return &exitcode.ExitError{Code: exitErr.ExitCode(), Err: fmt.Errorf("plugin %s exited with code %d", p.Name, exitErr.ExitCode())}
+		}
+		return fmt.Errorf("failed to run plugin %s: %w", p.Name, err)
+	}
+	return nil
+}
+
+// pluginEnv exports the settings in pluginEnvKeys from cfg using the same naming
+// as the environment overrides (app.log_level -> APP_LOG_LEVEL), so plugins see
+// the host's effective values, including flags such as --log-level.
+func pluginEnv(cfg *viper.Viper) []string {
+	replacer := strings.NewReplacer(".", "_")
+	env := make([]string, 0, len(pluginEnvKeys))
+	for _, key := range pluginEnvKeys {
+		env = append(env, fmt.Sprintf("%s=%s", strings.ToUpper(replacer.Replace(key)), cfg.GetString(key)))
+	}
+	return env
+}
@@ -0,0 +1,47 @@
+// cmd/privilege.go
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/peiman/ckeletin-go/internal/config"
+	"github.com/peiman/ckeletin-go/internal/exitcode"
+	"github.com/peiman/ckeletin-go/internal/privilege"
+	"github.com/peiman/ckeletin-go/internal/runctx"
+)
+
+// isElevated reports whether the process runs as root or Administrator, can be replaced in tests
+var isElevated = privilege.Elevated
+
+func init() {
+	initRootGuardConfig()
+}
+
+func initRootGuardConfig() {
+	config.Register(config.Option{Key: "app.root_guard", Default: string(privilege.Warn), Description: "What to do when run as root or Administrator: off, warn or refuse"})
+}
+
+// checkPrivileges warns about or refuses running elevated, depending on app.root_guard in rc.
+// Files created as root in the user's config, cache and state directories cannot be
+// changed by later runs as the regular user.
+func checkPrivileges(rc *runctx.RunContext) error {
+	mode, err := privilege.ParseMode(rc.Config.GetString("app.root_guard"))
+	if err != nil {
+		return &exitcode.ConfigError{Err: err}
+	}
+	if mode == privilege.Off || !isElevated() {
+		return nil
+	}
+
+	reason := fmt.Sprintf("files it creates in your config, cache and state directories will be owned by %s", privilege.Description())
+	if user := os.Getenv("SUDO_USER"); user != "" {
+		reason = fmt.Sprintf("files it creates may no longer be writable by %s", user)
+	}
+	if mode == privilege.Refuse {
+		return fmt.Errorf("refusing to run as %s: %s (set app.root_guard to warn or off to allow it)", privilege.Description(), reason)
+	}
+	rc.Logger.Warn().Msgf("Running as %s: %s. Set app.root_guard to off to silence this warning.", privilege.Description(), reason)
+	return nil
+}
@@ -0,0 +1,69 @@
+// cmd/privilege_test.go
+
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/peiman/ckeletin-go/internal/config"
+	"github.com/peiman/ckeletin-go/internal/exitcode"
+	"github.com/peiman/ckeletin-go/internal/runctx"
+	"github.com/rs/zerolog"
+	"github.com/spf13/viper"
+)
+
+func TestCheckPrivileges(t *testing.T) {
+	origElevated := isElevated
+	defer func() { isElevated = origElevated }()
+	t.Setenv("SUDO_USER", "alice")
+
+	tests := []struct {
+		name     string
+		mode     string
+		elevated bool
+		wantErr  string
+		wantLog  string
+	}{
+		{name: "not elevated", mode: "refuse", elevated: false},
+		{name: "off", mode: "off", elevated: true},
+		{name: "warn by default", elevated: true, wantLog: "may no longer be writable by alice"},
+		{name: "refuse", mode: "refuse", elevated: true, wantErr: "refusing to run as"},
+		{name: "invalid", mode: "sometimes", elevated: true, wantErr: "invalid root guard mode"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := viper.New()
+			config.ApplyDefaults(cfg)
+			if tt.mode != "" {
+				cfg.Set("app.root_guard", tt.mode)
+			}
+			isElevated = func() bool { return tt.elevated }
+			logBuf := new(bytes.Buffer)
+			rc := &runctx.RunContext{Config: cfg, Logger: zerolog.New(logBuf)}
+
+			err := checkPrivileges(rc)
+			if tt.wantErr == "" && err != nil {
+				t.Fatalf("checkPrivileges() error = %v", err)
+			}
+			if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Fatalf("checkPrivileges() error = %v, want %q", err, tt.wantErr)
+			}
+			if tt.wantLog != "" && !strings.Contains(logBuf.String(), tt.wantLog) {
+				t.Errorf("Expected warning containing %q, got %q", tt.wantLog, logBuf.String())
+			}
+			if tt.wantLog == "" && logBuf.Len() > 0 {
+				t.Errorf("Unexpected log output %q", logBuf.String())
+			}
+		})
+	}
+
+	cfg := viper.New()
+	cfg.Set("app.root_guard", "sometimes")
+	var cfgErr *exitcode.ConfigError
+	if err := checkPrivileges(&runctx.RunContext{Config: cfg}); !errors.As(err, &cfgErr) {
+		t.Errorf("Expected a config error for an invalid mode, got %T", err)
+	}
+}
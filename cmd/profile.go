@@ -0,0 +1,110 @@
+// cmd/profile.go
+
+package cmd
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/peiman/ckeletin-go/internal/exitcode"
+	"github.com/peiman/ckeletin-go/internal/profile"
+	"github.com/peiman/ckeletin-go/internal/xdg"
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// autoProfilePath is the value of a profiling flag that writes into the cache
+// directory. The flags always take a value: with an optional value,
+// "--cpuprofile /tmp/cpu.out" would leave the path as an argument of the command.
+const autoProfilePath = "auto"
+
+// profileFlags are the hidden profiling flags and the file name suffix of
+// their default path in the cache directory
+var profileFlags = []struct {
+	name, usage, suffix string
+}{
+	{"cpuprofile", "Write a CPU profile to this file ('auto' for the cache directory)", "cpu.pprof"},
+	{"memprofile", "Write a heap profile to this file on exit ('auto' for the cache directory)", "mem.pprof"},
+	{"trace", "Write an execution trace to this file ('auto' for the cache directory)", "trace.out"},
+}
+
+func init() {
+	addProfileFlags(RootCmd.PersistentFlags())
+	afterExecute = append(afterExecute, finishProfiling)
+}
+
+// addProfileFlags adds the hidden profiling flags to fs
+func addProfileFlags(fs *pflag.FlagSet) {
+	for _, f := range profileFlags {
+		fs.String(f.name, "", f.usage)
+		if err := fs.MarkHidden(f.name); err != nil {
+			log.Fatal().Err(err).Msgf("Failed to hide '%s'", f.name)
+		}
+	}
+}
+
+// startProfiling starts the profiles requested with --cpuprofile, --memprofile
+// and --trace for the invocation of cmd. It runs once the config is loaded,
+// so "auto" paths are in the cache directory of dirs, which follow --config-dir.
+func startProfiling(cmd *cobra.Command, dirs xdg.Dirs) error {
+	paths := map[string]string{}
+	stamp := time.Now().Format("20060102-150405")
+	for _, f := range profileFlags {
+		flag := cmd.Flags().Lookup(f.name)
+		if flag == nil || !flag.Changed {
+			continue
+		}
+		path := flag.Value.String()
+		// "--cpuprofile --trace=x" takes the next flag as the file name
+		if strings.HasPrefix(path, "-") {
+			return &exitcode.UsageError{Err: fmt.Errorf("--%s needs a file name or '%s', got %q", f.name, autoProfilePath, path)}
+		}
+		if path == autoProfilePath {
+			var err error
+			if path, err = dirs.CacheFile(binaryName, fmt.Sprintf("%s-%s", stamp, f.suffix)); err != nil {
+				return err
+			}
+		}
+		paths[f.name] = path
+	}
+	if len(paths) == 0 {
+		return nil
+	}
+
+	stop, err := profile.Start(profile.Options{CPU: paths["cpuprofile"], Mem: paths["memprofile"], Trace: paths["trace"]})
+	if err != nil {
+		return err
+	}
+	inv := invocationFrom(cmd.Context())
+	inv.stopProfiling = stop
+	inv.profilePaths = nil
+	for _, f := range profileFlags {
+		if path := paths[f.name]; path != "" {
+			inv.profilePaths = append(inv.profilePaths, path)
+		}
+	}
+	return nil
+}
+
+// finishProfiling writes the profiles once the command finished
+func finishProfiling(cmd *cobra.Command, start time.Time, err error) {
+	if cmd == nil {
+		return
+	}
+	inv := invocationFrom(cmd.Context())
+	if inv.stopProfiling == nil {
+		return
+	}
+	stop := inv.stopProfiling
+	inv.stopProfiling = nil
+	log := runContext(cmd).Logger
+	if err := stop(); err != nil {
+		log.Warn().Err(err).Msg("Failed to write profiles")
+		return
+	}
+	for _, path := range inv.profilePaths {
+		log.Info().Str("path", path).Msg("Profile written")
+	}
+}
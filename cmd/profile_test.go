@@ -0,0 +1,97 @@
+// cmd/profile_test.go
+
+package cmd
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/peiman/ckeletin-go/internal/exitcode"
+	"github.com/peiman/ckeletin-go/internal/xdg"
+	"github.com/spf13/cobra"
+)
+
+// newProfileTestCmd returns a command with the profiling flags parsed from
+// args and the invocation it runs in
+func newProfileTestCmd(t *testing.T, args ...string) (*cobra.Command, *invocation) {
+	t.Helper()
+	c := &cobra.Command{Use: "test"}
+	addProfileFlags(c.Flags())
+	if err := c.Flags().Parse(args); err != nil {
+		t.Fatal(err)
+	}
+	inv := newInvocation(&App{}, time.Now())
+	c.SetContext(withInvocation(context.Background(), inv))
+	return c, inv
+}
+
+func TestProfiling(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("relies on XDG_CACHE_HOME for the default path")
+	}
+	cache := t.TempDir()
+	t.Setenv("XDG_CACHE_HOME", cache)
+	memPath := filepath.Join(t.TempDir(), "mem.pprof")
+
+	c, inv := newProfileTestCmd(t, "--cpuprofile", "auto", "--memprofile", memPath, "arg")
+	if args := c.Flags().Args(); len(args) != 1 || args[0] != "arg" {
+		t.Fatalf("Args() = %v, want only the command's argument", args)
+	}
+	if err := startProfiling(c, xdg.Dirs{}); err != nil {
+		t.Fatalf("startProfiling() error = %v", err)
+	}
+	finishProfiling(c, time.Now(), nil)
+
+	if len(inv.profilePaths) != 2 || inv.profilePaths[1] != memPath {
+		t.Fatalf("inv.profilePaths = %v", inv.profilePaths)
+	}
+	if dir := filepath.Join(cache, binaryName); filepath.Dir(inv.profilePaths[0]) != dir || !strings.HasSuffix(inv.profilePaths[0], "-cpu.pprof") {
+		t.Errorf("CPU profile %s not in the cache directory %s", inv.profilePaths[0], dir)
+	}
+	for _, path := range inv.profilePaths {
+		if info, err := os.Stat(path); err != nil || info.Size() == 0 {
+			t.Errorf("Profile %s not written: %v", path, err)
+		}
+	}
+	if inv.stopProfiling != nil {
+		t.Error("inv.stopProfiling not reset")
+	}
+}
+
+func TestProfiling_Disabled(t *testing.T) {
+	c, inv := newProfileTestCmd(t)
+	if err := startProfiling(c, xdg.Dirs{}); err != nil || inv.stopProfiling != nil {
+		t.Errorf("startProfiling() without flags = %v, running %v", err, inv.stopProfiling != nil)
+	}
+	// Nothing to do, must not panic
+	finishProfiling(c, time.Now(), nil)
+}
+
+func TestProfiling_Error(t *testing.T) {
+	c, inv := newProfileTestCmd(t, "--cpuprofile", "--memprofile=mem.pprof")
+	if err := startProfiling(c, xdg.Dirs{}); exitcode.Code(err) != exitcode.Usage || inv.stopProfiling != nil {
+		t.Errorf("Expected a usage error for a flag taken as the file name, got %v", err)
+	}
+
+	c, inv = newProfileTestCmd(t, "--trace="+filepath.Join(t.TempDir(), "missing", "trace.out"))
+	if err := startProfiling(c, xdg.Dirs{}); err == nil || inv.stopProfiling != nil {
+		t.Errorf("Expected an error for an unwritable trace, got %v", err)
+	}
+}
+
+func TestProfiling_ConfigDir(t *testing.T) {
+	root := t.TempDir()
+	c, inv := newProfileTestCmd(t, "--trace", autoProfilePath)
+	if err := startProfiling(c, xdg.Dirs{Root: root}); err != nil {
+		t.Fatalf("startProfiling() error = %v", err)
+	}
+	finishProfiling(c, time.Now(), nil)
+	if len(inv.profilePaths) != 1 || filepath.Dir(inv.profilePaths[0]) != filepath.Join(root, "cache") {
+		t.Errorf("inv.profilePaths = %v, want the cache directory of %s", inv.profilePaths, root)
+	}
+}
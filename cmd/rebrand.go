@@ -0,0 +1,117 @@
+// cmd/rebrand.go
+
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/peiman/ckeletin-go/internal/scaffold"
+	"github.com/spf13/cobra"
+)
+
+// gitDirty reports whether the git work tree at dir has uncommitted changes, can be replaced in tests
+var gitDirty = func(dir string) (bool, error) {
+	if _, err := os.Stat(filepath.Join(dir, ".git")); err != nil {
+		return false, nil
+	}
+	out, err := exec.Command("git", "-C", dir, "status", "--porcelain").Output()
+	if err != nil {
+		return false, fmt.Errorf("git status failed: %w", err)
+	}
+	return len(strings.TrimSpace(string(out))) > 0, nil
+}
+
+var rebrandCmd = &cobra.Command{
+	Use:         "rebrand",
+	Short:       "Rename the binary and module of a project created from this scaffold",
+	Annotations: map[string]string{docsAnnotation: "rebrand-command"},
+	Long: `Renames an existing project in place: the binary name (user-facing strings,
+config file name, Taskfile settings) and/or the Go module path (go.mod and all
+imports) are rewritten in every file, and files named after the binary are renamed.
+- Use --dry-run to print the changes as a diff without writing anything.
+- The project must be a clean git work tree so the change can be reviewed and
+  reverted; use --force to skip this check.`,
+	Args: cobra.NoArgs,
+	RunE: runRebrand,
+}
+
+func init() {
+	rebrandCmd.Flags().String("name", "", "New binary name")
+	rebrandCmd.Flags().String("module", "", "New Go module path")
+	rebrandCmd.Flags().String("dir", ".", "Project directory")
+	rebrandCmd.Flags().Bool("dry-run", false, "Print the changes without writing them")
+	rebrandCmd.Flags().Bool("force", false, "Rename even when the git work tree has uncommitted changes")
+	RootCmd.AddCommand(rebrandCmd)
+	addExamples(rebrandCmd, example{
+		Desc:  "Preview renaming the binary and module as a diff",
+		Line:  "rebrand --name newcli --module github.com/acme/newcli --dry-run",
+		NoRun: "needs a project created from the scaffold",
+	})
+}
+
+func runRebrand(cmd *cobra.Command, args []string) error {
+	name, _ := cmd.Flags().GetString("name")
+	modulePath, _ := cmd.Flags().GetString("module")
+	dir, _ := cmd.Flags().GetString("dir")
+	// The local flag prints a diff; it also follows the global --dry-run
+	dryRun, _ := cmd.Flags().GetBool("dry-run")
+	dryRun = dryRun || runContext(cmd).DryRun
+	force, _ := cmd.Flags().GetBool("force")
+
+	if name == "" && modulePath == "" {
+		return errors.New("nothing to rename, pass --name and/or --module")
+	}
+
+	oldModule, err := scaffold.ReadModulePath(dir)
+	if err != nil {
+		return err
+	}
+	if modulePath == "" {
+		modulePath = oldModule
+	} else if err := scaffold.ValidateModulePath(modulePath); err != nil {
+		return err
+	}
+	oldName := scaffold.ReadBinaryName(dir, oldModule)
+	if name == "" {
+		name = oldName
+	}
+
+	if !dryRun && !force {
+		dirty, err := gitDirty(dir)
+		if err != nil {
+			return err
+		}
+		if dirty {
+			return errors.New("the git work tree has uncommitted changes, commit them first or use --force")
+		}
+	}
+
+	files, err := scaffold.ListFiles(dir)
+	if err != nil {
+		return fmt.Errorf("failed to list project files: %w", err)
+	}
+	changes, err := scaffold.Plan(dir, files, scaffold.Rename{OldModule: oldModule, NewModule: modulePath, OldName: oldName, NewName: name})
+	if err != nil {
+		return err
+	}
+
+	out := cmd.OutOrStdout()
+	if dryRun {
+		for _, c := range changes {
+			fmt.Fprint(out, c.Diff())
+		}
+		_, err := fmt.Fprintf(out, "%d files would change (dry run)\n", len(changes))
+		return err
+	}
+
+	if err := scaffold.Apply(dir, changes); err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(out, "Renamed %s (%s) to %s (%s) in %d files\n", oldName, oldModule, name, modulePath, len(changes))
+	return err
+}
@@ -0,0 +1,120 @@
+// cmd/rebrand_test.go
+
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeRebrandProject(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	files := map[string]string{
+		"go.mod":      "module github.com/acme/oldcli\n",
+		"main.go":     "package main\n\nimport \"github.com/acme/oldcli/cmd\"\n",
+		"cmd/root.go": "package cmd\n\nvar binaryName = \"oldcli\"\n",
+	}
+	for name, content := range files {
+		p := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func setRebrandFlags(t *testing.T, flags map[string]string) {
+	t.Helper()
+	t.Cleanup(func() {
+		for _, name := range []string{"name", "module", "dir", "dry-run", "force"} {
+			f := rebrandCmd.Flags().Lookup(name)
+			_ = f.Value.Set(f.DefValue)
+			f.Changed = false
+		}
+		rebrandCmd.SetOut(nil)
+	})
+	for name, value := range flags {
+		if err := rebrandCmd.Flags().Set(name, value); err != nil {
+			t.Fatalf("Failed to set flag: %v", err)
+		}
+	}
+}
+
+func TestRunRebrand(t *testing.T) {
+	origDirty := gitDirty
+	defer func() { gitDirty = origDirty }()
+	gitDirty = func(string) (bool, error) { return false, nil }
+
+	dir := writeRebrandProject(t)
+	out := new(bytes.Buffer)
+	rebrandCmd.SetOut(out)
+	setRebrandFlags(t, map[string]string{"dir": dir, "name": "newcli", "dry-run": "true"})
+
+	if err := runRebrand(rebrandCmd, nil); err != nil {
+		t.Fatalf("runRebrand() dry run error = %v", err)
+	}
+	if !strings.Contains(out.String(), "-var binaryName = \"oldcli\"\n+var binaryName = \"newcli\"") ||
+		!strings.Contains(out.String(), "3 files would change") {
+		t.Errorf("Unexpected dry run output:\n%s", out.String())
+	}
+	if data, _ := os.ReadFile(filepath.Join(dir, "go.mod")); string(data) != "module github.com/acme/oldcli\n" {
+		t.Fatalf("Dry run modified go.mod: %q", data)
+	}
+
+	out.Reset()
+	if err := rebrandCmd.Flags().Set("dry-run", "false"); err != nil {
+		t.Fatal(err)
+	}
+	if err := rebrandCmd.Flags().Set("module", "github.com/acme/newcli"); err != nil {
+		t.Fatal(err)
+	}
+	if err := runRebrand(rebrandCmd, nil); err != nil {
+		t.Fatalf("runRebrand() error = %v", err)
+	}
+	if data, _ := os.ReadFile(filepath.Join(dir, "main.go")); !strings.Contains(string(data), `"github.com/acme/newcli/cmd"`) {
+		t.Errorf("main.go = %q", data)
+	}
+	if !strings.Contains(out.String(), "Renamed oldcli (github.com/acme/oldcli) to newcli (github.com/acme/newcli) in 3 files") {
+		t.Errorf("Unexpected output %q", out.String())
+	}
+}
+
+func TestRunRebrand_Errors(t *testing.T) {
+	origDirty := gitDirty
+	defer func() { gitDirty = origDirty }()
+	gitDirty = func(string) (bool, error) { return true, nil }
+
+	dir := writeRebrandProject(t)
+	tests := []struct {
+		name    string
+		flags   map[string]string
+		wantErr string
+	}{
+		{"Nothing to rename", map[string]string{"dir": dir}, "nothing to rename"},
+		{"Invalid module", map[string]string{"dir": dir, "module": "Bad Path"}, "invalid module path"},
+		{"Dirty work tree", map[string]string{"dir": dir, "name": "newcli"}, "uncommitted changes"},
+		{"Not a module", map[string]string{"dir": t.TempDir(), "name": "newcli"}, "not a Go module"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setRebrandFlags(t, tt.flags)
+			if err := runRebrand(rebrandCmd, nil); err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+
+	setRebrandFlags(t, map[string]string{"dir": dir, "name": "newcli", "force": "true"})
+	rebrandCmd.SetOut(new(bytes.Buffer))
+	if err := runRebrand(rebrandCmd, nil); err != nil {
+		t.Errorf("Expected --force to skip the git check, got %v", err)
+	}
+}
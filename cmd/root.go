@@ -3,18 +3,28 @@
 package cmd
 
 import (
+	"errors"
 	"fmt"
+	"io/fs"
 	"os"
+	"path/filepath"
 	"strings"
+	"time"
 
-	"github.com/peiman/ckeletin-go/internal/logger"
-	"github.com/rs/zerolog/log"
+	"github.com/peiman/ckeletin-go/internal/config"
+	"github.com/peiman/ckeletin-go/internal/exitcode"
+	"github.com/peiman/ckeletin-go/internal/output"
+	"github.com/peiman/ckeletin-go/internal/runctx"
+	"github.com/peiman/ckeletin-go/internal/ui"
+	"github.com/peiman/ckeletin-go/internal/xdg"
+	"github.com/peiman/ckeletin-go/pkg/prompt"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 )
 
+// Build information, set with -ldflags -X when building; the binary name is
+// also rewritten by rebrand. They never change at runtime.
 var (
-	cfgFile    string
 	Version    = "dev"
 	Commit     = ""
 	Date       = ""
@@ -23,64 +33,277 @@ var (
 
 // Export RootCmd so that tests in other packages can manipulate it without getters/setters.
 var RootCmd = &cobra.Command{
-	Use:   binaryName,
-	Short: "A scaffold for building professional CLI applications in Go",
+	Use: binaryName,
+	// Errors and usage hints are printed by PrintError.
+	SilenceErrors: true,
+	SilenceUsage:  true,
+	Short:         "A scaffold for building professional CLI applications in Go",
 	Long: fmt.Sprintf(`%s is a scaffold project that helps you kickstart your Go CLI applications.
-It integrates Cobra, Viper, Zerolog, and Bubble Tea, along with a testing framework.`, binaryName),
+It integrates Cobra, Viper, Zerolog, and Bubble Tea, along with a testing framework.
+
+%s`, binaryName, exitcode.Help()),
 	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
-		if err := initConfig(); err != nil {
+		inv := invocationFrom(cmd.Context())
+		inv.markStartup("parse")
+		if isCompletionRequest(cmd) {
+			startCompletion(cmd)
+			return nil
+		}
+		// Read from the root, as plugin commands parse the host flags there
+		configFile, _ := cmd.Root().PersistentFlags().GetString("config")
+		// Each invocation loads its own config instead of sharing one
+		cfg, err := newConfig(cmd, configFile)
+		if err != nil {
+			return &exitcode.ConfigError{Err: err}
+		}
+		inv.markStartup("config")
+		// Before anything is written, so all output uses the same symbols
+		settings, err := uiSettings(cfg)
+		if err != nil {
+			return err
+		}
+		// Everything logged from here on carries the invocation ID
+		rc := newRunContext(cmd, cfg, settings)
+		logConfigFile(rc)
+		inv.markStartup("logger")
+		if err := startProfiling(cmd, rc.Dirs); err != nil {
 			return err
 		}
-		if err := logger.Init(nil); err != nil {
-			return fmt.Errorf("failed to initialize logger: %w", err)
+		suggestTheme(rc)
+		if err := checkPrivileges(rc); err != nil {
+			return err
 		}
+		// Without a terminal, prompts still read answers piped to stdin unless
+		// input is disabled explicitly.
+		ctx := prompt.WithOptions(cmd.Context(), prompt.Options{
+			AssumeYes: cfg.GetBool("app.assume_yes"),
+			NoInput:   settings.NonInteractive,
+			In:        cmd.InOrStdin(),
+			Out:       cmd.ErrOrStderr(),
+			Unicode:   settings.Unicode,
+		})
+		// Library code takes the logger and UI settings from the context
+		ctx = ui.WithSettings(rc.Logger.WithContext(ctx), settings)
+		cmd.SetContext(runctx.With(ctx, rc))
+		if err := applyTimeout(cmd, cfg); err != nil {
+			return err
+		}
+		startUpdateNotice(cmd)
+		inv.markStartup("setup")
+		return nil
+	},
+	PersistentPostRunE: func(cmd *cobra.Command, args []string) error {
+		finishUpdateNotice(cmd)
 		return nil
 	},
 }
 
+// Execute runs the CLI with the arguments and streams of the process until
+// it finishes or is interrupted by a signal
 func Execute() error {
-	RootCmd.Version = fmt.Sprintf("%s, commit %s, built at %s", Version, Commit, Date)
-	return RootCmd.Execute()
+	return NewApp().Main()
 }
 
-func init() {
-	RootCmd.PersistentFlags().StringVar(&cfgFile, "config", "", fmt.Sprintf("Config file (default is $HOME/.%s.yaml)", binaryName))
-	if err := viper.BindPFlag("config", RootCmd.PersistentFlags().Lookup("config")); err != nil {
-		log.Fatal().Err(err).Msg("Failed to bind 'config' flag")
+// afterExecute are called with the executed command, its start time and final
+// error. They record the invocation and must not change the result.
+var afterExecute []func(cmd *cobra.Command, start time.Time, err error)
+
+// commandName returns the command path below the root, e.g. "dev config watch"
+func commandName(cmd *cobra.Command) string {
+	if !cmd.HasParent() {
+		return ""
 	}
+	return strings.TrimPrefix(cmd.CommandPath(), cmd.Root().Name()+" ")
+}
 
+func init() {
+	config.Register(
+		config.Option{Key: "app.log_level", Default: "info", Description: "Log level: trace, debug, info, warn, error, fatal or panic"},
+		config.Option{Key: "app.output", Default: output.Text, Description: "Output format for command results: text, json or yaml"},
+		config.Option{Key: "app.non_interactive", Default: false, Description: "Disable interactive UIs, prompts and animations"},
+		config.Option{Key: "app.no_input", Default: false, Description: "Never prompt; questions without a default fail"},
+		config.Option{Key: "app.assume_yes", Default: false, Description: "Answer yes to confirmations and use defaults for other questions"},
+		config.Option{Key: "app.dry_run", Default: false, Description: "Show what commands would change without changing anything"},
+		config.Option{Key: "app.config_dir", Default: "", Description: "Directory holding the config file and the cache, data and state directories, instead of the usual locations"},
+	)
+
+	RootCmd.PersistentFlags().String("config", "", fmt.Sprintf("Config file (default is $HOME/.%s.yaml)", binaryName))
+	RootCmd.PersistentFlags().String("config-dir", "", "Use config.yaml and the cache, data and state directories in this directory instead of the usual locations")
 	RootCmd.PersistentFlags().String("log-level", "info", "Set the log level (trace, debug, info, warn, error, fatal, panic)")
-	if err := viper.BindPFlag("app.log_level", RootCmd.PersistentFlags().Lookup("log-level")); err != nil {
-		log.Fatal().Err(err).Msg("Failed to bind 'log-level'")
+	RootCmd.PersistentFlags().Bool("non-interactive", false, "Disable interactive UIs, prompts and animations (implied without a terminal or in CI)")
+	RootCmd.PersistentFlags().BoolP("yes", "y", false, "Answer yes to confirmations and use defaults for other questions")
+	RootCmd.PersistentFlags().Bool("no-input", false, "Never prompt; questions without a default fail (implies --non-interactive)")
+	RootCmd.PersistentFlags().Bool("dry-run", false, "Show what commands would change without changing anything")
+	RootCmd.PersistentFlags().Bool("no-pager", false, "Do not pipe long output through a pager")
+	RootCmd.PersistentFlags().Bool("copy", false, "Also copy command output to the system clipboard")
+	RootCmd.PersistentFlags().StringP("output", "o", output.Text, fmt.Sprintf("Output format for command results (%s)", strings.Join(output.Formats, ", ")))
+
+	bindFlags(RootCmd.PersistentFlags(), map[string]string{
+		"config":          "config",
+		"config-dir":      "app.config_dir",
+		"log-level":       "app.log_level",
+		"non-interactive": "app.non_interactive",
+		"yes":             "app.assume_yes",
+		"no-input":        "app.no_input",
+		"dry-run":         "app.dry_run",
+		"output":          "app.output",
+	})
+}
+
+// newConfig returns the config of an invocation of cmd, loaded from
+// configFile or the default location, with the flags of cmd bound. Watchers
+// reacting to changes of the file load a new instance too: expanded
+// placeholders and resolved paths are overrides, which would hide the new
+// contents of the file if the old instance were reloaded in place.
+func newConfig(cmd *cobra.Command, configFile string) (*viper.Viper, error) {
+	v := viper.New()
+	if err := bindConfigFlags(v, cmd); err != nil {
+		return nil, err
+	}
+	if err := initConfig(v, invocationFrom(cmd.Context()).app.config, configFile); err != nil {
+		return nil, err
 	}
+	return v, nil
 }
 
-func initConfig() error {
-	if cfgFile != "" {
-		viper.SetConfigFile(cfgFile)
-	} else {
+// initConfig loads the defaults of all registered options, replaced by the
+// values of base if not nil, the config from configFile, or from the default
+// location when it is empty, and the environment into v
+func initConfig(v, base *viper.Viper, configFile string) error {
+	config.ApplyDefaults(v)
+	if base != nil {
+		for _, key := range base.AllKeys() {
+			v.SetDefault(key, base.Get(key))
+		}
+	}
+	v.SetEnvKeyReplacer(envKeyReplacer)
+	v.AutomaticEnv()
+
+	dir, err := configDir(v)
+	if err != nil {
+		return err
+	}
+	dirs := xdg.Dirs{Root: dir}
+
+	switch {
+	case configFile != "":
+		v.SetConfigFile(configFile)
+	case dir != "":
+		v.AddConfigPath(dir)
+		v.SetConfigName("config")
+	default:
 		home, err := os.UserHomeDir()
 		if err != nil {
 			return err
 		}
-		viper.AddConfigPath(home)
-		viper.SetConfigName(fmt.Sprintf(".%s", binaryName))
+		v.AddConfigPath(home)
+		v.SetConfigName(fmt.Sprintf(".%s", binaryName))
 	}
 
-	viper.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
-	viper.AutomaticEnv()
-	viper.SetDefault("app.log_level", "info")
-
-	if err := viper.ReadInConfig(); err != nil {
-		if _, ok := err.(viper.ConfigFileNotFoundError); ok {
-			log.Info().Msg("No config file found, using defaults and environment variables")
-		} else {
-			log.Error().Err(err).Msg("Failed to read config file")
+	if err := v.ReadInConfig(); err != nil {
+		if _, ok := err.(viper.ConfigFileNotFoundError); !ok {
 			return fmt.Errorf("failed to read config file: %w", err)
 		}
 	} else {
-		log.Info().Str("config_file", viper.ConfigFileUsed()).Msg("Using config file")
+		if err := checkConfigVersion(v); err != nil {
+			return err
+		}
+		if err := mergeIncludes(v, dirs); err != nil {
+			return err
+		}
+	}
+
+	if err := config.ExpandAll(v, config.Placeholders(dirs, binaryName)); err != nil {
+		return fmt.Errorf("invalid config value: %w", err)
+	}
+	if err := config.ResolvePaths(v, dirs, binaryName); err != nil {
+		return fmt.Errorf("invalid config path: %w", err)
+	}
+	if err := config.Validate(v); err != nil {
+		return fmt.Errorf("invalid config value: %w", err)
 	}
 
 	return nil
 }
+
+// logConfigFile logs the config file the invocation of rc loaded and
+// warnings about its format, once its logger is set up
+func logConfigFile(rc *runctx.RunContext) {
+	if path := rc.Config.ConfigFileUsed(); path != "" {
+		rc.Logger.Debug().Str("config_file", path).Msg("Using config file")
+		warnConfigVersion(rc)
+		return
+	}
+	rc.Logger.Debug().Msg("No config file found, using defaults and environment variables")
+}
+
+// configDir returns the absolute path of app.config_dir in v, which must be
+// an existing directory, or "" when it is not set
+func configDir(v *viper.Viper) (string, error) {
+	dir := v.GetString("app.config_dir")
+	if dir == "" {
+		return "", nil
+	}
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return "", fmt.Errorf("invalid config dir: %w", err)
+	}
+	info, err := os.Stat(dir)
+	if err != nil {
+		return "", fmt.Errorf("invalid config dir: %w", err)
+	}
+	if !info.IsDir() {
+		return "", fmt.Errorf("invalid config dir: %s is not a directory", dir)
+	}
+	return dir, nil
+}
+
+// envKeyReplacer maps config keys to environment variables, e.g. app.log_level to APP_LOG_LEVEL
+var envKeyReplacer = strings.NewReplacer(".", "_")
+
+// configFilePath returns the config file cfg loaded, or the default location when none was loaded.
+func configFilePath(cfg *viper.Viper) (string, error) {
+	if path := cfg.ConfigFileUsed(); path != "" {
+		return path, nil
+	}
+	dir, err := configDir(cfg)
+	if err != nil {
+		return "", err
+	}
+	if dir != "" {
+		return filepath.Join(dir, "config.yaml"), nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, fmt.Sprintf(".%s.yaml", binaryName)), nil
+}
+
+// writeConfigValues updates the given keys in the config file cfg loaded,
+// creating it if needed, and returns its path. Other keys in the file are
+// preserved and, unlike viper.WriteConfig, defaults and environment overrides
+// are not written out.
+func writeConfigValues(cfg *viper.Viper, values map[string]interface{}) (string, error) {
+	path, err := configFilePath(cfg)
+	if err != nil {
+		return "", err
+	}
+
+	v := viper.New()
+	v.SetConfigFile(path)
+	if err := v.ReadInConfig(); errors.Is(err, fs.ErrNotExist) {
+		// New files start out in the current format
+		v.Set(config.VersionKey, configMigrations.Latest())
+	} else if err != nil {
+		return "", fmt.Errorf("failed to read config file: %w", err)
+	}
+	for key, value := range values {
+		v.Set(key, value)
+	}
+
+	if err := v.WriteConfigAs(path); err != nil {
+		return "", fmt.Errorf("failed to write config file: %w", err)
+	}
+	return path, nil
+}
@@ -3,25 +3,19 @@
 package cmd
 
 import (
-	"bytes"
 	"errors"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 
-	"github.com/rs/zerolog"
-	"github.com/rs/zerolog/log"
+	"github.com/peiman/ckeletin-go/internal/ui"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 )
 
 func TestInitConfig_InvalidConfigFile(t *testing.T) {
-	cfgFile = "/invalid/path/to/config.yaml"
-	defer func() { cfgFile = "" }()
-
-	buf := new(bytes.Buffer)
-	log.Logger = zerolog.New(buf)
-
-	err := initConfig()
+	err := initConfig(viper.New(), nil, "/invalid/path/to/config.yaml")
 
 	if err == nil {
 		t.Errorf("Expected initConfig() to return an error for invalid config file")
@@ -34,12 +28,110 @@ func TestInitConfig_InvalidConfigFile(t *testing.T) {
 }
 
 func TestInitConfig_NoConfigFile(t *testing.T) {
-	viper.Reset()
-	cfgFile = ""
-	err := initConfig()
+	v := viper.New()
+	err := initConfig(v, nil, "")
 	if err != nil {
 		t.Errorf("Expected no error, got %v", err)
 	}
+	// Defaults of registered options are loaded into the instance
+	if got := v.GetString("app.ping.output_message"); got != "Pong" {
+		t.Errorf("app.ping.output_message = %q, want the default", got)
+	}
+}
+
+func TestInitConfig_Isolated(t *testing.T) {
+	dir := t.TempDir()
+	first, second := filepath.Join(dir, "first.yaml"), filepath.Join(dir, "second.yaml")
+	for path, msg := range map[string]string{first: "one", second: "two"} {
+		if err := os.WriteFile(path, []byte("app:\n  ping:\n    output_message: "+msg+"\n"), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	v1, v2 := viper.New(), viper.New()
+	if err := initConfig(v1, nil, first); err != nil {
+		t.Fatalf("initConfig(first) error = %v", err)
+	}
+	if err := initConfig(v2, nil, second); err != nil {
+		t.Fatalf("initConfig(second) error = %v", err)
+	}
+	if got := v1.GetString("app.ping.output_message"); got != "one" {
+		t.Errorf("first config message = %q, want one", got)
+	}
+	if got := v2.GetString("app.ping.output_message"); got != "two" {
+		t.Errorf("second config message = %q, want two", got)
+	}
+}
+
+func TestInitConfig_Base(t *testing.T) {
+	base := viper.New()
+	base.Set("app.ping.output_message", "from base")
+	base.Set("app.ping.output_color", "red")
+	t.Setenv("APP_PING_OUTPUT_COLOR", "blue")
+
+	v := viper.New()
+	if err := initConfig(v, base, ""); err != nil {
+		t.Fatalf("initConfig() error = %v", err)
+	}
+	if got := v.GetString("app.ping.output_message"); got != "from base" {
+		t.Errorf("app.ping.output_message = %q, want the value of the base config", got)
+	}
+	if got := v.GetString("app.ping.output_color"); got != "blue" {
+		t.Errorf("app.ping.output_color = %q, want the environment to override the base config", got)
+	}
+	if got := v.GetInt("app.ping.count"); got != 1 {
+		t.Errorf("app.ping.count = %d, want the registered default", got)
+	}
+}
+
+func TestInitConfig_ExpandsPlaceholders(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.yaml")
+	content := "app:\n  log_file: ${env:CKELETIN_TEST_DIR}/${binary_name}.log\n  broken: ${nope}\n"
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("CKELETIN_TEST_DIR", "/srv/logs")
+
+	err := initConfig(viper.New(), nil, path)
+	if err == nil || !strings.Contains(err.Error(), "app.broken: unknown placeholder ${nope}") {
+		t.Fatalf("initConfig() error = %v, want unknown placeholder", err)
+	}
+
+	if err := os.WriteFile(path, []byte(strings.Replace(content, "  broken: ${nope}\n", "", 1)), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	v := viper.New()
+	if err := initConfig(v, nil, path); err != nil {
+		t.Fatalf("initConfig() error = %v", err)
+	}
+	if got, want := v.GetString("app.log_file"), "/srv/logs/"+binaryName+".log"; got != want {
+		t.Errorf("app.log_file = %q, want %q", got, want)
+	}
+}
+
+func TestInitConfig_ConfigDir(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "config.yaml"), []byte("app:\n  serve:\n    message: bundled\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("APP_CONFIG_DIR", dir)
+
+	v := viper.New()
+	if err := initConfig(v, nil, ""); err != nil {
+		t.Fatalf("initConfig() error = %v", err)
+	}
+	if got := v.GetString("app.serve.message"); got != "bundled" {
+		t.Errorf("app.serve.message = %q, want the value from %s", got, dir)
+	}
+	rc := newRunContext(&cobra.Command{}, v, ui.Settings{})
+	if state, _ := rc.Dirs.StateDir(binaryName); state != filepath.Join(dir, "state") {
+		t.Errorf("StateDir() = %q, want it inside %s", state, dir)
+	}
+
+	t.Setenv("APP_CONFIG_DIR", filepath.Join(dir, "missing"))
+	if err := initConfig(viper.New(), nil, ""); err == nil || !strings.Contains(err.Error(), "invalid config dir") {
+		t.Errorf("initConfig() error = %v, want invalid config dir", err)
+	}
 }
 
 func TestExecute_ErrorPropagation(t *testing.T) {
@@ -61,3 +153,19 @@ func TestExecute_ErrorPropagation(t *testing.T) {
 		t.Errorf("Expected 'some error', got %v", err)
 	}
 }
+
+func BenchmarkInitConfig(b *testing.B) {
+	path := filepath.Join(b.TempDir(), "config.yaml")
+	content := "app:\n  log_level: warn\n  ping:\n    output_message: ${binary_name}\n"
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		b.Fatal(err)
+	}
+	b.Setenv("APP_PING_COUNT", "3")
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if err := initConfig(viper.New(), nil, path); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,205 @@
+// cmd/run.go
+
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/peiman/ckeletin-go/internal/config"
+	"github.com/peiman/ckeletin-go/internal/exitcode"
+	"github.com/peiman/ckeletin-go/internal/taskfile"
+	"github.com/spf13/cobra"
+)
+
+// taskRun describes one run of a Taskfile task
+type taskRun struct {
+	Taskfile string
+	Task     string
+	Args     []string
+	Stdin    io.Reader
+	Stdout   io.Writer
+	Stderr   io.Writer
+}
+
+// taskfileDir is where the search for the Taskfile starts, can be replaced in tests
+var taskfileDir = os.Getwd
+
+// runTask runs a task with the task binary, can be replaced in tests
+var runTask = func(ctx context.Context, r taskRun) error {
+	args := []string{"--taskfile", r.Taskfile, r.Task}
+	if len(r.Args) > 0 {
+		args = append(append(args, "--"), r.Args...)
+	}
+	c := exec.CommandContext(ctx, "task", args...)
+	c.Dir = filepath.Dir(r.Taskfile)
+	c.Stdin = r.Stdin
+	c.Stdout = r.Stdout
+	c.Stderr = r.Stderr
+	if err := c.Run(); err != nil {
+		if errors.Is(err, exec.ErrNotFound) {
+			return errors.New("task not found, install it from https://taskfile.dev/installation/")
+		}
+		return err
+	}
+	return nil
+}
+
+var runCmd = &cobra.Command{
+	Use:         "run [TASK] [-- ARGS...]",
+	Short:       "Run a task from the project's Taskfile",
+	Annotations: map[string]string{docsAnnotation: "run-command"},
+	Long: `Runs a task defined in the Taskfile of the current directory or its nearest
+parent with Task (https://taskfile.dev), so project workflows are available
+from the same CLI.
+- Without a task, lists the tasks and their descriptions.
+- Arguments after -- are passed to the task as CLI_ARGS.
+- Prints the task and its duration on stderr (disable with --progress=false).
+- Exits with the exit code of the task when it fails.`,
+	Args:              cobra.ArbitraryArgs,
+	ValidArgsFunction: completeTasks,
+	RunE:              runRun,
+}
+
+func init() {
+	initRunConfig()
+	configFlags(runCmd, "app.run")
+	runCmd.Flags().String("format", "", "Output format of the task list, overrides --output (text, json, yaml)")
+
+	addExamples(runCmd,
+		example{Desc: "List the tasks of the Taskfile", Line: "run", NoRun: "needs a Taskfile"},
+		example{Desc: "Run a task and pass arguments to it as CLI_ARGS", Line: "run test -- -race", NoRun: "needs a Taskfile"},
+	)
+	RootCmd.AddCommand(runCmd)
+}
+
+func initRunConfig() {
+	config.Register(config.Option{Key: "app.run.progress", Flag: "progress", Default: true, Description: "Show the running task and its duration on stderr"})
+}
+
+func runRun(cmd *cobra.Command, args []string) error {
+	rc := runContext(cmd)
+	progress := rc.Config.GetBool("app.run.progress")
+	if cmd.Flags().Changed("progress") {
+		progress, _ = cmd.Flags().GetBool("progress")
+	}
+
+	path, tasks, err := loadTasks()
+	if err != nil {
+		return err
+	}
+	if len(args) == 0 {
+		if tasks == nil {
+			tasks = []taskfile.Task{}
+		}
+		return renderOutput(cmd, taskList(tasks))
+	}
+
+	task, ok := taskfile.Lookup(tasks, args[0])
+	if !ok {
+		return &exitcode.UsageError{Err: fmt.Errorf("unknown task %q in %s, run '%s run' to list the tasks", args[0], path, binaryName)}
+	}
+	rc.Logger.Debug().Str("taskfile", path).Str("task", task.Name).Strs("args", args[1:]).Msg("Running task")
+
+	stderr := cmd.ErrOrStderr()
+	glyphs, theme := rc.UI.Caps(stderr).Glyphs(), rc.UI.Theme
+	if progress {
+		fmt.Fprintf(stderr, "%s task %s\n", glyphs.Running, task.Name)
+	}
+	start := time.Now()
+	err = runTask(cmd.Context(), taskRun{
+		Taskfile: path,
+		Task:     task.Name,
+		Args:     args[1:],
+		Stdin:    cmd.InOrStdin(),
+		Stdout:   cmd.OutOrStdout(),
+		Stderr:   stderr,
+	})
+	elapsed := time.Since(start).Round(time.Millisecond)
+
+	var exitErr *exec.ExitError
+	switch {
+	case err == nil:
+		if progress {
+			fmt.Fprintf(stderr, "%s task %s finished in %s\n", theme.Pass(glyphs), task.Name, elapsed)
+		}
+		return nil
+	case errors.As(err, &exitErr) && cmd.Context().Err() == nil:
+		if progress {
+			fmt.Fprintf(stderr, "%s task %s failed after %s\n", theme.Fail(glyphs), task.Name, elapsed)
+		}
+		return &exitcode.ExitError{Code: exitErr.ExitCode(), Err: fmt.Errorf("task %s exited with code %d", task.Name, exitErr.ExitCode())}
+	case cmd.Context().Err() != nil:
+		return cmd.Context().Err()
+	}
+	return fmt.Errorf("failed to run task %s: %w", task.Name, err)
+}
+
+// loadTasks returns the Taskfile for the working directory and its tasks
+func loadTasks() (string, []taskfile.Task, error) {
+	dir, err := taskfileDir()
+	if err != nil {
+		return "", nil, err
+	}
+	path, err := taskfile.Find(dir)
+	if errors.Is(err, taskfile.ErrNotFound) {
+		return "", nil, fmt.Errorf("no Taskfile found in %s or its parent directories", dir)
+	}
+	if err != nil {
+		return "", nil, err
+	}
+	tasks, err := taskfile.Load(path)
+	if err != nil {
+		return "", nil, &exitcode.ConfigError{Err: err}
+	}
+	return path, tasks, nil
+}
+
+// completeTasks completes the task names of the nearest Taskfile
+func completeTasks(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveDefault
+	}
+	_, tasks, err := loadTasks()
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	var names []string
+	for _, t := range tasks {
+		for _, name := range append([]string{t.Name}, t.Aliases...) {
+			if strings.HasPrefix(name, toComplete) {
+				names = append(names, name+"\t"+t.Desc)
+			}
+		}
+	}
+	return names, cobra.ShellCompDirectiveNoFileComp
+}
+
+// taskList is the result of run without a task
+type taskList []taskfile.Task
+
+// WriteText prints a table of the tasks
+func (l taskList) WriteText(w io.Writer) error {
+	if len(l) == 0 {
+		_, err := fmt.Fprintln(w, "No tasks.")
+		return err
+	}
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "TASK\tDESCRIPTION")
+	for _, t := range l {
+		name := t.Name
+		if len(t.Aliases) > 0 {
+			name += " (" + strings.Join(t.Aliases, ", ") + ")"
+		}
+		fmt.Fprintf(tw, "%s\t%s\n", name, t.Desc)
+	}
+	return tw.Flush()
+}
@@ -0,0 +1,160 @@
+// cmd/run_test.go
+
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/peiman/ckeletin-go/internal/exitcode"
+	"github.com/peiman/ckeletin-go/pkg/termcaps"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+const runTaskfile = `version: '3'
+tasks:
+  build:
+    desc: Build the binary
+    aliases: [b]
+    cmds: [go build]
+  test:
+    desc: Run tests
+    cmds: [go test ./...]
+`
+
+// useTaskfile makes the run command find a Taskfile with content in a temp dir
+func useTaskfile(t *testing.T, content string) string {
+	t.Helper()
+	dir := t.TempDir()
+	if content != "" {
+		if err := os.WriteFile(filepath.Join(dir, "Taskfile.yml"), []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	origDir := taskfileDir
+	taskfileDir = func() (string, error) { return dir, nil }
+	t.Cleanup(func() {
+		taskfileDir = origDir
+		runCmd.Flags().VisitAll(func(f *pflag.Flag) {
+			_ = f.Value.Set(f.DefValue)
+			f.Changed = false
+		})
+	})
+	return dir
+}
+
+func TestRunRun_List(t *testing.T) {
+	useTaskfile(t, runTaskfile)
+	out := new(bytes.Buffer)
+	runCmd.SetOut(out)
+	defer runCmd.SetOut(nil)
+
+	if err := runRun(runCmd, nil); err != nil {
+		t.Fatalf("runRun() error = %v", err)
+	}
+	if !strings.Contains(out.String(), "build (b)") || !strings.Contains(out.String(), "Run tests") {
+		t.Errorf("Unexpected task list:\n%s", out.String())
+	}
+
+	out.Reset()
+	_ = runCmd.Flags().Set("format", "json")
+	if err := runRun(runCmd, nil); err != nil {
+		t.Fatalf("runRun() error = %v", err)
+	}
+	var tasks []map[string]interface{}
+	if err := json.Unmarshal(out.Bytes(), &tasks); err != nil || len(tasks) != 2 || tasks[0]["name"] != "build" {
+		t.Errorf("Unexpected JSON task list %s (%v)", out.String(), err)
+	}
+}
+
+func TestRunRun_Task(t *testing.T) {
+	dir := useTaskfile(t, runTaskfile)
+	origRun := runTask
+	defer func() { runTask = origRun }()
+	var got taskRun
+	runTask = func(ctx context.Context, r taskRun) error {
+		got = r
+		return nil
+	}
+
+	errOut := new(bytes.Buffer)
+	runCmd.SetErr(errOut)
+	runCmd.SetContext(context.Background())
+	defer runCmd.SetErr(nil)
+
+	if err := runRun(runCmd, []string{"b", "-v"}); err != nil {
+		t.Fatalf("runRun() error = %v", err)
+	}
+	if got.Task != "build" || got.Taskfile != filepath.Join(dir, "Taskfile.yml") || len(got.Args) != 1 || got.Args[0] != "-v" {
+		t.Errorf("Unexpected task run %+v", got)
+	}
+	g := termcaps.For(errOut).Glyphs()
+	if !strings.Contains(errOut.String(), g.Running+" task build") || !strings.Contains(errOut.String(), g.Success+" task build finished in") {
+		t.Errorf("Unexpected progress output %q", errOut.String())
+	}
+
+	errOut.Reset()
+	_ = runCmd.Flags().Set("progress", "false")
+	if err := runRun(runCmd, []string{"test"}); err != nil || errOut.Len() != 0 {
+		t.Errorf("runRun() = %v with progress output %q, want none", err, errOut.String())
+	}
+
+	if err := runRun(runCmd, []string{"deploy"}); exitcode.Code(err) != exitcode.Usage {
+		t.Errorf("Expected a usage error for an unknown task, got %v", err)
+	}
+}
+
+func TestRunRun_TaskFails(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses sh to produce an exit code")
+	}
+	useTaskfile(t, runTaskfile)
+	origRun := runTask
+	defer func() { runTask = origRun }()
+	runTask = func(ctx context.Context, r taskRun) error {
+		return exec.CommandContext(ctx, "sh", "-c", "exit 7").Run()
+	}
+
+	errOut := new(bytes.Buffer)
+	runCmd.SetErr(errOut)
+	runCmd.SetContext(context.Background())
+	defer runCmd.SetErr(nil)
+
+	err := runRun(runCmd, []string{"test"})
+	if exitcode.Code(err) != 7 || !strings.Contains(err.Error(), "task test exited with code 7") {
+		t.Errorf("Expected exit code 7, got %v", err)
+	}
+	if !strings.Contains(errOut.String(), "✘ task test failed after") {
+		t.Errorf("Unexpected progress output %q", errOut.String())
+	}
+}
+
+func TestRunRun_NoTaskfile(t *testing.T) {
+	useTaskfile(t, "")
+	if err := runRun(runCmd, nil); err == nil || !strings.Contains(err.Error(), "no Taskfile found") {
+		t.Errorf("Expected a missing Taskfile error, got %v", err)
+	}
+}
+
+func TestCompleteTasks(t *testing.T) {
+	useTaskfile(t, runTaskfile)
+
+	names, directive := completeTasks(runCmd, nil, "b")
+	if len(names) != 2 || names[0] != "build\tBuild the binary" || names[1] != "b\tBuild the binary" {
+		t.Errorf("completeTasks() = %v", names)
+	}
+	if directive != cobra.ShellCompDirectiveNoFileComp {
+		t.Errorf("directive = %v, want no file completion", directive)
+	}
+	if _, directive := completeTasks(runCmd, []string{"build"}, ""); directive != cobra.ShellCompDirectiveDefault {
+		t.Errorf("directive after the task = %v, want default", directive)
+	}
+}
@@ -0,0 +1,88 @@
+// cmd/runcontext.go
+
+package cmd
+
+import (
+	"context"
+	"os"
+
+	"github.com/peiman/ckeletin-go/internal/config"
+	"github.com/peiman/ckeletin-go/internal/output"
+	"github.com/peiman/ckeletin-go/internal/runctx"
+	"github.com/peiman/ckeletin-go/internal/ui"
+	"github.com/peiman/ckeletin-go/internal/xdg"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// runContext returns the RunContext the root command attached to cmd before
+// it ran. Commands run directly, e.g. in tests, get a new one with the
+// defaults of all registered options and the flags of cmd.
+func runContext(cmd *cobra.Command) *runctx.RunContext {
+	if rc, ok := runctx.From(cmd.Context()); ok {
+		return rc
+	}
+	cfg := viper.New()
+	config.ApplyDefaults(cfg)
+	// A flag that cannot be bound keeps the default of its key
+	_ = bindConfigFlags(cfg, cmd)
+	// Invalid settings are reported by the root command, which these commands skipped
+	settings, _ := uiSettings(cfg)
+	return newRunContext(cmd, cfg, settings)
+}
+
+// contextConfig returns the config of the invocation ctx belongs to, or the
+// defaults of all registered options outside of one. It serves code that
+// gets a context rather than the command, e.g. UI callbacks.
+func contextConfig(ctx context.Context) *viper.Viper {
+	if rc, ok := runctx.From(ctx); ok {
+		return rc.Config
+	}
+	cfg := viper.New()
+	config.ApplyDefaults(cfg)
+	return cfg
+}
+
+// newRunContext returns the RunContext of an invocation of cmd with the
+// config cfg, which the invocation owns from then on, and the UI settings
+// read from it
+func newRunContext(cmd *cobra.Command, cfg *viper.Viper, settings ui.Settings) *runctx.RunContext {
+	// An invalid format is reported by the commands that render results
+	format, _ := configOutputFormat(cmd, cfg, "")
+	printer := output.Printer{Out: cmd.OutOrStdout(), Err: cmd.ErrOrStderr(), Format: format}
+	logger := invocationFrom(cmd.Context()).app.newLogger(cmd.ErrOrStderr(), cfg.GetString("app.log_level"), settings.Unicode)
+	rc := runctx.New(Version, cfg, printer, logger)
+	rc.DryRun = cfg.GetBool("app.dry_run")
+	rc.Args = contextArgs(cmd.Context())
+	// The config dir was validated when the config was loaded
+	dir, _ := configDir(cfg)
+	rc.Dirs = xdg.Dirs{Root: dir}
+	rc.UI = settings
+	return rc
+}
+
+type argsKey struct{}
+
+// withArgs returns a copy of ctx carrying the arguments of the invocation
+func withArgs(ctx context.Context, args []string) context.Context {
+	return context.WithValue(ctx, argsKey{}, args)
+}
+
+// contextArgs returns the arguments of the invocation ctx belongs to, or the
+// arguments of the process outside of one
+func contextArgs(ctx context.Context) []string {
+	if ctx != nil {
+		if args, ok := ctx.Value(argsKey{}).([]string); ok {
+			return args
+		}
+	}
+	return processArgs()
+}
+
+// processArgs returns the arguments the process was started with
+func processArgs() []string {
+	if len(os.Args) < 2 {
+		return nil
+	}
+	return os.Args[1:]
+}
@@ -0,0 +1,105 @@
+// cmd/runcontext_test.go
+
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/peiman/ckeletin-go/internal/config"
+	"github.com/peiman/ckeletin-go/internal/output"
+	"github.com/peiman/ckeletin-go/internal/runctx"
+	"github.com/peiman/ckeletin-go/internal/ui"
+	"github.com/rs/zerolog"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// useConfig attaches a RunContext to cmd like the root command does before
+// cmd runs, see attachConfig. Its config has the defaults of all registered
+// options, the flags of cmd and values on top.
+func useConfig(t *testing.T, cmd *cobra.Command, values map[string]interface{}) *runctx.RunContext {
+	t.Helper()
+	cfg := viper.New()
+	config.ApplyDefaults(cfg)
+	if err := bindConfigFlags(cfg, cmd); err != nil {
+		t.Fatal(err)
+	}
+	for key, value := range values {
+		cfg.Set(key, value)
+	}
+	return attachConfig(t, cmd, cfg)
+}
+
+// loadConfig attaches the config an invocation of cmd would load from the
+// config file, the environment and the flags of cmd, see attachConfig
+func loadConfig(t *testing.T, cmd *cobra.Command) *runctx.RunContext {
+	t.Helper()
+	cfg, err := newConfig(cmd, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return attachConfig(t, cmd, cfg)
+}
+
+// attachConfig attaches a RunContext with the config cfg to cmd. Without an
+// invocation, the context of cmd gets a new one. The previous context is
+// restored after the test.
+func attachConfig(t *testing.T, cmd *cobra.Command, cfg *viper.Viper) *runctx.RunContext {
+	t.Helper()
+	settings, err := uiSettings(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	prev := cmd.Context()
+	ctx := prev
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if _, ok := ctx.Value(invocationKey{}).(*invocation); !ok {
+		ctx = withInvocation(ctx, newInvocation(&App{}, time.Now()))
+	}
+	rc := newRunContext(cmd, cfg, settings)
+	cmd.SetContext(runctx.With(ui.WithSettings(rc.Logger.WithContext(ctx), settings), rc))
+	t.Cleanup(func() { cmd.SetContext(prev) })
+	return rc
+}
+
+func TestRunContext_Fallback(t *testing.T) {
+	c := &cobra.Command{Use: "test"}
+	c.Flags().String("output", "", "")
+	bindFlags(c.Flags(), map[string]string{"output": "app.output"})
+	if err := c.ParseFlags([]string{"--output", "json"}); err != nil {
+		t.Fatal(err)
+	}
+
+	rc := runContext(c)
+	if rc.ID == "" || rc.Version != Version || rc.Printer.Format != output.JSON {
+		t.Errorf("Unexpected RunContext %+v", rc)
+	}
+	if got := rc.Config.GetString("app.ping.output_message"); got != "Pong" {
+		t.Errorf("Snapshot misses registered defaults, output_message = %q", got)
+	}
+}
+
+func TestRunPing_UsesRunContext(t *testing.T) {
+	cfg := viper.New()
+	cfg.Set("app.ping.output_message", "from run context")
+	cfg.Set("app.ping.output_color", "white")
+	cfg.Set("app.ping.count", 1)
+	out := new(bytes.Buffer)
+	rc := runctx.New("test", cfg, output.Printer{Out: out, Err: out, Format: output.Text}, zerolog.Nop())
+
+	c := &cobra.Command{Use: "ping"}
+	c.Flags().AddFlagSet(pingCmd.Flags())
+	c.SetContext(runctx.With(context.Background(), rc))
+	if err := runPing(c, nil); err != nil {
+		t.Fatalf("runPing() error = %v", err)
+	}
+	if out.String() != "from run context\n" {
+		t.Errorf("output = %q, want the message from the RunContext", out.String())
+	}
+}
@@ -0,0 +1,106 @@
+// cmd/sbom_test.go
+
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"runtime/debug"
+	"strings"
+	"testing"
+)
+
+func resetSBOMFlags() {
+	for _, name := range []string{"format", "binary", "output-file"} {
+		f := sbomGenerateCmd.Flags().Lookup(name)
+		_ = f.Value.Set(f.DefValue)
+		f.Changed = false
+	}
+}
+
+func TestRunSBOMGenerate(t *testing.T) {
+	origRead := readBuildInfo
+	defer func() { readBuildInfo = origRead }()
+	readBuildInfo = func() (*debug.BuildInfo, bool) {
+		return &debug.BuildInfo{
+			Main: debug.Module{Path: "github.com/peiman/ckeletin-go", Version: "(devel)"},
+			Deps: []*debug.Module{{Path: "github.com/spf13/cobra", Version: "v1.8.1"}},
+		}, true
+	}
+
+	tests := []struct {
+		name    string
+		flags   map[string]string
+		wantErr string
+		want    string
+	}{
+		{name: "CycloneDX by default", want: `"bomFormat": "CycloneDX"`},
+		{name: "SPDX", flags: map[string]string{"format": "spdx"}, want: `"spdxVersion": "SPDX-2.3"`},
+		{name: "Invalid format", flags: map[string]string{"format": "xml"}, wantErr: "invalid SBOM format"},
+		{name: "Missing binary", flags: map[string]string{"binary": filepath.Join(t.TempDir(), "none")}, wantErr: "failed to read module information"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out := new(bytes.Buffer)
+			sbomGenerateCmd.SetOut(out)
+			defer sbomGenerateCmd.SetOut(nil)
+			defer resetSBOMFlags()
+			for name, value := range tt.flags {
+				if err := sbomGenerateCmd.Flags().Set(name, value); err != nil {
+					t.Fatalf("Failed to set flag: %v", err)
+				}
+			}
+
+			err := runSBOMGenerate(sbomGenerateCmd, nil)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("Expected error containing %q, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("runSBOMGenerate() error = %v", err)
+			}
+			if !strings.Contains(out.String(), tt.want) || !strings.Contains(out.String(), "pkg:golang/github.com/spf13/cobra@v1.8.1") {
+				t.Errorf("Unexpected SBOM %s", out.String())
+			}
+		})
+	}
+}
+
+func TestRunSBOMGenerate_ToFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "sbom.json")
+	defer resetSBOMFlags()
+	if err := sbomGenerateCmd.Flags().Set("output-file", path); err != nil {
+		t.Fatalf("Failed to set flag: %v", err)
+	}
+
+	if err := runSBOMGenerate(sbomGenerateCmd, nil); err != nil {
+		t.Fatalf("runSBOMGenerate() error = %v", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("SBOM file not written: %v", err)
+	}
+	var doc map[string]interface{}
+	if err := json.Unmarshal(data, &doc); err != nil || doc["bomFormat"] != "CycloneDX" {
+		t.Errorf("Unexpected SBOM file %s (%v)", data, err)
+	}
+}
+
+func TestLoadBOM_Binary(t *testing.T) {
+	exe, err := os.Executable()
+	if err != nil {
+		t.Skip("test binary path unknown")
+	}
+	bom, err := loadBOM(exe)
+	if err != nil {
+		t.Fatalf("loadBOM() error = %v", err)
+	}
+	if bom.Name == "" || len(bom.Modules) == 0 {
+		t.Errorf("Expected modules of the test binary, got %+v", bom)
+	}
+}
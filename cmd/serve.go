@@ -0,0 +1,221 @@
+// cmd/serve.go
+
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"github.com/peiman/ckeletin-go/internal/config"
+	"github.com/rs/zerolog"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var serveCmd = &cobra.Command{
+	Use:         "serve",
+	Short:       "Run a long-running HTTP service",
+	Annotations: map[string]string{docsAnnotation: "serve-command"},
+	Long: `The serve command is a template for long-running daemons.
+- Serves a greeting on / and a health check on /healthz.
+- Logs every request with method, path, status and duration.
+- Reloads app.serve.message and app.log_level when the config file changes.
+- Shuts down gracefully on SIGINT/SIGTERM, waiting for in-flight requests.`,
+	RunE: runServe,
+}
+
+func init() {
+	initServeConfig()
+	configFlags(serveCmd, "app.serve")
+	RootCmd.AddCommand(serveCmd)
+}
+
+func initServeConfig() {
+	config.Register(
+		config.Option{Key: "app.serve.addr", Flag: "addr", Default: "127.0.0.1:8080", Description: "Address the server listens on"},
+		config.Option{Key: "app.serve.shutdown_timeout", Flag: "shutdown-timeout", Default: 10 * time.Second, Type: config.TypeDuration, Description: "How long to wait for open requests on shutdown"},
+		config.Option{Key: "app.serve.message", Default: fmt.Sprintf("Hello from %s", binaryName), Description: "Message the server responds with"},
+	)
+}
+
+func runServe(cmd *cobra.Command, args []string) error {
+	rc := runContext(cmd)
+	log, cfg := rc.Logger, rc.Config
+	addr := cfg.GetString("app.serve.addr")
+	if cmd.Flags().Changed("addr") {
+		addr, _ = cmd.Flags().GetString("addr")
+	}
+	shutdownTimeout := cfg.GetDuration("app.serve.shutdown_timeout")
+	if cmd.Flags().Changed("shutdown-timeout") {
+		shutdownTimeout, _ = cmd.Flags().GetDuration("shutdown-timeout")
+	}
+
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", addr, err)
+	}
+
+	srv := newServeServer(loadServeSettings(cfg), log)
+	ctx, stop := context.WithCancel(cmd.Context())
+	defer stop()
+	if path := cfg.ConfigFileUsed(); path != "" {
+		watcher, err := watchFile(path)
+		if err != nil {
+			return err
+		}
+		defer watcher.Close()
+		// The watcher stops with the server
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			watcher.run(ctx, log, func() { reloadServe(cmd, srv, path) })
+		}()
+		defer func() {
+			stop()
+			<-done
+		}()
+	}
+
+	fmt.Fprintf(cmd.OutOrStdout(), "Listening on http://%s (Ctrl-C to stop)\n", ln.Addr())
+	return srv.serve(ctx, ln, shutdownTimeout)
+}
+
+// reloadServe applies the settings of the config file at path to srv
+func reloadServe(cmd *cobra.Command, srv *serveServer, path string) {
+	next, err := newConfig(cmd, path)
+	if err != nil {
+		srv.log().Error().Err(err).Msg("Failed to reload config, keeping the previous settings")
+		return
+	}
+	srv.reload(loadServeSettings(next))
+	srv.log().Info().Str("config_file", path).Msg("Configuration reloaded")
+}
+
+// serveSettings holds the values that can change while the server is running.
+type serveSettings struct {
+	Message string
+	// LogLevel is the level of the server's logger
+	LogLevel zerolog.Level
+}
+
+func loadServeSettings(cfg *viper.Viper) *serveSettings {
+	level, err := zerolog.ParseLevel(cfg.GetString("app.log_level"))
+	if err != nil {
+		// Like the logger of the invocation
+		level = zerolog.InfoLevel
+	}
+	return &serveSettings{Message: cfg.GetString("app.serve.message"), LogLevel: level}
+}
+
+// serveServer is an HTTP server whose settings can be swapped safely while serving.
+type serveServer struct {
+	settings atomic.Pointer[serveSettings]
+	started  time.Time
+	// logger is the logger of the invocation, used at the level of the settings
+	logger zerolog.Logger
+}
+
+func newServeServer(settings *serveSettings, log zerolog.Logger) *serveServer {
+	s := &serveServer{started: time.Now(), logger: log}
+	s.settings.Store(settings)
+	return s
+}
+
+func (s *serveServer) reload(settings *serveSettings) {
+	s.settings.Store(settings)
+}
+
+// log returns the logger of the server at the current log level. Only this
+// server's logs follow a reloaded level, not other loggers in the process.
+func (s *serveServer) log() *zerolog.Logger {
+	log := s.logger.Level(s.settings.Load().LogLevel)
+	return &log
+}
+
+func (s *serveServer) handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/healthz", s.handleHealth)
+	mux.HandleFunc("/", s.handleRoot)
+	return logRequests(s.log, mux)
+}
+
+func (s *serveServer) handleRoot(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != "/" {
+		http.NotFound(w, r)
+		return
+	}
+	fmt.Fprintln(w, s.settings.Load().Message)
+}
+
+func (s *serveServer) handleHealth(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(map[string]string{
+		"status":  "ok",
+		"version": Version,
+		"uptime":  time.Since(s.started).Round(time.Second).String(),
+	})
+}
+
+// serve handles requests on ln until ctx is cancelled, then shuts down gracefully.
+func (s *serveServer) serve(ctx context.Context, ln net.Listener, shutdownTimeout time.Duration) error {
+	httpSrv := &http.Server{
+		Handler:           s.handler(),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	errCh := make(chan error, 1)
+	go func() { errCh <- httpSrv.Serve(ln) }()
+	s.log().Info().Str("addr", ln.Addr().String()).Msg("Server started")
+
+	select {
+	case err := <-errCh:
+		return fmt.Errorf("server stopped unexpectedly: %w", err)
+	case <-ctx.Done():
+	}
+
+	s.log().Info().Dur("timeout", shutdownTimeout).Msg("Shutting down server")
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	if err := httpSrv.Shutdown(shutdownCtx); err != nil {
+		return fmt.Errorf("graceful shutdown failed: %w", err)
+	}
+	if err := <-errCh; err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+
+	s.log().Info().Msg("Server stopped")
+	return nil
+}
+
+// statusRecorder captures the status code written by a handler.
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (r *statusRecorder) WriteHeader(code int) {
+	r.status = code
+	r.ResponseWriter.WriteHeader(code)
+}
+
+// logRequests logs one structured entry per request to the logger log returns.
+func logRequests(log func() *zerolog.Logger, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(rec, r)
+		log().Info().
+			Str("method", r.Method).
+			Str("path", r.URL.Path).
+			Int("status", rec.status).
+			Dur("duration", time.Since(start)).
+			Str("remote", r.RemoteAddr).
+			Msg("Request handled")
+	})
+}
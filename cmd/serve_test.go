@@ -0,0 +1,185 @@
+// cmd/serve_test.go
+
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/spf13/cobra"
+)
+
+func TestServeServer_Handlers(t *testing.T) {
+	srv := newServeServer(&serveSettings{Message: "Hello"}, zerolog.Nop())
+	ts := httptest.NewServer(srv.handler())
+	defer ts.Close()
+
+	resp, err := http.Get(ts.URL + "/")
+	if err != nil {
+		t.Fatalf("GET / error = %v", err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if string(body) != "Hello\n" {
+		t.Errorf("GET / body = %q, want %q", body, "Hello\n")
+	}
+
+	resp, err = http.Get(ts.URL + "/healthz")
+	if err != nil {
+		t.Fatalf("GET /healthz error = %v", err)
+	}
+	var health map[string]string
+	if err := json.NewDecoder(resp.Body).Decode(&health); err != nil {
+		t.Fatalf("Failed to decode health response: %v", err)
+	}
+	resp.Body.Close()
+	if health["status"] != "ok" || health["version"] != Version {
+		t.Errorf("Unexpected health response: %v", health)
+	}
+
+	resp, err = http.Get(ts.URL + "/missing")
+	if err != nil {
+		t.Fatalf("GET /missing error = %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("GET /missing status = %d, want %d", resp.StatusCode, http.StatusNotFound)
+	}
+}
+
+func TestServeServer_Reload(t *testing.T) {
+	logBuf := &bytes.Buffer{}
+	srv := newServeServer(&serveSettings{Message: "Before", LogLevel: zerolog.InfoLevel}, zerolog.New(logBuf))
+	srv.reload(&serveSettings{Message: "After", LogLevel: zerolog.WarnLevel})
+
+	rec := httptest.NewRecorder()
+	srv.handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	if rec.Body.String() != "After\n" {
+		t.Errorf("Expected reloaded message, got %q", rec.Body.String())
+	}
+	if logBuf.Len() != 0 {
+		t.Errorf("Request logged at the reloaded level warn: %s", logBuf.String())
+	}
+}
+
+func TestRunServe_ReloadsConfig(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte("app:\n  serve:\n    addr: 127.0.0.1:0\n    message: Before\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	out, logBuf := &syncBuffer{}, &syncBuffer{}
+	cmd := &cobra.Command{Use: "serve"}
+	cmd.SetOut(out)
+	cmd.SetErr(logBuf)
+	cmd.SetContext(ctx)
+	cfg, err := newConfig(cmd, path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	attachConfig(t, cmd, cfg)
+
+	done := make(chan error, 1)
+	go func() { done <- runServe(cmd, nil) }()
+	if !waitForOutput(out, "Listening on") {
+		t.Fatalf("Server did not start: %s", logBuf.String())
+	}
+	url := strings.Fields(out.String())[2]
+	get := func() string {
+		resp, err := http.Get(url)
+		if err != nil {
+			t.Fatalf("GET / error = %v", err)
+		}
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+		return string(body)
+	}
+	if body := get(); body != "Before\n" {
+		t.Fatalf("GET / = %q, want the configured message", body)
+	}
+
+	global := zerolog.GlobalLevel()
+	if err := os.WriteFile(path, []byte("app:\n  log_level: error\n  serve:\n    addr: 127.0.0.1:0\n    message: After\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	deadline := time.Now().Add(3 * time.Second)
+	for get() != "After\n" {
+		if time.Now().After(deadline) {
+			t.Fatalf("Config not reloaded: %s", logBuf.String())
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	logged := len(logBuf.String())
+	get()
+	if l := logBuf.String()[logged:]; strings.Contains(l, "Request handled") {
+		t.Errorf("Request logged after the level was reloaded to error: %s", l)
+	}
+	if zerolog.GlobalLevel() != global {
+		t.Errorf("Reload changed the process-wide log level to %s", zerolog.GlobalLevel())
+	}
+
+	cancel()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("runServe() error = %v", err)
+		}
+	case <-time.After(3 * time.Second):
+		t.Fatal("runServe() did not return after context cancellation")
+	}
+}
+
+func TestLogRequests(t *testing.T) {
+	logBuf := &bytes.Buffer{}
+	log := zerolog.New(logBuf)
+	h := logRequests(func() *zerolog.Logger { return &log }, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTeapot)
+	}))
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/brew", nil))
+
+	for _, want := range []string{`"method":"POST"`, `"path":"/brew"`, `"status":418`} {
+		if !strings.Contains(logBuf.String(), want) {
+			t.Errorf("Expected log to contain %s, got %s", want, logBuf.String())
+		}
+	}
+}
+
+func TestServeServer_GracefulShutdown(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+
+	srv := newServeServer(&serveSettings{Message: "Hello"}, zerolog.Nop())
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- srv.serve(ctx, ln, time.Second) }()
+
+	resp, err := http.Get("http://" + ln.Addr().String() + "/healthz")
+	if err != nil {
+		t.Fatalf("GET /healthz error = %v", err)
+	}
+	resp.Body.Close()
+
+	cancel()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("serve() error = %v", err)
+		}
+	case <-time.After(3 * time.Second):
+		t.Fatal("serve() did not return after context cancellation")
+	}
+}
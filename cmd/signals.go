@@ -0,0 +1,57 @@
+// cmd/signals.go
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/peiman/ckeletin-go/internal/exitcode"
+	"github.com/peiman/ckeletin-go/pkg/termcaps"
+)
+
+var (
+	// shutdownSignals cancel the root context passed to every command
+	shutdownSignals = []os.Signal{os.Interrupt, syscall.SIGTERM}
+
+	// exitFunc ends the process on a second signal, can be replaced in tests
+	exitFunc = os.Exit
+)
+
+// notifyShutdown returns a context that is cancelled on the first shutdown signal,
+// so commands can finish their work and clean up (the TUI restores the terminal,
+// servers drain connections). A second signal exits immediately. Call stop once
+// the command has returned to release the handler.
+func notifyShutdown(parent context.Context, errOut io.Writer) (ctx context.Context, stop func()) {
+	ctx, cancel := context.WithCancel(parent)
+	sigs := make(chan os.Signal, 2)
+	signal.Notify(sigs, shutdownSignals...)
+	done := make(chan struct{})
+
+	go func() {
+		select {
+		case <-sigs:
+		case <-done:
+			return
+		}
+		fmt.Fprintf(errOut, "interrupt received, finishing up%s (press Ctrl-C again to force exit)\n", termcaps.For(errOut).Glyphs().Ellipsis)
+		cancel()
+
+		select {
+		case <-sigs:
+			fmt.Fprintln(errOut, "forced exit")
+			exitFunc(exitcode.Canceled)
+		case <-done:
+		}
+	}()
+
+	return ctx, func() {
+		signal.Stop(sigs)
+		close(done)
+		cancel()
+	}
+}
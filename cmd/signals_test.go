@@ -0,0 +1,68 @@
+// cmd/signals_test.go
+
+package cmd
+
+import (
+	"context"
+	"os"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/peiman/ckeletin-go/internal/exitcode"
+)
+
+func sendInterrupt(t *testing.T) {
+	t.Helper()
+	p, err := os.FindProcess(os.Getpid())
+	if err != nil {
+		t.Fatalf("FindProcess() error = %v", err)
+	}
+	if err := p.Signal(os.Interrupt); err != nil {
+		t.Fatalf("Signal() error = %v", err)
+	}
+}
+
+func TestNotifyShutdown(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("sending os.Interrupt is not supported on Windows")
+	}
+
+	exited := make(chan int, 1)
+	origExit := exitFunc
+	exitFunc = func(code int) { exited <- code }
+	defer func() { exitFunc = origExit }()
+
+	errOut := &syncBuffer{}
+	ctx, stop := notifyShutdown(context.Background(), errOut)
+	defer stop()
+
+	sendInterrupt(t)
+	select {
+	case <-ctx.Done():
+	case <-time.After(2 * time.Second):
+		t.Fatal("Expected the first interrupt to cancel the context")
+	}
+	if !strings.Contains(errOut.String(), "interrupt received, finishing up") {
+		t.Errorf("Expected interrupt notice, got %q", errOut.String())
+	}
+
+	sendInterrupt(t)
+	select {
+	case code := <-exited:
+		if code != exitcode.Canceled {
+			t.Errorf("exit code = %d, want %d", code, exitcode.Canceled)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Expected the second interrupt to force an exit")
+	}
+}
+
+func TestNotifyShutdown_Stop(t *testing.T) {
+	ctx, stop := notifyShutdown(context.Background(), &syncBuffer{})
+	stop()
+	if ctx.Err() == nil {
+		t.Error("Expected stop() to cancel the context")
+	}
+}
@@ -0,0 +1,42 @@
+// cmd/startup.go
+
+package cmd
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+)
+
+// initStart is when the variables of this package were initialized, before
+// its init functions ran: the start of the startup of the process's command
+var initStart = time.Now()
+
+func init() {
+	RootCmd.PersistentFlags().Bool("debug-startup", false, "Print how long each phase of the startup took")
+	if err := RootCmd.PersistentFlags().MarkHidden("debug-startup"); err != nil {
+		log.Fatal().Err(err).Msg("Failed to hide 'debug-startup'")
+	}
+	afterExecute = append(afterExecute, printStartup)
+}
+
+// printStartup writes the startup phases to stderr when --debug-startup is set
+func printStartup(cmd *cobra.Command, _ time.Time, _ error) {
+	if cmd == nil {
+		return
+	}
+	// Read from the root, as plugin commands parse the host flags there
+	if enabled, _ := cmd.Root().PersistentFlags().GetBool("debug-startup"); !enabled {
+		return
+	}
+	inv := invocationFrom(cmd.Context())
+	inv.markStartup("run")
+	w := cmd.ErrOrStderr()
+	if err := inv.startup.Write(w); err != nil {
+		runContext(cmd).Logger.Debug().Err(err).Msg("Failed to print startup phases")
+		return
+	}
+	fmt.Fprintln(w, "Package initialization before main is not included, GODEBUG=inittrace=1 shows it.")
+}
@@ -0,0 +1,42 @@
+// cmd/startup_test.go
+
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+func TestPrintStartup(t *testing.T) {
+	inv := newInvocation(&App{}, time.Now())
+	inv.markStartup("init")
+	inv.markStartup("config")
+
+	root := &cobra.Command{Use: binaryName}
+	root.PersistentFlags().Bool("debug-startup", false, "")
+	sub := &cobra.Command{Use: "sub"}
+	sub.SetContext(withInvocation(context.Background(), inv))
+	root.AddCommand(sub)
+	var stderr bytes.Buffer
+	root.SetErr(&stderr)
+
+	printStartup(sub, time.Now(), nil)
+	if stderr.Len() != 0 {
+		t.Errorf("Startup breakdown printed without --debug-startup:\n%s", stderr.String())
+	}
+
+	if err := root.PersistentFlags().Set("debug-startup", "true"); err != nil {
+		t.Fatal(err)
+	}
+	printStartup(sub, time.Now(), nil)
+	for _, want := range []string{"PHASE", "init", "config", "run", "total", "GODEBUG=inittrace=1"} {
+		if !strings.Contains(stderr.String(), want) {
+			t.Errorf("Startup breakdown missing %q:\n%s", want, stderr.String())
+		}
+	}
+}
@@ -0,0 +1,230 @@
+// cmd/telemetry.go
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"runtime"
+	"time"
+
+	"github.com/peiman/ckeletin-go/internal/config"
+	"github.com/peiman/ckeletin-go/internal/runctx"
+	"github.com/peiman/ckeletin-go/internal/telemetry"
+	"github.com/spf13/cobra"
+)
+
+// telemetryTimeout bounds a batch upload, and thus the delay it can add to a command
+const telemetryTimeout = 2 * time.Second
+
+var telemetryCmd = &cobra.Command{
+	Use:         "telemetry",
+	Short:       "Manage anonymous usage telemetry",
+	Annotations: map[string]string{docsAnnotation: "telemetry-command"},
+	Long: `Telemetry is off unless you enable it. When enabled, each command records its name,
+duration, exit status, the binary version, OS and architecture, and the day it ran.
+Arguments, flag values, paths and error messages are never recorded.
+
+Events are queued locally and uploaded in batches only when app.telemetry.endpoint
+is set and app.telemetry.offline is false; otherwise nothing leaves the machine.
+DO_NOT_TRACK=1 disables recording regardless of the configuration.`,
+}
+
+var telemetryEnableCmd = &cobra.Command{
+	Use:   "enable",
+	Short: "Enable anonymous usage telemetry",
+	Args:  cobra.NoArgs,
+	RunE:  runTelemetryEnable,
+}
+
+var telemetryDisableCmd = &cobra.Command{
+	Use:   "disable",
+	Short: "Disable telemetry and delete queued events",
+	Args:  cobra.NoArgs,
+	RunE:  runTelemetryDisable,
+}
+
+var telemetryStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Show whether telemetry is enabled and how many events are queued",
+	Args:  cobra.NoArgs,
+	RunE:  runTelemetryStatus,
+}
+
+func init() {
+	initTelemetryConfig()
+	telemetryCmd.AddCommand(telemetryEnableCmd, telemetryDisableCmd, telemetryStatusCmd)
+	RootCmd.AddCommand(telemetryCmd)
+	afterExecute = append(afterExecute, recordTelemetry)
+}
+
+func initTelemetryConfig() {
+	config.Register(
+		config.Option{Key: "app.telemetry.enabled", Default: false, Description: "Record anonymous usage events"},
+		config.Option{Key: "app.telemetry.endpoint", Default: "", Description: "URL telemetry events are uploaded to (empty keeps them local)"},
+		config.Option{Key: "app.telemetry.offline", Default: false, Description: "Keep telemetry events local even with an endpoint"},
+	)
+}
+
+// newTelemetryClient returns the client for the local queue; uploads are only
+// configured when the config of rc sets an endpoint and offline mode is off.
+func newTelemetryClient(rc *runctx.RunContext) (*telemetry.Client, error) {
+	cfg := rc.Config
+	path, err := rc.Dirs.StateFile(binaryName, "telemetry-queue.jsonl")
+	if err != nil {
+		return nil, err
+	}
+	c := &telemetry.Client{QueuePath: path}
+	if !cfg.GetBool("app.telemetry.offline") {
+		c.Endpoint = cfg.GetString("app.telemetry.endpoint")
+		// scaffold:http-client
+		c.Client = newHTTPClient(cfg)
+		// scaffold:end
+	}
+	return c, nil
+}
+
+// telemetryEnabled reports whether cmd should be recorded
+func telemetryEnabled(cmd *cobra.Command) bool {
+	for c := cmd; c != nil; c = c.Parent() {
+		switch c.Name() {
+		case telemetryCmd.Name(), "completion", cobra.ShellCompRequestCmd, cobra.ShellCompNoDescRequestCmd:
+			return false
+		}
+	}
+	return runContext(cmd).Config.GetBool("app.telemetry.enabled") && !doNotTrack()
+}
+
+func doNotTrack() bool {
+	v, ok := os.LookupEnv("DO_NOT_TRACK")
+	return ok && v != "" && v != "0" && v != "false"
+}
+
+// recordTelemetry queues an event for cmd when telemetry is enabled.
+// Failures are logged at debug level and never affect the command result.
+func recordTelemetry(cmd *cobra.Command, start time.Time, runErr error) {
+	if cmd == nil || !telemetryEnabled(cmd) {
+		return
+	}
+	rc := runContext(cmd)
+	c, err := newTelemetryClient(rc)
+	if err != nil {
+		rc.Logger.Debug().Err(err).Msg("Telemetry disabled: no state directory")
+		return
+	}
+
+	e := telemetry.NewEvent(telemetryCommandName(cmd), start, time.Since(start), runErr, Version, runtime.GOOS, runtime.GOARCH)
+	ctx, cancel := context.WithTimeout(context.Background(), telemetryTimeout)
+	defer cancel()
+	if err := c.Record(ctx, e); err != nil {
+		rc.Logger.Debug().Err(err).Msg("Failed to record telemetry")
+	}
+}
+
+// telemetryCommandName returns the command path below the root. Plugin names are
+// user-defined, so they are all reported as "plugin".
+func telemetryCommandName(cmd *cobra.Command) string {
+	// scaffold:plugins
+	if cmd.Annotations[pluginAnnotation] != "" {
+		return "plugin"
+	}
+	// scaffold:end
+	return commandName(cmd)
+}
+
+func runTelemetryEnable(cmd *cobra.Command, args []string) error {
+	if dryRun(cmd) {
+		return printPlannedConfig(cmd, "app.telemetry.enabled", true)
+	}
+	rc := runContext(cmd)
+	cfg := rc.Config
+	path, err := writeConfigValues(cfg, map[string]interface{}{"app.telemetry.enabled": true})
+	if err != nil {
+		return err
+	}
+	cfg.Set("app.telemetry.enabled", true)
+
+	fmt.Fprintf(cmd.OutOrStdout(), "Telemetry enabled in %s.\n", path)
+	if c, err := newTelemetryClient(rc); err == nil && c.Endpoint == "" {
+		fmt.Fprintln(cmd.OutOrStdout(), "No upload endpoint is configured; events are only kept locally.")
+	}
+	return nil
+}
+
+func runTelemetryDisable(cmd *cobra.Command, args []string) error {
+	if dryRun(cmd) {
+		return printPlannedConfig(cmd, "app.telemetry.enabled", false)
+	}
+	rc := runContext(cmd)
+	cfg := rc.Config
+	path, err := writeConfigValues(cfg, map[string]interface{}{"app.telemetry.enabled": false})
+	if err != nil {
+		return err
+	}
+	cfg.Set("app.telemetry.enabled", false)
+
+	c, err := newTelemetryClient(rc)
+	if err != nil {
+		return err
+	}
+	pending, err := c.Pending()
+	if err != nil {
+		return err
+	}
+	if err := c.Clear(); err != nil {
+		return err
+	}
+	fmt.Fprintf(cmd.OutOrStdout(), "Telemetry disabled in %s; %d queued events deleted.\n", path, len(pending))
+	return nil
+}
+
+func runTelemetryStatus(cmd *cobra.Command, args []string) error {
+	rc := runContext(cmd)
+	cfg := rc.Config
+	c, err := newTelemetryClient(rc)
+	if err != nil {
+		return err
+	}
+	pending, err := c.Pending()
+	if err != nil {
+		return err
+	}
+
+	status := telemetryStatus{
+		Enabled:    cfg.GetBool("app.telemetry.enabled") && !doNotTrack(),
+		DoNotTrack: doNotTrack(),
+		Endpoint:   c.Endpoint,
+		Queued:     len(pending),
+		Queue:      c.QueuePath,
+	}
+	return renderOutput(cmd, status)
+}
+
+// telemetryStatus is the result of telemetry status
+type telemetryStatus struct {
+	Enabled    bool   `json:"enabled"`
+	DoNotTrack bool   `json:"do_not_track"`
+	Endpoint   string `json:"endpoint"`
+	Queued     int    `json:"queued"`
+	Queue      string `json:"queue"`
+}
+
+// WriteText prints the status for humans
+func (s telemetryStatus) WriteText(w io.Writer) error {
+	status := "disabled"
+	switch {
+	case s.DoNotTrack:
+		status = "disabled (DO_NOT_TRACK is set)"
+	case s.Enabled:
+		status = "enabled"
+	}
+	mode := "offline (events are kept locally)"
+	if s.Endpoint != "" {
+		mode = "upload to " + s.Endpoint
+	}
+
+	_, err := fmt.Fprintf(w, "Telemetry: %s\nMode:      %s\nQueued:    %d events\nQueue:     %s\n", status, mode, s.Queued, s.Queue)
+	return err
+}
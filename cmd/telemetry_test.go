@@ -0,0 +1,165 @@
+// cmd/telemetry_test.go
+
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// setupTelemetryTest isolates the config file and the telemetry queue in a
+// temp config directory and returns the path of the config file
+func setupTelemetryTest(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	t.Setenv("APP_CONFIG_DIR", dir)
+	t.Setenv("DO_NOT_TRACK", "")
+	return filepath.Join(dir, "config.yaml")
+}
+
+// executeTelemetry runs a telemetry command with the config it would load
+func executeTelemetry(t *testing.T, run func(*cobra.Command, []string) error) string {
+	t.Helper()
+	out := new(bytes.Buffer)
+	cmd := &cobra.Command{}
+	cmd.SetOut(out)
+	loadConfig(t, cmd)
+	if err := run(cmd, nil); err != nil {
+		t.Fatalf("command error = %v", err)
+	}
+	return out.String()
+}
+
+func TestTelemetryEnabled(t *testing.T) {
+	root := &cobra.Command{Use: "root"}
+	ping := &cobra.Command{Use: "ping"}
+	status := &cobra.Command{Use: "status"}
+	tele := &cobra.Command{Use: "telemetry"}
+	tele.AddCommand(status)
+	root.AddCommand(ping, tele)
+
+	tests := []struct {
+		name       string
+		cmd        *cobra.Command
+		enabled    bool
+		doNotTrack string
+		want       bool
+	}{
+		{"Opted in", ping, true, "", true},
+		{"Not opted in", ping, false, "", false},
+		{"DO_NOT_TRACK", ping, true, "1", false},
+		{"Telemetry subcommand", status, true, "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("DO_NOT_TRACK", tt.doNotTrack)
+			useConfig(t, tt.cmd, map[string]interface{}{"app.telemetry.enabled": tt.enabled})
+			if got := telemetryEnabled(tt.cmd); got != tt.want {
+				t.Errorf("telemetryEnabled() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTelemetryCommandName(t *testing.T) {
+	root := &cobra.Command{Use: "root"}
+	dev := &cobra.Command{Use: "dev"}
+	watch := &cobra.Command{Use: "watch"}
+	dev.AddCommand(watch)
+	root.AddCommand(dev)
+
+	if got := telemetryCommandName(watch); got != "dev watch" {
+		t.Errorf("telemetryCommandName() = %q, want %q", got, "dev watch")
+	}
+	// scaffold:plugins
+	plug := &cobra.Command{Use: "secret-tool", Annotations: map[string]string{pluginAnnotation: "/bin/x"}}
+	root.AddCommand(plug)
+	if got := telemetryCommandName(plug); got != "plugin" {
+		t.Errorf("telemetryCommandName() for plugin = %q, want %q", got, "plugin")
+	}
+	// scaffold:end
+}
+
+func TestRecordTelemetry(t *testing.T) {
+	setupTelemetryTest(t)
+	root := &cobra.Command{Use: "root"}
+	ping := &cobra.Command{Use: "ping"}
+	root.AddCommand(ping)
+
+	rc := loadConfig(t, ping)
+	recordTelemetry(ping, time.Now(), nil)
+	c, err := newTelemetryClient(rc)
+	if err != nil {
+		t.Fatalf("newTelemetryClient() error = %v", err)
+	}
+	if pending, _ := c.Pending(); len(pending) != 0 {
+		t.Fatalf("Expected nothing recorded while disabled, got %d events", len(pending))
+	}
+
+	t.Setenv("APP_TELEMETRY_ENABLED", "true")
+	loadConfig(t, ping)
+	recordTelemetry(ping, time.Now(), errors.New("boom"))
+	pending, err := c.Pending()
+	if err != nil {
+		t.Fatalf("Pending() error = %v", err)
+	}
+	if len(pending) != 1 || pending[0].Command != "ping" || pending[0].ExitStatus != "error" {
+		t.Errorf("Expected one failed ping event, got %+v", pending)
+	}
+}
+
+func TestTelemetryEnableDisableStatus(t *testing.T) {
+	configPath := setupTelemetryTest(t)
+
+	out := executeTelemetry(t, runTelemetryEnable)
+	if !strings.Contains(out, "Telemetry enabled") || !strings.Contains(out, "only kept locally") {
+		t.Errorf("Unexpected enable output %q", out)
+	}
+	data, err := os.ReadFile(configPath)
+	if err != nil || !strings.Contains(string(data), "enabled: true") {
+		t.Fatalf("Expected config file to enable telemetry, got %q (%v)", data, err)
+	}
+
+	root := &cobra.Command{Use: "root"}
+	ping := &cobra.Command{Use: "ping"}
+	root.AddCommand(ping)
+	loadConfig(t, ping)
+	recordTelemetry(ping, time.Now(), nil)
+
+	out = executeTelemetry(t, runTelemetryStatus)
+	for _, want := range []string{"Telemetry: enabled", "Mode:      offline", "Queued:    1 events"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Expected status output to contain %q, got %q", want, out)
+		}
+	}
+
+	out = executeTelemetry(t, runTelemetryDisable)
+	if !strings.Contains(out, "1 queued events deleted") {
+		t.Errorf("Unexpected disable output %q", out)
+	}
+	out = executeTelemetry(t, runTelemetryStatus)
+	if !strings.Contains(out, "Telemetry: disabled") || !strings.Contains(out, "Queued:    0 events") {
+		t.Errorf("Unexpected status after disable %q", out)
+	}
+}
+
+func TestDryRun_TelemetryEnable(t *testing.T) {
+	configPath := setupTelemetryTest(t)
+	t.Setenv("APP_DRY_RUN", "true")
+
+	out := executeTelemetry(t, runTelemetryEnable)
+	if out != "Would set app.telemetry.enabled to true in "+configPath+" (dry run)\n" {
+		t.Errorf("Unexpected dry run output %q", out)
+	}
+	if _, err := os.Stat(configPath); !os.IsNotExist(err) {
+		t.Errorf("Dry run wrote the config file: %v", err)
+	}
+}
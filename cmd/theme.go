@@ -0,0 +1,112 @@
+// cmd/theme.go
+
+package cmd
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/peiman/ckeletin-go/internal/config"
+	"github.com/peiman/ckeletin-go/internal/exitcode"
+	"github.com/peiman/ckeletin-go/internal/runctx"
+	"github.com/peiman/ckeletin-go/internal/ui"
+	"github.com/peiman/ckeletin-go/pkg/termcaps"
+	"github.com/spf13/viper"
+)
+
+// autoTheme selects the default theme and suggests the accessible one on
+// terminals with limited colors
+const autoTheme = "auto"
+
+func init() {
+	const colorHelp = "a color name, an ANSI color number from 0 to 255 or #rrggbb; empty for the theme's"
+	config.Register(
+		config.Option{
+			Key:         "app.ui.theme",
+			Default:     autoTheme,
+			Description: fmt.Sprintf("Colors and labels of status output: %s or %s", autoTheme, strings.Join(ui.ThemeNames(), ", ")),
+		},
+		config.Option{Key: "app.ui.colors.success", Default: "", Description: "Color of successful status output, " + colorHelp},
+		config.Option{Key: "app.ui.colors.failure", Default: "", Description: "Color of failed status output and errors, " + colorHelp},
+		config.Option{Key: "app.ui.colors.warning", Default: "", Description: "Color of warnings, " + colorHelp},
+		config.Option{Key: "app.ui.colors.accent", Default: "", Description: "Color of highlights, " + colorHelp},
+		config.Option{Key: "app.ui.icons.pass", Default: "", Description: "Symbol of successful steps instead of the theme's"},
+		config.Option{Key: "app.ui.icons.fail", Default: "", Description: "Symbol of failed steps instead of the theme's"},
+		config.Option{Key: "app.ui.unicode", Default: "auto", Description: "Use unicode symbols in all output: auto (detect from the terminal and locale), true or false"},
+		config.Option{Key: "app.ui.max_width", Default: 120, Description: "Wrap status output and errors at this column, also when not writing to a terminal (0 to wrap only at the terminal width)"},
+	)
+}
+
+// uiSettings returns the theme, width and unicode settings of app.ui in cfg
+// and whether the invocation may prompt or animate
+func uiSettings(cfg *viper.Viper) (ui.Settings, error) {
+	unicode, err := unicodeMode(cfg)
+	if err != nil {
+		return ui.Settings{}, err
+	}
+	width := cfg.GetInt("app.ui.max_width")
+	if width < 0 {
+		return ui.Settings{}, &exitcode.ConfigError{Err: fmt.Errorf("invalid app.ui.max_width %d: must not be negative", width)}
+	}
+	theme, err := configTheme(cfg)
+	if err != nil {
+		return ui.Settings{}, err
+	}
+	return ui.Settings{
+		Theme:          theme,
+		MaxWidth:       width,
+		Unicode:        unicode,
+		NonInteractive: cfg.GetBool("app.no_input") || cfg.GetBool("app.non_interactive") || runningInCI(),
+	}, nil
+}
+
+// unicodeMode returns whether output uses unicode symbols or their ASCII
+// equivalents as set by app.ui.unicode in cfg
+func unicodeMode(cfg *viper.Viper) (termcaps.UnicodeMode, error) {
+	value := cfg.GetString("app.ui.unicode")
+	if value == "auto" {
+		return termcaps.UnicodeAuto, nil
+	}
+	on, err := strconv.ParseBool(value)
+	if err != nil {
+		return termcaps.UnicodeAuto, &exitcode.ConfigError{Err: fmt.Errorf("invalid app.ui.unicode %q: use auto, true or false", value)}
+	}
+	if on {
+		return termcaps.UnicodeOn, nil
+	}
+	return termcaps.UnicodeOff, nil
+}
+
+// configTheme returns the theme named by app.ui.theme in cfg, with the
+// overrides of app.ui.colors and app.ui.icons
+func configTheme(cfg *viper.Viper) (ui.Theme, error) {
+	name := cfg.GetString("app.ui.theme")
+	if name == autoTheme {
+		name = ui.DefaultTheme().Name
+	}
+	theme, err := ui.ThemeNamed(name)
+	if err != nil {
+		return ui.Theme{}, &exitcode.ConfigError{Err: fmt.Errorf("invalid app.ui.theme: %w", err)}
+	}
+	theme, err = theme.With(ui.ThemeOverrides{
+		Success:  cfg.GetString("app.ui.colors.success"),
+		Failure:  cfg.GetString("app.ui.colors.failure"),
+		Warning:  cfg.GetString("app.ui.colors.warning"),
+		Accent:   cfg.GetString("app.ui.colors.accent"),
+		PassIcon: cfg.GetString("app.ui.icons.pass"),
+		FailIcon: cfg.GetString("app.ui.icons.fail"),
+	})
+	if err != nil {
+		return ui.Theme{}, &exitcode.ConfigError{Err: fmt.Errorf("invalid app.ui.colors: %w", err)}
+	}
+	return theme, nil
+}
+
+// suggestTheme suggests the accessible theme when app.ui.theme is auto on a
+// terminal with limited colors
+func suggestTheme(rc *runctx.RunContext) {
+	if rc.Config.GetString("app.ui.theme") == autoTheme && ui.LimitedColors(termcaps.Stderr()) {
+		rc.Logger.Info().Msg("This terminal has only basic colors; if its status colors are hard to tell apart, set app.ui.theme to accessible, or to default to hide this message")
+	}
+}
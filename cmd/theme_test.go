@@ -0,0 +1,114 @@
+// cmd/theme_test.go
+
+package cmd
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/peiman/ckeletin-go/internal/config"
+	"github.com/peiman/ckeletin-go/internal/exitcode"
+	"github.com/peiman/ckeletin-go/internal/ui"
+	"github.com/peiman/ckeletin-go/pkg/termcaps"
+	"github.com/spf13/viper"
+)
+
+func TestConfigTheme(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    string
+		wantErr bool
+	}{
+		{autoTheme, "default", false},
+		{"accessible", "accessible", false},
+		{"default", "default", false},
+		{"neon", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			cfg := viper.New()
+			config.ApplyDefaults(cfg)
+			cfg.Set("app.ui.theme", tt.value)
+			theme, err := configTheme(cfg)
+			if tt.wantErr {
+				if exitcode.Code(err) != exitcode.Config {
+					t.Errorf("configTheme() error = %v, want a config error", err)
+				}
+				return
+			}
+			if err != nil || theme.Name != tt.want {
+				t.Errorf("configTheme() = %q, %v; want %q", theme.Name, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestConfigTheme_Colors(t *testing.T) {
+	cfg := viper.New()
+	config.ApplyDefaults(cfg)
+	cfg.Set("app.ui.theme", "accessible")
+	cfg.Set("app.ui.colors.success", "green")
+	cfg.Set("app.ui.icons.pass", "OK")
+	cfg.Set("app.ui.icons.fail", "!!")
+	got, err := configTheme(cfg)
+	if err != nil {
+		t.Fatalf("configTheme() error = %v", err)
+	}
+	if got.Name != "accessible" || got.Success != ui.ColorMap["green"] {
+		t.Errorf("Theme = %+v, want accessible with a green success color", got)
+	}
+	var glyphs termcaps.Glyphs
+	if got.Pass(glyphs) != "OK [PASS]" || got.Fail(glyphs) != "!! [FAIL]" {
+		t.Errorf("Theme icons = %q, %q; want the app.ui.icons symbols", got.Pass(glyphs), got.Fail(glyphs))
+	}
+
+	cfg.Set("app.ui.colors.failure", "#nothex")
+	if _, err := configTheme(cfg); exitcode.Code(err) != exitcode.Config {
+		t.Errorf("configTheme() error = %v, want a config error for an invalid color", err)
+	}
+}
+
+func TestUnicodeMode(t *testing.T) {
+	cfg := viper.New()
+	config.ApplyDefaults(cfg)
+	auto := termcaps.For(new(bytes.Buffer)).Unicode
+
+	for _, tt := range []struct {
+		value interface{}
+		want  bool
+	}{{false, false}, {"true", true}, {"0", false}, {"auto", auto}} {
+		cfg.Set("app.ui.unicode", tt.value)
+		mode, err := unicodeMode(cfg)
+		if err != nil {
+			t.Fatalf("unicodeMode(%v) error = %v", tt.value, err)
+		}
+		if got := mode.Apply(termcaps.For(new(bytes.Buffer))).Unicode; got != tt.want {
+			t.Errorf("Unicode = %v with app.ui.unicode %v, want %v", got, tt.value, tt.want)
+		}
+	}
+
+	cfg.Set("app.ui.unicode", "sometimes")
+	if _, err := unicodeMode(cfg); exitcode.Code(err) != exitcode.Config {
+		t.Errorf("unicodeMode(sometimes) error = %v, want a config error", err)
+	}
+}
+
+func TestUISettings(t *testing.T) {
+	cfg := viper.New()
+	config.ApplyDefaults(cfg)
+	cfg.Set("app.ui.max_width", 80)
+	cfg.Set("app.ui.unicode", false)
+	cfg.Set("app.no_input", true)
+	s, err := uiSettings(cfg)
+	if err != nil {
+		t.Fatalf("uiSettings() error = %v", err)
+	}
+	if s.MaxWidth != 80 || s.Unicode != termcaps.UnicodeOff || !s.NonInteractive || s.Theme.Name != "default" {
+		t.Errorf("uiSettings() = %+v", s)
+	}
+
+	cfg.Set("app.ui.max_width", -1)
+	if _, err := uiSettings(cfg); exitcode.Code(err) != exitcode.Config {
+		t.Errorf("uiSettings() with max_width -1 error = %v, want a config error", err)
+	}
+}
@@ -0,0 +1,57 @@
+// cmd/timeout.go
+
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/peiman/ckeletin-go/internal/config"
+	"github.com/peiman/ckeletin-go/internal/exitcode"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+func init() {
+	config.Register(config.Option{Key: "app.timeout", Default: time.Duration(0), Type: config.TypeDuration, Description: "Abort every command after this long (0 for no limit)"})
+	RootCmd.PersistentFlags().Duration("timeout", 0, "Abort the command after this long, e.g. 30s or 5m (0 for no limit)")
+	bindFlags(RootCmd.PersistentFlags(), map[string]string{
+		"timeout": "app.timeout",
+	})
+	afterExecute = append(afterExecute, func(cmd *cobra.Command, _ time.Time, _ error) {
+		if cmd != nil {
+			invocationFrom(cmd.Context()).cancelTimeout()
+		}
+	})
+}
+
+// applyTimeout sets the deadline of app.timeout in cfg on the context of cmd,
+// so everything using cmd.Context() stops when it passes
+func applyTimeout(cmd *cobra.Command, cfg *viper.Viper) error {
+	timeout := cfg.GetDuration("app.timeout")
+	if timeout < 0 {
+		return &exitcode.UsageError{Err: fmt.Errorf("invalid timeout %s: must not be negative", timeout)}
+	}
+	if timeout == 0 {
+		return nil
+	}
+	ctx, cancel := context.WithTimeoutCause(cmd.Context(), timeout, &exitcode.TimeoutError{Timeout: timeout})
+	invocationFrom(cmd.Context()).cancelTimeout = cancel
+	cmd.SetContext(ctx)
+	return nil
+}
+
+// timeoutError marks err as caused by the deadline when the command failed
+// after its deadline passed, whatever error the interrupted work returned
+func timeoutError(cmd *cobra.Command, err error) error {
+	if err == nil || cmd == nil || cmd.Context() == nil {
+		return err
+	}
+	var cause *exitcode.TimeoutError
+	if errors.As(context.Cause(cmd.Context()), &cause) && !errors.As(err, new(*exitcode.TimeoutError)) {
+		return &exitcode.TimeoutError{Timeout: cause.Timeout, Err: err}
+	}
+	return err
+}
@@ -0,0 +1,68 @@
+// cmd/timeout_test.go
+
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/peiman/ckeletin-go/internal/exitcode"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+func TestApplyTimeout(t *testing.T) {
+	inv := newInvocation(&App{}, time.Now())
+	defer func() { inv.cancelTimeout() }()
+	c := &cobra.Command{Use: "test"}
+	c.SetContext(withInvocation(context.Background(), inv))
+
+	cfg := viper.New()
+	if err := applyTimeout(c, cfg); err != nil {
+		t.Fatalf("applyTimeout() without timeout error = %v", err)
+	}
+	if _, ok := c.Context().Deadline(); ok {
+		t.Error("Deadline set without --timeout")
+	}
+
+	cfg.Set("app.timeout", "-1s")
+	if err := applyTimeout(c, cfg); exitcode.Code(err) != exitcode.Usage {
+		t.Errorf("Expected a usage error for a negative timeout, got %v", err)
+	}
+
+	cfg.Set("app.timeout", 10*time.Millisecond)
+	if err := applyTimeout(c, cfg); err != nil {
+		t.Fatalf("applyTimeout() error = %v", err)
+	}
+	<-c.Context().Done()
+
+	err := timeoutError(c, fmt.Errorf("fetch: %w", c.Context().Err()))
+	if exitcode.Code(err) != exitcode.Timeout || err.Error() != "deadline exceeded after 10ms" {
+		t.Errorf("timeoutError() = %v (exit %d)", err, exitcode.Code(err))
+	}
+	// Any failure after the deadline counts as a timeout, e.g. a killed process
+	if err := timeoutError(c, errors.New("signal: killed")); exitcode.Code(err) != exitcode.Timeout {
+		t.Errorf("timeoutError() = %v, want a timeout", err)
+	}
+	if err := timeoutError(c, nil); err != nil {
+		t.Errorf("timeoutError(nil) = %v", err)
+	}
+}
+
+func TestTimeoutError_BeforeDeadline(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Hour)
+	defer cancel()
+	c := &cobra.Command{Use: "test"}
+	c.SetContext(ctx)
+
+	base := errors.New("boom")
+	if err := timeoutError(c, base); err != base {
+		t.Errorf("timeoutError() = %v, want the error unchanged", err)
+	}
+	if err := timeoutError(&cobra.Command{}, base); err != base {
+		t.Errorf("timeoutError() without context = %v", err)
+	}
+}
@@ -0,0 +1,43 @@
+// cmd/timing.go
+
+package cmd
+
+import (
+	"time"
+
+	"github.com/peiman/ckeletin-go/internal/config"
+	"github.com/peiman/ckeletin-go/internal/exitcode"
+	"github.com/peiman/ckeletin-go/internal/rusage"
+	"github.com/peiman/ckeletin-go/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+// peakRSS returns the peak memory use of the process, can be replaced in tests
+var peakRSS = rusage.PeakRSS
+
+func init() {
+	config.Register(config.Option{Key: "app.ui.timing", Default: false, Description: "Print wall time, peak memory and exit code after every command"})
+	afterExecute = append(afterExecute, printTiming)
+}
+
+// printTiming writes the timing footer to stderr when app.ui.timing is enabled
+func printTiming(cmd *cobra.Command, start time.Time, err error) {
+	if cmd == nil || isCompletionRequest(cmd) {
+		return
+	}
+	rc := runContext(cmd)
+	if !rc.Config.GetBool("app.ui.timing") {
+		return
+	}
+
+	code := exitcode.Code(err)
+	f := ui.Footer{Elapsed: time.Since(start), ExitCode: code, Class: exitcode.Class(code)}
+	if rss, rssErr := peakRSS(); rssErr == nil {
+		f.PeakRSS = rss
+	} else {
+		rc.Logger.Debug().Err(rssErr).Msg("Peak memory use not available")
+	}
+	if printErr := ui.PrintFooter(cmd.ErrOrStderr(), rc.UI, f); printErr != nil {
+		rc.Logger.Debug().Err(printErr).Msg("Failed to print timing footer")
+	}
+}
@@ -0,0 +1,54 @@
+// cmd/timing_test.go
+
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/peiman/ckeletin-go/internal/exitcode"
+	"github.com/spf13/cobra"
+)
+
+func TestPrintTiming(t *testing.T) {
+	origRSS := peakRSS
+	defer func() { peakRSS = origRSS }()
+	peakRSS = func() (int64, error) { return 3 << 20, nil }
+
+	c := &cobra.Command{Use: "ping"}
+	errOut := new(bytes.Buffer)
+	c.SetErr(errOut)
+	start := time.Now().Add(-1500 * time.Millisecond)
+
+	rc := useConfig(t, c, nil)
+	printTiming(c, start, nil)
+	if errOut.Len() != 0 {
+		t.Errorf("Footer printed while disabled: %q", errOut.String())
+	}
+
+	rc.Config.Set("app.ui.timing", true)
+	printTiming(c, start, &exitcode.UsageError{Err: errors.New("bad flag")})
+	got := errOut.String()
+	if !strings.HasPrefix(got, "time 1.5") || !strings.Contains(got, "peak RSS 3.0 MiB") || !strings.HasSuffix(got, "exit 2 (usage)\n") {
+		t.Errorf("Unexpected footer %q", got)
+	}
+
+	errOut.Reset()
+	peakRSS = func() (int64, error) { return 0, errors.New("unsupported") }
+	printTiming(c, start, nil)
+	if strings.Contains(errOut.String(), "peak RSS") || !strings.Contains(errOut.String(), "exit 0 (ok)") {
+		t.Errorf("Unexpected footer without memory %q", errOut.String())
+	}
+
+	errOut.Reset()
+	complete := &cobra.Command{Use: cobra.ShellCompRequestCmd}
+	complete.SetErr(errOut)
+	printTiming(complete, start, nil)
+	printTiming(nil, start, nil)
+	if errOut.Len() != 0 {
+		t.Errorf("Footer printed for completion: %q", errOut.String())
+	}
+}
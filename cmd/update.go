@@ -0,0 +1,124 @@
+// cmd/update.go
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/peiman/ckeletin-go/internal/config"
+	"github.com/peiman/ckeletin-go/internal/exitcode"
+	"github.com/peiman/ckeletin-go/internal/update"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var (
+	// newUpdater builds the updater used by the update command, can be replaced in tests
+	newUpdater = func(cfg *viper.Viper) *update.Updater {
+		return &update.Updater{
+			APIURL:     update.DefaultAPIURL,
+			Repo:       cfg.GetString("app.update.repository"),
+			BinaryName: binaryName,
+			PublicKey:  cfg.GetString("app.update.public_key"),
+			// scaffold:http-client
+			Client: newHTTPClient(cfg),
+			// scaffold:end
+		}
+	}
+
+	// executablePath returns the binary to replace, can be replaced in tests
+	executablePath = func() (string, error) {
+		path, err := os.Executable()
+		if err != nil {
+			return "", err
+		}
+		return filepath.EvalSymlinks(path)
+	}
+)
+
+var updateCmd = &cobra.Command{
+	Use:         "update",
+	Short:       "Update to the latest release",
+	Annotations: map[string]string{docsAnnotation: "update-command"},
+	Long: `Checks GitHub Releases for a newer version and replaces the running binary.
+- The release must publish an asset named <binary>_<os>_<arch> (or with dashes),
+  its SHA-256 in checksums.txt or <asset>.sha256, and a minisign or signify
+  signature of that file (<file>.minisig or <file>.sig) made with the key in
+  app.update.public_key. Without a key, or with a missing or wrong signature or
+  checksum, nothing is installed.
+- Use --check-only to report whether an update is available without installing it.
+- Set app.update.enabled to false to disable self-update, e.g. for package-managed installs.
+- Set app.update.notify to true to be told about new releases after any command
+  (checked at most once a day, never in CI).`,
+	RunE: WithSingleInstance("update", runUpdate),
+}
+
+func init() {
+	initUpdateConfig()
+	updateCmd.Flags().Bool("check-only", false, "Only check for a newer version")
+	RootCmd.AddCommand(updateCmd)
+}
+
+func initUpdateConfig() {
+	config.Register(
+		config.Option{Key: "app.update.enabled", Default: true, Description: "Allow self-update with the update command"},
+		config.Option{Key: "app.update.notify", Default: false, Description: "Print a notice after commands when a newer release exists"},
+		config.Option{Key: "app.update.repository", Default: "peiman/ckeletin-go", Description: "GitHub repository to check for releases, as owner/name"},
+		config.Option{Key: "app.update.public_key", Default: "", Description: "Minisign or signify public key that release checksums must be signed with; update refuses to install without it"},
+	)
+}
+
+func runUpdate(cmd *cobra.Command, args []string) error {
+	rc := runContext(cmd)
+	cfg := rc.Config
+	if !cfg.GetBool("app.update.enabled") {
+		return fmt.Errorf("self-update is disabled by configuration (app.update.enabled)")
+	}
+	checkOnly, _ := cmd.Flags().GetBool("check-only")
+
+	u := newUpdater(cfg)
+	rel, err := u.Latest(cmd.Context())
+	if err != nil {
+		return err
+	}
+
+	out := cmd.OutOrStdout()
+	fmt.Fprintf(out, "Current version: %s\nLatest version:  %s\n", Version, rel.Version())
+
+	cmp, err := update.CompareVersions(Version, rel.Version())
+	if err != nil {
+		return fmt.Errorf("cannot compare versions (is this a development build?): %w", err)
+	}
+	if cmp >= 0 {
+		fmt.Fprintln(out, "Already up to date.")
+		return nil
+	}
+	if checkOnly {
+		fmt.Fprintf(out, "Run '%s update' to install %s.\n", binaryName, rel.Version())
+		return nil
+	}
+
+	if u.PublicKey == "" {
+		return &exitcode.ConfigError{Err: fmt.Errorf("no release signing key configured (app.update.public_key), refusing to install an unverified binary")}
+	}
+	if _, err := update.ParsePublicKey(u.PublicKey); err != nil {
+		return &exitcode.ConfigError{Err: fmt.Errorf("invalid app.update.public_key: %w", err)}
+	}
+
+	target, err := executablePath()
+	if err != nil {
+		return fmt.Errorf("failed to locate the running binary: %w", err)
+	}
+	if dryRun(cmd) {
+		return printPlanned(cmd, "replace %s with %s", target, rel.Version())
+	}
+	rc.Logger.Info().Str("target", target).Str("version", rel.Version()).Msg("Installing update")
+	if err := u.Apply(cmd.Context(), rel, target); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(out, "Updated %s to %s.\n", binaryName, rel.Version())
+	return nil
+}
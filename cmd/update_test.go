@@ -0,0 +1,102 @@
+// cmd/update_test.go
+
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/peiman/ckeletin-go/internal/exitcode"
+	"github.com/peiman/ckeletin-go/internal/update"
+	"github.com/spf13/viper"
+)
+
+// setupUpdateTest points the update command at a fake release API returning tag
+func setupUpdateTest(t *testing.T, tag string) {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(update.Release{TagName: tag})
+	}))
+	t.Cleanup(srv.Close)
+
+	origUpdater, origVersion := newUpdater, Version
+	newUpdater = func(cfg *viper.Viper) *update.Updater {
+		return &update.Updater{APIURL: srv.URL, Repo: "acme/mycli", BinaryName: "mycli", PublicKey: cfg.GetString("app.update.public_key")}
+	}
+	t.Cleanup(func() { newUpdater, Version = origUpdater, origVersion })
+}
+
+// executeUpdate runs the update command with args and values set in its config
+func executeUpdate(t *testing.T, values map[string]interface{}, args ...string) (string, error) {
+	t.Helper()
+	buf := new(bytes.Buffer)
+	updateCmd.SetOut(buf)
+	updateCmd.SetContext(context.Background())
+	if err := updateCmd.ParseFlags(args); err != nil {
+		t.Fatalf("ParseFlags() error = %v", err)
+	}
+	useConfig(t, updateCmd, values)
+	t.Cleanup(func() { _ = updateCmd.Flags().Set("check-only", "false") })
+	err := runUpdate(updateCmd, nil)
+	return buf.String(), err
+}
+
+func TestRunUpdate(t *testing.T) {
+	tests := []struct {
+		name       string
+		version    string
+		tag        string
+		args       []string
+		wantErr    string
+		wantOutput string
+	}{
+		{"Up to date", "1.2.0", "v1.2.0", nil, "", "Already up to date."},
+		{"Newer local build", "1.3.0", "v1.2.0", nil, "", "Already up to date."},
+		{"Check only", "1.0.0", "v1.2.0", []string{"--check-only"}, "", "Run 'ckeletin-go update' to install 1.2.0."},
+		{"Development build", "dev", "v1.2.0", nil, "cannot compare versions", ""},
+		{"No signing key", "1.0.0", "v1.2.0", nil, "no release signing key configured", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setupUpdateTest(t, tt.tag)
+			Version = tt.version
+
+			output, err := executeUpdate(t, nil, tt.args...)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Expected error containing %q, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("runUpdate() error = %v", err)
+			}
+			if !strings.Contains(output, tt.wantOutput) {
+				t.Errorf("Expected output to contain %q, got %q", tt.wantOutput, output)
+			}
+		})
+	}
+}
+
+func TestRunUpdate_InvalidKey(t *testing.T) {
+	setupUpdateTest(t, "v1.2.0")
+	Version = "1.0.0"
+	_, err := executeUpdate(t, map[string]interface{}{"app.update.public_key": "not a key"})
+	if exitcode.Code(err) != exitcode.Config || !strings.Contains(err.Error(), "invalid app.update.public_key") {
+		t.Errorf("Expected a config error for an invalid key, got %v", err)
+	}
+}
+
+func TestRunUpdate_Disabled(t *testing.T) {
+	setupUpdateTest(t, "v1.2.0")
+	_, err := executeUpdate(t, map[string]interface{}{"app.update.enabled": false})
+	if err == nil || !strings.Contains(err.Error(), "disabled") {
+		t.Errorf("Expected disabled error, got %v", err)
+	}
+}
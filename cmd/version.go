@@ -0,0 +1,119 @@
+// cmd/version.go
+
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"runtime"
+	"runtime/debug"
+	"strings"
+
+	"github.com/peiman/ckeletin-go/internal/output"
+	"github.com/spf13/cobra"
+)
+
+// readBuildInfo returns the Go build info of the binary, can be replaced in tests
+var readBuildInfo = debug.ReadBuildInfo
+
+var versionCmd = &cobra.Command{
+	Use:         "version",
+	Short:       "Print version and build information",
+	Annotations: map[string]string{docsAnnotation: "version-command"},
+	Long: `Prints the version, commit, build date, Go version, platform and whether the
+binary was built from a modified working tree.
+- Use --short to print only the version, e.g. in scripts.
+- Use --output json or yaml (or --format) for machine-readable output.`,
+	Args: cobra.NoArgs,
+	RunE: runVersion,
+}
+
+func init() {
+	versionCmd.Flags().Bool("short", false, "Print only the version number")
+	versionCmd.Flags().String("format", "", "Output format, overrides --output (text, json, yaml)")
+	addExamples(versionCmd,
+		example{Desc: "Show the version with commit, build date and Go version", Line: "version"},
+		example{Desc: "Print only the version number, e.g. in scripts", Line: "version --short"},
+		example{Desc: "Show the build details as JSON", Line: "version --output json"},
+	)
+	RootCmd.AddCommand(versionCmd)
+}
+
+// buildInfo describes how the running binary was built
+type buildInfo struct {
+	Version   string `json:"version"`
+	Commit    string `json:"commit"`
+	Date      string `json:"date"`
+	GoVersion string `json:"go_version"`
+	Platform  string `json:"platform"`
+	Dirty     bool   `json:"dirty"`
+}
+
+// currentBuildInfo combines the ldflags variables with the VCS data embedded by the Go toolchain.
+// The ldflags values take precedence; VCS data fills in what was not set.
+func currentBuildInfo() buildInfo {
+	info := buildInfo{
+		Version:   Version,
+		Commit:    Commit,
+		Date:      Date,
+		GoVersion: runtime.Version(),
+		Platform:  runtime.GOOS + "/" + runtime.GOARCH,
+		Dirty:     strings.HasSuffix(Version, "-dirty"),
+	}
+
+	if bi, ok := readBuildInfo(); ok {
+		for _, s := range bi.Settings {
+			switch s.Key {
+			case "vcs.revision":
+				if info.Commit == "" {
+					info.Commit = s.Value
+				}
+			case "vcs.time":
+				if info.Date == "" {
+					info.Date = s.Value
+				}
+			case "vcs.modified":
+				info.Dirty = info.Dirty || s.Value == "true"
+			}
+		}
+	}
+	return info
+}
+
+func runVersion(cmd *cobra.Command, args []string) error {
+	short, _ := cmd.Flags().GetBool("short")
+	format, err := outputFormat(cmd, "")
+	if err != nil {
+		return err
+	}
+	info := currentBuildInfo()
+	out := cmd.OutOrStdout()
+
+	switch {
+	case short && format == output.Text:
+		_, err := fmt.Fprintln(out, info.Version)
+		return err
+	case short:
+		return output.Render(out, format, map[string]string{"version": info.Version})
+	}
+	return output.Render(out, format, info)
+}
+
+// WriteText prints the build details for humans
+func (info buildInfo) WriteText(w io.Writer) error {
+	_, err := fmt.Fprintf(w, `%s %s
+  Commit:     %s
+  Built:      %s
+  Go version: %s
+  Platform:   %s
+  Dirty:      %t
+`, binaryName, info.Version, valueOrUnknown(info.Commit), valueOrUnknown(info.Date), info.GoVersion, info.Platform, info.Dirty)
+	return err
+}
+
+func valueOrUnknown(s string) string {
+	if s == "" {
+		return "unknown"
+	}
+	return s
+}
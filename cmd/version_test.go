@@ -0,0 +1,125 @@
+// cmd/version_test.go
+
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"runtime"
+	"runtime/debug"
+	"strings"
+	"testing"
+)
+
+// setBuildVars overrides the ldflags variables and Go build info for one test
+func setBuildVars(t *testing.T, version, commit, date string, settings ...debug.BuildSetting) {
+	t.Helper()
+	origVersion, origCommit, origDate, origRead := Version, Commit, Date, readBuildInfo
+	t.Cleanup(func() { Version, Commit, Date, readBuildInfo = origVersion, origCommit, origDate, origRead })
+
+	Version, Commit, Date = version, commit, date
+	readBuildInfo = func() (*debug.BuildInfo, bool) {
+		return &debug.BuildInfo{Settings: settings}, true
+	}
+}
+
+func executeVersion(t *testing.T, args ...string) string {
+	t.Helper()
+	buf := new(bytes.Buffer)
+	versionCmd.SetOut(buf)
+	_ = versionCmd.Flags().Set("short", "false")
+	_ = versionCmd.Flags().Set("format", "text")
+	if err := versionCmd.ParseFlags(args); err != nil {
+		t.Fatalf("ParseFlags() error = %v", err)
+	}
+	if err := runVersion(versionCmd, nil); err != nil {
+		t.Fatalf("runVersion() error = %v", err)
+	}
+	return buf.String()
+}
+
+func TestCurrentBuildInfo(t *testing.T) {
+	tests := []struct {
+		name       string
+		version    string
+		commit     string
+		settings   []debug.BuildSetting
+		wantCommit string
+		wantDate   string
+		wantDirty  bool
+	}{
+		{
+			name:       "ldflags take precedence",
+			version:    "1.0.0",
+			commit:     "abc123",
+			settings:   []debug.BuildSetting{{Key: "vcs.revision", Value: "def456"}, {Key: "vcs.time", Value: "2024-01-01T00:00:00Z"}},
+			wantCommit: "abc123",
+			wantDate:   "2024-01-01T00:00:00Z",
+		},
+		{
+			name:       "VCS data fills in",
+			version:    "dev",
+			settings:   []debug.BuildSetting{{Key: "vcs.revision", Value: "def456"}, {Key: "vcs.modified", Value: "true"}},
+			wantCommit: "def456",
+			wantDirty:  true,
+		},
+		{
+			name:      "Dirty version suffix",
+			version:   "1.0.0-3-gabc-dirty",
+			wantDirty: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setBuildVars(t, tt.version, tt.commit, "", tt.settings...)
+			info := currentBuildInfo()
+
+			if info.Version != tt.version || info.Commit != tt.wantCommit || info.Date != tt.wantDate || info.Dirty != tt.wantDirty {
+				t.Errorf("currentBuildInfo() = %+v", info)
+			}
+			if info.Platform != runtime.GOOS+"/"+runtime.GOARCH || info.GoVersion != runtime.Version() {
+				t.Errorf("Unexpected platform info: %+v", info)
+			}
+		})
+	}
+}
+
+func TestVersionCommand(t *testing.T) {
+	setBuildVars(t, "1.2.3", "abc123", "")
+
+	output := executeVersion(t)
+	for _, want := range []string{"ckeletin-go 1.2.3", "Commit:     abc123", "Built:      unknown", "Dirty:      false"} {
+		if !strings.Contains(output, want) {
+			t.Errorf("Expected output to contain %q, got %q", want, output)
+		}
+	}
+
+	if got := executeVersion(t, "--short"); got != "1.2.3\n" {
+		t.Errorf("--short output = %q, want %q", got, "1.2.3\n")
+	}
+
+	var info buildInfo
+	if err := json.Unmarshal([]byte(executeVersion(t, "--format", "json")), &info); err != nil {
+		t.Fatalf("--format json output is not valid JSON: %v", err)
+	}
+	if info.Version != "1.2.3" || info.Commit != "abc123" {
+		t.Errorf("Unexpected JSON build info: %+v", info)
+	}
+
+	var short map[string]string
+	if err := json.Unmarshal([]byte(executeVersion(t, "--short", "--format", "json")), &short); err != nil || short["version"] != "1.2.3" {
+		t.Errorf("Unexpected short JSON output: %v (err %v)", short, err)
+	}
+}
+
+func TestVersionCommand_InvalidFormat(t *testing.T) {
+	if err := versionCmd.ParseFlags([]string{"--format", "xml"}); err != nil {
+		t.Fatalf("ParseFlags() error = %v", err)
+	}
+	defer func() { _ = versionCmd.Flags().Set("format", "text") }()
+
+	if err := runVersion(versionCmd, nil); err == nil {
+		t.Errorf("Expected error for invalid format")
+	}
+}
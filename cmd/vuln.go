@@ -0,0 +1,172 @@
+// cmd/vuln.go
+
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/peiman/ckeletin-go/internal/config"
+	"github.com/peiman/ckeletin-go/internal/exitcode"
+	"github.com/peiman/ckeletin-go/internal/vulncheck"
+	"github.com/peiman/ckeletin-go/pkg/retry"
+	"github.com/spf13/cobra"
+)
+
+// govulncheckRetry retries govulncheck, which fails when the vulnerability
+// database cannot be downloaded. Retries are logged by the command.
+var govulncheckRetry = retry.Policy{
+	Retries: 2,
+	Backoff: retry.DefaultBackoff,
+}
+
+// runGovulncheck returns the JSON output of govulncheck for patterns, can be replaced in tests
+var runGovulncheck = func(ctx context.Context, patterns []string, stderr io.Writer) ([]byte, error) {
+	var out bytes.Buffer
+	c := exec.CommandContext(ctx, "govulncheck", append([]string{"-json"}, patterns...)...)
+	c.Stdout = &out
+	c.Stderr = stderr
+	if err := c.Run(); err != nil {
+		if errors.Is(err, exec.ErrNotFound) {
+			return nil, retry.Permanent(errors.New("govulncheck not found, install it with 'go install golang.org/x/vuln/cmd/govulncheck@latest'"))
+		}
+		return nil, fmt.Errorf("govulncheck failed: %w", err)
+	}
+	return out.Bytes(), nil
+}
+
+var devVulnCmd = &cobra.Command{
+	Use:   "vuln [packages]",
+	Short: "Report vulnerabilities reachable from the code",
+	Long: `Runs govulncheck (default on ./...) and reports only vulnerabilities whose
+vulnerable functions are actually called, with the affected module, the called
+symbols, the fixed version and the command that upgrades to it.
+
+Accepted risks are listed in the ignore file (app.vuln.ignore_file, default
+.vulnignore.yaml) with a reason and an expiry date, after which they are
+reported again:
+
+  ignore:
+    - id: GO-2024-0001
+      reason: only parses trusted input
+      expires: 2025-06-30
+
+Exits with status 4 when unignored vulnerabilities are found.`,
+	RunE: runDevVuln,
+}
+
+func init() {
+	devVulnCmd.Flags().String("input", "", "Read saved 'govulncheck -json' output from this file ('-' for stdin) instead of running govulncheck")
+	// scaffold:jobs
+	devVulnCmd.Flags().Bool("background", false, "Run detached; see 'jobs list' for the result")
+	// scaffold:end
+
+	initVulnConfig()
+	configFlags(devVulnCmd, "app.vuln")
+	devCmd.AddCommand(devVulnCmd)
+}
+
+func initVulnConfig() {
+	config.Register(config.Option{Key: "app.vuln.ignore_file", Flag: "ignore-file", Default: ".vulnignore.yaml", Description: "File listing accepted vulnerabilities", Path: config.PathWorkDir})
+}
+
+func runDevVuln(cmd *cobra.Command, args []string) error {
+	// scaffold:jobs
+	if background, _ := cmd.Flags().GetBool("background"); background {
+		return runInBackground(cmd)
+	}
+	// scaffold:end
+
+	rc := runContext(cmd)
+	log := rc.Logger
+	ignoreFile := rc.Config.GetString("app.vuln.ignore_file")
+	if cmd.Flags().Changed("ignore-file") {
+		ignoreFile, _ = cmd.Flags().GetString("ignore-file")
+	}
+	input, _ := cmd.Flags().GetString("input")
+
+	ignores, err := vulncheck.LoadIgnores(ignoreFile)
+	if err != nil {
+		return &exitcode.ConfigError{Err: err}
+	}
+
+	var data []byte
+	switch input {
+	case "":
+		patterns := args
+		if len(patterns) == 0 {
+			patterns = []string{"./..."}
+		}
+		policy := govulncheckRetry
+		policy.OnRetry = func(e retry.Event) {
+			log.Warn().Err(e.Err).Dur("delay", e.Delay).Msgf("Retrying govulncheck (%d/%d)", e.Retry, e.Retries)
+		}
+		err = retry.Do(cmd.Context(), policy, func(ctx context.Context) (err error) {
+			data, err = runGovulncheck(ctx, patterns, cmd.ErrOrStderr())
+			return err
+		})
+	case "-":
+		data, err = io.ReadAll(cmd.InOrStdin())
+	default:
+		data, err = os.ReadFile(input)
+	}
+	if err != nil {
+		return err
+	}
+
+	vulns, err := vulncheck.Parse(bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+
+	active, ignored, expired := vulncheck.Filter(vulns, ignores, time.Now())
+	// Empty lists rather than null in JSON and YAML output
+	report := vulnReport{
+		Vulns:   append([]vulncheck.Vuln{}, active...),
+		Ignored: append([]vulncheck.Vuln{}, ignored...),
+		Expired: append([]vulncheck.Ignore{}, expired...),
+	}
+	for _, i := range expired {
+		log.Warn().Str("id", i.ID).Str("expired", i.Expires).Msg("Vulnerability ignore has expired")
+	}
+
+	if err := renderOutput(cmd, report); err != nil {
+		return err
+	}
+	if len(report.Vulns) > 0 {
+		return &exitcode.CheckFailure{Err: fmt.Errorf("%d reachable vulnerabilities found", len(report.Vulns))}
+	}
+	return nil
+}
+
+// vulnReport is the result of dev vuln
+type vulnReport struct {
+	Vulns   []vulncheck.Vuln   `json:"vulnerabilities"`
+	Ignored []vulncheck.Vuln   `json:"ignored"`
+	Expired []vulncheck.Ignore `json:"expired_ignores"`
+}
+
+// WriteText prints one block per vulnerability
+func (r vulnReport) WriteText(w io.Writer) error {
+	var b strings.Builder
+	for _, v := range r.Vulns {
+		fmt.Fprintf(&b, "%s: %s\n", v.ID, v.Summary)
+		fmt.Fprintf(&b, "  Module:   %s@%s\n", v.Module, v.Version)
+		fmt.Fprintf(&b, "  Called:   %s\n", strings.Join(v.Symbols, ", "))
+		fmt.Fprintf(&b, "  Fixed in: %s\n", valueOrUnknown(v.FixedVersion))
+		fmt.Fprintf(&b, "  Fix:      %s\n\n", v.Remediation())
+	}
+	for _, i := range r.Expired {
+		fmt.Fprintf(&b, "Ignore for %s expired on %s (%s)\n", i.ID, i.Expires, i.Reason)
+	}
+	fmt.Fprintf(&b, "%d reachable vulnerabilities, %d ignored\n", len(r.Vulns), len(r.Ignored))
+	_, err := io.WriteString(w, b.String())
+	return err
+}
@@ -0,0 +1,179 @@
+// cmd/vuln_test.go
+
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	// scaffold:jobs
+	"reflect"
+	// scaffold:end
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/peiman/ckeletin-go/internal/exitcode"
+	// scaffold:jobs
+	"github.com/peiman/ckeletin-go/internal/jobs"
+	// scaffold:end
+	"github.com/peiman/ckeletin-go/pkg/retry"
+)
+
+const vulnOutput = `{"osv": {"id": "GO-2024-0001", "summary": "Panic in YAML parsing"}}
+{"finding": {"osv": "GO-2024-0001", "fixed_version": "v3.0.2",
+  "trace": [{"module": "gopkg.in/yaml.v3", "version": "v3.0.1", "package": "gopkg.in/yaml.v3", "function": "Unmarshal"}]}}
+`
+
+func TestRunDevVuln(t *testing.T) {
+	origRun := runGovulncheck
+	defer func() { runGovulncheck = origRun }()
+
+	var gotPatterns []string
+	runGovulncheck = func(ctx context.Context, patterns []string, stderr io.Writer) ([]byte, error) {
+		gotPatterns = patterns
+		return []byte(vulnOutput), nil
+	}
+
+	dir := t.TempDir()
+	ignorePath := filepath.Join(dir, "ignore.yaml")
+
+	out := new(bytes.Buffer)
+	devVulnCmd.SetOut(out)
+	defer devVulnCmd.SetOut(nil)
+	useConfig(t, devVulnCmd, map[string]interface{}{"app.vuln.ignore_file": ignorePath})
+
+	err := runDevVuln(devVulnCmd, nil)
+	if exitcode.Code(err) != exitcode.CheckFailed {
+		t.Errorf("Expected a check failure, got %v", err)
+	}
+	if len(gotPatterns) != 1 || gotPatterns[0] != "./..." {
+		t.Errorf("patterns = %v, want ./...", gotPatterns)
+	}
+	for _, want := range []string{"GO-2024-0001: Panic in YAML parsing", "gopkg.in/yaml.v3.Unmarshal", "go get gopkg.in/yaml.v3@v3.0.2", "1 reachable vulnerabilities, 0 ignored"} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("Output missing %q:\n%s", want, out.String())
+		}
+	}
+
+	ignore := "ignore:\n  - id: GO-2024-0001\n    reason: trusted input\n    expires: 2999-01-01\n"
+	if err := os.WriteFile(ignorePath, []byte(ignore), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	out.Reset()
+	if err := runDevVuln(devVulnCmd, []string{"./cmd/..."}); err != nil {
+		t.Errorf("Expected ignored vulnerability to pass, got %v", err)
+	}
+	if !strings.Contains(out.String(), "0 reachable vulnerabilities, 1 ignored") || gotPatterns[0] != "./cmd/..." {
+		t.Errorf("Unexpected output %q for patterns %v", out.String(), gotPatterns)
+	}
+
+	if err := os.WriteFile(ignorePath, []byte("ignore:\n  - id: GO-2024-0001\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := runDevVuln(devVulnCmd, nil); exitcode.Code(err) != exitcode.Config {
+		t.Errorf("Expected a config error for an invalid ignore file, got %v", err)
+	}
+}
+
+func TestRunDevVuln_Input(t *testing.T) {
+	origRun := runGovulncheck
+	defer func() { runGovulncheck = origRun }()
+	runGovulncheck = func(context.Context, []string, io.Writer) ([]byte, error) {
+		return nil, errors.New("should not run")
+	}
+
+	out := new(bytes.Buffer)
+	devVulnCmd.SetOut(out)
+	devVulnCmd.SetIn(strings.NewReader(`{"config": {"scanner_name": "govulncheck"}}`))
+	useConfig(t, devVulnCmd, map[string]interface{}{"app.vuln.ignore_file": filepath.Join(t.TempDir(), "none.yaml"), "app.output": "json"})
+	defer func() {
+		devVulnCmd.SetOut(nil)
+		devVulnCmd.SetIn(nil)
+		_ = devVulnCmd.Flags().Set("input", "")
+	}()
+	if err := devVulnCmd.Flags().Set("input", "-"); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := runDevVuln(devVulnCmd, nil); err != nil {
+		t.Fatalf("runDevVuln() error = %v", err)
+	}
+	if !strings.Contains(out.String(), `"vulnerabilities": []`) {
+		t.Errorf("Expected empty JSON report, got %s", out.String())
+	}
+}
+
+func TestRunDevVuln_Retry(t *testing.T) {
+	origRun, origRetry := runGovulncheck, govulncheckRetry
+	defer func() { runGovulncheck, govulncheckRetry = origRun, origRetry }()
+	govulncheckRetry.Backoff = retry.Backoff{Initial: time.Millisecond}
+
+	calls := 0
+	runGovulncheck = func(context.Context, []string, io.Writer) ([]byte, error) {
+		calls++
+		if calls == 1 {
+			return nil, errors.New("fetching vulnerabilities: connection reset")
+		}
+		return []byte(`{"config": {"scanner_name": "govulncheck"}}`), nil
+	}
+
+	devVulnCmd.SetOut(io.Discard)
+	devVulnCmd.SetContext(context.Background())
+	defer devVulnCmd.SetOut(nil)
+	useConfig(t, devVulnCmd, map[string]interface{}{"app.vuln.ignore_file": filepath.Join(t.TempDir(), "none.yaml")})
+
+	if err := runDevVuln(devVulnCmd, nil); err != nil || calls != 2 {
+		t.Errorf("runDevVuln() error = %v after %d calls, want success after 2", err, calls)
+	}
+
+	calls = 0
+	runGovulncheck = func(context.Context, []string, io.Writer) ([]byte, error) {
+		calls++
+		return nil, retry.Permanent(errors.New("govulncheck not found"))
+	}
+	if err := runDevVuln(devVulnCmd, nil); err == nil || calls != 1 {
+		t.Errorf("Expected a permanent error without retries, got %v after %d calls", err, calls)
+	}
+}
+
+// scaffold:jobs
+
+func TestRunDevVuln_Background(t *testing.T) {
+	useJobsDir(t)
+	origStart := startJob
+	defer func() { startJob = origStart }()
+	var started []string
+	startJob = func(m *jobs.Manager, args []string) (*jobs.Job, error) {
+		started = args
+		return &jobs.Job{ID: "abcd1234"}, nil
+	}
+	origArgs := os.Args
+	defer func() { os.Args = origArgs }()
+	os.Args = []string{binaryName, "dev", "vuln", "--background", "./..."}
+
+	out := new(bytes.Buffer)
+	devVulnCmd.SetOut(out)
+	defer func() {
+		devVulnCmd.SetOut(nil)
+		_ = devVulnCmd.Flags().Set("background", "false")
+	}()
+	if err := devVulnCmd.Flags().Set("background", "true"); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := runDevVuln(devVulnCmd, []string{"./..."}); err != nil {
+		t.Fatalf("runDevVuln() error = %v", err)
+	}
+	if want := []string{"dev", "vuln", "./..."}; !reflect.DeepEqual(started, want) {
+		t.Errorf("Started %v, want %v", started, want)
+	}
+	if !strings.Contains(out.String(), "Started job abcd1234") {
+		t.Errorf("Unexpected output %q", out.String())
+	}
+}
+
+// scaffold:end
@@ -0,0 +1,72 @@
+// cmd/watch.go
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/rs/zerolog"
+)
+
+// configDebounce coalesces the burst of events an editor produces for one save
+// (e.g. truncate followed by write) into a single change.
+var configDebounce = 200 * time.Millisecond
+
+// fileWatcher reports changes of one file, see watchFile
+type fileWatcher struct {
+	watcher *fsnotify.Watcher
+	file    string
+}
+
+// watchFile starts watching the file at path. Changes made after it returns
+// are reported by run; Close the watcher when done.
+func watchFile(path string) (*fileWatcher, error) {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, fmt.Errorf("failed to watch config file: %w", err)
+	}
+	file := filepath.Clean(path)
+	// Watch the directory, as editors often replace the file instead of writing to it.
+	if err := watcher.Add(filepath.Dir(file)); err != nil {
+		_ = watcher.Close()
+		return nil, fmt.Errorf("failed to watch config file: %w", err)
+	}
+	return &fileWatcher{watcher: watcher, file: file}, nil
+}
+
+// Close stops watching the file
+func (w *fileWatcher) Close() error {
+	return w.watcher.Close()
+}
+
+// run calls changed whenever the file was written or replaced, once the
+// writes settled, until ctx is cancelled or the watcher is closed. Events are
+// handled on the calling goroutine, one at a time.
+func (w *fileWatcher) run(ctx context.Context, log zerolog.Logger, changed func()) {
+	var settled <-chan time.Time
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case e, ok := <-w.watcher.Events:
+			if !ok {
+				return
+			}
+			if filepath.Clean(e.Name) == w.file && e.Has(fsnotify.Write|fsnotify.Create) {
+				settled = time.After(configDebounce)
+			}
+		case err, ok := <-w.watcher.Errors:
+			if !ok {
+				return
+			}
+			log.Error().Err(err).Msg("Config file watcher failed")
+		case <-settled:
+			settled = nil
+			changed()
+		}
+	}
+}
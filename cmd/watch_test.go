@@ -0,0 +1,65 @@
+// cmd/watch_test.go
+
+package cmd
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+func TestFileWatcher(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte("a: 1\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	watcher, err := watchFile(path)
+	if err != nil {
+		t.Fatalf("watchFile() error = %v", err)
+	}
+	defer watcher.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	changed := make(chan struct{}, 10)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		watcher.run(ctx, zerolog.Nop(), func() { changed <- struct{}{} })
+	}()
+
+	// Other files in the directory are ignored
+	if err := os.WriteFile(filepath.Join(filepath.Dir(path), "other.yaml"), nil, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte("a: 2\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case <-changed:
+	case <-time.After(3 * time.Second):
+		t.Fatal("Change of the file not reported")
+	}
+	time.Sleep(configDebounce + 50*time.Millisecond)
+	if n := len(changed); n != 0 {
+		t.Errorf("Got %d more changes, want one per save", n)
+	}
+
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(3 * time.Second):
+		t.Fatal("run() did not return after context cancellation")
+	}
+}
+
+func TestWatchFile_MissingDirectory(t *testing.T) {
+	_, err := watchFile(filepath.Join(t.TempDir(), "missing", "config.yaml"))
+	if err == nil || !strings.Contains(err.Error(), "failed to watch config file") {
+		t.Errorf("Expected a watch error, got %v", err)
+	}
+}
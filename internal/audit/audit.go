@@ -0,0 +1,208 @@
+// internal/audit/audit.go
+
+// Package audit keeps an append-only trail of command invocations as JSON lines:
+// who ran what, when, and with which result. Values of secret flags are masked
+// before anything is written.
+package audit
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/user"
+	"strings"
+	"time"
+
+	"github.com/spf13/pflag"
+)
+
+// SecretAnnotation marks a flag whose value must never be written to the audit log:
+//
+//	_ = cmd.Flags().SetAnnotation("token", audit.SecretAnnotation, []string{"true"})
+const SecretAnnotation = "audit_secret"
+
+// Masked replaces the value of secret flags
+const Masked = "****"
+
+// secretWords identify secret flags by name when they are not annotated,
+// including flags of plugins the host knows nothing about.
+var secretWords = []string{"password", "passwd", "secret", "token", "apikey", "api-key", "api_key", "credential", "private-key"}
+
+// Entry is one recorded invocation
+type Entry struct {
+	Start    time.Time `json:"start"`
+	End      time.Time `json:"end"`
+	User     string    `json:"user"`
+	Command  string    `json:"command"`
+	Args     []string  `json:"args"`
+	ExitCode int       `json:"exit_code"`
+}
+
+// Log is an audit log stored at Path
+type Log struct {
+	Path string
+}
+
+// Append adds e to the log
+func (l *Log) Append(e Entry) error {
+	data, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	f, err := os.OpenFile(l.Path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		return fmt.Errorf("failed to open audit log: %w", err)
+	}
+	defer f.Close()
+	// A single write keeps lines from concurrent invocations intact.
+	if _, err := f.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("failed to write audit log: %w", err)
+	}
+	return nil
+}
+
+// Entries returns all entries, oldest first. Unreadable lines are skipped.
+func (l *Log) Entries() ([]Entry, error) {
+	data, err := os.ReadFile(l.Path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read audit log: %w", err)
+	}
+
+	var entries []Entry
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 0, 64*1024), len(data)+1)
+	for scanner.Scan() {
+		var e Entry
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			continue
+		}
+		entries = append(entries, e)
+	}
+	return entries, scanner.Err()
+}
+
+// CurrentUser returns the name of the user running the process
+func CurrentUser() string {
+	if u, err := user.Current(); err == nil && u.Username != "" {
+		return u.Username
+	}
+	for _, name := range []string{"USER", "USERNAME"} {
+		if v := os.Getenv(name); v != "" {
+			return v
+		}
+	}
+	return "unknown"
+}
+
+// MaskArgs returns a copy of args with the values of secret flags replaced by Masked.
+// A flag is secret when it carries SecretAnnotation in fs or its name looks like a
+// credential. Shorthands are read like pflag does, so the value of "-tSECRET" or
+// "-vt SECRET" is masked too. Arguments after "--" are left untouched.
+func MaskArgs(fs *pflag.FlagSet, args []string) []string {
+	masked := make([]string, len(args))
+	copy(masked, args)
+
+	for i := 0; i < len(masked); i++ {
+		arg := masked[i]
+		if arg == "--" {
+			break
+		}
+		if !strings.HasPrefix(arg, "-") || arg == "-" {
+			continue
+		}
+
+		if !strings.HasPrefix(arg, "--") {
+			var maskNext, ok bool
+			if masked[i], maskNext, ok = maskShorthands(fs, arg); ok {
+				if maskNext && i+1 < len(masked) {
+					masked[i+1] = Masked
+					i++
+				}
+				continue
+			}
+		}
+
+		name, _, hasValue := strings.Cut(strings.TrimLeft(arg, "-"), "=")
+		var f *pflag.Flag
+		if fs != nil && strings.HasPrefix(arg, "--") {
+			f = fs.Lookup(name)
+		}
+		if !isSecret(f, name) {
+			continue
+		}
+
+		if hasValue {
+			prefix, _, _ := strings.Cut(arg, "=")
+			masked[i] = prefix + "=" + Masked
+			continue
+		}
+		// The value is the next argument unless the flag is a known boolean.
+		if f != nil && f.NoOptDefVal != "" {
+			continue
+		}
+		if i+1 < len(masked) && (f != nil || !strings.HasPrefix(masked[i+1], "-")) {
+			masked[i+1] = Masked
+			i++
+		}
+	}
+	return masked
+}
+
+// maskShorthands masks the value of a secret flag in a group of shorthands such as
+// "-vtSECRET", read like pflag does: boolean shorthands are skipped and the first
+// one taking a value consumes the rest of the group, or the next argument when
+// nothing is left, reported by maskNext. ok is false when the group does not
+// start with a known shorthand.
+func maskShorthands(fs *pflag.FlagSet, arg string) (masked string, maskNext, ok bool) {
+	if fs == nil {
+		return arg, false, false
+	}
+	group := arg[1:]
+	for j := 0; j < len(group); j++ {
+		f := fs.ShorthandLookup(group[j : j+1])
+		if f == nil {
+			return arg, false, j > 0
+		}
+		rest := group[j+1:]
+		if f.NoOptDefVal != "" {
+			if strings.HasPrefix(rest, "=") {
+				return arg, false, true
+			}
+			continue
+		}
+		switch {
+		case !isSecret(f, f.Name):
+			return arg, false, true
+		case rest == "":
+			return arg, true, true
+		case rest[0] == '=':
+			return arg[:j+3] + Masked, false, true
+		default:
+			return arg[:j+2] + Masked, false, true
+		}
+	}
+	return arg, false, true
+}
+
+func isSecret(f *pflag.Flag, name string) bool {
+	if f != nil {
+		if _, ok := f.Annotations[SecretAnnotation]; ok {
+			return true
+		}
+		name = f.Name
+	}
+	name = strings.ToLower(name)
+	for _, word := range secretWords {
+		if strings.Contains(name, word) {
+			return true
+		}
+	}
+	return false
+}
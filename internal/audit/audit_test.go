@@ -0,0 +1,83 @@
+// internal/audit/audit_test.go
+
+package audit
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/spf13/pflag"
+)
+
+func TestMaskArgs(t *testing.T) {
+	fs := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	fs.String("message", "", "")
+	fs.StringP("auth", "a", "", "")
+	_ = fs.SetAnnotation("auth", SecretAnnotation, []string{"true"})
+	fs.String("api-token", "", "")
+	fs.Bool("password-prompt", false, "")
+	fs.BoolP("verbose", "v", false, "")
+	fs.StringP("output", "o", "", "")
+
+	tests := []struct {
+		name string
+		args []string
+		want []string
+	}{
+		{"Plain flags", []string{"ping", "--message", "hi"}, []string{"ping", "--message", "hi"}},
+		{"Annotated flag", []string{"--auth", "s3cret", "x"}, []string{"--auth", Masked, "x"}},
+		{"Annotated shorthand", []string{"-a", "s3cret"}, []string{"-a", Masked}},
+		{"Shorthand with attached value", []string{"-as3cret", "x"}, []string{"-a" + Masked, "x"}},
+		{"Shorthand with equals", []string{"-a=s3cret"}, []string{"-a=" + Masked}},
+		{"Combined shorthands", []string{"-vas3cret", "-va", "s3cret"}, []string{"-va" + Masked, "-va", Masked}},
+		{"Other shorthand value", []string{"-ojson", "-o", "a"}, []string{"-ojson", "-o", "a"}},
+		{"Secret by name with equals", []string{"--api-token=abc"}, []string{"--api-token=" + Masked}},
+		{"Boolean secret-looking flag", []string{"--password-prompt", "ping"}, []string{"--password-prompt", "ping"}},
+		{"Unknown plugin flag", []string{"deploy", "--db-password", "pw", "--verbose"}, []string{"deploy", "--db-password", Masked, "--verbose"}},
+		{"After double dash", []string{"--", "--api-token", "abc"}, []string{"--", "--api-token", "abc"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := MaskArgs(fs, tt.args); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("MaskArgs() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLog_AppendAndEntries(t *testing.T) {
+	l := &Log{Path: filepath.Join(t.TempDir(), "audit.jsonl")}
+
+	entries, err := l.Entries()
+	if err != nil || len(entries) != 0 {
+		t.Fatalf("Entries() on missing log = %v, %v", entries, err)
+	}
+
+	start := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	for i, cmd := range []string{"ping", "fetch"} {
+		e := Entry{Start: start, End: start.Add(time.Second), User: "alice", Command: cmd, Args: []string{cmd}, ExitCode: i}
+		if err := l.Append(e); err != nil {
+			t.Fatalf("Append() error = %v", err)
+		}
+	}
+
+	entries, err = l.Entries()
+	if err != nil {
+		t.Fatalf("Entries() error = %v", err)
+	}
+	if len(entries) != 2 || entries[0].Command != "ping" || entries[1].ExitCode != 1 {
+		t.Errorf("Entries() = %+v", entries)
+	}
+	if !entries[0].Start.Equal(start) || entries[0].User != "alice" {
+		t.Errorf("Entry not round-tripped: %+v", entries[0])
+	}
+}
+
+func TestCurrentUser(t *testing.T) {
+	if CurrentUser() == "" {
+		t.Error("CurrentUser() returned an empty name")
+	}
+}
@@ -0,0 +1,201 @@
+// internal/benchcheck/benchcheck.go
+
+// Package benchcheck reads the output of go test -bench and compares the
+// results with a committed baseline, so performance regressions fail checks
+// like test failures do.
+package benchcheck
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Result is the measurement of one benchmark
+type Result struct {
+	// Name is the package and benchmark, e.g. "internal/output.BenchmarkRender/json"
+	Name        string  `yaml:"-" json:"name"`
+	NsPerOp     float64 `yaml:"ns_per_op" json:"ns_per_op"`
+	BytesPerOp  int64   `yaml:"bytes_per_op" json:"bytes_per_op"`
+	AllocsPerOp int64   `yaml:"allocs_per_op" json:"allocs_per_op"`
+}
+
+// procsSuffix matches the GOMAXPROCS suffix go test appends to benchmark
+// names, compiled on first use
+var procsSuffix = sync.OnceValue(func() *regexp.Regexp { return regexp.MustCompile(`-\d+$`) })
+
+// Parse reads the output of go test -bench -benchmem for one or more packages
+// and returns the results sorted by name. Names are prefixed with the package
+// path below module, so benchmarks of different packages do not clash. A
+// benchmark run more than once (-count) keeps its fastest run.
+func Parse(r io.Reader, module string) ([]Result, error) {
+	byName := map[string]Result{}
+	pkg := ""
+	sc := bufio.NewScanner(r)
+	for sc.Scan() {
+		line := sc.Text()
+		if p, ok := strings.CutPrefix(line, "pkg: "); ok {
+			pkg = strings.TrimPrefix(strings.TrimPrefix(strings.TrimSpace(p), module), "/")
+			continue
+		}
+		fields := strings.Fields(line)
+		if len(fields) < 4 || !strings.HasPrefix(fields[0], "Benchmark") {
+			continue
+		}
+		name := procsSuffix().ReplaceAllString(fields[0], "")
+		if pkg != "" {
+			name = pkg + "." + name
+		}
+		res, err := parseMetrics(name, fields[2:])
+		if err != nil {
+			return nil, err
+		}
+		if prev, ok := byName[name]; !ok || res.NsPerOp < prev.NsPerOp {
+			byName[name] = res
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read benchmark output: %w", err)
+	}
+
+	results := make([]Result, 0, len(byName))
+	for _, res := range byName {
+		results = append(results, res)
+	}
+	sort.Slice(results, func(i, j int) bool { return results[i].Name < results[j].Name })
+	return results, nil
+}
+
+// parseMetrics reads the value and unit pairs after the iteration count
+func parseMetrics(name string, fields []string) (Result, error) {
+	res := Result{Name: name}
+	for i := 0; i+1 < len(fields); i += 2 {
+		value, err := strconv.ParseFloat(fields[i], 64)
+		if err != nil {
+			return Result{}, fmt.Errorf("invalid benchmark output for %s: %q is not a number", name, fields[i])
+		}
+		switch fields[i+1] {
+		case "ns/op":
+			res.NsPerOp = value
+		case "B/op":
+			res.BytesPerOp = int64(value)
+		case "allocs/op":
+			res.AllocsPerOp = int64(value)
+		}
+	}
+	if res.NsPerOp <= 0 {
+		return Result{}, fmt.Errorf("invalid benchmark output for %s: no ns/op", name)
+	}
+	return res, nil
+}
+
+// Baseline holds the expected results by benchmark name
+type Baseline map[string]Result
+
+// baselineFile is the layout of the baseline file
+type baselineFile struct {
+	Benchmarks Baseline `yaml:"benchmarks"`
+}
+
+// LoadBaseline reads the baseline file at path. A missing file is an empty
+// baseline, so every benchmark is reported as new.
+func LoadBaseline(path string) (Baseline, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return Baseline{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read baseline file: %w", err)
+	}
+
+	var f baselineFile
+	if err := yaml.Unmarshal(data, &f); err != nil {
+		return nil, fmt.Errorf("invalid baseline file %s: %w", path, err)
+	}
+	if f.Benchmarks == nil {
+		return Baseline{}, nil
+	}
+	for name, res := range f.Benchmarks {
+		if res.NsPerOp <= 0 {
+			return nil, fmt.Errorf("invalid baseline file %s: %s needs a positive ns_per_op", path, name)
+		}
+		res.Name = name
+		f.Benchmarks[name] = res
+	}
+	return f.Benchmarks, nil
+}
+
+// WriteBaseline writes results to path as the new baseline
+func WriteBaseline(path string, results []Result) error {
+	f := baselineFile{Benchmarks: Baseline{}}
+	for _, res := range results {
+		f.Benchmarks[res.Name] = res
+	}
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(f); err != nil {
+		return fmt.Errorf("failed to encode baseline: %w", err)
+	}
+	if err := os.WriteFile(path, buf.Bytes(), 0o644); err != nil {
+		return fmt.Errorf("failed to write baseline file: %w", err)
+	}
+	return nil
+}
+
+// Comparison is a result next to its baseline
+type Comparison struct {
+	Result
+	// Baseline is nil for benchmarks without a baseline
+	Baseline *Result `json:"baseline,omitempty"`
+	// Regressed is true when allocations, or time if compared, grew by more
+	// than the tolerance
+	Regressed bool `json:"regressed"`
+}
+
+// Change returns the relative change of the time per operation, e.g. 0.25 for
+// 25% slower, or 0 without a baseline
+func (c Comparison) Change() float64 {
+	if c.Baseline == nil {
+		return 0
+	}
+	return c.NsPerOp/c.Baseline.NsPerOp - 1
+}
+
+// Compare compares results with base. A result regresses when its allocations
+// per operation, and with withTime also its time, exceed the baseline by more
+// than tolerance, e.g. 0.5 for 50%. Allocations do not depend on the machine,
+// time does, so only compare time on the machine that recorded the baseline.
+func Compare(results []Result, base Baseline, tolerance float64, withTime bool) []Comparison {
+	comparisons := make([]Comparison, 0, len(results))
+	for _, res := range results {
+		c := Comparison{Result: res}
+		if b, ok := base[res.Name]; ok {
+			c.Baseline = &b
+			c.Regressed = exceeds(float64(res.AllocsPerOp), float64(b.AllocsPerOp), tolerance) ||
+				withTime && exceeds(res.NsPerOp, b.NsPerOp, tolerance)
+		}
+		comparisons = append(comparisons, c)
+	}
+	return comparisons
+}
+
+// exceeds reports whether value is more than tolerance above base. Values
+// below one are compared as one, so a single new allocation is not a 100%
+// regression over none.
+func exceeds(value, base, tolerance float64) bool {
+	if base < 1 {
+		base = 1
+	}
+	return value > base*(1+tolerance)
+}
@@ -0,0 +1,111 @@
+// internal/benchcheck/benchcheck_test.go
+
+package benchcheck
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// benchOutput mimics go test -bench -benchmem -count 2 for two packages
+const benchOutput = `goos: linux
+goarch: amd64
+pkg: example.com/app/internal/output
+cpu: Some CPU
+BenchmarkRender/json-8         	  200000	      5200 ns/op	    1024 B/op	      12 allocs/op
+BenchmarkRender/json-8         	  200000	      5000 ns/op	    1024 B/op	      12 allocs/op
+PASS
+ok  	example.com/app/internal/output	2.1s
+goos: linux
+goarch: amd64
+pkg: example.com/app/cmd
+BenchmarkInitConfig-8   	   10000	    120000 ns/op
+PASS
+ok  	example.com/app/cmd	1.3s
+`
+
+func TestParse(t *testing.T) {
+	results, err := Parse(strings.NewReader(benchOutput), "example.com/app")
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	want := []Result{
+		{Name: "cmd.BenchmarkInitConfig", NsPerOp: 120000},
+		{Name: "internal/output.BenchmarkRender/json", NsPerOp: 5000, BytesPerOp: 1024, AllocsPerOp: 12},
+	}
+	if !reflect.DeepEqual(results, want) {
+		t.Errorf("Parse() = %+v, want %+v", results, want)
+	}
+
+	if _, err := Parse(strings.NewReader("BenchmarkX-8  10  fast ns/op\n"), ""); err == nil {
+		t.Error("Parse() expected an error for a value that is not a number")
+	}
+}
+
+func TestBaseline(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "baseline.yaml")
+	if base, err := LoadBaseline(path); err != nil || len(base) != 0 {
+		t.Fatalf("LoadBaseline(missing) = %v, %v, want an empty baseline", base, err)
+	}
+
+	results := []Result{{Name: "pkg.BenchmarkA", NsPerOp: 100, BytesPerOp: 8, AllocsPerOp: 1}}
+	if err := WriteBaseline(path, results); err != nil {
+		t.Fatalf("WriteBaseline() error = %v", err)
+	}
+	base, err := LoadBaseline(path)
+	if err != nil {
+		t.Fatalf("LoadBaseline() error = %v", err)
+	}
+	if got := base["pkg.BenchmarkA"]; got != results[0] {
+		t.Errorf("LoadBaseline() = %+v, want %+v", got, results[0])
+	}
+
+	if err := os.WriteFile(path, []byte("benchmarks:\n  pkg.BenchmarkA:\n    allocs_per_op: 1\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadBaseline(path); err == nil || !strings.Contains(err.Error(), "ns_per_op") {
+		t.Errorf("LoadBaseline() error = %v, want the missing ns_per_op", err)
+	}
+}
+
+func TestCompare(t *testing.T) {
+	base := Baseline{
+		"a": {Name: "a", NsPerOp: 100, AllocsPerOp: 10},
+		"b": {Name: "b", NsPerOp: 100, AllocsPerOp: 10},
+		"c": {Name: "c", NsPerOp: 100, AllocsPerOp: 0},
+		"d": {Name: "d", NsPerOp: 100, AllocsPerOp: 0},
+	}
+	results := []Result{
+		{Name: "a", NsPerOp: 140, AllocsPerOp: 10},
+		{Name: "b", NsPerOp: 160, AllocsPerOp: 10},
+		{Name: "c", NsPerOp: 50, AllocsPerOp: 1},
+		{Name: "d", NsPerOp: 100, AllocsPerOp: 2},
+		{Name: "new", NsPerOp: 1},
+	}
+	got := map[string]bool{}
+	for _, c := range Compare(results, base, 0.5, true) {
+		got[c.Name] = c.Regressed
+		if c.Name == "a" && (c.Change() < 0.39 || c.Change() > 0.41) {
+			t.Errorf("Change() = %v, want 0.4", c.Change())
+		}
+		if c.Name == "new" && (c.Baseline != nil || c.Change() != 0) {
+			t.Errorf("new benchmark = %+v, want no baseline", c)
+		}
+	}
+	want := map[string]bool{"a": false, "b": true, "c": false, "d": true, "new": false}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Compare() regressions = %v, want %v", got, want)
+	}
+
+	got = map[string]bool{}
+	for _, c := range Compare(results, base, 0.5, false) {
+		got[c.Name] = c.Regressed
+	}
+	want["b"] = false
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Compare() without time = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,110 @@
+// internal/config/expand.go
+
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/peiman/ckeletin-go/internal/xdg"
+	"github.com/spf13/viper"
+)
+
+// Resolver returns the value of a placeholder name, e.g. "env:HOME" for ${env:HOME}
+type Resolver func(name string) (string, error)
+
+// Expand replaces the ${...} placeholders in s using resolve. $${ is a literal ${.
+func Expand(s string, resolve Resolver) (string, error) {
+	var b strings.Builder
+	for {
+		i := strings.Index(s, "${")
+		if i < 0 {
+			b.WriteString(s)
+			return b.String(), nil
+		}
+		if i > 0 && s[i-1] == '$' {
+			b.WriteString(s[:i-1] + "${")
+			s = s[i+2:]
+			continue
+		}
+		end := strings.IndexByte(s[i:], '}')
+		if end < 0 {
+			return "", fmt.Errorf("unterminated placeholder in %q", s)
+		}
+		value, err := resolve(s[i+2 : i+end])
+		if err != nil {
+			return "", err
+		}
+		b.WriteString(s[:i] + value)
+		s = s[i+end+1:]
+	}
+}
+
+// ExpandAll expands the placeholders in all string values of v, including
+// string lists, and sets the results as overrides. The overrides hide later
+// changes of the underlying values, so expand a newly loaded instance when
+// the config file changes instead of reloading v.
+func ExpandAll(v *viper.Viper, resolve Resolver) error {
+	for _, key := range v.AllKeys() {
+		switch value := v.Get(key).(type) {
+		case string:
+			if !strings.Contains(value, "${") {
+				continue
+			}
+			expanded, err := Expand(value, resolve)
+			if err != nil {
+				return fmt.Errorf("%s: %w", key, err)
+			}
+			v.Set(key, expanded)
+		case []interface{}, []string:
+			list := v.GetStringSlice(key)
+			changed := false
+			for i, item := range list {
+				if !strings.Contains(item, "${") {
+					continue
+				}
+				expanded, err := Expand(item, resolve)
+				if err != nil {
+					return fmt.Errorf("%s: %w", key, err)
+				}
+				list[i], changed = expanded, true
+			}
+			if changed {
+				v.Set(key, list)
+			}
+		}
+	}
+	return nil
+}
+
+// Placeholders resolves the placeholders available in config values:
+//
+//	${env:VAR}       the environment variable VAR (empty if unset)
+//	${xdg:KIND}      the state, cache, data or runtime directory of the app in dirs
+//	${home}          the user's home directory
+//	${binary_name}   the name of the binary
+func Placeholders(dirs xdg.Dirs, binaryName string) Resolver {
+	return func(name string) (string, error) {
+		kind, arg, _ := strings.Cut(name, ":")
+		switch kind {
+		case "env":
+			return os.Getenv(arg), nil
+		case "xdg":
+			path, ok, err := xdgDir(dirs, arg, binaryName)
+			if !ok {
+				return "", fmt.Errorf("unknown directory ${%s}: must be one of xdg:state, xdg:cache, xdg:data, xdg:runtime", name)
+			}
+			if err != nil {
+				return "", err
+			}
+			return filepath.ToSlash(path), nil
+		case "home":
+			return os.UserHomeDir()
+		case "binary_name":
+			return binaryName, nil
+		}
+		return "", fmt.Errorf("unknown placeholder ${%s}", name)
+	}
+}
@@ -0,0 +1,111 @@
+// internal/config/expand_test.go
+
+package config
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/peiman/ckeletin-go/internal/xdg"
+	"github.com/spf13/viper"
+)
+
+func testResolver(name string) (string, error) {
+	switch name {
+	case "env:HOME":
+		return "/home/me", nil
+	case "binary_name":
+		return "myapp", nil
+	}
+	return "", errors.New("unknown placeholder ${" + name + "}")
+}
+
+func TestExpand(t *testing.T) {
+	tests := []struct {
+		in, want string
+		wantErr  string
+	}{
+		{in: "plain", want: "plain"},
+		{in: "${env:HOME}/.${binary_name}", want: "/home/me/.myapp"},
+		{in: "a${binary_name}b${binary_name}", want: "amyappbmyapp"},
+		{in: "cost: $5", want: "cost: $5"},
+		{in: "$${env:HOME}", want: "${env:HOME}"},
+		{in: "${env:HOME", wantErr: "unterminated placeholder"},
+		{in: "${foo}", wantErr: "unknown placeholder ${foo}"},
+	}
+	for _, tt := range tests {
+		got, err := Expand(tt.in, testResolver)
+		if tt.wantErr != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Expand(%q) error = %v, want %q", tt.in, err, tt.wantErr)
+			}
+			continue
+		}
+		if err != nil || got != tt.want {
+			t.Errorf("Expand(%q) = %q, %v, want %q", tt.in, got, err, tt.want)
+		}
+	}
+}
+
+func TestExpandAll(t *testing.T) {
+	v := viper.New()
+	v.SetDefault("app.log_file", "${env:HOME}/app.log")
+	v.Set("app.paths", []interface{}{"${binary_name}", "fixed"})
+	v.Set("app.count", 3)
+
+	if err := ExpandAll(v, testResolver); err != nil {
+		t.Fatalf("ExpandAll() error = %v", err)
+	}
+	if got := v.GetString("app.log_file"); got != "/home/me/app.log" {
+		t.Errorf("app.log_file = %q", got)
+	}
+	if got := v.GetStringSlice("app.paths"); len(got) != 2 || got[0] != "myapp" || got[1] != "fixed" {
+		t.Errorf("app.paths = %v", got)
+	}
+	if got := v.GetInt("app.count"); got != 3 {
+		t.Errorf("app.count = %d", got)
+	}
+
+	v.Set("app.bad", "${foo}")
+	if err := ExpandAll(v, testResolver); err == nil || !strings.HasPrefix(err.Error(), "app.bad: ") {
+		t.Errorf("ExpandAll() error = %v, want it to name the key", err)
+	}
+}
+
+func TestPlaceholders(t *testing.T) {
+	t.Setenv("EXPAND_TEST", "value")
+	t.Setenv("XDG_STATE_HOME", "/xdg/state")
+	resolve := Placeholders(xdg.Dirs{}, "myapp")
+
+	if got, _ := resolve("env:EXPAND_TEST"); got != "value" {
+		t.Errorf("env:EXPAND_TEST = %q", got)
+	}
+	if got, _ := resolve("env:EXPAND_TEST_UNSET"); got != "" {
+		t.Errorf("unset env = %q, want empty", got)
+	}
+	if got, _ := resolve("binary_name"); got != "myapp" {
+		t.Errorf("binary_name = %q", got)
+	}
+	if got, err := resolve("xdg:state"); err != nil || !strings.HasSuffix(got, "myapp") {
+		t.Errorf("xdg:state = %q, %v", got, err)
+	}
+	for _, name := range []string{"xdg:music", "foo"} {
+		if _, err := resolve(name); err == nil {
+			t.Errorf("resolve(%q) expected an error", name)
+		}
+	}
+}
+
+func FuzzExpand(f *testing.F) {
+	for _, s := range []string{"plain", "${env:HOME}/x", "$${literal}", "${", "a}${b}${c", "$$${x}"} {
+		f.Add(s)
+	}
+	resolve := func(name string) (string, error) { return "<" + name + ">", nil }
+	f.Fuzz(func(t *testing.T, s string) {
+		out, err := Expand(s, resolve)
+		if !strings.Contains(s, "${") && (err != nil || out != s) {
+			t.Fatalf("Expand(%q) = %q, %v, want it unchanged", s, out, err)
+		}
+	})
+}
@@ -0,0 +1,110 @@
+// internal/config/include.go
+
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/viper"
+)
+
+// IncludeKey lists further config files to merge into the file that contains it
+const IncludeKey = "include"
+
+// MergeIncludes merges the files listed under include in the config file read
+// by v over its settings, in order, so later files win. Included files can
+// include files themselves; relative paths are relative to the including file
+// and can contain placeholders resolved with resolve. Every included file must
+// pass CheckFile, and a file including itself, directly or not, is an error.
+func MergeIncludes(v *viper.Viper, resolve Resolver) error {
+	file := v.ConfigFileUsed()
+	if file == "" || !v.InConfig(IncludeKey) {
+		return nil
+	}
+	abs, err := filepath.Abs(file)
+	if err != nil {
+		return err
+	}
+	includes, err := includePaths(v, abs, resolve)
+	if err != nil {
+		return err
+	}
+	for _, path := range includes {
+		settings, err := loadInclude(path, []string{abs}, resolve)
+		if err != nil {
+			return err
+		}
+		if err := v.MergeConfigMap(settings); err != nil {
+			return fmt.Errorf("failed to merge %s: %w", path, err)
+		}
+	}
+	return nil
+}
+
+// loadInclude returns the settings of the file at path merged with its own
+// includes. chain holds the including files, to detect cycles.
+func loadInclude(path string, chain []string, resolve Resolver) (map[string]interface{}, error) {
+	for _, p := range chain {
+		if p == path {
+			return nil, fmt.Errorf("include cycle: %s", strings.Join(append(chain, path), " -> "))
+		}
+	}
+	if err := CheckFile(path); err != nil {
+		return nil, err
+	}
+	v := viper.New()
+	v.SetConfigFile(path)
+	if err := v.ReadInConfig(); err != nil {
+		return nil, fmt.Errorf("failed to read included config file %s: %w", path, err)
+	}
+	includes, err := includePaths(v, path, resolve)
+	if err != nil {
+		return nil, err
+	}
+	for _, include := range includes {
+		settings, err := loadInclude(include, append(chain[:len(chain):len(chain)], path), resolve)
+		if err != nil {
+			return nil, err
+		}
+		if err := v.MergeConfigMap(settings); err != nil {
+			return nil, fmt.Errorf("failed to merge %s: %w", include, err)
+		}
+	}
+	settings := v.AllSettings()
+	DeleteValue(settings, IncludeKey)
+	return settings, nil
+}
+
+// includePaths returns the absolute paths of the files included by the config
+// file at path, read into v
+func includePaths(v *viper.Viper, path string, resolve Resolver) ([]string, error) {
+	var paths []string
+	for _, include := range v.GetStringSlice(IncludeKey) {
+		expanded, err := Expand(include, resolve)
+		if err != nil {
+			return nil, fmt.Errorf("%s: include %q: %w", path, include, err)
+		}
+		resolved, err := ResolvePath(expanded, filepath.Dir(path))
+		if err != nil {
+			return nil, fmt.Errorf("%s: include %q: %w", path, include, err)
+		}
+		paths = append(paths, filepath.Clean(resolved))
+	}
+	return paths, nil
+}
+
+// CheckFile returns an error unless path is a regular file that only its owner
+// can change, so other users cannot inject settings through it
+func CheckFile(path string) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return fmt.Errorf("included config file: %w", err)
+	}
+	if !info.Mode().IsRegular() {
+		return fmt.Errorf("included config file %s is not a regular file", path)
+	}
+	return checkOwner(path, info)
+}
@@ -0,0 +1,155 @@
+// internal/config/include_test.go
+
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/peiman/ckeletin-go/internal/xdg"
+	"github.com/spf13/viper"
+)
+
+func writeConfig(t *testing.T, dir, name, content string) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func readConfig(t *testing.T, path string) *viper.Viper {
+	t.Helper()
+	v := viper.New()
+	v.SetConfigFile(path)
+	if err := v.ReadInConfig(); err != nil {
+		t.Fatal(err)
+	}
+	return v
+}
+
+func TestMergeIncludes(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("INCLUDE_TEST_DIR", dir)
+	main := writeConfig(t, dir, "main.yaml", `include: [shared.yaml, "${env:INCLUDE_TEST_DIR}/local/machine.yaml"]
+app:
+  log_level: info
+  ping:
+    output_message: main
+    output_color: red
+`)
+	writeConfig(t, dir, "shared.yaml", `include: [nested/more.yaml]
+app:
+  ping:
+    output_message: shared
+    count: 2
+`)
+	writeConfig(t, dir, "nested/more.yaml", `app:
+  ping:
+    count: 1
+    interval: 5s
+`)
+	writeConfig(t, dir, "local/machine.yaml", "app:\n  ping:\n    output_message: machine\n")
+
+	v := readConfig(t, main)
+	if err := MergeIncludes(v, Placeholders(xdg.Dirs{}, "myapp")); err != nil {
+		t.Fatalf("MergeIncludes() error = %v", err)
+	}
+	want := map[string]string{
+		"app.log_level":           "info",
+		"app.ping.output_color":   "red",
+		"app.ping.output_message": "machine",
+		"app.ping.count":          "1",
+		"app.ping.interval":       "5s",
+	}
+	for key, w := range want {
+		if got := v.GetString(key); got != w {
+			t.Errorf("%s = %q, want %q", key, got, w)
+		}
+	}
+}
+
+func TestMergeIncludes_Errors(t *testing.T) {
+	dir := t.TempDir()
+	tests := []struct {
+		name  string
+		files map[string]string
+		want  string
+	}{
+		{
+			name:  "cycle",
+			files: map[string]string{"main.yaml": "include: [a.yaml]\n", "a.yaml": "include: [b.yaml]\n", "b.yaml": "include: [main.yaml]\n"},
+			want:  "include cycle: ",
+		},
+		{
+			name:  "self",
+			files: map[string]string{"main.yaml": "include: [./main.yaml]\n"},
+			want:  "include cycle: ",
+		},
+		{
+			name:  "missing",
+			files: map[string]string{"main.yaml": "include: [missing.yaml]\n"},
+			want:  "no such file",
+		},
+		{
+			name:  "invalid",
+			files: map[string]string{"main.yaml": "include: [bad.yaml]\n", "bad.yaml": "app: [\n"},
+			want:  "failed to read included config file",
+		},
+		{
+			name:  "placeholder",
+			files: map[string]string{"main.yaml": "include: [\"${nope}/x.yaml\"]\n"},
+			want:  "unknown placeholder",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sub := filepath.Join(dir, tt.name)
+			for name, content := range tt.files {
+				writeConfig(t, sub, name, content)
+			}
+			err := MergeIncludes(readConfig(t, filepath.Join(sub, "main.yaml")), Placeholders(xdg.Dirs{}, "myapp"))
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("MergeIncludes() error = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestMergeIncludes_WithoutIncludes(t *testing.T) {
+	if err := MergeIncludes(viper.New(), Placeholders(xdg.Dirs{}, "myapp")); err != nil {
+		t.Errorf("MergeIncludes() without a config file error = %v", err)
+	}
+	v := readConfig(t, writeConfig(t, t.TempDir(), "main.yaml", "app:\n  log_level: debug\n"))
+	if err := MergeIncludes(v, Placeholders(xdg.Dirs{}, "myapp")); err != nil || v.GetString("app.log_level") != "debug" {
+		t.Errorf("MergeIncludes() without includes = %v", err)
+	}
+}
+
+func TestCheckFile(t *testing.T) {
+	dir := t.TempDir()
+	if err := CheckFile(dir); err == nil || !strings.Contains(err.Error(), "not a regular file") {
+		t.Errorf("CheckFile(dir) error = %v", err)
+	}
+	path := writeConfig(t, dir, "ok.yaml", "")
+	if err := CheckFile(path); err != nil {
+		t.Errorf("CheckFile() error = %v", err)
+	}
+
+	if runtime.GOOS == "windows" {
+		return
+	}
+	if err := os.Chmod(path, 0o666); err != nil {
+		t.Fatal(err)
+	}
+	if err := CheckFile(path); err == nil || !strings.Contains(err.Error(), "writable by other users") {
+		t.Errorf("CheckFile() of a world-writable file error = %v", err)
+	}
+}
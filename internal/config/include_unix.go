@@ -0,0 +1,25 @@
+// internal/config/include_unix.go
+
+//go:build !windows
+
+package config
+
+import (
+	"fmt"
+	"os"
+	"syscall"
+)
+
+// checkOwner requires the file to be owned by the current user or root and
+// not writable by group or others
+func checkOwner(path string, info os.FileInfo) error {
+	if info.Mode().Perm()&0o022 != 0 {
+		return fmt.Errorf("included config file %s is writable by other users, fix it with: chmod go-w %s", path, path)
+	}
+	if st, ok := info.Sys().(*syscall.Stat_t); ok {
+		if uid := os.Getuid(); int(st.Uid) != uid && st.Uid != 0 {
+			return fmt.Errorf("included config file %s is owned by another user (uid %d)", path, st.Uid)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,148 @@
+// internal/config/migrate.go
+
+package config
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cast"
+)
+
+// VersionKey records the version of the config file format
+const VersionKey = "app.config_version"
+
+// Migration upgrades the settings of a config file to Version from the version before it
+type Migration struct {
+	// Version is the config version after the migration, starting at 2
+	Version int
+	// Description says what changes, e.g. "rename app.log to app.logging"
+	Description string
+	// Apply changes the settings read from the config file in place
+	Apply func(settings map[string]interface{}) error
+}
+
+// Migrations is the list of migrations of an app, in any order
+type Migrations []Migration
+
+// Latest returns the config version the migrations lead to. Without
+// migrations it is 1, the version of config files without app.config_version.
+func (m Migrations) Latest() int {
+	latest := 1
+	for _, mig := range m {
+		if mig.Version > latest {
+			latest = mig.Version
+		}
+	}
+	return latest
+}
+
+// Pending returns the migrations that upgrade a config file at version from,
+// sorted by version
+func (m Migrations) Pending(from int) Migrations {
+	var pending Migrations
+	for _, mig := range m {
+		if mig.Version > from {
+			pending = append(pending, mig)
+		}
+	}
+	sort.Slice(pending, func(i, j int) bool { return pending[i].Version < pending[j].Version })
+	return pending
+}
+
+// Apply upgrades settings to the latest version and sets app.config_version.
+// It returns the migrations applied; settings are left partially migrated
+// when one fails.
+func (m Migrations) Apply(settings map[string]interface{}) (Migrations, error) {
+	from, err := SettingsVersion(settings)
+	if err != nil {
+		return nil, err
+	}
+	if latest := m.Latest(); from > latest {
+		return nil, fmt.Errorf("config version %d is newer than the latest supported version %d", from, latest)
+	}
+	pending := m.Pending(from)
+	for _, mig := range pending {
+		if err := mig.Apply(settings); err != nil {
+			return nil, fmt.Errorf("migration to version %d (%s) failed: %w", mig.Version, mig.Description, err)
+		}
+	}
+	SetValue(settings, VersionKey, m.Latest())
+	return pending, nil
+}
+
+// SettingsVersion returns the config version recorded in settings, 1 if there is none
+func SettingsVersion(settings map[string]interface{}) (int, error) {
+	value, _ := Value(settings, VersionKey)
+	return ParseVersion(value)
+}
+
+// ParseVersion returns the config version in value, 1 if value is nil
+func ParseVersion(value interface{}) (int, error) {
+	if value == nil {
+		return 1, nil
+	}
+	version, err := cast.ToIntE(value)
+	if err != nil || version < 1 {
+		return 0, fmt.Errorf("invalid %s %v: must be a positive number", VersionKey, value)
+	}
+	return version, nil
+}
+
+// Value returns the value of the dotted key in the nested settings
+func Value(settings map[string]interface{}, key string) (interface{}, bool) {
+	parts := strings.Split(key, ".")
+	m := settings
+	for _, part := range parts[:len(parts)-1] {
+		next, ok := m[part].(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		m = next
+	}
+	value, ok := m[parts[len(parts)-1]]
+	return value, ok
+}
+
+// SetValue sets the dotted key in the nested settings, creating parent maps as needed
+func SetValue(settings map[string]interface{}, key string, value interface{}) {
+	parts := strings.Split(key, ".")
+	m := settings
+	for _, part := range parts[:len(parts)-1] {
+		next, ok := m[part].(map[string]interface{})
+		if !ok {
+			next = map[string]interface{}{}
+			m[part] = next
+		}
+		m = next
+	}
+	m[parts[len(parts)-1]] = value
+}
+
+// DeleteValue removes the dotted key from the nested settings, along with
+// parent maps it leaves empty
+func DeleteValue(settings map[string]interface{}, key string) {
+	parent, last, found := strings.Cut(key, ".")
+	if !found {
+		delete(settings, key)
+		return
+	}
+	if m, ok := settings[parent].(map[string]interface{}); ok {
+		DeleteValue(m, last)
+		if len(m) == 0 {
+			delete(settings, parent)
+		}
+	}
+}
+
+// RenameKey moves the value of the dotted key from to the key to, if it is set.
+// It is the most common migration step.
+func RenameKey(settings map[string]interface{}, from, to string) {
+	value, ok := Value(settings, from)
+	if !ok {
+		return
+	}
+	DeleteValue(settings, from)
+	SetValue(settings, to, value)
+}
@@ -0,0 +1,93 @@
+// internal/config/migrate_test.go
+
+package config
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestMigrations(t *testing.T) {
+	var order []int
+	step := func(version int) Migration {
+		return Migration{Version: version, Description: "step", Apply: func(map[string]interface{}) error {
+			order = append(order, version)
+			return nil
+		}}
+	}
+	m := Migrations{step(3), step(2), step(4)}
+
+	if got := (Migrations{}).Latest(); got != 1 {
+		t.Errorf("Latest() without migrations = %d, want 1", got)
+	}
+	if got := m.Latest(); got != 4 {
+		t.Errorf("Latest() = %d, want 4", got)
+	}
+
+	settings := map[string]interface{}{"app": map[string]interface{}{"config_version": 2}}
+	applied, err := m.Apply(settings)
+	if err != nil {
+		t.Fatalf("Apply() error = %v", err)
+	}
+	if len(applied) != 2 || !reflect.DeepEqual(order, []int{3, 4}) {
+		t.Errorf("Apply() ran %v, want migrations 3 and 4 in order", order)
+	}
+	if v, _ := SettingsVersion(settings); v != 4 {
+		t.Errorf("version after Apply() = %d, want 4", v)
+	}
+
+	settings = map[string]interface{}{"app": map[string]interface{}{"config_version": 5}}
+	if _, err := m.Apply(settings); err == nil || !strings.Contains(err.Error(), "newer") {
+		t.Errorf("Apply() on a newer config error = %v", err)
+	}
+
+	failing := Migrations{{Version: 2, Description: "broken", Apply: func(map[string]interface{}) error { return errors.New("boom") }}}
+	if _, err := failing.Apply(map[string]interface{}{}); err == nil || !strings.Contains(err.Error(), "version 2 (broken) failed: boom") {
+		t.Errorf("Apply() failing migration error = %v", err)
+	}
+}
+
+func TestParseVersion(t *testing.T) {
+	for value, want := range map[interface{}]int{nil: 1, 1: 1, 3: 3, "2": 2} {
+		if got, err := ParseVersion(value); err != nil || got != want {
+			t.Errorf("ParseVersion(%v) = %d, %v, want %d", value, got, err, want)
+		}
+	}
+	for _, value := range []interface{}{0, -1, "new"} {
+		if _, err := ParseVersion(value); err == nil {
+			t.Errorf("ParseVersion(%v) expected an error", value)
+		}
+	}
+}
+
+func TestSettingsHelpers(t *testing.T) {
+	settings := map[string]interface{}{
+		"app": map[string]interface{}{
+			"old":  map[string]interface{}{"name": "x"},
+			"keep": true,
+		},
+	}
+
+	RenameKey(settings, "app.old.name", "app.new.name")
+	if v, ok := Value(settings, "app.new.name"); !ok || v != "x" {
+		t.Errorf("Value(app.new.name) = %v, %v", v, ok)
+	}
+	if _, ok := Value(settings, "app.old"); ok {
+		t.Error("Expected the emptied parent app.old to be removed")
+	}
+	RenameKey(settings, "app.missing", "app.other")
+	if _, ok := Value(settings, "app.other"); ok {
+		t.Error("RenameKey() of a missing key created the target")
+	}
+
+	SetValue(settings, "top", 1)
+	DeleteValue(settings, "top")
+	DeleteValue(settings, "app.keep")
+	DeleteValue(settings, "nothing.here")
+	want := map[string]interface{}{"app": map[string]interface{}{"new": map[string]interface{}{"name": "x"}}}
+	if !reflect.DeepEqual(settings, want) {
+		t.Errorf("settings = %v, want %v", settings, want)
+	}
+}
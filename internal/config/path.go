@@ -0,0 +1,112 @@
+// internal/config/path.go
+
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/peiman/ckeletin-go/internal/xdg"
+	"github.com/spf13/viper"
+)
+
+// PathBase is what a relative path in the value of an option is resolved against
+type PathBase string
+
+const (
+	// PathConfigDir resolves against the directory of the config file when the
+	// key is set there, and against the working directory otherwise
+	PathConfigDir PathBase = "config"
+	// PathWorkDir resolves against the working directory
+	PathWorkDir PathBase = "cwd"
+	// PathStateDir resolves against the app's XDG state directory
+	PathStateDir PathBase = "xdg:state"
+	// PathCacheDir resolves against the app's XDG cache directory
+	PathCacheDir PathBase = "xdg:cache"
+	// PathDataDir resolves against the app's XDG data directory
+	PathDataDir PathBase = "xdg:data"
+)
+
+// xdgDir returns the XDG directory of app by name in dirs, as used in
+// PathBase and ${xdg:...}
+func xdgDir(dirs xdg.Dirs, name, app string) (string, bool, error) {
+	var dir func(app string) (string, error)
+	switch name {
+	case "state":
+		dir = dirs.StateDir
+	case "cache":
+		dir = dirs.CacheDir
+	case "data":
+		dir = dirs.DataDir
+	case "runtime":
+		dir = dirs.RuntimeDir
+	default:
+		return "", false, nil
+	}
+	path, err := dir(app)
+	return path, true, err
+}
+
+// ResolvePath returns path made absolute: ~/ is the home directory, absolute
+// paths are kept and relative paths are joined to dir. Empty paths stay empty.
+func ResolvePath(path, dir string) (string, error) {
+	switch {
+	case path == "" || filepath.IsAbs(path):
+		return path, nil
+	case path == "~" || strings.HasPrefix(path, "~/") || strings.HasPrefix(path, `~\`):
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", err
+		}
+		return filepath.Join(home, path[1:]), nil
+	}
+	return filepath.Join(dir, path), nil
+}
+
+// ResolvePaths makes the values of all registered path options absolute
+// following their PathBase, with the XDG directories in dirs, and sets the
+// results as overrides. Like with
+// ExpandAll, the overrides hide later changes of the config file, so resolve
+// a newly loaded instance when the file changes.
+func ResolvePaths(v *viper.Viper, dirs xdg.Dirs, binaryName string) error {
+	for _, opt := range Options() {
+		if opt.Path == "" {
+			continue
+		}
+		path := v.GetString(opt.Key)
+		if path == "" || filepath.IsAbs(path) {
+			continue
+		}
+		dir, err := baseDir(v, opt, dirs, binaryName)
+		if err != nil {
+			return fmt.Errorf("%s: %w", opt.Key, err)
+		}
+		resolved, err := ResolvePath(path, dir)
+		if err != nil {
+			return fmt.Errorf("%s: %w", opt.Key, err)
+		}
+		v.Set(opt.Key, resolved)
+	}
+	return nil
+}
+
+// baseDir returns the directory relative paths of opt are resolved against
+func baseDir(v *viper.Viper, opt Option, dirs xdg.Dirs, binaryName string) (string, error) {
+	switch opt.Path {
+	case PathConfigDir:
+		if file := v.ConfigFileUsed(); file != "" && v.InConfig(opt.Key) {
+			return filepath.Dir(file), nil
+		}
+		return os.Getwd()
+	case PathWorkDir:
+		return os.Getwd()
+	}
+	if kind, ok := strings.CutPrefix(string(opt.Path), "xdg:"); ok {
+		if dir, ok, err := xdgDir(dirs, kind, binaryName); ok {
+			return dir, err
+		}
+	}
+	return "", fmt.Errorf("unknown path base %q", opt.Path)
+}
@@ -0,0 +1,87 @@
+// internal/config/path_test.go
+
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/peiman/ckeletin-go/internal/xdg"
+	"github.com/spf13/viper"
+)
+
+func TestResolvePath(t *testing.T) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		t.Skip("no home directory")
+	}
+	abs := filepath.Join(t.TempDir(), "abs.log")
+	tests := []struct{ in, want string }{
+		{"", ""},
+		{abs, abs},
+		{"logs/app.log", filepath.Join("/base", "logs", "app.log")},
+		{"./app.log", filepath.Join("/base", "app.log")},
+		{"~/app.log", filepath.Join(home, "app.log")},
+	}
+	for _, tt := range tests {
+		if got, err := ResolvePath(tt.in, "/base"); err != nil || got != tt.want {
+			t.Errorf("ResolvePath(%q) = %q, %v, want %q", tt.in, got, err, tt.want)
+		}
+	}
+}
+
+func TestResolvePaths(t *testing.T) {
+	dir := t.TempDir()
+	cfgPath := filepath.Join(dir, "config.yaml")
+	content := "test:\n  paths:\n    from_config: logs/app.log\n    absolute: /var/log/app.log\n"
+	if err := os.WriteFile(cfgPath, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("XDG_STATE_HOME", filepath.Join(dir, "state"))
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	Register(
+		Option{Key: "test.paths.from_config", Path: PathConfigDir},
+		Option{Key: "test.paths.absolute", Path: PathConfigDir},
+		Option{Key: "test.paths.not_in_config", Default: "data.db", Path: PathConfigDir},
+		Option{Key: "test.paths.cwd", Default: "ignore.yaml", Path: PathWorkDir},
+		Option{Key: "test.paths.state", Default: "audit.jsonl", Path: PathStateDir},
+		Option{Key: "test.paths.plain", Default: "not/a/path"},
+	)
+	v := viper.New()
+	for _, opt := range Options() {
+		v.SetDefault(opt.Key, opt.Default)
+	}
+	v.SetConfigFile(cfgPath)
+	if err := v.ReadInConfig(); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := ResolvePaths(v, xdg.Dirs{}, "myapp"); err != nil {
+		t.Fatalf("ResolvePaths() error = %v", err)
+	}
+	want := map[string]string{
+		"test.paths.from_config":   filepath.Join(dir, "logs", "app.log"),
+		"test.paths.absolute":      "/var/log/app.log",
+		"test.paths.not_in_config": filepath.Join(wd, "data.db"),
+		"test.paths.cwd":           filepath.Join(wd, "ignore.yaml"),
+		"test.paths.state":         filepath.Join(dir, "state", "myapp", "audit.jsonl"),
+		"test.paths.plain":         "not/a/path",
+	}
+	for key, w := range want {
+		if got := v.GetString(key); got != w {
+			t.Errorf("%s = %q, want %q", key, got, w)
+		}
+	}
+
+	Register(Option{Key: "test.paths.bad", Default: "x", Path: "elsewhere"})
+	v.SetDefault("test.paths.bad", "x")
+	if err := ResolvePaths(v, xdg.Dirs{}, "myapp"); err == nil {
+		t.Error("Expected an error for an unknown path base")
+	}
+	Register(Option{Key: "test.paths.bad", Default: "x"})
+}
@@ -0,0 +1,67 @@
+// internal/config/registry.go
+
+// Package config keeps a registry of configuration options contributed by
+// commands and extensions, so their defaults and documentation live in one place.
+package config
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/spf13/viper"
+)
+
+// Option describes a single configuration key
+type Option struct {
+	// Key is the viper key, e.g. "app.ping.output_message"
+	Key string
+	// Default is set by ApplyDefaults
+	Default interface{}
+	// Description is a one-line explanation shown in documentation
+	Description string
+	// Type is set for values that need parsing, see Validate
+	Type Type
+	// Path is set for options holding a file or directory path and says what
+	// a relative path is resolved against, see ResolvePaths
+	Path PathBase
+	// Flag names the command-line flag generated for the option by the
+	// command that owns the prefix of Key; empty for none
+	Flag string
+}
+
+var (
+	mu       sync.Mutex
+	registry = map[string]Option{}
+)
+
+// Register records opts. Their defaults are set in a viper instance with
+// ApplyDefaults. Registering a key again replaces the earlier option.
+func Register(opts ...Option) {
+	mu.Lock()
+	defer mu.Unlock()
+	for _, opt := range opts {
+		registry[opt.Key] = opt
+	}
+}
+
+// ApplyDefaults sets the defaults of all registered options in v, e.g. the
+// config of a new invocation.
+func ApplyDefaults(v *viper.Viper) {
+	mu.Lock()
+	defer mu.Unlock()
+	for _, opt := range registry {
+		v.SetDefault(opt.Key, opt.Default)
+	}
+}
+
+// Options returns all registered options sorted by key
+func Options() []Option {
+	mu.Lock()
+	defer mu.Unlock()
+	opts := make([]Option, 0, len(registry))
+	for _, opt := range registry {
+		opts = append(opts, opt)
+	}
+	sort.Slice(opts, func(i, j int) bool { return opts[i].Key < opts[j].Key })
+	return opts
+}
@@ -0,0 +1,42 @@
+// internal/config/registry_test.go
+
+package config
+
+import (
+	"testing"
+
+	"github.com/spf13/viper"
+)
+
+func TestRegister(t *testing.T) {
+	viper.Reset()
+	t.Cleanup(viper.Reset)
+	Register(
+		Option{Key: "test.registry.b", Default: 2, Description: "second"},
+		Option{Key: "test.registry.a", Default: "one", Description: "first"},
+	)
+	Register(Option{Key: "test.registry.b", Default: 3, Description: "replaced"})
+
+	if viper.IsSet("test.registry.a") {
+		t.Error("Register() set a default in the global viper")
+	}
+
+	var keys []string
+	for _, opt := range Options() {
+		if opt.Key == "test.registry.a" || opt.Key == "test.registry.b" {
+			keys = append(keys, opt.Key+"="+opt.Description)
+		}
+	}
+	if len(keys) != 2 || keys[0] != "test.registry.a=first" || keys[1] != "test.registry.b=replaced" {
+		t.Errorf("Options() = %v, want sorted a=first, b=replaced", keys)
+	}
+
+	v := viper.New()
+	ApplyDefaults(v)
+	if got := v.GetString("test.registry.a"); got != "one" {
+		t.Errorf("default for a = %q, want %q", got, "one")
+	}
+	if got := v.GetInt("test.registry.b"); got != 3 {
+		t.Errorf("default after ApplyDefaults() = %d, want 3", got)
+	}
+}
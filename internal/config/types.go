@@ -0,0 +1,172 @@
+// internal/config/types.go
+
+package config
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+// Type says how the value of an option is parsed and validated
+type Type string
+
+const (
+	// TypeDuration values are Go durations such as "30s", "5m" or "1h30m"
+	TypeDuration Type = "duration"
+	// TypeSize values are byte sizes such as "512", "10MB" (10^6) or "1GiB" (2^30)
+	TypeSize Type = "size"
+)
+
+// sizeUnits are the accepted size suffixes in lower case with their multipliers
+var sizeUnits = map[string]int64{
+	"": 1, "b": 1,
+	"kb": 1e3, "mb": 1e6, "gb": 1e9, "tb": 1e12,
+	"k": 1 << 10, "m": 1 << 20, "g": 1 << 30, "t": 1 << 40,
+	"kib": 1 << 10, "mib": 1 << 20, "gib": 1 << 30, "tib": 1 << 40,
+}
+
+// ParseSize parses a byte size: a number with an optional unit. KB, MB, GB
+// and TB are decimal, KiB, MiB, GiB and TiB (or K, M, G, T) are binary.
+// Units are case-insensitive and may be separated by a space.
+func ParseSize(s string) (int64, error) {
+	s = strings.TrimSpace(s)
+	i := strings.IndexFunc(s, func(r rune) bool { return (r < '0' || r > '9') && r != '.' })
+	if i < 0 {
+		i = len(s)
+	}
+	number, unit := s[:i], strings.ToLower(strings.TrimSpace(s[i:]))
+	mult, ok := sizeUnits[unit]
+	if number == "" || !ok {
+		return 0, fmt.Errorf("invalid size %q: use a number of bytes with an optional unit such as KB, MiB or GiB", s)
+	}
+	n, err := strconv.ParseFloat(number, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid size %q: %w", s, err)
+	}
+	size := n * float64(mult)
+	// float64(math.MaxInt64) rounds up to 2^63, which overflows int64
+	if size >= math.MaxInt64 {
+		return 0, fmt.Errorf("invalid size %q: too large", s)
+	}
+	return int64(size), nil
+}
+
+// FormatSize returns size with the largest binary unit that divides it,
+// in a form ParseSize accepts
+func FormatSize(size int64) string {
+	for _, u := range []struct {
+		name string
+		mult int64
+	}{{"TiB", 1 << 40}, {"GiB", 1 << 30}, {"MiB", 1 << 20}, {"KiB", 1 << 10}} {
+		if size != 0 && size%u.mult == 0 {
+			return strconv.FormatInt(size/u.mult, 10) + u.name
+		}
+	}
+	return strconv.FormatInt(size, 10)
+}
+
+// GetSize returns the value of key in v as a number of bytes, or 0 when it is
+// not a valid size. Validate reports invalid values.
+func GetSize(v *viper.Viper, key string) int64 {
+	size, err := toSize(v.Get(key))
+	if err != nil {
+		return 0
+	}
+	return size
+}
+
+// toSize converts a size from a config file, environment variable or flag
+func toSize(value interface{}) (int64, error) {
+	switch value := value.(type) {
+	case nil:
+		return 0, nil
+	case string:
+		return ParseSize(value)
+	case int:
+		return int64(value), nil
+	case int64:
+		return value, nil
+	case uint64:
+		if value > math.MaxInt64 {
+			return 0, fmt.Errorf("invalid size %d: too large", value)
+		}
+		return int64(value), nil
+	case float64:
+		if value != math.Trunc(value) {
+			return 0, fmt.Errorf("invalid size %v: must be a whole number of bytes", value)
+		}
+		return int64(value), nil
+	}
+	return 0, fmt.Errorf("invalid size %v", value)
+}
+
+// toDuration converts a duration from a config file, environment variable or
+// flag. Unlike viper.GetDuration, a number without a unit is an error.
+func toDuration(value interface{}) (time.Duration, error) {
+	switch value := value.(type) {
+	case nil:
+		return 0, nil
+	case time.Duration:
+		return value, nil
+	case string:
+		d, err := time.ParseDuration(strings.TrimSpace(value))
+		if err != nil {
+			return 0, fmt.Errorf("invalid duration %q: use a number with a unit such as 30s, 5m or 1h", value)
+		}
+		return d, nil
+	case int:
+		if value == 0 {
+			return 0, nil
+		}
+	}
+	return 0, fmt.Errorf("invalid duration %v: use a number with a unit such as 30s, 5m or 1h", value)
+}
+
+// Validate checks that the values in v of all registered typed options parse
+// and returns an error naming each invalid key
+func Validate(v *viper.Viper) error {
+	var errs []error
+	for _, opt := range Options() {
+		var err error
+		switch opt.Type {
+		case TypeDuration:
+			_, err = toDuration(v.Get(opt.Key))
+		case TypeSize:
+			var size int64
+			if size, err = toSize(v.Get(opt.Key)); err == nil && size < 0 {
+				err = fmt.Errorf("invalid size %d: must not be negative", size)
+			}
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", opt.Key, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// SizeValue is a pflag.Value for size flags, e.g.
+//
+//	cmd.Flags().Var(new(config.SizeValue), "max-size", "Maximum size (e.g. 10MB, 1GiB)")
+type SizeValue int64
+
+// String returns the size in the form FormatSize uses
+func (s *SizeValue) String() string { return FormatSize(int64(*s)) }
+
+// Set parses a size with ParseSize
+func (s *SizeValue) Set(value string) error {
+	size, err := ParseSize(value)
+	if err != nil {
+		return err
+	}
+	*s = SizeValue(size)
+	return nil
+}
+
+// Type is shown in help output
+func (s *SizeValue) Type() string { return string(TypeSize) }
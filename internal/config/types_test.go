@@ -0,0 +1,121 @@
+// internal/config/types_test.go
+
+package config
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+func TestParseSize(t *testing.T) {
+	tests := []struct {
+		in   string
+		want int64
+	}{
+		{"0", 0},
+		{"512", 512},
+		{"512B", 512},
+		{"10KB", 10_000},
+		{"10MB", 10_000_000},
+		{"1GiB", 1 << 30},
+		{"1.5 KiB", 1536},
+		{"2m", 2 << 20},
+		{" 3 tib ", 3 << 40},
+	}
+	for _, tt := range tests {
+		if got, err := ParseSize(tt.in); err != nil || got != tt.want {
+			t.Errorf("ParseSize(%q) = %d, %v, want %d", tt.in, got, err, tt.want)
+		}
+	}
+	for _, in := range []string{"", "MB", "10XB", "-5", "1.2.3MB", "99999999TiB", "9223372036854775807"} {
+		if _, err := ParseSize(in); err == nil {
+			t.Errorf("ParseSize(%q) expected an error", in)
+		}
+	}
+}
+
+func TestFormatSize(t *testing.T) {
+	for size, want := range map[int64]string{0: "0", 1000: "1000", 1024: "1KiB", 10 << 20: "10MiB", 3 << 30: "3GiB", 1<<20 + 1: "1048577"} {
+		if got := FormatSize(size); got != want {
+			t.Errorf("FormatSize(%d) = %q, want %q", size, got, want)
+		}
+		if back, err := ParseSize(FormatSize(size)); err != nil || back != size {
+			t.Errorf("ParseSize(FormatSize(%d)) = %d, %v", size, back, err)
+		}
+	}
+}
+
+func TestSizeValue(t *testing.T) {
+	var v SizeValue
+	if err := v.Set("10MiB"); err != nil || v != 10<<20 {
+		t.Errorf("Set(10MiB) = %d, %v", v, err)
+	}
+	if v.String() != "10MiB" || v.Type() != "size" {
+		t.Errorf("String() = %q, Type() = %q", v.String(), v.Type())
+	}
+	if err := v.Set("lots"); err == nil {
+		t.Error("Set(lots) expected an error")
+	}
+}
+
+func TestValidate(t *testing.T) {
+	Register(
+		Option{Key: "test.types.timeout", Default: 30 * time.Second, Type: TypeDuration},
+		Option{Key: "test.types.max_size", Default: 0, Type: TypeSize},
+		Option{Key: "test.types.name", Default: "x"},
+	)
+	t.Cleanup(func() {
+		Register(Option{Key: "test.types.timeout"}, Option{Key: "test.types.max_size"})
+	})
+
+	v := viper.New()
+	v.Set("test.types.timeout", "5m")
+	v.Set("test.types.max_size", "1GiB")
+	v.Set("test.types.name", "30")
+	if err := Validate(v); err != nil {
+		t.Fatalf("Validate() error = %v", err)
+	}
+	if got := GetSize(v, "test.types.max_size"); got != 1<<30 {
+		t.Errorf("GetSize() = %d", got)
+	}
+	v.Set("test.types.max_size", 2048)
+	if got := GetSize(v, "test.types.max_size"); got != 2048 {
+		t.Errorf("GetSize(int) = %d", got)
+	}
+
+	v.Set("test.types.timeout", "30")
+	v.Set("test.types.max_size", "big")
+	err := Validate(v)
+	if err == nil {
+		t.Fatal("Validate() expected an error")
+	}
+	for _, want := range []string{`test.types.timeout: invalid duration "30"`, `test.types.max_size: invalid size "big"`} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("Validate() error = %v, want it to contain %q", err, want)
+		}
+	}
+	if got := GetSize(v, "test.types.max_size"); got != 0 {
+		t.Errorf("GetSize(invalid) = %d, want 0", got)
+	}
+}
+
+func FuzzParseSize(f *testing.F) {
+	for _, s := range []string{"0", "512", "10MB", "1.5GiB", "2 kib", "1e3", ".", "9223372036854775807", "8EiB", "-1"} {
+		f.Add(s)
+	}
+	f.Fuzz(func(t *testing.T, s string) {
+		size, err := ParseSize(s)
+		if err != nil {
+			return
+		}
+		if size < 0 {
+			t.Fatalf("ParseSize(%q) = %d, want a non-negative size", s, size)
+		}
+		if again, err := ParseSize(FormatSize(size)); err != nil || again != size {
+			t.Fatalf("ParseSize(FormatSize(%d)) = %d, %v", size, again, err)
+		}
+	})
+}
@@ -0,0 +1,79 @@
+// internal/deps/deps.go
+
+// Package deps finds dependencies with newer versions from the output of
+// `go list -m -u -json all`.
+package deps
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/peiman/ckeletin-go/internal/update"
+)
+
+// module is one object of the go list -m -json stream; only the parts used here
+type module struct {
+	Path     string
+	Version  string
+	Main     bool
+	Indirect bool
+	Update   *struct{ Version string }
+	Replace  *struct{ Path string }
+}
+
+// Update is a dependency with a newer version available
+type Update struct {
+	Path     string `json:"path"`
+	Current  string `json:"current"`
+	Latest   string `json:"latest"`
+	Kind     string `json:"kind"`
+	Indirect bool   `json:"indirect"`
+}
+
+// Outdated reads `go list -m -u -json all` output and returns the modules that
+// have an update, sorted by path. Indirect dependencies are only included when
+// indirect is true; replaced modules are skipped since their version is pinned
+// by the replace directive.
+func Outdated(r io.Reader, indirect bool) ([]Update, error) {
+	var updates []Update
+
+	dec := json.NewDecoder(r)
+	for {
+		var m module
+		if err := dec.Decode(&m); errors.Is(err, io.EOF) {
+			break
+		} else if err != nil {
+			return nil, fmt.Errorf("invalid go list output: %w", err)
+		}
+
+		if m.Main || m.Update == nil || m.Replace != nil || (m.Indirect && !indirect) {
+			continue
+		}
+		kind, err := update.UpgradeKind(m.Version, m.Update.Version)
+		if err != nil {
+			return nil, fmt.Errorf("module %s: %w", m.Path, err)
+		}
+		updates = append(updates, Update{
+			Path:     m.Path,
+			Current:  m.Version,
+			Latest:   m.Update.Version,
+			Kind:     kind,
+			Indirect: m.Indirect,
+		})
+	}
+
+	sort.Slice(updates, func(i, j int) bool { return updates[i].Path < updates[j].Path })
+	return updates, nil
+}
+
+// CountByKind returns how many updates there are of each kind
+func CountByKind(updates []Update) map[string]int {
+	counts := map[string]int{}
+	for _, u := range updates {
+		counts[u.Kind]++
+	}
+	return counts
+}
@@ -0,0 +1,80 @@
+// internal/deps/deps_test.go
+
+package deps
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/peiman/ckeletin-go/internal/update"
+)
+
+const goListOutput = `{
+	"Path": "example.com/app",
+	"Main": true
+}
+{
+	"Path": "github.com/spf13/cobra",
+	"Version": "v1.8.1",
+	"Update": {"Path": "github.com/spf13/cobra", "Version": "v1.9.0"}
+}
+{
+	"Path": "github.com/rs/zerolog",
+	"Version": "v1.33.0"
+}
+{
+	"Path": "github.com/muesli/termenv",
+	"Version": "v0.15.2",
+	"Update": {"Path": "github.com/muesli/termenv", "Version": "v1.0.0"},
+	"Indirect": true
+}
+{
+	"Path": "github.com/old/lib",
+	"Version": "v1.0.0",
+	"Update": {"Path": "github.com/old/lib", "Version": "v1.0.1"},
+	"Replace": {"Path": "../lib"}
+}
+{
+	"Path": "github.com/fsnotify/fsnotify",
+	"Version": "v1.7.0",
+	"Update": {"Path": "github.com/fsnotify/fsnotify", "Version": "v1.7.1"}
+}
+`
+
+func TestOutdated(t *testing.T) {
+	got, err := Outdated(strings.NewReader(goListOutput), false)
+	if err != nil {
+		t.Fatalf("Outdated() error = %v", err)
+	}
+	want := []Update{
+		{Path: "github.com/fsnotify/fsnotify", Current: "v1.7.0", Latest: "v1.7.1", Kind: update.Patch},
+		{Path: "github.com/spf13/cobra", Current: "v1.8.1", Latest: "v1.9.0", Kind: update.Minor},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Outdated() = %+v\nwant %+v", got, want)
+	}
+
+	got, err = Outdated(strings.NewReader(goListOutput), true)
+	if err != nil {
+		t.Fatalf("Outdated() error = %v", err)
+	}
+	if len(got) != 3 || got[1].Path != "github.com/muesli/termenv" || got[1].Kind != update.Major || !got[1].Indirect {
+		t.Errorf("Outdated(indirect) = %+v", got)
+	}
+
+	counts := CountByKind(got)
+	if counts[update.Major] != 1 || counts[update.Minor] != 1 || counts[update.Patch] != 1 {
+		t.Errorf("CountByKind() = %v", counts)
+	}
+}
+
+func TestOutdated_Errors(t *testing.T) {
+	if _, err := Outdated(strings.NewReader("{"), false); err == nil {
+		t.Error("Expected an error for invalid JSON")
+	}
+	bad := `{"Path": "x", "Version": "master", "Update": {"Version": "v1.0.0"}}`
+	if _, err := Outdated(strings.NewReader(bad), false); err == nil || !strings.Contains(err.Error(), "module x") {
+		t.Errorf("Expected an error naming the module, got %v", err)
+	}
+}
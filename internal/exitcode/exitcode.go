@@ -0,0 +1,134 @@
+// internal/exitcode/exitcode.go
+
+// Package exitcode maps command errors to process exit codes, so scripts can tell
+// a usage mistake from a broken config file or a failed verification.
+//
+// Commands return one of the error types below (or any error, for a generic
+// failure) and Code picks the exit code.
+package exitcode
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Exit codes
+const (
+	OK          = 0
+	Failure     = 1
+	Usage       = 2
+	Config      = 3
+	CheckFailed = 4
+	Timeout     = 124
+	Canceled    = 130
+)
+
+// descriptions documents each code in help output, in order
+var descriptions = []struct {
+	code  int
+	class string
+	text  string
+}{
+	{OK, "ok", "success"},
+	{Failure, "failure", "the command failed"},
+	{Usage, "usage", "invalid usage: unknown command or flag, wrong arguments"},
+	{Config, "config", "the configuration could not be loaded"},
+	{CheckFailed, "check failed", "a verification failed, e.g. a checksum mismatch"},
+	{Timeout, "timeout", "the deadline set with --timeout was exceeded"},
+	{Canceled, "canceled", "interrupted by Ctrl-C or SIGTERM"},
+}
+
+// UsageError reports that the command was invoked incorrectly
+type UsageError struct{ Err error }
+
+func (e *UsageError) Error() string { return e.Err.Error() }
+func (e *UsageError) Unwrap() error { return e.Err }
+
+// ConfigError reports that the configuration could not be loaded or is invalid
+type ConfigError struct{ Err error }
+
+func (e *ConfigError) Error() string { return e.Err.Error() }
+func (e *ConfigError) Unwrap() error { return e.Err }
+
+// CheckFailure reports that the command ran but a verification did not pass
+type CheckFailure struct{ Err error }
+
+func (e *CheckFailure) Error() string { return e.Err.Error() }
+func (e *CheckFailure) Unwrap() error { return e.Err }
+
+// ExitError carries an explicit exit code, e.g. the one of a plugin process
+type ExitError struct {
+	Code int
+	Err  error
+}
+
+func (e *ExitError) Error() string { return e.Err.Error() }
+func (e *ExitError) Unwrap() error { return e.Err }
+
+// TimeoutError reports that the command was stopped by its deadline
+type TimeoutError struct {
+	Timeout time.Duration
+	Err     error
+}
+
+func (e *TimeoutError) Error() string {
+	if e.Err == nil || errors.Is(e.Err, context.DeadlineExceeded) {
+		return fmt.Sprintf("deadline exceeded after %s", e.Timeout)
+	}
+	return fmt.Sprintf("deadline exceeded after %s: %v", e.Timeout, e.Err)
+}
+
+func (e *TimeoutError) Unwrap() error { return e.Err }
+
+// Code returns the exit code for err
+func Code(err error) int {
+	var (
+		timeoutErr *TimeoutError
+		exitErr    *ExitError
+		usageErr   *UsageError
+		configErr  *ConfigError
+		checkErr   *CheckFailure
+	)
+	switch {
+	case err == nil:
+		return OK
+	// A process killed at the deadline is a timeout, not its own failure
+	case errors.As(err, &timeoutErr):
+		return Timeout
+	case errors.As(err, &exitErr):
+		return exitErr.Code
+	case errors.Is(err, context.Canceled):
+		return Canceled
+	case errors.As(err, &usageErr):
+		return Usage
+	case errors.As(err, &configErr):
+		return Config
+	case errors.As(err, &checkErr):
+		return CheckFailed
+	}
+	return Failure
+}
+
+// Help describes the exit codes for the help output
+func Help() string {
+	var b strings.Builder
+	b.WriteString("Exit codes:\n")
+	for _, d := range descriptions {
+		fmt.Fprintf(&b, "  %-3d  %s\n", d.code, d.text)
+	}
+	return b.String()
+}
+
+// Class returns a short name for the kind of result code stands for, e.g.
+// "usage"; codes passed on from other processes are "external"
+func Class(code int) string {
+	for _, d := range descriptions {
+		if d.code == code {
+			return d.class
+		}
+	}
+	return "external"
+}
@@ -0,0 +1,75 @@
+// internal/exitcode/exitcode_test.go
+
+package exitcode
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCode(t *testing.T) {
+	base := errors.New("boom")
+	tests := []struct {
+		name string
+		err  error
+		want int
+	}{
+		{"Nil", nil, OK},
+		{"Plain error", base, Failure},
+		{"Usage", &UsageError{base}, Usage},
+		{"Wrapped config", fmt.Errorf("loading: %w", &ConfigError{base}), Config},
+		{"Check failure", &CheckFailure{base}, CheckFailed},
+		{"Canceled", fmt.Errorf("ping: %w", context.Canceled), Canceled},
+		{"Explicit code", &ExitError{Code: 42, Err: base}, 42},
+		{"Timeout", fmt.Errorf("run: %w", &TimeoutError{Timeout: time.Second, Err: &ExitError{Code: 1, Err: base}}), Timeout},
+		{"Request deadline without --timeout", fmt.Errorf("get: %w", context.DeadlineExceeded), Failure},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Code(tt.err); got != tt.want {
+				t.Errorf("Code() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTypedErrorsKeepMessage(t *testing.T) {
+	base := errors.New("bad flag")
+	err := &UsageError{base}
+	if err.Error() != "bad flag" || !errors.Is(err, base) {
+		t.Errorf("UsageError does not wrap %v: %v", base, err)
+	}
+}
+
+func TestHelp(t *testing.T) {
+	help := Help()
+	for _, want := range []string{"Exit codes:", "  2    invalid usage", "  130  interrupted"} {
+		if !strings.Contains(help, want) {
+			t.Errorf("Help() missing %q:\n%s", want, help)
+		}
+	}
+}
+
+func TestClass(t *testing.T) {
+	for code, want := range map[int]string{OK: "ok", Usage: "usage", CheckFailed: "check failed", Canceled: "canceled", 42: "external"} {
+		if got := Class(code); got != want {
+			t.Errorf("Class(%d) = %q, want %q", code, got, want)
+		}
+	}
+}
+
+func TestTimeoutError(t *testing.T) {
+	err := &TimeoutError{Timeout: 2 * time.Second, Err: fmt.Errorf("fetch: %w", context.DeadlineExceeded)}
+	if err.Error() != "deadline exceeded after 2s" {
+		t.Errorf("Error() = %q", err.Error())
+	}
+	err.Err = errors.New("task build exited with code 1")
+	if err.Error() != "deadline exceeded after 2s: task build exited with code 1" {
+		t.Errorf("Error() = %q", err.Error())
+	}
+}
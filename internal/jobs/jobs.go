@@ -0,0 +1,263 @@
+// internal/jobs/jobs.go
+
+// Package jobs runs commands of the CLI detached in the background and keeps
+// their state and output in a directory, one subdirectory per job:
+//
+//	<dir>/<id>/job.json     what was started, by the starting process
+//	<dir>/<id>/result.json  the exit code, by the job itself when it finishes
+//	<dir>/<id>/output.log   combined stdout and stderr
+//
+// Each file has a single writer, so the two processes never race.
+package jobs
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// EnvID is set to the job ID in the environment of a background job
+const EnvID = "APP_JOB_ID"
+
+// canceledCode is the exit code of a job stopped by Cancel, as for Ctrl-C
+const canceledCode = 130
+
+// Status of a job
+type Status string
+
+const (
+	Running   Status = "running"
+	Succeeded Status = "succeeded"
+	Failed    Status = "failed"
+	Canceled  Status = "canceled"
+	// Lost jobs exited without recording a result, e.g. killed or crashed
+	Lost Status = "lost"
+)
+
+// Job is a background command
+type Job struct {
+	ID   string   `json:"id"`
+	Args []string `json:"args"`
+	Dir  string   `json:"dir"`
+	PID  int      `json:"pid"`
+	// ProcessStart is when the process PID started, in a unit of the OS; 0 if
+	// unknown. A different start time means the PID was reused by another process.
+	ProcessStart int64      `json:"process_start,omitempty"`
+	Started      time.Time  `json:"started"`
+	Status       Status     `json:"status"`
+	Finished     *time.Time `json:"finished,omitempty"`
+	ExitCode     *int       `json:"exit_code,omitempty"`
+}
+
+type result struct {
+	ExitCode int       `json:"exit_code"`
+	Finished time.Time `json:"finished"`
+}
+
+// Manager starts and tracks the jobs stored in Dir
+type Manager struct {
+	Dir string
+}
+
+// Start runs executable with args detached from the current process and the
+// terminal, in the current working directory. The job must call Finish when done.
+func (m *Manager) Start(executable string, args []string) (*Job, error) {
+	id, err := newID()
+	if err != nil {
+		return nil, err
+	}
+	dir := filepath.Join(m.Dir, id)
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, fmt.Errorf("failed to create job directory: %w", err)
+	}
+	logFile, err := os.OpenFile(filepath.Join(dir, "output.log"), os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create job log: %w", err)
+	}
+	defer logFile.Close()
+	wd, err := os.Getwd()
+	if err != nil {
+		return nil, fmt.Errorf("failed to determine working directory: %w", err)
+	}
+
+	c := exec.Command(executable, args...)
+	c.Dir = wd
+	c.Stdout, c.Stderr = logFile, logFile
+	// Background jobs have no one to answer prompts
+	c.Env = append(os.Environ(), EnvID+"="+id, "APP_NO_INPUT=true")
+	c.SysProcAttr = detached()
+	if err := c.Start(); err != nil {
+		return nil, fmt.Errorf("failed to start job: %w", err)
+	}
+
+	pid := c.Process.Pid
+	job := &Job{ID: id, Args: args, Dir: wd, PID: pid, ProcessStart: processStart(pid), Started: time.Now().UTC(), Status: Running}
+	if err := writeJSON(filepath.Join(dir, "job.json"), job); err != nil {
+		return nil, err
+	}
+	return job, c.Process.Release()
+}
+
+// Finish records the exit code of the job id; called by the job itself
+func (m *Manager) Finish(id string, exitCode int) error {
+	if !ValidID(id) {
+		return invalidID(id)
+	}
+	return writeJSON(filepath.Join(m.Dir, id, "result.json"), result{ExitCode: exitCode, Finished: time.Now().UTC()})
+}
+
+// Get returns the job id with its current status
+func (m *Manager) Get(id string) (*Job, error) {
+	if !ValidID(id) {
+		return nil, invalidID(id)
+	}
+	var job Job
+	if err := readJSON(filepath.Join(m.Dir, id, "job.json"), &job); err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, fmt.Errorf("job %s not found", id)
+		}
+		return nil, err
+	}
+
+	var res result
+	err := readJSON(filepath.Join(m.Dir, id, "result.json"), &res)
+	switch {
+	case err == nil:
+		job.Finished, job.ExitCode = &res.Finished, &res.ExitCode
+		switch res.ExitCode {
+		case 0:
+			job.Status = Succeeded
+		case canceledCode:
+			job.Status = Canceled
+		default:
+			job.Status = Failed
+		}
+	case !errors.Is(err, os.ErrNotExist):
+		return nil, err
+	case !alive(job.PID) || !job.sameProcess():
+		job.Status = Lost
+	}
+	return &job, nil
+}
+
+// List returns all jobs, most recently started first
+func (m *Manager) List() ([]*Job, error) {
+	entries, err := os.ReadDir(m.Dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read jobs: %w", err)
+	}
+	var jobs []*Job
+	for _, e := range entries {
+		if !e.IsDir() || !ValidID(e.Name()) {
+			continue
+		}
+		job, err := m.Get(e.Name())
+		if err != nil {
+			// A job directory whose job.json is not written yet
+			continue
+		}
+		jobs = append(jobs, job)
+	}
+	sort.Slice(jobs, func(i, j int) bool { return jobs[i].Started.After(jobs[j].Started) })
+	return jobs, nil
+}
+
+// LogPath returns the output log of the job id
+func (m *Manager) LogPath(id string) string {
+	return filepath.Join(m.Dir, id, "output.log")
+}
+
+// Cancel asks the running job id to stop. Jobs whose PID now belongs to
+// another process are reported as lost rather than signaled. On Unix it gets SIGTERM and can finish
+// up like after Ctrl-C; on Windows it is killed and recorded as canceled here.
+func (m *Manager) Cancel(id string) error {
+	job, err := m.Get(id)
+	if err != nil {
+		return err
+	}
+	if job.Status != Running {
+		return fmt.Errorf("job %s is not running (%s)", id, job.Status)
+	}
+	p, err := os.FindProcess(job.PID)
+	if err != nil {
+		return fmt.Errorf("failed to find job process: %w", err)
+	}
+	recorded, err := terminate(p)
+	if err != nil {
+		return fmt.Errorf("failed to cancel job %s: %w", id, err)
+	}
+	if !recorded {
+		return m.Finish(id, canceledCode)
+	}
+	return nil
+}
+
+// sameProcess reports whether the process PID is still the one started for the
+// job, as far as the start time tells
+func (j *Job) sameProcess() bool {
+	if j.ProcessStart == 0 {
+		return true
+	}
+	start := processStart(j.PID)
+	return start == 0 || start == j.ProcessStart
+}
+
+// ValidID reports whether id has the form of a job ID: 8 lowercase hex digits
+func ValidID(id string) bool {
+	if len(id) != 8 {
+		return false
+	}
+	for _, c := range id {
+		if (c < '0' || c > '9') && (c < 'a' || c > 'f') {
+			return false
+		}
+	}
+	return true
+}
+
+func invalidID(id string) error {
+	return fmt.Errorf("invalid job ID %q: expected 8 hex digits", id)
+}
+
+func newID() (string, error) {
+	b := make([]byte, 4)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate job ID: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// writeJSON writes v to path through a temporary file, so readers never see
+// a partial file
+func writeJSON(path string, v interface{}) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return fmt.Errorf("failed to write %s: %w", filepath.Base(path), err)
+	}
+	return os.Rename(tmp, path)
+}
+
+func readJSON(path string, v interface{}) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("invalid %s: %w", filepath.Base(path), err)
+	}
+	return nil
+}
@@ -0,0 +1,14 @@
+// internal/jobs/jobs_darwin.go
+
+package jobs
+
+import "golang.org/x/sys/unix"
+
+// processStart returns the start time of pid in microseconds, or 0
+func processStart(pid int) int64 {
+	p, err := unix.SysctlKinfoProc("kern.proc.pid", pid)
+	if err != nil || p.Proc.P_pid != int32(pid) {
+		return 0
+	}
+	return p.Proc.P_starttime.Nano() / 1000
+}
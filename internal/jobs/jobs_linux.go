@@ -0,0 +1,29 @@
+// internal/jobs/jobs_linux.go
+
+package jobs
+
+import (
+	"bytes"
+	"os"
+	"strconv"
+)
+
+// processStart returns the start time of pid in clock ticks since boot, or 0
+func processStart(pid int) int64 {
+	data, err := os.ReadFile("/proc/" + strconv.Itoa(pid) + "/stat")
+	if err != nil {
+		return 0
+	}
+	// The command name in parentheses may contain spaces; the start time is
+	// the 20th field after it.
+	i := bytes.LastIndexByte(data, ')')
+	if i < 0 {
+		return 0
+	}
+	fields := bytes.Fields(data[i+1:])
+	if len(fields) < 20 {
+		return 0
+	}
+	start, _ := strconv.ParseInt(string(fields[19]), 10, 64)
+	return start
+}
@@ -0,0 +1,10 @@
+// internal/jobs/jobs_other.go
+
+//go:build !linux && !darwin && !windows
+
+package jobs
+
+// processStart is not available here, so PID reuse goes undetected
+func processStart(pid int) int64 {
+	return 0
+}
@@ -0,0 +1,175 @@
+// internal/jobs/jobs_test.go
+
+package jobs
+
+import (
+	"os"
+	"os/signal"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+)
+
+// TestHelperProcess is the background job started by the tests below
+func TestHelperProcess(t *testing.T) {
+	id := os.Getenv(EnvID)
+	if id == "" {
+		return
+	}
+	m := &Manager{Dir: os.Getenv("JOBS_TEST_DIR")}
+	code, _ := strconv.Atoi(os.Getenv("JOBS_TEST_EXIT"))
+	os.Stdout.WriteString("working in " + os.Getenv("APP_NO_INPUT") + "\n")
+	if os.Getenv("JOBS_TEST_WAIT") != "" {
+		sigs := make(chan os.Signal, 1)
+		signal.Notify(sigs, syscall.SIGTERM)
+		<-sigs
+		code = canceledCode
+	}
+	_ = m.Finish(id, code)
+	os.Exit(0)
+}
+
+func startHelper(t *testing.T, m *Manager, env ...string) *Job {
+	t.Helper()
+	t.Setenv("JOBS_TEST_DIR", m.Dir)
+	for _, kv := range env {
+		k, v, _ := strings.Cut(kv, "=")
+		t.Setenv(k, v)
+	}
+	job, err := m.Start(os.Args[0], []string{"-test.run=TestHelperProcess"})
+	if err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	return job
+}
+
+func waitFor(t *testing.T, m *Manager, id string, want Status) *Job {
+	t.Helper()
+	deadline := time.Now().Add(10 * time.Second)
+	for {
+		job, err := m.Get(id)
+		if err != nil {
+			t.Fatalf("Get() error = %v", err)
+		}
+		if job.Status == want {
+			return job
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("Job status = %s, want %s", job.Status, want)
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+}
+
+func TestStartAndFinish(t *testing.T) {
+	m := &Manager{Dir: t.TempDir()}
+	ok := startHelper(t, m, "JOBS_TEST_EXIT=0")
+	failed := startHelper(t, m, "JOBS_TEST_EXIT=4")
+
+	job := waitFor(t, m, ok.ID, Succeeded)
+	if job.PID == 0 || job.ExitCode == nil || *job.ExitCode != 0 || job.Finished == nil {
+		t.Errorf("Unexpected job %+v", job)
+	}
+	if job = waitFor(t, m, failed.ID, Failed); *job.ExitCode != 4 {
+		t.Errorf("ExitCode = %d, want 4", *job.ExitCode)
+	}
+
+	data, err := os.ReadFile(m.LogPath(ok.ID))
+	if err != nil || !strings.Contains(string(data), "working in true") {
+		t.Errorf("output.log = %q, %v", data, err)
+	}
+
+	jobs, err := m.List()
+	if err != nil || len(jobs) != 2 || jobs[0].ID != failed.ID {
+		t.Errorf("List() = %v, %v; want the most recent job first", jobs, err)
+	}
+	if _, err := m.Get("0badc0de"); err == nil || !strings.Contains(err.Error(), "not found") {
+		t.Errorf("Expected not found error, got %v", err)
+	}
+	if err := m.Cancel(ok.ID); err == nil || !strings.Contains(err.Error(), "not running") {
+		t.Errorf("Expected not running error, got %v", err)
+	}
+}
+
+func TestCancel(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the helper waits for SIGTERM")
+	}
+	m := &Manager{Dir: t.TempDir()}
+	job := startHelper(t, m, "JOBS_TEST_WAIT=1")
+	// Give the helper time to install its signal handler
+	time.Sleep(300 * time.Millisecond)
+
+	if err := m.Cancel(job.ID); err != nil {
+		t.Fatalf("Cancel() error = %v", err)
+	}
+	waitFor(t, m, job.ID, Canceled)
+}
+
+func TestLost(t *testing.T) {
+	m := &Manager{Dir: t.TempDir()}
+	write := func(job Job) {
+		t.Helper()
+		if err := os.MkdirAll(filepath.Join(m.Dir, job.ID), 0o700); err != nil {
+			t.Fatal(err)
+		}
+		if err := writeJSON(filepath.Join(m.Dir, job.ID, "job.json"), job); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// A PID that cannot exist
+	write(Job{ID: "deadbeef", PID: -1, Status: Running})
+	if job, err := m.Get("deadbeef"); err != nil || job.Status != Lost {
+		t.Errorf("Get() = %+v, %v; want a lost job", job, err)
+	}
+
+	pid := os.Getpid()
+	start := processStart(pid)
+	if start == 0 {
+		t.Skip("process start times are not available here")
+	}
+	write(Job{ID: "0000cafe", PID: pid, ProcessStart: start, Status: Running})
+	if job, err := m.Get("0000cafe"); err != nil || job.Status != Running {
+		t.Errorf("Get() = %+v, %v; want the job of this process running", job, err)
+	}
+	// The PID now belongs to a process that started at another time
+	write(Job{ID: "0000beef", PID: pid, ProcessStart: start - 1, Status: Running})
+	if job, err := m.Get("0000beef"); err != nil || job.Status != Lost {
+		t.Errorf("Get() = %+v, %v; want a reused PID to be lost", job, err)
+	}
+	if err := m.Cancel("0000beef"); err == nil || !strings.Contains(err.Error(), "not running (lost)") {
+		t.Errorf("Cancel() error = %v, want a lost job not to be signaled", err)
+	}
+}
+
+func TestInvalidID(t *testing.T) {
+	m := &Manager{Dir: filepath.Join(t.TempDir(), "jobs")}
+	if err := os.MkdirAll(filepath.Join(m.Dir, "escape"), 0o700); err != nil {
+		t.Fatal(err)
+	}
+	for _, id := range []string{"../..", "..", "", "escape", "ABCD1234", "abcd12345", "abcd/234"} {
+		if _, err := m.Get(id); err == nil || !strings.Contains(err.Error(), "invalid job ID") {
+			t.Errorf("Get(%q) error = %v, want invalid job ID", id, err)
+		}
+		if err := m.Cancel(id); err == nil || !strings.Contains(err.Error(), "invalid job ID") {
+			t.Errorf("Cancel(%q) error = %v, want invalid job ID", id, err)
+		}
+		if err := m.Finish(id, 0); err == nil {
+			t.Errorf("Finish(%q) succeeded, want an error", id)
+		}
+	}
+	if jobs, err := m.List(); err != nil || len(jobs) != 0 {
+		t.Errorf("List() = %v, %v; want directories that are not jobs skipped", jobs, err)
+	}
+}
+
+func TestList_NoJobs(t *testing.T) {
+	m := &Manager{Dir: filepath.Join(t.TempDir(), "missing")}
+	if jobs, err := m.List(); err != nil || len(jobs) != 0 {
+		t.Errorf("List() = %v, %v", jobs, err)
+	}
+}
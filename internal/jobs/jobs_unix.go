@@ -0,0 +1,30 @@
+// internal/jobs/jobs_unix.go
+
+//go:build !windows
+
+package jobs
+
+import (
+	"errors"
+	"os"
+	"syscall"
+)
+
+// detached starts the job in its own session, so it survives the terminal closing
+func detached() *syscall.SysProcAttr {
+	return &syscall.SysProcAttr{Setsid: true}
+}
+
+// alive reports whether a process with pid exists
+func alive(pid int) bool {
+	if pid <= 0 {
+		return false
+	}
+	err := syscall.Kill(pid, 0)
+	return err == nil || errors.Is(err, syscall.EPERM)
+}
+
+// terminate sends SIGTERM; the job records its own result
+func terminate(p *os.Process) (recorded bool, err error) {
+	return true, p.Signal(syscall.SIGTERM)
+}
@@ -0,0 +1,48 @@
+// internal/jobs/jobs_windows.go
+
+//go:build windows
+
+package jobs
+
+import (
+	"os"
+	"syscall"
+
+	"golang.org/x/sys/windows"
+)
+
+// detached starts the job without a console, so it survives the terminal closing
+func detached() *syscall.SysProcAttr {
+	return &syscall.SysProcAttr{CreationFlags: windows.CREATE_NEW_PROCESS_GROUP | windows.DETACHED_PROCESS}
+}
+
+// alive reports whether a process with pid is still running
+func alive(pid int) bool {
+	h, err := windows.OpenProcess(windows.PROCESS_QUERY_LIMITED_INFORMATION, false, uint32(pid))
+	if err != nil {
+		return false
+	}
+	defer windows.CloseHandle(h)
+	var code uint32
+	const stillActive = 259
+	return windows.GetExitCodeProcess(h, &code) == nil && code == stillActive
+}
+
+// terminate kills the process, which cannot record its result itself
+func terminate(p *os.Process) (recorded bool, err error) {
+	return false, p.Kill()
+}
+
+// processStart returns the creation time of pid in 100ns intervals, or 0
+func processStart(pid int) int64 {
+	h, err := windows.OpenProcess(windows.PROCESS_QUERY_LIMITED_INFORMATION, false, uint32(pid))
+	if err != nil {
+		return 0
+	}
+	defer windows.CloseHandle(h)
+	var created, exited, kernel, user windows.Filetime
+	if err := windows.GetProcessTimes(h, &created, &exited, &kernel, &user); err != nil {
+		return 0
+	}
+	return int64(created.HighDateTime)<<32 | int64(created.LowDateTime)
+}
@@ -0,0 +1,86 @@
+// internal/lock/lock.go
+
+// Package lock provides advisory file locks that record the PID of their holder,
+// so a second process can report which instance is already running. The
+// operating system releases the lock when the holder exits, even if it crashes.
+package lock
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// HeldError is returned by Acquire when another process holds the lock
+type HeldError struct {
+	Path string
+	// PID of the holder, 0 if it could not be read
+	PID int
+}
+
+func (e *HeldError) Error() string {
+	if e.PID == 0 {
+		return fmt.Sprintf("lock %s is held by another process", e.Path)
+	}
+	return fmt.Sprintf("lock %s is held by process %d", e.Path, e.PID)
+}
+
+// Lock is a held lock file
+type Lock struct {
+	f *os.File
+}
+
+// Acquire takes the lock at path without waiting, creating the file if needed
+func Acquire(path string) (*Lock, error) {
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open lock file: %w", err)
+	}
+
+	held, err := tryLock(f)
+	if err != nil {
+		f.Close()
+		return nil, fmt.Errorf("failed to lock %s: %w", path, err)
+	}
+	if held {
+		f.Close()
+		return nil, &HeldError{Path: path, PID: readPID(path)}
+	}
+
+	if err := writePID(f); err != nil {
+		_ = unlock(f)
+		f.Close()
+		return nil, fmt.Errorf("failed to write lock file: %w", err)
+	}
+	return &Lock{f: f}, nil
+}
+
+// Release unlocks and closes the lock file. The file is left in place, since
+// removing it could let two processes lock different files of the same name.
+func (l *Lock) Release() error {
+	if l == nil || l.f == nil {
+		return nil
+	}
+	err := errors.Join(l.f.Truncate(0), unlock(l.f), l.f.Close())
+	l.f = nil
+	return err
+}
+
+func writePID(f *os.File) error {
+	if err := f.Truncate(0); err != nil {
+		return err
+	}
+	_, err := f.WriteAt([]byte(strconv.Itoa(os.Getpid())+"\n"), 0)
+	return err
+}
+
+func readPID(path string) int {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return 0
+	}
+	pid, _ := strconv.Atoi(strings.TrimSpace(string(data)))
+	return pid
+}
@@ -0,0 +1,51 @@
+// internal/lock/lock_test.go
+
+package lock
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestAcquire(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "update.lock")
+
+	l, err := Acquire(path)
+	if err != nil {
+		t.Fatalf("Acquire() error = %v", err)
+	}
+
+	// flock conflicts between open files, also within one process
+	_, err = Acquire(path)
+	var held *HeldError
+	if !errors.As(err, &held) {
+		t.Fatalf("Expected HeldError, got %v", err)
+	}
+	if held.PID != os.Getpid() {
+		t.Errorf("HeldError.PID = %d, want %d", held.PID, os.Getpid())
+	}
+
+	if err := l.Release(); err != nil {
+		t.Fatalf("Release() error = %v", err)
+	}
+	if err := l.Release(); err != nil {
+		t.Errorf("Second Release() error = %v", err)
+	}
+
+	l2, err := Acquire(path)
+	if err != nil {
+		t.Fatalf("Acquire() after release error = %v", err)
+	}
+	defer l2.Release()
+}
+
+func TestHeldError(t *testing.T) {
+	if got := (&HeldError{Path: "x.lock", PID: 42}).Error(); got != "lock x.lock is held by process 42" {
+		t.Errorf("Error() = %q", got)
+	}
+	if got := (&HeldError{Path: "x.lock"}).Error(); got != "lock x.lock is held by another process" {
+		t.Errorf("Error() = %q", got)
+	}
+}
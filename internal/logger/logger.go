@@ -1,37 +1,77 @@
 package logger
 
 import (
+	"fmt"
 	"io"
 	"os"
+	"strings"
 	"time"
+	"unicode/utf8"
 
+	"github.com/peiman/ckeletin-go/pkg/termcaps"
 	"github.com/rs/zerolog"
-	"github.com/rs/zerolog/log"
-	"github.com/spf13/viper"
 )
 
-// Init initializes the logger with options from Viper.
-// Call this once in rootCmd's PersistentPreRunE or main initialization.
-func Init(out io.Writer) error {
+func init() {
+	// Loggers filter by their own level; zerolog's process-wide level, debug
+	// by default, would otherwise drop trace messages of every logger.
+	zerolog.SetGlobalLevel(zerolog.TraceLevel)
+}
+
+// New returns the logger of an invocation, writing to out at logLevelStr,
+// e.g. the app.log_level setting, with the unicode override of the
+// invocation. The level is set on the logger rather than process-wide, so
+// loggers of invocations running in one process do not affect each other.
+func New(out io.Writer, logLevelStr string, unicode termcaps.UnicodeMode) zerolog.Logger {
 	if out == nil {
 		out = os.Stderr
 	}
 
-	logLevelStr := viper.GetString("app.log_level")
+	// Color only when writing to a terminal that supports it (not when piped or NO_COLOR is set).
+	caps := unicode.Apply(termcaps.For(out))
+	w := zerolog.ConsoleWriter{Out: out, TimeFormat: time.RFC3339, NoColor: caps.Color == termcaps.NoColor}
+	if !caps.Unicode {
+		w.FormatMessage = asciiOnly
+		w.FormatFieldValue = asciiOnly
+	}
+	logger := zerolog.New(w).
+		With().
+		Timestamp().
+		Logger()
+	return WithLevel(logger, logLevelStr)
+}
+
+// WithLevel returns logger at logLevelStr, or at info with a warning logged
+// when logLevelStr is not a valid level
+func WithLevel(logger zerolog.Logger, logLevelStr string) zerolog.Logger {
 	level, err := zerolog.ParseLevel(logLevelStr)
 	if err != nil {
 		level = zerolog.InfoLevel
-		log.Warn().
+		logger.Warn().
 			Err(err).
 			Str("provided_level", logLevelStr).
 			Msg("Invalid log level provided, defaulting to 'info'")
 	}
-	zerolog.SetGlobalLevel(level)
-
-	log.Logger = zerolog.New(zerolog.ConsoleWriter{Out: out, TimeFormat: time.RFC3339}).
-		With().
-		Timestamp().
-		Logger()
+	return logger.Level(level)
+}
 
-	return nil
+// asciiOnly formats a log value with its non-ASCII characters escaped, e.g.
+// "\u2714" for a check mark, for outputs that cannot display them
+func asciiOnly(i interface{}) string {
+	if i == nil {
+		return ""
+	}
+	s := fmt.Sprint(i)
+	var b strings.Builder
+	for _, r := range s {
+		switch {
+		case r < utf8.RuneSelf:
+			b.WriteRune(r)
+		case r > 0xFFFF:
+			fmt.Fprintf(&b, "\\U%08x", r)
+		default:
+			fmt.Fprintf(&b, "\\u%04x", r)
+		}
+	}
+	return b.String()
 }
@@ -7,16 +7,13 @@ import (
 	"os"
 	"testing"
 
-	"github.com/rs/zerolog/log"
-	"github.com/spf13/viper"
+	"github.com/peiman/ckeletin-go/pkg/termcaps"
+	"github.com/rs/zerolog"
 )
 
-func TestInit(t *testing.T) {
+func TestNew(t *testing.T) {
 	buf := new(bytes.Buffer)
-	viper.Set("app.log_level", "info")
-	if err := Init(buf); err != nil {
-		t.Fatalf("Init returned an error: %v", err)
-	}
+	log := New(buf, "info", termcaps.UnicodeAuto)
 	log.Info().Msg("Test message")
 
 	if !bytes.Contains(buf.Bytes(), []byte("Test message")) {
@@ -24,11 +21,8 @@ func TestInit(t *testing.T) {
 	}
 
 	// Test with invalid log level
-	viper.Set("app.log_level", "invalid")
 	buf.Reset()
-	if err := Init(buf); err != nil {
-		t.Fatalf("Init returned an error: %v", err)
-	}
+	log = New(buf, "invalid", termcaps.UnicodeAuto)
 	log.Info().Msg("Test message with invalid level")
 
 	if !bytes.Contains(buf.Bytes(), []byte("Test message with invalid level")) {
@@ -36,11 +30,8 @@ func TestInit(t *testing.T) {
 	}
 
 	// Test with 'debug' log level
-	viper.Set("app.log_level", "debug")
 	buf.Reset()
-	if err := Init(buf); err != nil {
-		t.Fatalf("Init returned an error: %v", err)
-	}
+	log = New(buf, "debug", termcaps.UnicodeAuto)
 	log.Debug().Msg("Debug message")
 
 	if !bytes.Contains(buf.Bytes(), []byte("Debug message")) {
@@ -48,14 +39,52 @@ func TestInit(t *testing.T) {
 	}
 }
 
-func TestInit_ValidLogLevel(t *testing.T) {
+func TestNew_UnicodeOff(t *testing.T) {
 	buf := new(bytes.Buffer)
-	viper.Set("app.log_level", "debug")
+	log := New(buf, "info", termcaps.UnicodeOff)
+	log.Info().Str("status", "✔ done").Msg("Build ✔")
 
-	err := Init(buf)
-	if err != nil {
-		t.Fatalf("Init() error: %v", err)
+	out := buf.String()
+	if !bytes.Contains(buf.Bytes(), []byte(`Build \u2714`)) || !bytes.Contains(buf.Bytes(), []byte(`\u2714 done`)) {
+		t.Errorf("Expected escaped check marks in %q", out)
 	}
+}
+
+func TestNew_IndependentLevels(t *testing.T) {
+	debugBuf, errorBuf := new(bytes.Buffer), new(bytes.Buffer)
+	debugLog := New(debugBuf, "debug", termcaps.UnicodeAuto)
+	errorLog := New(errorBuf, "error", termcaps.UnicodeAuto)
+
+	debugLog.Debug().Msg("Debug message")
+	errorLog.Info().Msg("Info message")
+
+	if !bytes.Contains(debugBuf.Bytes(), []byte("Debug message")) {
+		t.Errorf("Expected 'Debug message' from the debug logger after creating an error logger")
+	}
+	if errorBuf.Len() != 0 {
+		t.Errorf("Did not expect output from the error logger, got %q", errorBuf.String())
+	}
+}
+
+func TestASCIIOnly(t *testing.T) {
+	tests := map[interface{}]string{
+		nil:         "",
+		"plain":     "plain",
+		"caf\u00e9": `caf\u00e9`,
+		"rocket 🚀":  `rocket \U0001f680`,
+		42:          "42",
+	}
+	for in, want := range tests {
+		if got := asciiOnly(in); got != want {
+			t.Errorf("asciiOnly(%v) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestNew_ValidLogLevel(t *testing.T) {
+	buf := new(bytes.Buffer)
+
+	log := New(buf, "debug", termcaps.UnicodeAuto)
 
 	log.Debug().Msg("Debug message")
 	output := buf.String()
@@ -64,14 +93,10 @@ func TestInit_ValidLogLevel(t *testing.T) {
 	}
 }
 
-func TestInit_InvalidLogLevel(t *testing.T) {
+func TestNew_InvalidLogLevel(t *testing.T) {
 	buf := new(bytes.Buffer)
-	viper.Set("app.log_level", "invalid")
 
-	err := Init(buf)
-	if err != nil {
-		t.Fatalf("Init() error: %v", err)
-	}
+	log := New(buf, "invalid", termcaps.UnicodeAuto)
 
 	log.Info().Msg("Info message")
 	log.Debug().Msg("Debug message")
@@ -85,7 +110,28 @@ func TestInit_InvalidLogLevel(t *testing.T) {
 	}
 }
 
-func TestInit_NilOutput(t *testing.T) {
+func TestWithLevel(t *testing.T) {
+	buf := new(bytes.Buffer)
+
+	log := WithLevel(zerolog.New(buf), "warn")
+
+	log.Info().Msg("Info message")
+	log.Warn().Msg("Warn message")
+
+	output := buf.String()
+	if bytes.Contains([]byte(output), []byte("Info message")) || !bytes.Contains([]byte(output), []byte("Warn message")) {
+		t.Errorf("Expected only the warning in log output, got %q", output)
+	}
+
+	buf.Reset()
+	log = WithLevel(zerolog.New(buf), "loud")
+	log.Info().Msg("Info message")
+	if !bytes.Contains(buf.Bytes(), []byte("Invalid log level")) || !bytes.Contains(buf.Bytes(), []byte("Info message")) {
+		t.Errorf("Expected a warning and info level for an invalid level, got %q", buf.String())
+	}
+}
+
+func TestNew_NilOutput(t *testing.T) {
 	// Save the original os.Stderr
 	oldStderr := os.Stderr
 
@@ -98,10 +144,8 @@ func TestInit_NilOutput(t *testing.T) {
 	// Redirect os.Stderr to the write end of the pipe
 	os.Stderr = w
 
-	// Initialize the logger with nil output
-	if err := Init(nil); err != nil {
-		t.Fatalf("Failed to initialize logger with nil output: %v", err)
-	}
+	// Create the logger with nil output
+	log := New(nil, "info", termcaps.UnicodeAuto)
 
 	// Log a message to test the output
 	log.Info().Msg("Test message to stderr")
@@ -125,3 +169,11 @@ func TestInit_NilOutput(t *testing.T) {
 		t.Errorf("Expected 'Test message to stderr' in output, got '%s'", buf.String())
 	}
 }
+
+func BenchmarkLog(b *testing.B) {
+	log := New(io.Discard, "info", termcaps.UnicodeAuto)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		log.Info().Str("command", "ping").Int("count", i).Msg("Ping sent")
+	}
+}
@@ -0,0 +1,130 @@
+// internal/output/output.go
+
+// Package output renders the final result of a command in the format chosen
+// with the global --output flag, so every command offers the same machine-readable
+// formats with the same field names.
+package output
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Supported formats
+const (
+	Text = "text"
+	JSON = "json"
+	YAML = "yaml"
+)
+
+// Formats lists the supported formats
+var Formats = []string{Text, JSON, YAML}
+
+// Texter is implemented by results with their own human-readable form.
+// Other values are printed with fmt in text format.
+type Texter interface {
+	WriteText(w io.Writer) error
+}
+
+// Validate returns an error if format is not supported
+func Validate(format string) error {
+	for _, f := range Formats {
+		if format == f {
+			return nil
+		}
+	}
+	return fmt.Errorf("invalid format %q: must be one of %s", format, strings.Join(Formats, ", "))
+}
+
+// Render writes v to w in format. JSON and YAML use the json struct tags of v,
+// so both formats have the same field names and order.
+func Render(w io.Writer, format string, v interface{}) error {
+	if err := Validate(format); err != nil {
+		return err
+	}
+
+	var data []byte
+	switch format {
+	case Text:
+		if t, ok := v.(Texter); ok {
+			return t.WriteText(w)
+		}
+		data = []byte(fmt.Sprintln(v))
+	case JSON:
+		b, err := json.MarshalIndent(v, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to encode JSON: %w", err)
+		}
+		data = append(b, '\n')
+	case YAML:
+		b, err := toYAML(v)
+		if err != nil {
+			return fmt.Errorf("failed to encode YAML: %w", err)
+		}
+		data = b
+	}
+
+	if _, err := w.Write(data); err != nil {
+		return fmt.Errorf("failed to write output: %w", err)
+	}
+	return nil
+}
+
+// toYAML converts v through JSON, which YAML is a superset of, so the json tags
+// and field order carry over.
+func toYAML(v interface{}) ([]byte, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var node yaml.Node
+	if err := yaml.Unmarshal(data, &node); err != nil {
+		return nil, err
+	}
+	resetStyle(&node)
+
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(&node); err != nil {
+		return nil, err
+	}
+	if err := enc.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// resetStyle switches the JSON flow style and quoting to YAML's block defaults
+func resetStyle(n *yaml.Node) {
+	n.Style = 0
+	for _, c := range n.Content {
+		resetStyle(c)
+	}
+}
+
+// Printer writes the results of a command in the selected format and its
+// messages for the user, which are kept off the result stream
+type Printer struct {
+	Out    io.Writer
+	Err    io.Writer
+	Format string
+}
+
+// Print renders v to Out in the printer's format
+func (p Printer) Print(v interface{}) error {
+	return Render(p.Out, p.Format, v)
+}
+
+// Message writes a line for the user to Err
+func (p Printer) Message(format string, args ...interface{}) error {
+	if _, err := fmt.Fprintf(p.Err, format+"\n", args...); err != nil {
+		return fmt.Errorf("failed to write message: %w", err)
+	}
+	return nil
+}
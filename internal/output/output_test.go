@@ -0,0 +1,92 @@
+// internal/output/output_test.go
+
+package output
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+)
+
+type result struct {
+	Name  string   `json:"name"`
+	Count int      `json:"count"`
+	Tags  []string `json:"tags"`
+}
+
+type textResult struct{ result }
+
+func (r textResult) WriteText(w io.Writer) error {
+	_, err := fmt.Fprintf(w, "%s has %d tags\n", r.Name, r.Count)
+	return err
+}
+
+func TestRender(t *testing.T) {
+	r := result{Name: "ping", Count: 2, Tags: []string{"a", "b"}}
+
+	tests := []struct {
+		name   string
+		format string
+		v      interface{}
+		want   string
+	}{
+		{"JSON", JSON, r, "{\n  \"name\": \"ping\",\n  \"count\": 2,\n  \"tags\": [\n    \"a\",\n    \"b\"\n  ]\n}\n"},
+		{"YAML uses json tags and order", YAML, r, "name: ping\ncount: 2\ntags:\n  - a\n  - b\n"},
+		{"Text with Texter", Text, textResult{r}, "ping has 2 tags\n"},
+		{"Text without Texter", Text, "plain", "plain\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := Render(&buf, tt.format, tt.v); err != nil {
+				t.Fatalf("Render() error = %v", err)
+			}
+			if buf.String() != tt.want {
+				t.Errorf("Render() = %q, want %q", buf.String(), tt.want)
+			}
+		})
+	}
+}
+
+func TestRender_InvalidFormat(t *testing.T) {
+	err := Render(io.Discard, "xml", nil)
+	if err == nil || !strings.Contains(err.Error(), "invalid format \"xml\"") {
+		t.Errorf("Expected invalid format error, got %v", err)
+	}
+}
+
+func TestPrinter(t *testing.T) {
+	var out, errOut bytes.Buffer
+	p := Printer{Out: &out, Err: &errOut, Format: JSON}
+
+	if err := p.Print(result{Name: "ping"}); err != nil {
+		t.Fatalf("Print() error = %v", err)
+	}
+	if !strings.Contains(out.String(), `"name": "ping"`) {
+		t.Errorf("Print() wrote %q", out.String())
+	}
+	if err := p.Message("%d done", 3); err != nil {
+		t.Fatalf("Message() error = %v", err)
+	}
+	if errOut.String() != "3 done\n" || strings.Contains(out.String(), "done") {
+		t.Errorf("Message() wrote %q to Err and %q to Out", errOut.String(), out.String())
+	}
+}
+
+func BenchmarkPrinter(b *testing.B) {
+	r := textResult{result{Name: "ping", Count: 3, Tags: []string{"a", "b", "c"}}}
+	for _, format := range Formats {
+		b.Run(format, func(b *testing.B) {
+			p := Printer{Out: io.Discard, Err: io.Discard, Format: format}
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				if err := p.Print(r); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
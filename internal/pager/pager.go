@@ -0,0 +1,153 @@
+// internal/pager/pager.go
+
+// Package pager shows long output through a pager such as less and copies
+// output to the system clipboard.
+package pager
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/atotto/clipboard"
+)
+
+// DefaultCommand is used when neither the configuration nor $PAGER name a pager.
+// less quits by itself when the output fits on one screen (-F), keeps colors (-R)
+// and leaves the output on the terminal (-X).
+const DefaultCommand = "less -FRX"
+
+// Command returns the pager to run: configured if set, otherwise $PAGER, otherwise
+// DefaultCommand. An empty result means paging was disabled with PAGER="".
+func Command(configured string, lookupEnv func(string) (string, bool)) string {
+	if configured != "" {
+		return configured
+	}
+	if v, ok := lookupEnv("PAGER"); ok {
+		return strings.TrimSpace(v)
+	}
+	return DefaultCommand
+}
+
+// Lines returns the number of lines content occupies
+func Lines(content []byte) int {
+	n := bytes.Count(content, []byte("\n"))
+	if len(content) > 0 && content[len(content)-1] != '\n' {
+		n++
+	}
+	return n
+}
+
+// ErrNotStarted is wrapped by the errors of Run when the pager did not start,
+// so none of the content was shown and it can be written directly instead
+var ErrNotStarted = errors.New("failed to start pager")
+
+// Run pipes content through the pager command line, which shows it on out.
+// The command line is split into words like a shell does, so arguments can be
+// quoted. A pager that exits early (e.g. the user quit less) is not an error.
+func Run(ctx context.Context, command string, content []byte, out, errOut io.Writer) error {
+	args, err := Split(command)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrNotStarted, err)
+	}
+	if len(args) == 0 {
+		return fmt.Errorf("%w: no pager command", ErrNotStarted)
+	}
+
+	c := exec.CommandContext(ctx, args[0], args[1:]...)
+	c.Stdin = bytes.NewReader(content)
+	c.Stdout = out
+	c.Stderr = errOut
+	c.Env = os.Environ()
+	if _, ok := os.LookupEnv("LESS"); !ok {
+		c.Env = append(c.Env, "LESS=FRX")
+	}
+
+	if err := c.Start(); err != nil {
+		return fmt.Errorf("%w %q: %w", ErrNotStarted, args[0], err)
+	}
+	if err := c.Wait(); err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			return nil
+		}
+		return fmt.Errorf("pager %q failed: %w", args[0], err)
+	}
+	return nil
+}
+
+// Split splits a command line into words like a POSIX shell, without
+// expanding anything: words are separated by blanks, single quotes keep
+// everything literally, and in double quotes and unquoted text a backslash
+// escapes the next character (in double quotes only \, \", $ and `).
+func Split(command string) ([]string, error) {
+	var (
+		words  []string
+		word   strings.Builder
+		inWord bool
+		quote  rune
+		escape bool
+	)
+	for _, r := range command {
+		switch {
+		case escape:
+			if quote == '"' && !strings.ContainsRune("\\\"$`\n", r) {
+				word.WriteRune('\\')
+			}
+			if r != '\n' {
+				word.WriteRune(r)
+				inWord = true
+			}
+			escape = false
+		case quote == '\'':
+			if r == '\'' {
+				quote = 0
+			} else {
+				word.WriteRune(r)
+			}
+		case r == '\\':
+			escape = true
+		case quote == '"':
+			if r == '"' {
+				quote = 0
+			} else {
+				word.WriteRune(r)
+			}
+		case r == '\'' || r == '"':
+			quote, inWord = r, true
+		case r == ' ' || r == '\t' || r == '\n':
+			if inWord {
+				words = append(words, word.String())
+				word.Reset()
+				inWord = false
+			}
+		default:
+			word.WriteRune(r)
+			inWord = true
+		}
+	}
+	if quote != 0 {
+		return nil, fmt.Errorf("unterminated %c quote in %q", quote, command)
+	}
+	if escape {
+		return nil, fmt.Errorf("trailing backslash in %q", command)
+	}
+	if inWord {
+		words = append(words, word.String())
+	}
+	return words, nil
+}
+
+// Copy places text on the system clipboard. It needs pbcopy on macOS, and
+// wl-copy, xclip or xsel on Linux.
+func Copy(text string) error {
+	if clipboard.Unsupported {
+		return errors.New("no clipboard utility found (install wl-copy, xclip or xsel)")
+	}
+	return clipboard.WriteAll(text)
+}
@@ -0,0 +1,131 @@
+// internal/pager/pager_test.go
+
+package pager
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os/exec"
+	"reflect"
+	"testing"
+)
+
+func TestCommand(t *testing.T) {
+	lookup := func(vars map[string]string) func(string) (string, bool) {
+		return func(name string) (string, bool) {
+			v, ok := vars[name]
+			return v, ok
+		}
+	}
+
+	tests := []struct {
+		name       string
+		configured string
+		env        map[string]string
+		want       string
+	}{
+		{"Configured wins", "most", map[string]string{"PAGER": "more"}, "most"},
+		{"PAGER", "", map[string]string{"PAGER": " more "}, "more"},
+		{"Empty PAGER disables paging", "", map[string]string{"PAGER": ""}, ""},
+		{"Default", "", nil, DefaultCommand},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Command(tt.configured, lookup(tt.env)); got != tt.want {
+				t.Errorf("Command() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLines(t *testing.T) {
+	tests := []struct {
+		content string
+		want    int
+	}{
+		{"", 0},
+		{"one", 1},
+		{"one\n", 1},
+		{"one\ntwo", 2},
+		{"one\ntwo\n\n", 3},
+	}
+	for _, tt := range tests {
+		if got := Lines([]byte(tt.content)); got != tt.want {
+			t.Errorf("Lines(%q) = %d, want %d", tt.content, got, tt.want)
+		}
+	}
+}
+
+func TestRun(t *testing.T) {
+	if _, err := exec.LookPath("cat"); err != nil {
+		t.Skip("cat not available")
+	}
+
+	out := new(bytes.Buffer)
+	if err := Run(context.Background(), "cat", []byte("paged\n"), out, new(bytes.Buffer)); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if out.String() != "paged\n" {
+		t.Errorf("output = %q, want %q", out.String(), "paged\n")
+	}
+}
+
+func TestRun_Quoted(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh not available")
+	}
+
+	out := new(bytes.Buffer)
+	command := `sh -c 'printf "%s|%s\n" "$0" "$(cat)"' "two words"`
+	if err := Run(context.Background(), command, []byte("paged"), out, new(bytes.Buffer)); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if out.String() != "two words|paged\n" {
+		t.Errorf("output = %q, want the quoted argument kept together", out.String())
+	}
+}
+
+func TestRun_Errors(t *testing.T) {
+	for _, command := range []string{"  ", "no-such-pager-xyz", "less 'unterminated"} {
+		err := Run(context.Background(), command, nil, new(bytes.Buffer), new(bytes.Buffer))
+		if !errors.Is(err, ErrNotStarted) {
+			t.Errorf("Run(%q) error = %v, want ErrNotStarted", command, err)
+		}
+	}
+
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh not available")
+	}
+	// A pager that started and then failed has consumed the content
+	out := new(bytes.Buffer)
+	if err := Run(context.Background(), "sh -c 'cat; exit 3'", []byte("x"), out, new(bytes.Buffer)); err != nil || out.String() != "x" {
+		t.Errorf("Run() = %q, %v; want the exit status ignored", out.String(), err)
+	}
+}
+
+func TestSplit(t *testing.T) {
+	tests := []struct {
+		command string
+		want    []string
+	}{
+		{"less -FRX", []string{"less", "-FRX"}},
+		{"  less\t -R  ", []string{"less", "-R"}},
+		{`"/opt/my pager/bin/less" -R`, []string{"/opt/my pager/bin/less", "-R"}},
+		{`less --prompt='a b' -P"x y"`, []string{"less", "--prompt=a b", "-Px y"}},
+		{`my\ pager "a\"b" 'c\d' "e\f" ""`, []string{"my pager", `a"b`, `c\d`, `e\f`, ""}},
+		{"", nil},
+	}
+	for _, tt := range tests {
+		got, err := Split(tt.command)
+		if err != nil || !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Split(%q) = %q, %v; want %q", tt.command, got, err, tt.want)
+		}
+	}
+	for _, command := range []string{`less "-R`, "less 'x", `less \`} {
+		if _, err := Split(command); err == nil {
+			t.Errorf("Split(%q) succeeded, want an error", command)
+		}
+	}
+}
@@ -0,0 +1,86 @@
+// internal/plugin/plugin.go
+
+// Package plugin discovers external executables that extend the CLI, git-style:
+// an executable named <binary>-<name> becomes the subcommand <name>.
+package plugin
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+)
+
+// Plugin is an external executable providing a subcommand
+type Plugin struct {
+	Name string
+	Path string
+}
+
+// SearchDirs returns the directories searched for plugins: pluginDir first, then PATH
+func SearchDirs(pluginDir string) []string {
+	dirs := []string{}
+	if pluginDir != "" {
+		dirs = append(dirs, pluginDir)
+	}
+	return append(dirs, filepath.SplitList(os.Getenv("PATH"))...)
+}
+
+// Discover returns the plugins for binaryName found in dirs, sorted by name.
+// When several directories provide the same plugin, the first one wins.
+func Discover(binaryName string, dirs []string) []Plugin {
+	prefix := binaryName + "-"
+	seen := make(map[string]bool)
+	var plugins []Plugin
+
+	for _, dir := range dirs {
+		if dir == "" {
+			continue
+		}
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			continue // missing or unreadable PATH entries are common
+		}
+		for _, e := range entries {
+			name, ok := pluginName(e.Name(), prefix)
+			if !ok || seen[name] {
+				continue
+			}
+			path := filepath.Join(dir, e.Name())
+			if !isExecutable(path) {
+				continue
+			}
+			seen[name] = true
+			plugins = append(plugins, Plugin{Name: name, Path: path})
+		}
+	}
+
+	sort.Slice(plugins, func(i, j int) bool { return plugins[i].Name < plugins[j].Name })
+	return plugins
+}
+
+func pluginName(fileName, prefix string) (string, bool) {
+	if runtime.GOOS == "windows" {
+		if !strings.EqualFold(filepath.Ext(fileName), ".exe") {
+			return "", false
+		}
+		fileName = strings.TrimSuffix(fileName, filepath.Ext(fileName))
+	}
+	if !strings.HasPrefix(fileName, prefix) {
+		return "", false
+	}
+	name := strings.TrimPrefix(fileName, prefix)
+	return name, name != ""
+}
+
+func isExecutable(path string) bool {
+	info, err := os.Stat(path)
+	if err != nil || info.IsDir() {
+		return false
+	}
+	if runtime.GOOS == "windows" {
+		return true
+	}
+	return info.Mode()&0o111 != 0
+}
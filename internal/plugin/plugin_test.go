@@ -0,0 +1,70 @@
+// internal/plugin/plugin_test.go
+
+package plugin
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+func writeExecutable(t *testing.T, dir, name string, mode os.FileMode) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte("#!/bin/sh\n"), mode); err != nil {
+		t.Fatalf("Failed to write %s: %v", path, err)
+	}
+	return path
+}
+
+func TestDiscover(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses unix executable bits")
+	}
+	first, second := t.TempDir(), t.TempDir()
+
+	hello := writeExecutable(t, first, "mycli-hello", 0o755)
+	writeExecutable(t, second, "mycli-hello", 0o755) // shadowed by first
+	deploy := writeExecutable(t, second, "mycli-deploy", 0o755)
+	writeExecutable(t, second, "mycli-notes.txt", 0o644) // not executable
+	writeExecutable(t, second, "othercli-tool", 0o755)   // different binary
+	writeExecutable(t, second, "mycli-", 0o755)          // no name
+	if err := os.Mkdir(filepath.Join(second, "mycli-dir"), 0o755); err != nil {
+		t.Fatalf("Failed to create dir: %v", err)
+	}
+
+	got := Discover("mycli", []string{first, "", filepath.Join(first, "missing"), second})
+	want := []Plugin{
+		{Name: "deploy", Path: deploy},
+		{Name: "hello", Path: hello},
+	}
+
+	if len(got) != len(want) {
+		t.Fatalf("Discover() = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("Discover()[%d] = %v, want %v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestSearchDirs(t *testing.T) {
+	t.Setenv("PATH", "/usr/bin"+string(os.PathListSeparator)+"/bin")
+
+	got := SearchDirs("/plugins")
+	want := []string{"/plugins", "/usr/bin", "/bin"}
+	if len(got) != len(want) {
+		t.Fatalf("SearchDirs() = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("SearchDirs()[%d] = %q, want %q", i, got[i], want[i])
+		}
+	}
+
+	if got := SearchDirs(""); len(got) != 2 {
+		t.Errorf("SearchDirs(\"\") = %v, want only PATH entries", got)
+	}
+}
@@ -0,0 +1,48 @@
+// internal/privilege/privilege.go
+
+// Package privilege detects whether the process runs as root or as an elevated
+// Administrator. Tools run that way create files in the user's config, cache
+// and state directories that the user can no longer change afterwards.
+package privilege
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Mode is what the startup guard does when the process is elevated
+type Mode string
+
+const (
+	// Off disables the guard
+	Off Mode = "off"
+	// Warn logs a warning and continues
+	Warn Mode = "warn"
+	// Refuse stops before the command runs
+	Refuse Mode = "refuse"
+)
+
+// Modes lists the valid modes
+var Modes = []Mode{Off, Warn, Refuse}
+
+// ParseMode returns the mode named s, ignoring case
+func ParseMode(s string) (Mode, error) {
+	m := Mode(strings.ToLower(strings.TrimSpace(s)))
+	for _, valid := range Modes {
+		if m == valid {
+			return m, nil
+		}
+	}
+	return "", fmt.Errorf("invalid root guard mode %q: must be off, warn or refuse", s)
+}
+
+// Elevated reports whether the process runs as root on Unix or with an
+// elevated Administrator token on Windows
+func Elevated() bool {
+	return elevated()
+}
+
+// Description names the elevated account for messages
+func Description() string {
+	return description
+}
@@ -0,0 +1,35 @@
+// internal/privilege/privilege_test.go
+
+package privilege
+
+import (
+	"os"
+	"runtime"
+	"testing"
+)
+
+func TestParseMode(t *testing.T) {
+	tests := map[string]Mode{"off": Off, "warn": Warn, " Refuse ": Refuse}
+	for in, want := range tests {
+		if got, err := ParseMode(in); err != nil || got != want {
+			t.Errorf("ParseMode(%q) = %q, %v; want %q", in, got, err, want)
+		}
+	}
+	for _, in := range []string{"", "deny", "true"} {
+		if _, err := ParseMode(in); err == nil {
+			t.Errorf("ParseMode(%q) expected an error", in)
+		}
+	}
+}
+
+func TestElevated(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("elevation depends on the process token")
+	}
+	if got, want := Elevated(), os.Geteuid() == 0; got != want {
+		t.Errorf("Elevated() = %v, want %v", got, want)
+	}
+	if Description() != "root" {
+		t.Errorf("Description() = %q, want root", Description())
+	}
+}
@@ -0,0 +1,13 @@
+// internal/privilege/privilege_unix.go
+
+//go:build !windows
+
+package privilege
+
+import "os"
+
+const description = "root"
+
+func elevated() bool {
+	return os.Geteuid() == 0
+}
@@ -0,0 +1,13 @@
+// internal/privilege/privilege_windows.go
+
+//go:build windows
+
+package privilege
+
+import "golang.org/x/sys/windows"
+
+const description = "Administrator"
+
+func elevated() bool {
+	return windows.GetCurrentProcessToken().IsElevated()
+}
@@ -0,0 +1,91 @@
+// internal/profile/profile.go
+
+// Package profile records CPU and heap profiles and execution traces of the
+// running process for `go tool pprof` and `go tool trace`.
+package profile
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"runtime"
+	"runtime/pprof"
+	"runtime/trace"
+)
+
+// Options name the files to write; empty paths are not recorded
+type Options struct {
+	CPU   string
+	Mem   string
+	Trace string
+}
+
+// Start begins the CPU profile and trace. The returned stop function ends them,
+// writes the heap profile and returns the first error. On error nothing is
+// left running.
+func Start(o Options) (stop func() error, err error) {
+	var stops []func() error
+	defer func() {
+		if err != nil {
+			for _, s := range stops {
+				_ = s()
+			}
+		}
+	}()
+
+	if o.CPU != "" {
+		f, err := os.Create(o.CPU)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create CPU profile: %w", err)
+		}
+		if err := pprof.StartCPUProfile(f); err != nil {
+			f.Close()
+			return nil, fmt.Errorf("failed to start CPU profile: %w", err)
+		}
+		stops = append(stops, func() error {
+			pprof.StopCPUProfile()
+			return f.Close()
+		})
+	}
+	if o.Trace != "" {
+		f, err := os.Create(o.Trace)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create trace: %w", err)
+		}
+		if err := trace.Start(f); err != nil {
+			f.Close()
+			return nil, fmt.Errorf("failed to start trace: %w", err)
+		}
+		stops = append(stops, func() error {
+			trace.Stop()
+			return f.Close()
+		})
+	}
+	if o.Mem != "" {
+		// Fail early rather than after the command ran
+		f, err := os.Create(o.Mem)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create memory profile: %w", err)
+		}
+		stops = append(stops, func() error {
+			// Up-to-date statistics of everything allocated so far
+			runtime.GC()
+			err := pprof.WriteHeapProfile(f)
+			if closeErr := f.Close(); err == nil {
+				err = closeErr
+			}
+			if err != nil {
+				return fmt.Errorf("failed to write memory profile: %w", err)
+			}
+			return nil
+		})
+	}
+
+	return func() error {
+		var errs []error
+		for _, s := range stops {
+			errs = append(errs, s())
+		}
+		return errors.Join(errs...)
+	}, nil
+}
@@ -0,0 +1,60 @@
+// internal/profile/profile_test.go
+
+package profile
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestStart(t *testing.T) {
+	dir := t.TempDir()
+	o := Options{
+		CPU:   filepath.Join(dir, "cpu.pprof"),
+		Mem:   filepath.Join(dir, "mem.pprof"),
+		Trace: filepath.Join(dir, "trace.out"),
+	}
+
+	stop, err := Start(o)
+	if err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	sum := 0
+	for i := 0; i < 1e6; i++ {
+		sum += i
+	}
+	_ = make([]byte, 1<<20)
+	if err := stop(); err != nil {
+		t.Fatalf("stop() error = %v", err)
+	}
+
+	for _, path := range []string{o.CPU, o.Mem, o.Trace} {
+		info, err := os.Stat(path)
+		if err != nil || info.Size() == 0 {
+			t.Errorf("Expected %s to be written, got %v", filepath.Base(path), err)
+		}
+	}
+}
+
+func TestStart_Errors(t *testing.T) {
+	dir := t.TempDir()
+	missing := filepath.Join(dir, "missing", "mem.pprof")
+
+	// The CPU profile started first is stopped again when a later file fails
+	if _, err := Start(Options{CPU: filepath.Join(dir, "cpu.pprof"), Mem: missing}); err == nil {
+		t.Fatal("Expected an error for an unwritable memory profile")
+	}
+	stop, err := Start(Options{CPU: filepath.Join(dir, "cpu2.pprof")})
+	if err != nil {
+		t.Fatalf("CPU profiling still running after a failed Start: %v", err)
+	}
+	if err := stop(); err != nil {
+		t.Errorf("stop() error = %v", err)
+	}
+
+	stop, err = Start(Options{})
+	if err != nil || stop() != nil {
+		t.Errorf("Start() without profiles = %v", err)
+	}
+}
@@ -0,0 +1,87 @@
+// internal/runctx/runctx.go
+
+// Package runctx carries the metadata of one CLI invocation through the
+// context of the running command, so business logic reads its configuration,
+// output and logger from one value instead of package globals and tests can
+// hand it a RunContext of their own.
+package runctx
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	"github.com/peiman/ckeletin-go/internal/output"
+	"github.com/peiman/ckeletin-go/internal/ui"
+	"github.com/peiman/ckeletin-go/internal/xdg"
+	"github.com/rs/zerolog"
+	"github.com/spf13/viper"
+)
+
+// RunContext describes one invocation of the CLI
+type RunContext struct {
+	// ID identifies the invocation in logs and records
+	ID string
+	// Start is when the invocation started
+	Start time.Time
+	// Version is the version of the binary
+	Version string
+	// Args are the arguments the CLI was invoked with, without the binary name
+	Args []string
+	// DryRun is true when mutating commands should only show what they would do
+	DryRun bool
+	// Config is the effective configuration of this invocation: defaults,
+	// config file, environment and flags. It is owned by the invocation, not
+	// shared with other invocations in the process or the global viper.
+	Config *viper.Viper
+	// Printer writes the command's results and messages
+	Printer output.Printer
+	// Logger logs with the invocation ID attached, at the level of this invocation
+	Logger zerolog.Logger
+	// Dirs are the XDG directories of this invocation, see --xdg-root
+	Dirs xdg.Dirs
+	// UI are the theme, width and terminal settings of this invocation
+	UI ui.Settings
+}
+
+// New returns a RunContext with a fresh ID for an invocation starting now.
+// The logger is derived from logger with an invocation_id field.
+func New(version string, cfg *viper.Viper, printer output.Printer, logger zerolog.Logger) *RunContext {
+	id := NewID()
+	return &RunContext{
+		ID:      id,
+		Start:   time.Now(),
+		Version: version,
+		Config:  cfg,
+		Printer: printer,
+		Logger:  logger.With().Str("invocation_id", id).Logger(),
+	}
+}
+
+// NewID returns a random 16 character hex ID
+func NewID() string {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		// crypto/rand does not fail on supported platforms; fall back to the clock
+		return fmt.Sprintf("%016x", time.Now().UnixNano())
+	}
+	return hex.EncodeToString(b)
+}
+
+type contextKey struct{}
+
+// With returns a copy of ctx carrying rc
+func With(ctx context.Context, rc *RunContext) context.Context {
+	return context.WithValue(ctx, contextKey{}, rc)
+}
+
+// From returns the RunContext carried by ctx, if any
+func From(ctx context.Context) (*RunContext, bool) {
+	if ctx == nil {
+		return nil, false
+	}
+	rc, ok := ctx.Value(contextKey{}).(*RunContext)
+	return rc, ok && rc != nil
+}
@@ -0,0 +1,46 @@
+// internal/runctx/runctx_test.go
+
+package runctx
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/peiman/ckeletin-go/internal/output"
+	"github.com/rs/zerolog"
+	"github.com/spf13/viper"
+)
+
+func TestNew(t *testing.T) {
+	logBuf := new(bytes.Buffer)
+	rc := New("1.2.3", viper.New(), output.Printer{Format: output.Text}, zerolog.New(logBuf))
+
+	if len(rc.ID) != 16 || rc.Version != "1.2.3" || rc.Start.IsZero() || rc.Printer.Format != output.Text {
+		t.Errorf("Unexpected RunContext %+v", rc)
+	}
+	if other := NewID(); other == rc.ID {
+		t.Errorf("NewID() repeated %q", other)
+	}
+
+	rc.Logger.Info().Msg("hello")
+	if !strings.Contains(logBuf.String(), `"invocation_id":"`+rc.ID+`"`) {
+		t.Errorf("Log line without invocation ID: %s", logBuf.String())
+	}
+}
+
+func TestWithFrom(t *testing.T) {
+	if _, ok := From(context.Background()); ok {
+		t.Error("From() found a RunContext in an empty context")
+	}
+	if _, ok := From(nil); ok { //nolint:staticcheck // commands run without Execute have no context
+		t.Error("From(nil) found a RunContext")
+	}
+
+	rc := &RunContext{ID: "abc"}
+	got, ok := From(With(context.Background(), rc))
+	if !ok || got != rc {
+		t.Errorf("From() = %v, %v, want the stored RunContext", got, ok)
+	}
+}
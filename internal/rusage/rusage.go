@@ -0,0 +1,10 @@
+// internal/rusage/rusage.go
+
+// Package rusage reports resource usage of the running process.
+package rusage
+
+// PeakRSS returns the largest resident set size (on Windows, the peak working
+// set) of the process so far, in bytes
+func PeakRSS() (int64, error) {
+	return peakRSS()
+}
@@ -0,0 +1,31 @@
+// internal/rusage/rusage_test.go
+
+package rusage
+
+import "testing"
+
+func TestPeakRSS(t *testing.T) {
+	before, err := PeakRSS()
+	if err != nil {
+		t.Fatalf("PeakRSS() error = %v", err)
+	}
+	// A running Go test binary uses at least a megabyte
+	if before < 1<<20 {
+		t.Errorf("PeakRSS() = %d bytes, implausibly small", before)
+	}
+
+	buf := make([]byte, 64<<20)
+	for i := range buf {
+		buf[i] = 1
+	}
+	after, err := PeakRSS()
+	if err != nil {
+		t.Fatalf("PeakRSS() error = %v", err)
+	}
+	// The peak may already have been above the buffer size, so only check
+	// that it covers the buffer and never decreases
+	if after < 64<<20 || after < before {
+		t.Errorf("PeakRSS() = %d after touching 64 MiB, was %d", after, before)
+	}
+	_ = buf[len(buf)-1]
+}
@@ -0,0 +1,24 @@
+// internal/rusage/rusage_unix.go
+
+//go:build !windows
+
+package rusage
+
+import (
+	"fmt"
+	"runtime"
+
+	"golang.org/x/sys/unix"
+)
+
+func peakRSS() (int64, error) {
+	var ru unix.Rusage
+	if err := unix.Getrusage(unix.RUSAGE_SELF, &ru); err != nil {
+		return 0, fmt.Errorf("getrusage: %w", err)
+	}
+	// macOS reports bytes, the other systems kilobytes
+	if runtime.GOOS == "darwin" || runtime.GOOS == "ios" {
+		return int64(ru.Maxrss), nil
+	}
+	return int64(ru.Maxrss) * 1024, nil
+}
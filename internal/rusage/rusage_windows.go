@@ -0,0 +1,38 @@
+// internal/rusage/rusage_windows.go
+
+//go:build windows
+
+package rusage
+
+import (
+	"fmt"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+var procGetProcessMemoryInfo = windows.NewLazySystemDLL("psapi.dll").NewProc("GetProcessMemoryInfo")
+
+// processMemoryCounters is PROCESS_MEMORY_COUNTERS
+type processMemoryCounters struct {
+	Cb                         uint32
+	PageFaultCount             uint32
+	PeakWorkingSetSize         uintptr
+	WorkingSetSize             uintptr
+	QuotaPeakPagedPoolUsage    uintptr
+	QuotaPagedPoolUsage        uintptr
+	QuotaPeakNonPagedPoolUsage uintptr
+	QuotaNonPagedPoolUsage     uintptr
+	PagefileUsage              uintptr
+	PeakPagefileUsage          uintptr
+}
+
+func peakRSS() (int64, error) {
+	var c processMemoryCounters
+	c.Cb = uint32(unsafe.Sizeof(c))
+	r, _, err := procGetProcessMemoryInfo.Call(uintptr(windows.CurrentProcess()), uintptr(unsafe.Pointer(&c)), uintptr(c.Cb))
+	if r == 0 {
+		return 0, fmt.Errorf("GetProcessMemoryInfo: %w", err)
+	}
+	return int64(c.PeakWorkingSetSize), nil
+}
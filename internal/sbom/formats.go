@@ -0,0 +1,173 @@
+// internal/sbom/formats.go
+
+package sbom
+
+import (
+	"fmt"
+	"time"
+)
+
+// CycloneDX 1.5 JSON, limited to the fields a module list can fill
+
+type cdxDocument struct {
+	BOMFormat    string          `json:"bomFormat"`
+	SpecVersion  string          `json:"specVersion"`
+	SerialNumber string          `json:"serialNumber"`
+	Version      int             `json:"version"`
+	Metadata     cdxMetadata     `json:"metadata"`
+	Components   []cdxComponent  `json:"components"`
+	Dependencies []cdxDependency `json:"dependencies"`
+}
+
+type cdxMetadata struct {
+	Timestamp string       `json:"timestamp"`
+	Tools     *cdxTools    `json:"tools,omitempty"`
+	Component cdxComponent `json:"component"`
+}
+
+type cdxTools struct {
+	Components []cdxComponent `json:"components"`
+}
+
+type cdxComponent struct {
+	Type    string    `json:"type"`
+	BOMRef  string    `json:"bom-ref"`
+	Name    string    `json:"name"`
+	Version string    `json:"version,omitempty"`
+	PURL    string    `json:"purl,omitempty"`
+	Hashes  []cdxHash `json:"hashes,omitempty"`
+}
+
+type cdxHash struct {
+	Alg     string `json:"alg"`
+	Content string `json:"content"`
+}
+
+type cdxDependency struct {
+	Ref       string   `json:"ref"`
+	DependsOn []string `json:"dependsOn"`
+}
+
+func cycloneDX(bom BOM, now time.Time) cdxDocument {
+	main := cdxComponent{
+		Type:    "application",
+		BOMRef:  bom.Main.PURL(),
+		Name:    bom.Name,
+		Version: bom.Main.Version,
+		PURL:    bom.Main.PURL(),
+	}
+	var tools *cdxTools
+	if bom.Tool != "" {
+		tools = &cdxTools{Components: []cdxComponent{{Type: "application", BOMRef: "tool", Name: bom.Tool}}}
+	}
+	doc := cdxDocument{
+		BOMFormat:    "CycloneDX",
+		SpecVersion:  "1.5",
+		SerialNumber: "urn:uuid:" + newUUID(),
+		Version:      1,
+		Metadata:     cdxMetadata{Timestamp: now.UTC().Format(time.RFC3339), Tools: tools, Component: main},
+		Components:   []cdxComponent{},
+	}
+
+	deps := cdxDependency{Ref: main.BOMRef, DependsOn: []string{}}
+	for _, m := range bom.Modules {
+		c := cdxComponent{Type: "library", BOMRef: m.PURL(), Name: m.Path, Version: m.Version, PURL: m.PURL()}
+		if sum := m.SHA256(); sum != "" {
+			c.Hashes = []cdxHash{{Alg: "SHA-256", Content: sum}}
+		}
+		doc.Components = append(doc.Components, c)
+		deps.DependsOn = append(deps.DependsOn, c.BOMRef)
+	}
+	doc.Dependencies = []cdxDependency{deps}
+	return doc
+}
+
+// SPDX 2.3 JSON
+
+type spdxDocument struct {
+	SPDXVersion       string             `json:"spdxVersion"`
+	DataLicense       string             `json:"dataLicense"`
+	SPDXID            string             `json:"SPDXID"`
+	Name              string             `json:"name"`
+	DocumentNamespace string             `json:"documentNamespace"`
+	CreationInfo      spdxCreationInfo   `json:"creationInfo"`
+	Packages          []spdxPackage      `json:"packages"`
+	Relationships     []spdxRelationship `json:"relationships"`
+}
+
+type spdxCreationInfo struct {
+	Created  string   `json:"created"`
+	Creators []string `json:"creators"`
+}
+
+type spdxPackage struct {
+	Name             string         `json:"name"`
+	SPDXID           string         `json:"SPDXID"`
+	VersionInfo      string         `json:"versionInfo,omitempty"`
+	DownloadLocation string         `json:"downloadLocation"`
+	FilesAnalyzed    bool           `json:"filesAnalyzed"`
+	Checksums        []spdxChecksum `json:"checksums,omitempty"`
+	ExternalRefs     []spdxRef      `json:"externalRefs"`
+}
+
+type spdxChecksum struct {
+	Algorithm     string `json:"algorithm"`
+	ChecksumValue string `json:"checksumValue"`
+}
+
+type spdxRef struct {
+	ReferenceCategory string `json:"referenceCategory"`
+	ReferenceType     string `json:"referenceType"`
+	ReferenceLocator  string `json:"referenceLocator"`
+}
+
+type spdxRelationship struct {
+	SPDXElementID      string `json:"spdxElementId"`
+	RelationshipType   string `json:"relationshipType"`
+	RelatedSPDXElement string `json:"relatedSpdxElement"`
+}
+
+func spdxPackageFor(name string, id int, m Module) spdxPackage {
+	p := spdxPackage{
+		Name:             name,
+		SPDXID:           fmt.Sprintf("SPDXRef-Package-%d", id),
+		VersionInfo:      m.Version,
+		DownloadLocation: "NOASSERTION",
+		ExternalRefs:     []spdxRef{{ReferenceCategory: "PACKAGE-MANAGER", ReferenceType: "purl", ReferenceLocator: m.PURL()}},
+	}
+	if sum := m.SHA256(); sum != "" {
+		p.Checksums = []spdxChecksum{{Algorithm: "SHA256", ChecksumValue: sum}}
+	}
+	return p
+}
+
+func spdx(bom BOM, now time.Time) spdxDocument {
+	main := spdxPackageFor(bom.Name, 0, bom.Main)
+	creator := bom.Tool
+	if creator == "" {
+		creator = bom.Name
+	}
+	doc := spdxDocument{
+		SPDXVersion:       "SPDX-2.3",
+		DataLicense:       "CC0-1.0",
+		SPDXID:            "SPDXRef-DOCUMENT",
+		Name:              bom.Name,
+		DocumentNamespace: fmt.Sprintf("https://spdx.org/spdxdocs/%s-%s", bom.Name, newUUID()),
+		CreationInfo: spdxCreationInfo{
+			Created:  now.UTC().Format(time.RFC3339),
+			Creators: []string{"Tool: " + creator},
+		},
+		Packages: []spdxPackage{main},
+		Relationships: []spdxRelationship{
+			{SPDXElementID: "SPDXRef-DOCUMENT", RelationshipType: "DESCRIBES", RelatedSPDXElement: main.SPDXID},
+		},
+	}
+
+	for i, m := range bom.Modules {
+		p := spdxPackageFor(m.Path, i+1, m)
+		doc.Packages = append(doc.Packages, p)
+		doc.Relationships = append(doc.Relationships,
+			spdxRelationship{SPDXElementID: main.SPDXID, RelationshipType: "DEPENDS_ON", RelatedSPDXElement: p.SPDXID})
+	}
+	return doc
+}
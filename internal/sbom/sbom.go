@@ -0,0 +1,126 @@
+// internal/sbom/sbom.go
+
+// Package sbom builds a software bill of materials from the module information
+// the Go toolchain embeds in every binary, and writes it as CycloneDX or SPDX JSON.
+package sbom
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"runtime/debug"
+	"strings"
+	"time"
+)
+
+// Supported SBOM formats
+const (
+	CycloneDX = "cyclonedx"
+	SPDX      = "spdx"
+)
+
+// Formats lists the supported formats
+var Formats = []string{CycloneDX, SPDX}
+
+// Module is a Go module linked into the binary
+type Module struct {
+	Path    string
+	Version string
+	// Sum is the go.sum hash ("h1:..."), empty for the main module
+	Sum string
+}
+
+// PURL returns the package URL of m
+func (m Module) PURL() string {
+	if m.Version == "" {
+		return "pkg:golang/" + m.Path
+	}
+	return fmt.Sprintf("pkg:golang/%s@%s", m.Path, m.Version)
+}
+
+// SHA256 returns the hex SHA-256 from an h1 hash, or "" when there is none
+func (m Module) SHA256() string {
+	b64, ok := strings.CutPrefix(m.Sum, "h1:")
+	if !ok {
+		return ""
+	}
+	sum, err := base64.StdEncoding.DecodeString(b64)
+	if err != nil {
+		return ""
+	}
+	return hex.EncodeToString(sum)
+}
+
+// BOM describes a binary and the modules it was built from
+type BOM struct {
+	// Tool names the program that generated the BOM, e.g. "ckeletin-go-1.2.0"
+	Tool      string
+	Name      string
+	Main      Module
+	GoVersion string
+	Modules   []Module
+}
+
+// FromBuildInfo returns the BOM of the binary named name. Replaced modules are
+// listed with the path, version and hash of their replacement. version
+// overrides the main module version, which is "(devel)" for local builds.
+func FromBuildInfo(name, version string, bi *debug.BuildInfo) BOM {
+	bom := BOM{
+		Name:      name,
+		Main:      Module{Path: bi.Main.Path, Version: bi.Main.Version},
+		GoVersion: bi.GoVersion,
+	}
+	if version != "" {
+		bom.Main.Version = version
+	}
+	for _, dep := range bi.Deps {
+		if dep.Replace != nil {
+			dep = dep.Replace
+		}
+		bom.Modules = append(bom.Modules, Module{Path: dep.Path, Version: dep.Version, Sum: dep.Sum})
+	}
+	return bom
+}
+
+// Validate checks that format is supported
+func Validate(format string) error {
+	for _, f := range Formats {
+		if format == f {
+			return nil
+		}
+	}
+	return fmt.Errorf("invalid SBOM format %q: must be one of %s", format, strings.Join(Formats, ", "))
+}
+
+// Write writes bom to w in the given format, stamped with the creation time now
+func Write(w io.Writer, format string, bom BOM, now time.Time) error {
+	if err := Validate(format); err != nil {
+		return err
+	}
+	var doc interface{}
+	switch format {
+	case CycloneDX:
+		doc = cycloneDX(bom, now)
+	case SPDX:
+		doc = spdx(bom, now)
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(doc); err != nil {
+		return fmt.Errorf("failed to write SBOM: %w", err)
+	}
+	return nil
+}
+
+// newUUID returns a random (version 4) UUID
+func newUUID() string {
+	var b [16]byte
+	_, _ = rand.Read(b[:])
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+}
@@ -0,0 +1,127 @@
+// internal/sbom/sbom_test.go
+
+package sbom
+
+import (
+	"bytes"
+	"encoding/json"
+	"runtime/debug"
+	"strings"
+	"testing"
+	"time"
+)
+
+// h1 hash of 32 zero bytes followed by 0x01
+const testSum = "h1:AAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAE="
+
+func testBuildInfo() *debug.BuildInfo {
+	return &debug.BuildInfo{
+		GoVersion: "go1.23.3",
+		Main:      debug.Module{Path: "example.com/app", Version: "(devel)"},
+		Deps: []*debug.Module{
+			{Path: "example.com/lib", Version: "v1.2.3", Sum: testSum},
+			{Path: "example.com/old", Version: "v0.1.0", Sum: "h1:bad",
+				Replace: &debug.Module{Path: "example.com/fork", Version: "v0.1.1", Sum: testSum}},
+		},
+	}
+}
+
+func TestFromBuildInfo(t *testing.T) {
+	bom := FromBuildInfo("app", "1.0.0", testBuildInfo())
+
+	if bom.Name != "app" || bom.Main.Version != "1.0.0" || bom.GoVersion != "go1.23.3" {
+		t.Errorf("Unexpected BOM header %+v", bom)
+	}
+	if len(bom.Modules) != 2 {
+		t.Fatalf("len(Modules) = %d, want 2", len(bom.Modules))
+	}
+	if m := bom.Modules[1]; m.Path != "example.com/fork" || m.Version != "v0.1.1" {
+		t.Errorf("Replaced module = %+v, want the replacement", m)
+	}
+
+	if bom := FromBuildInfo("app", "", testBuildInfo()); bom.Main.Version != "(devel)" {
+		t.Errorf("Main.Version = %q, want the embedded version", bom.Main.Version)
+	}
+}
+
+func TestModule(t *testing.T) {
+	m := Module{Path: "example.com/lib", Version: "v1.2.3", Sum: testSum}
+	if got := m.PURL(); got != "pkg:golang/example.com/lib@v1.2.3" {
+		t.Errorf("PURL() = %q", got)
+	}
+	if got := m.SHA256(); got != strings.Repeat("00", 31)+"01" {
+		t.Errorf("SHA256() = %q", got)
+	}
+	if got := (Module{Sum: "h1:not base64!"}).SHA256(); got != "" {
+		t.Errorf("SHA256() of invalid hash = %q, want empty", got)
+	}
+	if got := (Module{Path: "example.com/app"}).PURL(); got != "pkg:golang/example.com/app" {
+		t.Errorf("PURL() without version = %q", got)
+	}
+}
+
+func TestWrite_CycloneDX(t *testing.T) {
+	bom := FromBuildInfo("app", "1.0.0", testBuildInfo())
+	bom.Tool = "app-1.0.0"
+	var buf bytes.Buffer
+	if err := Write(&buf, CycloneDX, bom, time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+
+	var doc cdxDocument
+	if err := json.Unmarshal(buf.Bytes(), &doc); err != nil {
+		t.Fatalf("Invalid JSON: %v", err)
+	}
+	if doc.BOMFormat != "CycloneDX" || doc.SpecVersion != "1.5" || !strings.HasPrefix(doc.SerialNumber, "urn:uuid:") {
+		t.Errorf("Unexpected header %+v", doc)
+	}
+	if doc.Metadata.Timestamp != "2024-01-02T03:04:05Z" || doc.Metadata.Component.Name != "app" {
+		t.Errorf("Unexpected metadata %+v", doc.Metadata)
+	}
+	if doc.Metadata.Tools == nil || doc.Metadata.Tools.Components[0].Name != "app-1.0.0" {
+		t.Errorf("Expected the tool in metadata, got %+v", doc.Metadata.Tools)
+	}
+	if len(doc.Components) != 2 || doc.Components[0].Hashes[0].Alg != "SHA-256" {
+		t.Errorf("Unexpected components %+v", doc.Components)
+	}
+	if len(doc.Dependencies) != 1 || len(doc.Dependencies[0].DependsOn) != 2 {
+		t.Errorf("Unexpected dependencies %+v", doc.Dependencies)
+	}
+}
+
+func TestWrite_SPDX(t *testing.T) {
+	bom := FromBuildInfo("app", "1.0.0", testBuildInfo())
+	var buf bytes.Buffer
+	if err := Write(&buf, SPDX, bom, time.Now()); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+
+	var doc spdxDocument
+	if err := json.Unmarshal(buf.Bytes(), &doc); err != nil {
+		t.Fatalf("Invalid JSON: %v", err)
+	}
+	if doc.SPDXVersion != "SPDX-2.3" || doc.SPDXID != "SPDXRef-DOCUMENT" || doc.CreationInfo.Creators[0] != "Tool: app" {
+		t.Errorf("Unexpected header %+v", doc)
+	}
+	if len(doc.Packages) != 3 || doc.Packages[1].Checksums[0].Algorithm != "SHA256" {
+		t.Errorf("Unexpected packages %+v", doc.Packages)
+	}
+	if len(doc.Relationships) != 3 || doc.Relationships[0].RelationshipType != "DESCRIBES" ||
+		doc.Relationships[2].RelationshipType != "DEPENDS_ON" {
+		t.Errorf("Unexpected relationships %+v", doc.Relationships)
+	}
+}
+
+func TestWrite_InvalidFormat(t *testing.T) {
+	err := Write(new(bytes.Buffer), "xml", BOM{}, time.Now())
+	if err == nil || !strings.Contains(err.Error(), "invalid SBOM format") {
+		t.Errorf("Expected invalid format error, got %v", err)
+	}
+}
+
+func TestNewUUID(t *testing.T) {
+	id := newUUID()
+	if len(id) != 36 || id[14] != '4' || id == newUUID() {
+		t.Errorf("newUUID() = %q, want a random version 4 UUID", id)
+	}
+}
@@ -0,0 +1,154 @@
+// internal/scaffold/features.go
+
+package scaffold
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Feature is an optional part of the scaffold that new projects can leave out
+type Feature struct {
+	Name        string
+	Description string
+	// Paths are the files and directories (ending in "/") that only this feature uses
+	Paths []string
+	// Optional features are left out unless requested with --with
+	Optional bool
+}
+
+// Features lists the features of the scaffold. Code that other files share with a
+// feature is wrapped in marker comments naming it:
+//
+//	// scaffold:telemetry
+//	recordTelemetry(cmd, start, err)
+//	// scaffold:end
+//
+// "#" and "<!-- -->" comments work the same in YAML and Markdown files.
+var Features = []Feature{
+	{Name: "audit", Description: "audit log of every invocation", Paths: []string{"cmd/audit.go", "cmd/audit_test.go", "internal/audit/"}},
+	{Name: "deps", Description: "deps outdated command", Paths: []string{"cmd/deps.go", "cmd/deps_test.go", "internal/deps/"}},
+	{Name: "http-client", Description: "fetch command and app.http client settings", Optional: true,
+		Paths: []string{"cmd/fetch.go", "cmd/fetch_test.go", "cmd/http.go", "cmd/http_test.go", "pkg/httpclient/"}},
+	{Name: "jobs", Description: "background jobs and the jobs command", Paths: []string{"cmd/jobs.go", "cmd/jobs_test.go", "internal/jobs/"}},
+	{Name: "plugins", Description: "git-style plugin executables", Paths: []string{"cmd/plugin.go", "cmd/plugin_test.go", "internal/plugin/"}},
+	{Name: "sbom", Description: "sbom generate command", Paths: []string{"cmd/sbom.go", "cmd/sbom_test.go", "internal/sbom/"}},
+	{Name: "scaffolding", Description: "new and rebrand commands", Optional: true,
+		Paths: []string{"cmd/new.go", "cmd/new_test.go", "cmd/rebrand.go", "cmd/rebrand_test.go", "internal/scaffold/"}},
+	{Name: "telemetry", Description: "opt-in usage telemetry", Paths: []string{"cmd/telemetry.go", "cmd/telemetry_test.go", "internal/telemetry/"}},
+	{Name: "vuln", Description: "dev vuln command and task", Paths: []string{"cmd/vuln.go", "cmd/vuln_test.go", "internal/vulncheck/"}},
+}
+
+// FeatureNames returns the names of all features
+func FeatureNames() []string {
+	names := make([]string, 0, len(Features))
+	for _, f := range Features {
+		names = append(names, f.Name)
+	}
+	return names
+}
+
+// Exclusions returns the features left out of a new project: the optional ones not
+// listed in with, plus those listed in without.
+func Exclusions(with, without []string) (map[string]bool, error) {
+	known := map[string]bool{}
+	excluded := map[string]bool{}
+	for _, f := range Features {
+		known[f.Name] = true
+		if f.Optional {
+			excluded[f.Name] = true
+		}
+	}
+
+	for _, name := range with {
+		if !known[name] {
+			return nil, unknownFeature(name)
+		}
+		delete(excluded, name)
+	}
+	for _, name := range without {
+		if !known[name] {
+			return nil, unknownFeature(name)
+		}
+		excluded[name] = true
+	}
+	return excluded, nil
+}
+
+func unknownFeature(name string) error {
+	names := FeatureNames()
+	sort.Strings(names)
+	return fmt.Errorf("unknown feature %q: must be one of %s", name, strings.Join(names, ", "))
+}
+
+// FilterFiles returns files without those belonging to excluded features
+func FilterFiles(files []string, excluded map[string]bool) []string {
+	var kept []string
+	for _, f := range files {
+		if !excludedPath(f, excluded) {
+			kept = append(kept, f)
+		}
+	}
+	return kept
+}
+
+func excludedPath(file string, excluded map[string]bool) bool {
+	for _, feature := range Features {
+		if !excluded[feature.Name] {
+			continue
+		}
+		for _, p := range feature.Paths {
+			if file == p || (strings.HasSuffix(p, "/") && strings.HasPrefix(file, p)) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// markerRE matches a feature marker line such as "// scaffold:audit",
+// "# scaffold:end" or "<!-- scaffold:vuln -->", compiled on first use so
+// commands that never scaffold do not pay for it at startup
+var markerRE = sync.OnceValue(func() *regexp.Regexp {
+	return regexp.MustCompile(`^\s*(?://|#|<!--)\s*scaffold:([a-z-]+)\s*(?:-->)?\s*$`)
+})
+
+// StripMarkers removes the blocks of excluded features from content and the
+// marker lines of all others.
+func StripMarkers(content string, excluded map[string]bool) (string, error) {
+	lines := strings.SplitAfter(content, "\n")
+	var (
+		out   strings.Builder
+		stack []string
+		skip  int // depth of the outermost excluded block, 0 when keeping lines
+	)
+	for i, line := range lines {
+		m := markerRE().FindStringSubmatch(strings.TrimRight(line, "\r\n"))
+		switch {
+		case m == nil:
+			if skip == 0 {
+				out.WriteString(line)
+			}
+		case m[1] == "end":
+			if len(stack) == 0 {
+				return "", fmt.Errorf("line %d: scaffold:end without a matching feature marker", i+1)
+			}
+			if skip == len(stack) {
+				skip = 0
+			}
+			stack = stack[:len(stack)-1]
+		default:
+			stack = append(stack, m[1])
+			if skip == 0 && excluded[m[1]] {
+				skip = len(stack)
+			}
+		}
+	}
+	if len(stack) > 0 {
+		return "", fmt.Errorf("feature marker scaffold:%s is not closed", stack[len(stack)-1])
+	}
+	return out.String(), nil
+}
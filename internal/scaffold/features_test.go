@@ -0,0 +1,78 @@
+// internal/scaffold/features_test.go
+
+package scaffold
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestExclusions(t *testing.T) {
+	excluded, err := Exclusions(nil, nil)
+	if err != nil || !reflect.DeepEqual(excluded, map[string]bool{"http-client": true, "scaffolding": true}) {
+		t.Errorf("Exclusions() = %v, %v; want only the optional features", excluded, err)
+	}
+
+	excluded, err = Exclusions([]string{"http-client", "scaffolding"}, []string{"telemetry", "audit"})
+	if err != nil || !reflect.DeepEqual(excluded, map[string]bool{"telemetry": true, "audit": true}) {
+		t.Errorf("Exclusions() = %v, %v", excluded, err)
+	}
+
+	for _, names := range [][2][]string{{{"tui"}, nil}, {nil, {"file-logging"}}} {
+		if _, err := Exclusions(names[0], names[1]); err == nil || !strings.Contains(err.Error(), "must be one of audit, deps, http-client, jobs,") {
+			t.Errorf("Exclusions(%v) expected an unknown feature error, got %v", names, err)
+		}
+	}
+}
+
+func TestFilterFiles(t *testing.T) {
+	files := []string{"cmd/root.go", "cmd/audit.go", "cmd/audit_test.go", "internal/audit/audit.go", "internal/auditor/x.go", "cmd/telemetry.go"}
+	got := FilterFiles(files, map[string]bool{"audit": true})
+	want := []string{"cmd/root.go", "internal/auditor/x.go", "cmd/telemetry.go"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("FilterFiles() = %v, want %v", got, want)
+	}
+}
+
+func TestStripMarkers(t *testing.T) {
+	in := "a\n" +
+		"\t// scaffold:audit\n" +
+		"\taudit()\n" +
+		"\t// scaffold:plugins\n" +
+		"\tplugins()\n" +
+		"\t// scaffold:end\n" +
+		"\t// scaffold:end\n" +
+		"  # scaffold:plugins\n" +
+		"  - plugin\n" +
+		"  # scaffold:end\n" +
+		"<!-- scaffold:vuln -->\n" +
+		"## vuln\n" +
+		"<!-- scaffold:end -->\n" +
+		"z\n"
+
+	tests := []struct {
+		excluded map[string]bool
+		want     string
+	}{
+		{nil, "a\n\taudit()\n\tplugins()\n  - plugin\n## vuln\nz\n"},
+		{map[string]bool{"plugins": true}, "a\n\taudit()\n## vuln\nz\n"},
+		{map[string]bool{"audit": true, "vuln": true}, "a\n  - plugin\nz\n"},
+	}
+	for _, tt := range tests {
+		got, err := StripMarkers(in, tt.excluded)
+		if err != nil || got != tt.want {
+			t.Errorf("StripMarkers(%v) = %q, %v; want %q", tt.excluded, got, err, tt.want)
+		}
+	}
+
+	for _, bad := range []string{"// scaffold:end\n", "// scaffold:audit\nx\n"} {
+		if _, err := StripMarkers(bad, nil); err == nil {
+			t.Errorf("StripMarkers(%q) expected an error", bad)
+		}
+	}
+	// Markers only count on lines of their own
+	if got, err := StripMarkers("x := \"// scaffold:end\"\n", nil); err != nil || got != "x := \"// scaffold:end\"\n" {
+		t.Errorf("StripMarkers() = %q, %v", got, err)
+	}
+}
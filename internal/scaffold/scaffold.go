@@ -0,0 +1,298 @@
+// internal/scaffold/scaffold.go
+
+// Package scaffold copies and renames projects generated from this scaffold:
+// the Go module path and the binary name are rewritten in every text file, so a
+// new CLI starts with its own imports, config file name, docs links and release
+// settings.
+package scaffold
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"go/format"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// skipDirs are never copied into a new project
+var skipDirs = map[string]bool{".git": true, "dist": true, "node_modules": true}
+
+// Rename describes how a project is renamed
+type Rename struct {
+	OldModule, NewModule string
+	OldName, NewName     string
+}
+
+// Apply returns s with the module path and then the binary name replaced.
+// The module path goes first since it usually contains the binary name.
+func (r Rename) Apply(s string) string {
+	const placeholder = "\x00module\x00"
+	if r.OldModule != "" && r.OldModule != r.NewModule {
+		s = strings.ReplaceAll(s, r.OldModule, placeholder)
+	}
+	if r.OldName != "" && r.OldName != r.NewName {
+		s = strings.ReplaceAll(s, r.OldName, r.NewName)
+	}
+	return strings.ReplaceAll(s, placeholder, r.NewModule)
+}
+
+// modulePathRE matches a module path: lower-case domain-like first element and
+// slash-separated elements. Like the other patterns of this package it is
+// compiled on first use, not at startup.
+var modulePathRE = sync.OnceValue(func() *regexp.Regexp {
+	return regexp.MustCompile(`^[a-z0-9][a-z0-9.-]*(/[A-Za-z0-9._~-]+)*$`)
+})
+
+// ValidateModulePath checks that p looks like a Go module path
+func ValidateModulePath(p string) error {
+	if !modulePathRE().MatchString(p) || strings.Contains(p, "..") {
+		return fmt.Errorf("invalid module path %q, e.g. github.com/acme/mycli", p)
+	}
+	return nil
+}
+
+// NameFromModule returns the default binary name for a module path: its last
+// element, ignoring a major version suffix such as /v2
+func NameFromModule(modulePath string) string {
+	base := path.Base(modulePath)
+	if len(base) > 1 && base[0] == 'v' && strings.Trim(base[1:], "0123456789") == "" {
+		base = path.Base(path.Dir(modulePath))
+	}
+	return base
+}
+
+// ReadModulePath returns the module path declared in dir/go.mod
+func ReadModulePath(dir string) (string, error) {
+	data, err := os.ReadFile(filepath.Join(dir, "go.mod"))
+	if err != nil {
+		return "", fmt.Errorf("not a Go module: %w", err)
+	}
+	sc := bufio.NewScanner(bytes.NewReader(data))
+	for sc.Scan() {
+		if fields := strings.Fields(sc.Text()); len(fields) == 2 && fields[0] == "module" {
+			return strings.Trim(fields[1], `"`), nil
+		}
+	}
+	return "", fmt.Errorf("no module directive in %s", filepath.Join(dir, "go.mod"))
+}
+
+// binaryNameRE matches the binary name declaration in cmd/root.go
+var binaryNameRE = sync.OnceValue(func() *regexp.Regexp {
+	return regexp.MustCompile(`(?m)^\s*binaryName\s*=\s*"([^"]+)"`)
+})
+
+// ReadBinaryName returns the binary name declared in dir/cmd/root.go, or the
+// name derived from the module path when there is no such declaration.
+func ReadBinaryName(dir, modulePath string) string {
+	if data, err := os.ReadFile(filepath.Join(dir, "cmd", "root.go")); err == nil {
+		if m := binaryNameRE().FindSubmatch(data); m != nil {
+			return string(m[1])
+		}
+	}
+	return NameFromModule(modulePath)
+}
+
+// ListFiles returns the regular files of the project in dir, relative and
+// slash-separated. In a git work tree these are the tracked and untracked but not
+// ignored files; otherwise all files except those in version control and build
+// directories.
+func ListFiles(dir string) ([]string, error) {
+	if _, err := os.Stat(filepath.Join(dir, ".git")); err == nil {
+		out, err := exec.Command("git", "-C", dir, "ls-files", "-z", "--cached", "--others", "--exclude-standard").Output()
+		if err == nil {
+			var files []string
+			for _, f := range strings.Split(string(out), "\x00") {
+				// Nested repositories are listed as "dir/", and deleted but
+				// still tracked files are listed too
+				if f == "" || strings.HasSuffix(f, "/") {
+					continue
+				}
+				if info, err := os.Lstat(filepath.Join(dir, filepath.FromSlash(f))); err == nil && info.Mode().IsRegular() {
+					files = append(files, f)
+				}
+			}
+			return files, nil
+		}
+	}
+
+	var files []string
+	err := filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if p != dir && skipDirs[d.Name()] {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		rel, err := filepath.Rel(dir, p)
+		if err != nil {
+			return err
+		}
+		files = append(files, filepath.ToSlash(rel))
+		return nil
+	})
+	return files, err
+}
+
+// ExcludeDir returns files of the project in dir without those under sub, so a
+// project created inside the scaffold is never copied into itself or the next one
+func ExcludeDir(files []string, dir, sub string) []string {
+	absDir, err1 := filepath.Abs(dir)
+	absSub, err2 := filepath.Abs(sub)
+	if err1 != nil || err2 != nil {
+		return files
+	}
+	rel, err := filepath.Rel(absDir, absSub)
+	if err != nil || rel == "." || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return files
+	}
+	prefix := filepath.ToSlash(rel) + "/"
+	var kept []string
+	for _, f := range files {
+		if !strings.HasPrefix(f, prefix) {
+			kept = append(kept, f)
+		}
+	}
+	return kept
+}
+
+// isText reports whether data looks like text rather than a binary file
+func isText(data []byte) bool {
+	if len(data) > 8000 {
+		data = data[:8000]
+	}
+	return !bytes.Contains(data, []byte{0})
+}
+
+// Copy copies files from src to dst, applying r to the contents of text files
+// and to the file names. The blocks of excluded features are removed from text
+// files, see StripMarkers; leaving out their files is up to the caller.
+// Go files are then formatted, since a new module path can change the order of
+// imports and removed blocks leave blank lines; files that do not parse are
+// copied as they are. dst must not exist or be empty.
+func Copy(src, dst string, files []string, r Rename, excluded map[string]bool) error {
+	if entries, err := os.ReadDir(dst); err == nil && len(entries) > 0 {
+		return fmt.Errorf("destination %s already exists and is not empty", dst)
+	} else if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("failed to read destination: %w", err)
+	}
+
+	for _, f := range files {
+		info, err := os.Stat(filepath.Join(src, filepath.FromSlash(f)))
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", f, err)
+		}
+		data, err := os.ReadFile(filepath.Join(src, filepath.FromSlash(f)))
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", f, err)
+		}
+		if isText(data) {
+			content, err := StripMarkers(string(data), excluded)
+			if err != nil {
+				return fmt.Errorf("%s: %w", f, err)
+			}
+			data = []byte(r.Apply(content))
+			if strings.HasSuffix(f, ".go") {
+				if formatted, err := format.Source(data); err == nil {
+					data = formatted
+				}
+			}
+		}
+
+		target := filepath.Join(dst, filepath.FromSlash(r.Apply(f)))
+		if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+			return fmt.Errorf("failed to create directory for %s: %w", f, err)
+		}
+		if err := os.WriteFile(target, data, info.Mode().Perm()); err != nil {
+			return fmt.Errorf("failed to write %s: %w", target, err)
+		}
+	}
+	return nil
+}
+
+// Change is the rewrite of one file by Plan
+type Change struct {
+	// Path is the current file, NewPath its name after renaming (equal when unchanged)
+	Path, NewPath string
+	Old, New      string
+	mode          fs.FileMode
+}
+
+// Diff returns the changed lines of c as "-" and "+" pairs with line numbers.
+// Rename only replaces text within lines, so lines are compared one by one.
+func (c Change) Diff() string {
+	var b strings.Builder
+	if c.NewPath != c.Path {
+		fmt.Fprintf(&b, "rename %s => %s\n", c.Path, c.NewPath)
+	}
+	oldLines, newLines := strings.Split(c.Old, "\n"), strings.Split(c.New, "\n")
+	for i := range oldLines {
+		if i < len(newLines) && oldLines[i] != newLines[i] {
+			fmt.Fprintf(&b, "@@ %s:%d\n-%s\n+%s\n", c.NewPath, i+1, oldLines[i], newLines[i])
+		}
+	}
+	return b.String()
+}
+
+// Plan returns the changes r makes to files in dir, without writing anything.
+// Binary files are only renamed, never rewritten.
+func Plan(dir string, files []string, r Rename) ([]Change, error) {
+	var changes []Change
+	for _, f := range files {
+		p := filepath.Join(dir, filepath.FromSlash(f))
+		info, err := os.Stat(p)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", f, err)
+		}
+		data, err := os.ReadFile(p)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", f, err)
+		}
+
+		c := Change{Path: f, NewPath: r.Apply(f), Old: string(data), New: string(data), mode: info.Mode().Perm()}
+		if isText(data) {
+			c.New = r.Apply(c.Old)
+		}
+		if c.New != c.Old || c.NewPath != c.Path {
+			changes = append(changes, c)
+		}
+	}
+	return changes, nil
+}
+
+// Apply writes changes made by Plan to dir, renaming files where needed
+func Apply(dir string, changes []Change) error {
+	for _, c := range changes {
+		target := filepath.Join(dir, filepath.FromSlash(c.NewPath))
+		if c.NewPath != c.Path {
+			if _, err := os.Lstat(target); err == nil {
+				return fmt.Errorf("cannot rename %s: %s already exists", c.Path, c.NewPath)
+			}
+			if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+				return fmt.Errorf("failed to create directory for %s: %w", c.NewPath, err)
+			}
+		}
+		if err := os.WriteFile(target, []byte(c.New), c.mode); err != nil {
+			return fmt.Errorf("failed to write %s: %w", c.NewPath, err)
+		}
+		if c.NewPath != c.Path {
+			if err := os.Remove(filepath.Join(dir, filepath.FromSlash(c.Path))); err != nil {
+				return fmt.Errorf("failed to remove %s: %w", c.Path, err)
+			}
+		}
+	}
+	return nil
+}
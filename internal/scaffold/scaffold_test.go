@@ -0,0 +1,301 @@
+// internal/scaffold/scaffold_test.go
+
+package scaffold
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+)
+
+var testRename = Rename{
+	OldModule: "github.com/peiman/ckeletin-go", NewModule: "github.com/acme/tool",
+	OldName: "ckeletin-go", NewName: "tool",
+}
+
+func TestRenameApply(t *testing.T) {
+	in := `import "github.com/peiman/ckeletin-go/cmd"
+binaryName = "ckeletin-go"
+config: $HOME/.ckeletin-go.yaml`
+	want := `import "github.com/acme/tool/cmd"
+binaryName = "tool"
+config: $HOME/.tool.yaml`
+	if got := testRename.Apply(in); got != want {
+		t.Errorf("Apply() = %q, want %q", got, want)
+	}
+
+	// A module path containing the old name is not rewritten twice
+	r := Rename{OldModule: "example.com/app", NewModule: "example.com/app-cli", OldName: "app", NewName: "app-cli"}
+	if got := r.Apply("example.com/app app"); got != "example.com/app-cli app-cli" {
+		t.Errorf("Apply() = %q", got)
+	}
+}
+
+func TestValidateModulePath(t *testing.T) {
+	for _, p := range []string{"github.com/acme/tool", "example.com/x/v2", "tool"} {
+		if err := ValidateModulePath(p); err != nil {
+			t.Errorf("ValidateModulePath(%q) error = %v", p, err)
+		}
+	}
+	for _, p := range []string{"", "/abs/path", "Github.com/x", "a/../b", "a b"} {
+		if err := ValidateModulePath(p); err == nil {
+			t.Errorf("ValidateModulePath(%q) expected an error", p)
+		}
+	}
+}
+
+func TestNameFromModule(t *testing.T) {
+	tests := map[string]string{
+		"github.com/acme/tool":    "tool",
+		"github.com/acme/tool/v2": "tool",
+		"tool":                    "tool",
+		"example.com/v":           "v",
+	}
+	for in, want := range tests {
+		if got := NameFromModule(in); got != want {
+			t.Errorf("NameFromModule(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestReadModulePath(t *testing.T) {
+	dir := t.TempDir()
+	if _, err := ReadModulePath(dir); err == nil {
+		t.Error("Expected an error without go.mod")
+	}
+	if err := os.WriteFile(filepath.Join(dir, "go.mod"), []byte("// comment\nmodule github.com/peiman/ckeletin-go\n\ngo 1.23\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if got, err := ReadModulePath(dir); err != nil || got != "github.com/peiman/ckeletin-go" {
+		t.Errorf("ReadModulePath() = %q, %v", got, err)
+	}
+}
+
+func TestReadBinaryName(t *testing.T) {
+	dir := t.TempDir()
+	if got := ReadBinaryName(dir, "github.com/acme/tool/v2"); got != "tool" {
+		t.Errorf("ReadBinaryName() without cmd/root.go = %q, want %q", got, "tool")
+	}
+	if err := os.MkdirAll(filepath.Join(dir, "cmd"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	root := "var (\n\tcfgFile    string\n\tbinaryName = \"my-cli\"\n)\n"
+	if err := os.WriteFile(filepath.Join(dir, "cmd", "root.go"), []byte(root), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if got := ReadBinaryName(dir, "github.com/acme/tool"); got != "my-cli" {
+		t.Errorf("ReadBinaryName() = %q, want %q", got, "my-cli")
+	}
+}
+
+func writeProject(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	files := map[string]string{
+		"go.mod":                 "module github.com/peiman/ckeletin-go\n",
+		"main.go":                "package main\n\nimport \"github.com/peiman/ckeletin-go/cmd\"\n",
+		"cmd/root.go":            "binaryName = \"ckeletin-go\"\n",
+		"docs/ckeletin-go.md":    "# ckeletin-go\n",
+		"assets/logo.bin":        "ckeletin-go\x00\x01",
+		".git/config":            "[core]\n",
+		"dist/ckeletin-go_linux": "binary",
+		"scripts/release.sh":     "#!/bin/sh\necho ckeletin-go\n",
+	}
+	for name, content := range files {
+		p := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.Chmod(filepath.Join(dir, "scripts/release.sh"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	return dir
+}
+
+func TestListFiles_NoGit(t *testing.T) {
+	dir := writeProject(t)
+	// Not a real repository: the walk must skip .git and dist itself
+	if err := os.RemoveAll(filepath.Join(dir, ".git")); err != nil {
+		t.Fatal(err)
+	}
+
+	files, err := ListFiles(dir)
+	if err != nil {
+		t.Fatalf("ListFiles() error = %v", err)
+	}
+	sort.Strings(files)
+	want := []string{"assets/logo.bin", "cmd/root.go", "docs/ckeletin-go.md", "go.mod", "main.go", "scripts/release.sh"}
+	if !reflect.DeepEqual(files, want) {
+		t.Errorf("ListFiles() = %v, want %v", files, want)
+	}
+}
+
+func TestListFiles_Git(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not found")
+	}
+	dir := writeProject(t)
+	if err := os.RemoveAll(filepath.Join(dir, ".git")); err != nil {
+		t.Fatal(err)
+	}
+	// A project created earlier, with its own repository, and a symlink
+	nested := filepath.Join(dir, "one")
+	for _, args := range [][]string{{"init", "-q", dir}, {"init", "-q", nested}} {
+		if out, err := exec.Command("git", args...).CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v: %s", args, err, out)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(nested, "go.mod"), []byte("module one\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink("main.go", filepath.Join(dir, "link.go")); err != nil {
+		t.Fatal(err)
+	}
+
+	files, err := ListFiles(dir)
+	if err != nil {
+		t.Fatalf("ListFiles() error = %v", err)
+	}
+	sort.Strings(files)
+	want := []string{"assets/logo.bin", "cmd/root.go", "dist/ckeletin-go_linux", "docs/ckeletin-go.md", "go.mod", "main.go", "scripts/release.sh"}
+	if !reflect.DeepEqual(files, want) {
+		t.Errorf("ListFiles() = %v, want %v", files, want)
+	}
+}
+
+func TestExcludeDir(t *testing.T) {
+	files := []string{"go.mod", "one/go.mod", "one/cmd/root.go", "oneself.go"}
+	if got, want := ExcludeDir(files, "src", "src/one"), []string{"go.mod", "oneself.go"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("ExcludeDir() = %v, want %v", got, want)
+	}
+	for _, sub := range []string{"other", "src", "."} {
+		if got := ExcludeDir(files, "src", sub); !reflect.DeepEqual(got, files) {
+			t.Errorf("ExcludeDir(%q) = %v, want all files", sub, got)
+		}
+	}
+}
+
+func TestCopy(t *testing.T) {
+	src := writeProject(t)
+	files := []string{"go.mod", "main.go", "cmd/root.go", "docs/ckeletin-go.md", "assets/logo.bin", "scripts/release.sh"}
+	dst := filepath.Join(t.TempDir(), "tool")
+
+	if err := Copy(src, dst, files, testRename, nil); err != nil {
+		t.Fatalf("Copy() error = %v", err)
+	}
+
+	read := func(name string) string {
+		data, err := os.ReadFile(filepath.Join(dst, filepath.FromSlash(name)))
+		if err != nil {
+			t.Fatalf("Missing %s: %v", name, err)
+		}
+		return string(data)
+	}
+	if got := read("go.mod"); got != "module github.com/acme/tool\n" {
+		t.Errorf("go.mod = %q", got)
+	}
+	if got := read("cmd/root.go"); !strings.Contains(got, `"tool"`) {
+		t.Errorf("cmd/root.go = %q", got)
+	}
+	if got := read("docs/tool.md"); got != "# tool\n" {
+		t.Errorf("renamed file = %q", got)
+	}
+	if got := read("assets/logo.bin"); got != "ckeletin-go\x00\x01" {
+		t.Errorf("binary file was rewritten: %q", got)
+	}
+	if info, err := os.Stat(filepath.Join(dst, "scripts/release.sh")); err != nil || info.Mode().Perm()&0o100 == 0 {
+		t.Errorf("Expected executable script, got %v, %v", info, err)
+	}
+
+	if err := Copy(src, dst, files, testRename, nil); err == nil || !strings.Contains(err.Error(), "not empty") {
+		t.Errorf("Expected error for non-empty destination, got %v", err)
+	}
+}
+
+func TestCopy_FormatsGo(t *testing.T) {
+	src := t.TempDir()
+	files := map[string]string{
+		"main.go": "package main\n\nimport (\n\t\"github.com/old/tool/x\"\n\t\"github.com/lib/y\"\n)\n\nvar a = x.A\n\n// scaffold:audit\n\nvar b = y.B\n\n// scaffold:end\n",
+		"bad.go":  "package main\n\nfunc {\n",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(src, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	dst := filepath.Join(t.TempDir(), "tool")
+	r := Rename{OldModule: "github.com/old/tool", NewModule: "github.com/acme/tool"}
+
+	if err := Copy(src, dst, []string{"main.go", "bad.go"}, r, map[string]bool{"audit": true}); err != nil {
+		t.Fatalf("Copy() error = %v", err)
+	}
+	want := "package main\n\nimport (\n\t\"github.com/acme/tool/x\"\n\t\"github.com/lib/y\"\n)\n\nvar a = x.A\n"
+	if got, _ := os.ReadFile(filepath.Join(dst, "main.go")); string(got) != want {
+		t.Errorf("main.go = %q, want %q", got, want)
+	}
+	if got, _ := os.ReadFile(filepath.Join(dst, "bad.go")); string(got) != files["bad.go"] {
+		t.Errorf("Expected unparsable file to be copied as is, got %q", got)
+	}
+}
+
+func TestPlanAndApply(t *testing.T) {
+	dir := writeProject(t)
+	files := []string{"go.mod", "main.go", "cmd/root.go", "docs/ckeletin-go.md", "assets/logo.bin", "scripts/release.sh"}
+
+	unchanged := Rename{OldModule: "github.com/peiman/ckeletin-go", NewModule: "github.com/peiman/ckeletin-go"}
+	if changes, err := Plan(dir, files, unchanged); err != nil || len(changes) != 0 {
+		t.Errorf("Plan() without renaming = %d changes, %v", len(changes), err)
+	}
+
+	changes, err := Plan(dir, files, testRename)
+	if err != nil {
+		t.Fatalf("Plan() error = %v", err)
+	}
+	// assets/logo.bin contains the name but is binary and keeps its file name
+	if len(changes) != 5 {
+		t.Fatalf("Plan() = %d changes, want 5", len(changes))
+	}
+
+	var diff strings.Builder
+	for _, c := range changes {
+		diff.WriteString(c.Diff())
+	}
+	for _, want := range []string{
+		"@@ go.mod:1\n-module github.com/peiman/ckeletin-go\n+module github.com/acme/tool\n",
+		"rename docs/ckeletin-go.md => docs/tool.md\n",
+		"@@ scripts/release.sh:2\n-echo ckeletin-go\n+echo tool\n",
+	} {
+		if !strings.Contains(diff.String(), want) {
+			t.Errorf("Diff missing %q:\n%s", want, diff.String())
+		}
+	}
+
+	// Planning writes nothing
+	if data, _ := os.ReadFile(filepath.Join(dir, "go.mod")); !strings.Contains(string(data), "ckeletin-go") {
+		t.Fatal("Plan() modified go.mod")
+	}
+
+	if err := Apply(dir, changes); err != nil {
+		t.Fatalf("Apply() error = %v", err)
+	}
+	if data, _ := os.ReadFile(filepath.Join(dir, "go.mod")); string(data) != "module github.com/acme/tool\n" {
+		t.Errorf("go.mod = %q", data)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "docs", "ckeletin-go.md")); !os.IsNotExist(err) {
+		t.Error("Expected the old file name to be removed")
+	}
+	if data, _ := os.ReadFile(filepath.Join(dir, "docs", "tool.md")); string(data) != "# tool\n" {
+		t.Errorf("docs/tool.md = %q", data)
+	}
+	if info, err := os.Stat(filepath.Join(dir, "scripts", "release.sh")); err != nil || info.Mode().Perm()&0o100 == 0 {
+		t.Errorf("Expected the script to stay executable, got %v, %v", info, err)
+	}
+}
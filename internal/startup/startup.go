@@ -0,0 +1,81 @@
+// internal/startup/startup.go
+
+// Package startup measures the phases of starting a command, so a slow phase
+// shows up in a breakdown instead of only in the total time.
+package startup
+
+import (
+	"fmt"
+	"io"
+	"sync"
+	"text/tabwriter"
+	"time"
+)
+
+// Phase is a step of the startup and how long it took
+type Phase struct {
+	Name     string
+	Duration time.Duration
+}
+
+// Timer records consecutive phases: each phase lasts from the end of the
+// previous one, or the start of the timer, until it is marked
+type Timer struct {
+	mu     sync.Mutex
+	start  time.Time
+	last   time.Time
+	phases []Phase
+}
+
+// NewTimer returns a timer whose first phase began at start
+func NewTimer(start time.Time) *Timer {
+	return &Timer{start: start, last: start}
+}
+
+// Mark ends the phase name now
+func (t *Timer) Mark(name string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	now := time.Now()
+	t.phases = append(t.phases, Phase{Name: name, Duration: now.Sub(t.last)})
+	t.last = now
+}
+
+// Phases returns the phases marked so far
+func (t *Timer) Phases() []Phase {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return append([]Phase(nil), t.phases...)
+}
+
+// Total returns the time from the start to the last mark
+func (t *Timer) Total() time.Duration {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.last.Sub(t.start)
+}
+
+// Write prints one line per phase with its duration and the time since the
+// start, followed by the total
+func (t *Timer) Write(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(tw, "PHASE\tTIME\tSINCE START\t")
+	var since time.Duration
+	for _, p := range t.Phases() {
+		since += p.Duration
+		fmt.Fprintf(tw, "%s\t%s\t%s\t\n", p.Name, round(p.Duration), round(since))
+	}
+	fmt.Fprintf(tw, "total\t%s\t\t\n", round(t.Total()))
+	return tw.Flush()
+}
+
+// round keeps three significant digits of d, enough to compare phases
+func round(d time.Duration) time.Duration {
+	switch {
+	case d >= time.Second:
+		return d.Round(10 * time.Millisecond)
+	case d >= time.Millisecond:
+		return d.Round(10 * time.Microsecond)
+	}
+	return d.Round(time.Microsecond)
+}
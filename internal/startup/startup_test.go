@@ -0,0 +1,55 @@
+// internal/startup/startup_test.go
+
+package startup
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTimer(t *testing.T) {
+	start := time.Now().Add(-time.Second)
+	timer := NewTimer(start)
+	timer.Mark("init")
+	time.Sleep(2 * time.Millisecond)
+	timer.Mark("config")
+
+	phases := timer.Phases()
+	if len(phases) != 2 || phases[0].Name != "init" || phases[1].Name != "config" {
+		t.Fatalf("Phases() = %+v, want init and config", phases)
+	}
+	if phases[0].Duration < time.Second {
+		t.Errorf("init took %s, want at least the second before the first mark", phases[0].Duration)
+	}
+	if phases[1].Duration < 2*time.Millisecond {
+		t.Errorf("config took %s, want at least 2ms", phases[1].Duration)
+	}
+	if total := timer.Total(); total != phases[0].Duration+phases[1].Duration {
+		t.Errorf("Total() = %s, want the sum of the phases", total)
+	}
+
+	var buf bytes.Buffer
+	if err := timer.Write(&buf); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 4 || !strings.Contains(lines[0], "PHASE") || !strings.Contains(lines[1], "init") ||
+		!strings.Contains(lines[2], "config") || !strings.Contains(lines[3], "total") {
+		t.Errorf("Write() =\n%s\nwant a header, one line per phase and the total", buf.String())
+	}
+}
+
+func TestRound(t *testing.T) {
+	tests := map[time.Duration]time.Duration{
+		1234567 * time.Nanosecond:    1230 * time.Microsecond,
+		1500 * time.Nanosecond:       2 * time.Microsecond,
+		1234567890 * time.Nanosecond: 1230 * time.Millisecond,
+	}
+	for in, want := range tests {
+		if got := round(in); got != want {
+			t.Errorf("round(%s) = %s, want %s", in, got, want)
+		}
+	}
+}
@@ -0,0 +1,159 @@
+// internal/store/store.go
+
+// Package store is a small transactional key/value store for state that must
+// survive between runs, such as update checks and run history. It is backed by a
+// single bbolt file, so a crash mid-write never leaves a partially written value.
+//
+// The file is opened for each transaction and closed again, so concurrent
+// invocations only wait for each other while a transaction is running.
+package store
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/peiman/ckeletin-go/internal/xdg"
+	bolt "go.etcd.io/bbolt"
+)
+
+// lockTimeout is how long a transaction waits for another process holding the file
+const lockTimeout = 2 * time.Second
+
+// Store is a key/value store grouped in named buckets
+type Store struct {
+	path string
+}
+
+// New returns a store backed by the file at path. The file is created on the first write.
+func New(path string) *Store {
+	return &Store{path: path}
+}
+
+// Default returns the store for app in its XDG state directory in dirs
+func Default(dirs xdg.Dirs, app string) (*Store, error) {
+	path, err := dirs.StateFile(app, "state.db")
+	if err != nil {
+		return nil, err
+	}
+	return New(path), nil
+}
+
+// Path returns the file backing the store
+func (s *Store) Path() string {
+	return s.path
+}
+
+// View runs fn in a read-only transaction
+func (s *Store) View(fn func(*Tx) error) error {
+	if _, err := os.Stat(s.path); errors.Is(err, fs.ErrNotExist) {
+		return fn(&Tx{})
+	}
+	db, err := bolt.Open(s.path, 0o600, &bolt.Options{Timeout: lockTimeout, ReadOnly: true})
+	if err != nil {
+		return fmt.Errorf("failed to open state store: %w", err)
+	}
+	defer db.Close()
+	return db.View(func(tx *bolt.Tx) error { return fn(&Tx{tx: tx}) })
+}
+
+// Update runs fn in a read-write transaction. Changes are committed only when fn returns nil.
+func (s *Store) Update(fn func(*Tx) error) error {
+	db, err := bolt.Open(s.path, 0o600, &bolt.Options{Timeout: lockTimeout})
+	if err != nil {
+		return fmt.Errorf("failed to open state store: %w", err)
+	}
+	defer db.Close()
+	return db.Update(func(tx *bolt.Tx) error { return fn(&Tx{tx: tx}) })
+}
+
+// GetJSON decodes the value of key in bucket into v and reports whether it was found
+func (s *Store) GetJSON(bucket, key string, v interface{}) (bool, error) {
+	var found bool
+	err := s.View(func(tx *Tx) error {
+		data := tx.Get(bucket, key)
+		if data == nil {
+			return nil
+		}
+		found = true
+		return json.Unmarshal(data, v)
+	})
+	return found, err
+}
+
+// PutJSON stores v encoded as JSON under key in bucket
+func (s *Store) PutJSON(bucket, key string, v interface{}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return s.Update(func(tx *Tx) error { return tx.Put(bucket, key, data) })
+}
+
+// Delete removes key from bucket
+func (s *Store) Delete(bucket, key string) error {
+	return s.Update(func(tx *Tx) error { return tx.Delete(bucket, key) })
+}
+
+// Tx is a transaction on the store
+type Tx struct {
+	tx *bolt.Tx
+}
+
+func (t *Tx) bucket(name string) *bolt.Bucket {
+	if t.tx == nil {
+		return nil
+	}
+	return t.tx.Bucket([]byte(name))
+}
+
+// Get returns a copy of the value of key in bucket, or nil if it does not exist
+func (t *Tx) Get(bucket, key string) []byte {
+	b := t.bucket(bucket)
+	if b == nil {
+		return nil
+	}
+	v := b.Get([]byte(key))
+	if v == nil {
+		return nil
+	}
+	// bbolt values are only valid during the transaction.
+	return append([]byte(nil), v...)
+}
+
+// Put sets key in bucket, creating the bucket if needed
+func (t *Tx) Put(bucket, key string, value []byte) error {
+	b, err := t.tx.CreateBucketIfNotExists([]byte(bucket))
+	if err != nil {
+		return fmt.Errorf("failed to create bucket %q: %w", bucket, err)
+	}
+	return b.Put([]byte(key), value)
+}
+
+// Delete removes key from bucket; missing keys are not an error
+func (t *Tx) Delete(bucket, key string) error {
+	b := t.bucket(bucket)
+	if b == nil {
+		return nil
+	}
+	return b.Delete([]byte(key))
+}
+
+// Keys returns the keys in bucket in sorted order
+func (t *Tx) Keys(bucket string) []string {
+	b := t.bucket(bucket)
+	if b == nil {
+		return nil
+	}
+	var keys []string
+	_ = b.ForEach(func(k, _ []byte) error {
+		keys = append(keys, string(k))
+		return nil
+	})
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,93 @@
+// internal/store/store_test.go
+
+package store
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/peiman/ckeletin-go/internal/xdg"
+)
+
+func newStore(t *testing.T) *Store {
+	t.Helper()
+	return New(filepath.Join(t.TempDir(), "state.db"))
+}
+
+func TestStore_JSON(t *testing.T) {
+	s := newStore(t)
+
+	type entry struct {
+		Name  string `json:"name"`
+		Count int    `json:"count"`
+	}
+
+	var got entry
+	found, err := s.GetJSON("history", "ping", &got)
+	if err != nil || found {
+		t.Fatalf("GetJSON() on empty store = %v, %v, want false, nil", found, err)
+	}
+
+	if err := s.PutJSON("history", "ping", entry{Name: "ping", Count: 2}); err != nil {
+		t.Fatalf("PutJSON() error = %v", err)
+	}
+	found, err = s.GetJSON("history", "ping", &got)
+	if err != nil || !found {
+		t.Fatalf("GetJSON() = %v, %v, want true, nil", found, err)
+	}
+	if got != (entry{Name: "ping", Count: 2}) {
+		t.Errorf("GetJSON() decoded %+v", got)
+	}
+
+	if err := s.Delete("history", "ping"); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if found, _ := s.GetJSON("history", "ping", &got); found {
+		t.Error("Expected key to be deleted")
+	}
+}
+
+func TestStore_UpdateRollsBackOnError(t *testing.T) {
+	s := newStore(t)
+	if err := s.Update(func(tx *Tx) error { return tx.Put("b", "kept", []byte("1")) }); err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+
+	boom := errors.New("boom")
+	err := s.Update(func(tx *Tx) error {
+		if err := tx.Put("b", "discarded", []byte("2")); err != nil {
+			return err
+		}
+		return boom
+	})
+	if !errors.Is(err, boom) {
+		t.Fatalf("Update() error = %v, want %v", err, boom)
+	}
+
+	err = s.View(func(tx *Tx) error {
+		if keys := tx.Keys("b"); len(keys) != 1 || keys[0] != "kept" {
+			t.Errorf("Keys() = %v, want [kept]", keys)
+		}
+		if v := tx.Get("b", "discarded"); v != nil {
+			t.Errorf("Expected failed transaction to be rolled back, got %q", v)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("View() error = %v", err)
+	}
+}
+
+func TestDefault(t *testing.T) {
+	base := t.TempDir()
+	t.Setenv("XDG_STATE_HOME", base)
+
+	s, err := Default(xdg.Dirs{}, "myapp")
+	if err != nil {
+		t.Fatalf("Default() error = %v", err)
+	}
+	if want := filepath.Join(base, "myapp", "state.db"); s.Path() != want {
+		t.Errorf("Path() = %q, want %q", s.Path(), want)
+	}
+}
@@ -0,0 +1,109 @@
+// internal/taskfile/taskfile.go
+
+// Package taskfile finds a project's Taskfile (https://taskfile.dev) and lists
+// the tasks it defines, so they can be run through the CLI.
+package taskfile
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Names are the Taskfile names Task looks for, in its order of preference
+var Names = []string{
+	"Taskfile.yml", "taskfile.yml", "Taskfile.yaml", "taskfile.yaml",
+	"Taskfile.dist.yml", "taskfile.dist.yml", "Taskfile.dist.yaml", "taskfile.dist.yaml",
+}
+
+// ErrNotFound is returned by Find when no directory up to the root has a Taskfile
+var ErrNotFound = errors.New("no Taskfile found")
+
+// Task is a task that can be called from the command line
+type Task struct {
+	Name    string   `json:"name"`
+	Desc    string   `json:"desc"`
+	Aliases []string `json:"aliases,omitempty"`
+}
+
+// definition is the part of a task definition used here
+type definition struct {
+	Desc     string   `yaml:"desc"`
+	Aliases  []string `yaml:"aliases"`
+	Internal bool     `yaml:"internal"`
+}
+
+// Find returns the path of the Taskfile in dir or the nearest parent directory
+func Find(dir string) (string, error) {
+	for {
+		for _, name := range Names {
+			path := filepath.Join(dir, name)
+			if _, err := os.Stat(path); err == nil {
+				return path, nil
+			} else if !errors.Is(err, fs.ErrNotExist) {
+				return "", err
+			}
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", ErrNotFound
+		}
+		dir = parent
+	}
+}
+
+// Load returns the tasks of the Taskfile at path sorted by name. Internal tasks
+// are left out; tasks of included Taskfiles are not listed.
+func Load(path string) ([]Task, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read Taskfile: %w", err)
+	}
+	var file struct {
+		Tasks yaml.Node `yaml:"tasks"`
+	}
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	if file.Tasks.Kind != yaml.MappingNode {
+		return nil, nil
+	}
+
+	var tasks []Task
+	content := file.Tasks.Content
+	for i := 0; i+1 < len(content); i += 2 {
+		var def definition
+		// Tasks can also be a single command or a list of commands
+		if content[i+1].Kind == yaml.MappingNode {
+			if err := content[i+1].Decode(&def); err != nil {
+				return nil, fmt.Errorf("failed to parse task %q in %s: %w", content[i].Value, path, err)
+			}
+		}
+		if def.Internal {
+			continue
+		}
+		tasks = append(tasks, Task{Name: content[i].Value, Desc: def.Desc, Aliases: def.Aliases})
+	}
+	sort.Slice(tasks, func(i, j int) bool { return tasks[i].Name < tasks[j].Name })
+	return tasks, nil
+}
+
+// Lookup returns the task called name, either by its name or an alias
+func Lookup(tasks []Task, name string) (Task, bool) {
+	for _, t := range tasks {
+		if t.Name == name {
+			return t, true
+		}
+		for _, a := range t.Aliases {
+			if a == name {
+				return t, true
+			}
+		}
+	}
+	return Task{}, false
+}
@@ -0,0 +1,94 @@
+// internal/taskfile/taskfile_test.go
+
+package taskfile
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+const testTaskfile = `version: '3'
+
+tasks:
+  test:
+    desc: Run tests
+    cmds:
+      - go test ./...
+  build:
+    desc: Build the binary
+    aliases: [b]
+    cmds:
+      - go build
+  fmt: gofmt -w .
+  tidy:
+    - go mod tidy
+  helper:
+    internal: true
+    cmds:
+      - echo helper
+`
+
+func writeTaskfile(t *testing.T, dir, name, content string) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestFind(t *testing.T) {
+	root := t.TempDir()
+	sub := filepath.Join(root, "a", "b")
+	if err := os.MkdirAll(sub, 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := Find(sub); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("Find() without Taskfile error = %v", err)
+	}
+
+	want := writeTaskfile(t, root, "Taskfile.yaml", testTaskfile)
+	got, err := Find(sub)
+	if err != nil || got != want {
+		t.Errorf("Find() = %q, %v, want %q", got, err, want)
+	}
+
+	// The preferred name wins when several exist
+	want = writeTaskfile(t, root, "Taskfile.yml", testTaskfile)
+	if got, _ := Find(root); got != want {
+		t.Errorf("Find() = %q, want %q", got, want)
+	}
+}
+
+func TestLoad(t *testing.T) {
+	path := writeTaskfile(t, t.TempDir(), "Taskfile.yml", testTaskfile)
+
+	tasks, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	var names []string
+	for _, task := range tasks {
+		names = append(names, task.Name)
+	}
+	if len(names) != 4 || names[0] != "build" || names[1] != "fmt" || names[2] != "test" || names[3] != "tidy" {
+		t.Errorf("Load() tasks = %v, want sorted build, fmt, test, tidy without internal tasks", names)
+	}
+	if tasks[0].Desc != "Build the binary" || len(tasks[0].Aliases) != 1 {
+		t.Errorf("Unexpected build task %+v", tasks[0])
+	}
+
+	if task, ok := Lookup(tasks, "b"); !ok || task.Name != "build" {
+		t.Errorf("Lookup(alias) = %+v, %v", task, ok)
+	}
+	if _, ok := Lookup(tasks, "helper"); ok {
+		t.Error("Lookup() found an internal task")
+	}
+
+	if _, err := Load(writeTaskfile(t, t.TempDir(), "Taskfile.yml", "tasks: [")); err == nil {
+		t.Error("Expected an error for invalid YAML")
+	}
+}
@@ -0,0 +1,332 @@
+// internal/telemetry/telemetry.go
+
+// Package telemetry records anonymous usage events to a local queue and uploads
+// them in batches. Nothing is recorded unless telemetry is explicitly enabled,
+// and nothing leaves the machine unless an upload endpoint is configured as well.
+//
+// Each event is one JSON object per line in the queue file, and a batch is uploaded
+// as a JSON array with the same schema:
+//
+//	{
+//	  "command":     "ping",                 // command path below the binary name, no arguments
+//	  "duration_ms": 42,                     // wall-clock run time in milliseconds
+//	  "exit_status": "success",              // "success" or "error"; error messages are never recorded
+//	  "version":     "1.2.0",                // version of the binary
+//	  "os":          "linux",                // runtime.GOOS
+//	  "arch":        "amd64",                // runtime.GOARCH
+//	  "date":        "2024-05-01"            // UTC day, without time of day
+//	}
+//
+// No arguments, flag values, paths, hostnames, user names or identifiers are collected.
+package telemetry
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"os"
+	"time"
+)
+
+// Exit statuses recorded in Event.ExitStatus
+const (
+	StatusSuccess = "success"
+	StatusError   = "error"
+)
+
+// DefaultBatchSize is the number of queued events that triggers an upload
+const DefaultBatchSize = 20
+
+// DefaultMaxQueued is the number of events kept in the queue while they cannot
+// be uploaded
+const DefaultMaxQueued = 1000
+
+// After a failed upload, Record waits retryDelay before uploading again,
+// doubling the wait with every further failure up to maxRetryDelay
+const (
+	retryDelay    = 5 * time.Minute
+	maxRetryDelay = 24 * time.Hour
+)
+
+// Event is a single anonymous usage record
+type Event struct {
+	Command    string `json:"command"`
+	DurationMS int64  `json:"duration_ms"`
+	ExitStatus string `json:"exit_status"`
+	Version    string `json:"version"`
+	OS         string `json:"os"`
+	Arch       string `json:"arch"`
+	Date       string `json:"date"`
+}
+
+// NewEvent builds an event, reducing the start time to its UTC day
+func NewEvent(command string, start time.Time, duration time.Duration, err error, version, goos, goarch string) Event {
+	status := StatusSuccess
+	if err != nil {
+		status = StatusError
+	}
+	return Event{
+		Command:    command,
+		DurationMS: duration.Milliseconds(),
+		ExitStatus: status,
+		Version:    version,
+		OS:         goos,
+		Arch:       goarch,
+		Date:       start.UTC().Format(time.DateOnly),
+	}
+}
+
+// Client appends events to QueuePath and uploads them to Endpoint.
+// With an empty Endpoint the client is fully offline: events stay in the local queue.
+type Client struct {
+	QueuePath string
+	Endpoint  string
+	BatchSize int
+	// MaxQueued caps the queue, DefaultMaxQueued if zero. Beyond it the oldest
+	// events are dropped.
+	MaxQueued int
+	Client    *http.Client
+
+	// now returns the current time, can be replaced in tests
+	now func() time.Time
+}
+
+func (c *Client) clock() time.Time {
+	if c.now != nil {
+		return c.now()
+	}
+	return time.Now()
+}
+
+func (c *Client) batchSize() int {
+	if c.BatchSize > 0 {
+		return c.BatchSize
+	}
+	return DefaultBatchSize
+}
+
+func (c *Client) maxQueued() int {
+	if c.MaxQueued > 0 {
+		return c.MaxQueued
+	}
+	return DefaultMaxQueued
+}
+
+func (c *Client) httpClient() *http.Client {
+	if c.Client != nil {
+		return c.Client
+	}
+	return http.DefaultClient
+}
+
+// Record appends e to the queue, dropping the oldest events beyond MaxQueued,
+// and uploads the queue once it holds a full batch. After a failed upload it
+// waits before uploading again, longer with every failure.
+func (c *Client) Record(ctx context.Context, e Event) error {
+	if err := c.append([]Event{e}); err != nil {
+		return err
+	}
+	pending, err := c.Pending()
+	if err != nil {
+		return err
+	}
+	if len(pending) > c.maxQueued() {
+		pending = pending[len(pending)-c.maxQueued():]
+		if err := c.rewrite(pending); err != nil {
+			return err
+		}
+	}
+
+	if c.Endpoint == "" || len(pending) < c.batchSize() {
+		return nil
+	}
+	if b := c.loadBackoff(); c.clock().Before(b.RetryAt) {
+		return nil
+	}
+	_, err = c.Flush(ctx)
+	return err
+}
+
+// Pending returns the queued events. Unreadable lines are skipped.
+func (c *Client) Pending() ([]Event, error) {
+	return readQueue(c.QueuePath)
+}
+
+// Flush uploads all queued events and returns how many were sent.
+// Events are put back in the queue when the upload fails.
+func (c *Client) Flush(ctx context.Context) (int, error) {
+	if c.Endpoint == "" {
+		return 0, nil
+	}
+
+	// Move the queue aside first so events recorded concurrently are not lost when it is removed.
+	sending := fmt.Sprintf("%s.%d.sending", c.QueuePath, os.Getpid())
+	if err := os.Rename(c.QueuePath, sending); err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return 0, nil
+		}
+		return 0, fmt.Errorf("failed to read telemetry queue: %w", err)
+	}
+	defer os.Remove(sending)
+
+	events, err := readQueue(sending)
+	if err != nil {
+		return 0, err
+	}
+	if len(events) == 0 {
+		return 0, nil
+	}
+
+	if err := c.upload(ctx, events); err != nil {
+		if requeueErr := c.append(events); requeueErr != nil {
+			err = errors.Join(err, requeueErr)
+		}
+		if backoffErr := c.backOff(); backoffErr != nil {
+			err = errors.Join(err, backoffErr)
+		}
+		return 0, err
+	}
+	_ = os.Remove(c.backoffPath())
+	return len(events), nil
+}
+
+// backoff records failed uploads next to the queue
+type backoff struct {
+	Failures int       `json:"failures"`
+	RetryAt  time.Time `json:"retry_at"`
+}
+
+func (c *Client) backoffPath() string {
+	return c.QueuePath + ".backoff"
+}
+
+// loadBackoff returns the recorded failures, none if the file is missing or unreadable
+func (c *Client) loadBackoff() backoff {
+	var b backoff
+	if data, err := os.ReadFile(c.backoffPath()); err == nil {
+		_ = json.Unmarshal(data, &b)
+	}
+	return b
+}
+
+// backOff records another failed upload and when to retry
+func (c *Client) backOff() error {
+	b := c.loadBackoff()
+	b.Failures++
+	delay := retryDelay
+	for i := 1; i < b.Failures && delay < maxRetryDelay; i++ {
+		delay *= 2
+	}
+	b.RetryAt = c.clock().Add(min(delay, maxRetryDelay))
+
+	data, err := json.Marshal(b)
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(c.backoffPath(), data, 0o600); err != nil {
+		return fmt.Errorf("failed to write telemetry state: %w", err)
+	}
+	return nil
+}
+
+// Clear deletes the queue without uploading it
+func (c *Client) Clear() error {
+	if err := os.Remove(c.QueuePath); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("failed to clear telemetry queue: %w", err)
+	}
+	return nil
+}
+
+func (c *Client) upload(ctx context.Context, events []Event) error {
+	body, err := json.Marshal(events)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.Endpoint, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create telemetry request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.httpClient().Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to upload telemetry: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("failed to upload telemetry: unexpected status %s", resp.Status)
+	}
+	return nil
+}
+
+// rewrite replaces the queue with events. Events appended concurrently
+// between reading the queue and replacing it are lost.
+func (c *Client) rewrite(events []Event) error {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	for _, e := range events {
+		if err := enc.Encode(e); err != nil {
+			return err
+		}
+	}
+	tmp := fmt.Sprintf("%s.%d.tmp", c.QueuePath, os.Getpid())
+	if err := os.WriteFile(tmp, buf.Bytes(), 0o600); err != nil {
+		return fmt.Errorf("failed to write telemetry queue: %w", err)
+	}
+	if err := os.Rename(tmp, c.QueuePath); err != nil {
+		_ = os.Remove(tmp)
+		return fmt.Errorf("failed to write telemetry queue: %w", err)
+	}
+	return nil
+}
+
+func (c *Client) append(events []Event) error {
+	f, err := os.OpenFile(c.QueuePath, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		return fmt.Errorf("failed to open telemetry queue: %w", err)
+	}
+	defer f.Close()
+
+	// Write all lines in one call so concurrent appends do not interleave.
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	for _, e := range events {
+		if err := enc.Encode(e); err != nil {
+			return err
+		}
+	}
+	if _, err := f.Write(buf.Bytes()); err != nil {
+		return fmt.Errorf("failed to write telemetry queue: %w", err)
+	}
+	return nil
+}
+
+func readQueue(path string) ([]Event, error) {
+	f, err := os.Open(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read telemetry queue: %w", err)
+	}
+	defer f.Close()
+
+	var events []Event
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var e Event
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			continue
+		}
+		events = append(events, e)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read telemetry queue: %w", err)
+	}
+	return events, nil
+}
@@ -0,0 +1,210 @@
+// internal/telemetry/telemetry_test.go
+
+package telemetry
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+// collector is a test upload endpoint recording received batches
+type collector struct {
+	mu       sync.Mutex
+	batches  [][]Event
+	status   int
+	requests int
+}
+
+func (c *collector) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.requests++
+	if c.status != 0 {
+		w.WriteHeader(c.status)
+		return
+	}
+	var batch []Event
+	if err := json.NewDecoder(r.Body).Decode(&batch); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	c.batches = append(c.batches, batch)
+}
+
+func newClient(t *testing.T, endpoint string) *Client {
+	t.Helper()
+	return &Client{QueuePath: filepath.Join(t.TempDir(), "queue.jsonl"), Endpoint: endpoint, BatchSize: 3}
+}
+
+func testEvent(command string) Event {
+	start := time.Date(2024, 5, 1, 13, 45, 0, 0, time.UTC)
+	return NewEvent(command, start, 1500*time.Millisecond, nil, "1.0.0", "linux", "amd64")
+}
+
+func TestNewEvent(t *testing.T) {
+	start := time.Date(2024, 5, 1, 23, 30, 0, 0, time.FixedZone("east", -2*3600))
+	e := NewEvent("config watch", start, 42*time.Millisecond, errors.New("secret detail"), "1.0.0", "linux", "arm64")
+
+	want := Event{Command: "config watch", DurationMS: 42, ExitStatus: StatusError, Version: "1.0.0", OS: "linux", Arch: "arm64", Date: "2024-05-02"}
+	if e != want {
+		t.Errorf("NewEvent() = %+v, want %+v", e, want)
+	}
+}
+
+func TestRecord_Offline(t *testing.T) {
+	c := newClient(t, "")
+	for i := 0; i < 5; i++ {
+		if err := c.Record(context.Background(), testEvent("ping")); err != nil {
+			t.Fatalf("Record() error = %v", err)
+		}
+	}
+
+	pending, err := c.Pending()
+	if err != nil {
+		t.Fatalf("Pending() error = %v", err)
+	}
+	if len(pending) != 5 {
+		t.Errorf("Expected 5 queued events in offline mode, got %d", len(pending))
+	}
+	if n, err := c.Flush(context.Background()); err != nil || n != 0 {
+		t.Errorf("Flush() offline = %d, %v, want 0, nil", n, err)
+	}
+}
+
+func TestRecord_UploadsFullBatch(t *testing.T) {
+	col := &collector{}
+	srv := httptest.NewServer(col)
+	defer srv.Close()
+	c := newClient(t, srv.URL)
+
+	for i := 0; i < 2; i++ {
+		if err := c.Record(context.Background(), testEvent("ping")); err != nil {
+			t.Fatalf("Record() error = %v", err)
+		}
+	}
+	if len(col.batches) != 0 {
+		t.Fatalf("Expected no upload before the batch is full, got %d", len(col.batches))
+	}
+
+	if err := c.Record(context.Background(), testEvent("version")); err != nil {
+		t.Fatalf("Record() error = %v", err)
+	}
+	if len(col.batches) != 1 || len(col.batches[0]) != 3 {
+		t.Fatalf("Expected one batch of 3 events, got %v", col.batches)
+	}
+	if col.batches[0][2].Command != "version" {
+		t.Errorf("Expected events in recording order, got %+v", col.batches[0])
+	}
+	if pending, _ := c.Pending(); len(pending) != 0 {
+		t.Errorf("Expected an empty queue after upload, got %d events", len(pending))
+	}
+}
+
+func TestFlush_FailureKeepsEvents(t *testing.T) {
+	col := &collector{status: http.StatusServiceUnavailable}
+	srv := httptest.NewServer(col)
+	defer srv.Close()
+	c := newClient(t, srv.URL)
+
+	for i := 0; i < 2; i++ {
+		if err := c.Record(context.Background(), testEvent("ping")); err != nil {
+			t.Fatalf("Record() error = %v", err)
+		}
+	}
+	if _, err := c.Flush(context.Background()); err == nil {
+		t.Fatal("Expected Flush() to fail")
+	}
+	if pending, _ := c.Pending(); len(pending) != 2 {
+		t.Errorf("Expected failed upload to keep 2 events, got %d", len(pending))
+	}
+}
+
+func TestRecord_DropsOldestEvents(t *testing.T) {
+	c := newClient(t, "")
+	c.MaxQueued = 3
+
+	for _, command := range []string{"one", "two", "three", "four", "five"} {
+		if err := c.Record(context.Background(), testEvent(command)); err != nil {
+			t.Fatalf("Record() error = %v", err)
+		}
+	}
+	pending, _ := c.Pending()
+	if len(pending) != 3 || pending[0].Command != "three" || pending[2].Command != "five" {
+		t.Errorf("Pending() = %+v, want the 3 most recent events", pending)
+	}
+}
+
+func TestRecord_BacksOffAfterFailedUpload(t *testing.T) {
+	col := &collector{status: http.StatusServiceUnavailable}
+	srv := httptest.NewServer(col)
+	defer srv.Close()
+	c := newClient(t, srv.URL)
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	c.now = func() time.Time { return now }
+
+	record := func() {
+		t.Helper()
+		_ = c.Record(context.Background(), testEvent("ping"))
+	}
+	for i := 0; i < 3; i++ {
+		record()
+	}
+	if col.requests != 1 {
+		t.Fatalf("Expected an upload once the batch is full, got %d requests", col.requests)
+	}
+
+	record()
+	now = now.Add(retryDelay - time.Second)
+	record()
+	if col.requests != 1 {
+		t.Errorf("Expected no upload within %s of the failure, got %d requests", retryDelay, col.requests)
+	}
+
+	now = now.Add(time.Second)
+	record()
+	if col.requests != 2 {
+		t.Fatalf("Expected a retry after %s, got %d requests", retryDelay, col.requests)
+	}
+	// The second failure doubles the wait
+	now = now.Add(retryDelay)
+	record()
+	if col.requests != 2 {
+		t.Errorf("Expected no upload within %s of the second failure, got %d requests", 2*retryDelay, col.requests)
+	}
+
+	col.mu.Lock()
+	col.status = 0
+	col.mu.Unlock()
+	now = now.Add(retryDelay)
+	record()
+	if col.requests != 3 || len(col.batches) != 1 || len(col.batches[0]) != 8 {
+		t.Fatalf("Expected all 8 queued events uploaded on the next retry, got %d requests and %v", col.requests, col.batches)
+	}
+	if _, err := os.Stat(c.backoffPath()); !os.IsNotExist(err) {
+		t.Errorf("Expected the backoff to be reset after a successful upload, got %v", err)
+	}
+}
+
+func TestClear(t *testing.T) {
+	c := newClient(t, "")
+	if err := c.Clear(); err != nil {
+		t.Errorf("Clear() without queue error = %v", err)
+	}
+	if err := c.Record(context.Background(), testEvent("ping")); err != nil {
+		t.Fatalf("Record() error = %v", err)
+	}
+	if err := c.Clear(); err != nil {
+		t.Fatalf("Clear() error = %v", err)
+	}
+	if pending, _ := c.Pending(); len(pending) != 0 {
+		t.Errorf("Expected empty queue after Clear(), got %d events", len(pending))
+	}
+}
@@ -2,7 +2,13 @@
 
 package ui
 
-import "github.com/charmbracelet/lipgloss"
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
 
 // ColorMap maps color names to their lipgloss.Color values
 var ColorMap = map[string]lipgloss.Color{
@@ -15,3 +21,20 @@ var ColorMap = map[string]lipgloss.Color{
 	"cyan":    lipgloss.Color("#00FFFF"),
 	"white":   lipgloss.Color("#FFFFFF"),
 }
+
+// ParseColor returns the color named s in ColorMap, the ANSI color number s
+// from 0 to 255 or the hex color s in the form "#rrggbb"
+func ParseColor(s string) (lipgloss.TerminalColor, error) {
+	if c, ok := ColorMap[strings.ToLower(s)]; ok {
+		return c, nil
+	}
+	if n, err := strconv.Atoi(s); err == nil && n >= 0 && n <= 255 {
+		return lipgloss.Color(s), nil
+	}
+	if len(s) == 7 && s[0] == '#' {
+		if _, err := strconv.ParseUint(s[1:], 16, 32); err == nil {
+			return lipgloss.Color(s), nil
+		}
+	}
+	return nil, fmt.Errorf("invalid color %q, use a name, a number from 0 to 255 or #rrggbb", s)
+}
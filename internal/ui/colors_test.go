@@ -0,0 +1,32 @@
+// internal/ui/colors_test.go
+
+package ui
+
+import (
+	"testing"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+func TestParseColor(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    lipgloss.TerminalColor
+		wantErr bool
+	}{
+		{"red", ColorMap["red"], false},
+		{"Blue", ColorMap["blue"], false},
+		{"208", lipgloss.Color("208"), false},
+		{"#0072b2", lipgloss.Color("#0072b2"), false},
+		{"256", nil, true},
+		{"#12345", nil, true},
+		{"#gggggg", nil, true},
+		{"teal", nil, true},
+	}
+	for _, tt := range tests {
+		got, err := ParseColor(tt.in)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("ParseColor(%q) = %v, %v, want %v", tt.in, got, err, tt.want)
+		}
+	}
+}
@@ -0,0 +1,92 @@
+// internal/ui/errorbox.go
+
+package ui
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/peiman/ckeletin-go/pkg/termcaps"
+)
+
+// ErrorBox describes an error shown to the user
+type ErrorBox struct {
+	// Message is the error itself
+	Message string
+	// Suggestions are likely intended commands or flags ("did you mean")
+	Suggestions []string
+	// Hint is an extra line such as how to get usage help
+	Hint string
+	// DocsURL links to the relevant documentation section
+	DocsURL string
+}
+
+// asciiBorder replaces the rounded border on terminals without unicode support
+var asciiBorder = lipgloss.Border{
+	Top: "-", Bottom: "-", Left: "|", Right: "|",
+	TopLeft: "+", TopRight: "+", BottomLeft: "+", BottomRight: "+",
+}
+
+// PrintErrorBox writes the error to out with the settings s. Terminals get a
+// bordered, colored box wrapped to the terminal width; other writers (pipes,
+// files, NO_COLOR) get plain lines starting with "Error:", so logs and scripts
+// keep working.
+func PrintErrorBox(out io.Writer, s Settings, e ErrorBox) error {
+	caps := s.Caps(out)
+	if caps.Color == termcaps.NoColor {
+		_, err := io.WriteString(out, wrap(e.plain(), s.lineWidth(caps)))
+		return err
+	}
+	r := lipgloss.NewRenderer(out)
+
+	failure := s.theme().Failure
+	title := r.NewStyle().Foreground(failure).Bold(true).Render("Error")
+	lines := []string{title + " " + e.Message}
+	if len(e.Suggestions) > 0 {
+		lines = append(lines, "", "Did you mean this?")
+		for _, s := range e.Suggestions {
+			lines = append(lines, "  "+r.NewStyle().Bold(true).Render(s))
+		}
+	}
+	faint := r.NewStyle().Faint(true)
+	if e.Hint != "" {
+		lines = append(lines, "", faint.Render(e.Hint))
+	}
+	if e.DocsURL != "" {
+		lines = append(lines, faint.Render("Docs: "+e.DocsURL))
+	}
+
+	border := lipgloss.RoundedBorder()
+	if !caps.Unicode {
+		border = asciiBorder
+	}
+	content := strings.Join(lines, "\n")
+	box := r.NewStyle().Border(border).BorderForeground(failure).Padding(0, 1)
+	width := caps.Width
+	if n := s.lineWidth(caps); n > 0 {
+		width = n
+	}
+	// Border and padding take 4 columns.
+	if lipgloss.Width(content)+4 > width {
+		box = box.Width(width - 2)
+	}
+	_, err := fmt.Fprintln(out, box.Render(content))
+	return err
+}
+
+func (e ErrorBox) plain() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Error: %s\n", e.Message)
+	if len(e.Suggestions) > 0 {
+		fmt.Fprintf(&b, "Did you mean this? %s\n", strings.Join(e.Suggestions, ", "))
+	}
+	if e.Hint != "" {
+		fmt.Fprintln(&b, e.Hint)
+	}
+	if e.DocsURL != "" {
+		fmt.Fprintf(&b, "Docs: %s\n", e.DocsURL)
+	}
+	return b.String()
+}
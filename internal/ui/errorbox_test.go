@@ -0,0 +1,39 @@
+// internal/ui/errorbox_test.go
+
+package ui
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestPrintErrorBox_Plain(t *testing.T) {
+	var buf bytes.Buffer
+	err := PrintErrorBox(&buf, Settings{}, ErrorBox{
+		Message:     `unknown command "pnig"`,
+		Suggestions: []string{"ping"},
+		Hint:        "Run 'app --help' for usage.",
+		DocsURL:     "https://example.com/#commands",
+	})
+	if err != nil {
+		t.Fatalf("PrintErrorBox() error = %v", err)
+	}
+
+	want := "Error: unknown command \"pnig\"\n" +
+		"Did you mean this? ping\n" +
+		"Run 'app --help' for usage.\n" +
+		"Docs: https://example.com/#commands\n"
+	if buf.String() != want {
+		t.Errorf("PrintErrorBox() = %q, want %q", buf.String(), want)
+	}
+}
+
+func TestPrintErrorBox_MessageOnly(t *testing.T) {
+	var buf bytes.Buffer
+	if err := PrintErrorBox(&buf, Settings{}, ErrorBox{Message: "boom"}); err != nil {
+		t.Fatalf("PrintErrorBox() error = %v", err)
+	}
+	if buf.String() != "Error: boom\n" {
+		t.Errorf("PrintErrorBox() = %q, want %q", buf.String(), "Error: boom\n")
+	}
+}
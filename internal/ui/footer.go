@@ -0,0 +1,57 @@
+// internal/ui/footer.go
+
+package ui
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/peiman/ckeletin-go/pkg/termcaps"
+)
+
+// Footer summarizes a finished command
+type Footer struct {
+	// Elapsed is the wall time of the command
+	Elapsed time.Duration
+	// PeakRSS is the peak memory use in bytes; 0 if unknown
+	PeakRSS int64
+	// ExitCode is the exit code of the process
+	ExitCode int
+	// Class names the kind of exit code, e.g. "usage"
+	Class string
+}
+
+// PrintFooter writes the footer to out as a single line with the settings s.
+// Terminals get it faint with the exit status in green or red; other writers
+// get plain text.
+func PrintFooter(out io.Writer, s Settings, f Footer) error {
+	parts := []string{"time " + f.Elapsed.Round(time.Millisecond).String()}
+	if f.PeakRSS > 0 {
+		parts = append(parts, "peak RSS "+FormatBytes(f.PeakRSS))
+	}
+	status := fmt.Sprintf("exit %d (%s)", f.ExitCode, f.Class)
+
+	caps := s.Caps(out)
+	sep := " | "
+	if caps.Color == termcaps.NoColor {
+		_, err := fmt.Fprintln(out, wrap(strings.Join(append(parts, status), sep), s.lineWidth(caps)))
+		return err
+	}
+	if caps.Unicode {
+		sep = " · "
+	}
+
+	r := lipgloss.NewRenderer(out)
+	theme := s.theme()
+	color := theme.Success
+	if f.ExitCode != 0 {
+		color = theme.Failure
+	}
+	faint := r.NewStyle().Faint(true)
+	line := faint.Render(strings.Join(parts, sep)+sep) + r.NewStyle().Foreground(color).Render(status)
+	_, err := fmt.Fprintln(out, wrap(line, s.lineWidth(caps)))
+	return err
+}
@@ -0,0 +1,39 @@
+// internal/ui/footer_test.go
+
+package ui
+
+import (
+	"bytes"
+	"testing"
+	"time"
+)
+
+func TestPrintFooter_Plain(t *testing.T) {
+	tests := []struct {
+		name   string
+		footer Footer
+		want   string
+	}{
+		{
+			"Success",
+			Footer{Elapsed: 1234567 * time.Microsecond, PeakRSS: 24 << 20, ExitCode: 0, Class: "ok"},
+			"time 1.235s | peak RSS 24.0 MiB | exit 0 (ok)\n",
+		},
+		{
+			"Unknown memory",
+			Footer{Elapsed: 2 * time.Millisecond, ExitCode: 2, Class: "usage"},
+			"time 2ms | exit 2 (usage)\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := PrintFooter(&buf, Settings{}, tt.footer); err != nil {
+				t.Fatalf("PrintFooter() error = %v", err)
+			}
+			if buf.String() != tt.want {
+				t.Errorf("PrintFooter() = %q, want %q", buf.String(), tt.want)
+			}
+		})
+	}
+}
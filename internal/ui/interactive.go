@@ -0,0 +1,23 @@
+// internal/ui/interactive.go
+
+package ui
+
+import (
+	"errors"
+
+	"github.com/peiman/ckeletin-go/pkg/termcaps"
+)
+
+// ErrNonInteractive is returned when an interactive UI is requested in non-interactive mode
+var ErrNonInteractive = errors.New("interactive UI is not available in non-interactive mode")
+
+// Interactive reports whether UIs and prompts may be used: non-interactive mode is
+// off and both standard input and output are terminals.
+func (s Settings) Interactive() bool {
+	return !s.NonInteractive && termcaps.Stdin().TTY && termcaps.Stdout().TTY
+}
+
+// animate reports whether output to a stream with caps may be redrawn in place
+func (s Settings) animate(caps termcaps.Caps) bool {
+	return !s.NonInteractive && caps.TTY
+}
@@ -2,6 +2,8 @@
 
 package ui
 
+import "context"
+
 // MockUIRunner is a mock implementation of the UIRunner interface for testing
 type MockUIRunner struct {
 	CalledWithMessage string
@@ -9,7 +11,7 @@ type MockUIRunner struct {
 	ReturnError       error
 }
 
-func (m *MockUIRunner) RunUI(message, col string) error {
+func (m *MockUIRunner) RunUI(ctx context.Context, message, col string) error {
 	m.CalledWithMessage = message
 	m.CalledWithColor = col
 	return m.ReturnError
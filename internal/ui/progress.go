@@ -0,0 +1,78 @@
+// internal/ui/progress.go
+
+package ui
+
+import (
+	"fmt"
+	"io"
+	"time"
+)
+
+// progressRedrawInterval limits how often the progress line is redrawn
+const progressRedrawInterval = 100 * time.Millisecond
+
+// ProgressWriter renders a single-line progress indicator for the bytes written through it.
+// Use it with io.TeeReader or io.MultiWriter to track a transfer.
+type ProgressWriter struct {
+	out      io.Writer
+	redraw   bool
+	total    int64
+	written  int64
+	lastDraw time.Time
+}
+
+// NewProgressWriter creates a ProgressWriter drawing to out with the settings s.
+// A total of zero or less means the size is unknown and no percentage is shown.
+// When out is not a terminal, or in non-interactive mode, only the final state is
+// written, on its own line.
+func NewProgressWriter(out io.Writer, s Settings, total int64) *ProgressWriter {
+	return &ProgressWriter{out: out, redraw: s.animate(s.Caps(out)), total: total}
+}
+
+// Write counts the bytes and redraws the progress line when due
+func (p *ProgressWriter) Write(b []byte) (int, error) {
+	p.written += int64(len(b))
+	if p.redraw && time.Since(p.lastDraw) >= progressRedrawInterval {
+		p.render()
+	}
+	return len(b), nil
+}
+
+// Written returns the number of bytes counted so far
+func (p *ProgressWriter) Written() int64 {
+	return p.written
+}
+
+// Done draws the final state and ends the progress line
+func (p *ProgressWriter) Done() {
+	p.render()
+	fmt.Fprintln(p.out)
+}
+
+func (p *ProgressWriter) render() {
+	p.lastDraw = time.Now()
+	prefix := ""
+	if p.redraw {
+		prefix = "\r"
+	}
+	if p.total > 0 {
+		percent := p.written * 100 / p.total
+		fmt.Fprintf(p.out, "%sDownloaded %s / %s (%d%%)", prefix, FormatBytes(p.written), FormatBytes(p.total), percent)
+		return
+	}
+	fmt.Fprintf(p.out, "%sDownloaded %s", prefix, FormatBytes(p.written))
+}
+
+// FormatBytes formats a byte count using binary units (KiB, MiB, ...)
+func FormatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
@@ -0,0 +1,77 @@
+// internal/ui/progress_test.go
+
+package ui
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestProgressWriter(t *testing.T) {
+	buf := new(bytes.Buffer)
+	p := NewProgressWriter(buf, Settings{}, 2048)
+
+	if _, err := p.Write(make([]byte, 1024)); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	if _, err := p.Write(make([]byte, 1024)); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	p.Done()
+
+	if p.Written() != 2048 {
+		t.Errorf("Written() = %d, want 2048", p.Written())
+	}
+	output := buf.String()
+	if !strings.Contains(output, "Downloaded 2.0 KiB / 2.0 KiB (100%)") {
+		t.Errorf("Expected final progress line, got %q", output)
+	}
+	if !strings.HasSuffix(output, "\n") {
+		t.Errorf("Expected Done() to end the line, got %q", output)
+	}
+}
+
+func TestProgressWriter_UnknownTotal(t *testing.T) {
+	buf := new(bytes.Buffer)
+	p := NewProgressWriter(buf, Settings{}, -1)
+	_, _ = p.Write([]byte("hello"))
+	p.Done()
+
+	if !strings.Contains(buf.String(), "Downloaded 5 B") || strings.Contains(buf.String(), "%") {
+		t.Errorf("Expected size without percentage, got %q", buf.String())
+	}
+}
+
+func TestFormatBytes(t *testing.T) {
+	tests := []struct {
+		in   int64
+		want string
+	}{
+		{0, "0 B"},
+		{1023, "1023 B"},
+		{1024, "1.0 KiB"},
+		{1536, "1.5 KiB"},
+		{5 * 1024 * 1024, "5.0 MiB"},
+		{3 * 1024 * 1024 * 1024, "3.0 GiB"},
+	}
+
+	for _, tt := range tests {
+		if got := FormatBytes(tt.in); got != tt.want {
+			t.Errorf("FormatBytes(%d) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestProgressWriter_NoRedrawWhenNotTerminal(t *testing.T) {
+	buf := new(bytes.Buffer)
+	p := NewProgressWriter(buf, Settings{}, 10)
+	_, _ = p.Write(make([]byte, 5))
+	if buf.Len() != 0 {
+		t.Errorf("Expected no intermediate progress on a non-terminal, got %q", buf.String())
+	}
+	p.Done()
+	if buf.String() != "Downloaded 5 B / 10 B (50%)\n" {
+		t.Errorf("Unexpected final line %q", buf.String())
+	}
+}
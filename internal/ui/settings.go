@@ -0,0 +1,52 @@
+// internal/ui/settings.go
+
+package ui
+
+import (
+	"context"
+	"io"
+
+	"github.com/peiman/ckeletin-go/pkg/termcaps"
+)
+
+// Settings are the display preferences of one invocation. The zero value uses
+// the default theme, wraps only boxes and detects unicode support.
+type Settings struct {
+	// Theme colors and labels status output; the zero Theme is DefaultTheme
+	Theme Theme
+	// MaxWidth makes status output wrap at this column: always when it is not
+	// written to a terminal, e.g. in CI logs, and on terminals wider than it.
+	// Zero wraps only boxes, at the terminal width.
+	MaxWidth int
+	// Unicode overrides the detected unicode support of all output
+	Unicode termcaps.UnicodeMode
+	// NonInteractive disables Bubble Tea UIs, prompts and animations, e.g.
+	// for --non-interactive or in CI
+	NonInteractive bool
+}
+
+// theme returns the theme of s, DefaultTheme when none is set
+func (s Settings) theme() Theme {
+	if s.Theme.Name == "" {
+		return DefaultTheme()
+	}
+	return s.Theme
+}
+
+// Caps returns the capabilities of w with the unicode override of s applied
+func (s Settings) Caps(w io.Writer) termcaps.Caps {
+	return s.Unicode.Apply(termcaps.For(w))
+}
+
+type settingsKey struct{}
+
+// WithSettings returns a copy of ctx carrying s for the UIs started with it
+func WithSettings(ctx context.Context, s Settings) context.Context {
+	return context.WithValue(ctx, settingsKey{}, s)
+}
+
+// SettingsFrom returns the Settings ctx carries, or the zero Settings
+func SettingsFrom(ctx context.Context) Settings {
+	s, _ := ctx.Value(settingsKey{}).(Settings)
+	return s
+}
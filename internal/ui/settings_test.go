@@ -0,0 +1,40 @@
+// internal/ui/settings_test.go
+
+package ui
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/peiman/ckeletin-go/pkg/termcaps"
+)
+
+func TestSettings_Theme(t *testing.T) {
+	if got := (Settings{}).theme(); got.Name != "default" {
+		t.Errorf("theme() = %q without a theme, want default", got.Name)
+	}
+	if got := (Settings{Theme: AccessibleTheme()}).theme(); got.Name != "accessible" {
+		t.Errorf("theme() = %q, want accessible", got.Name)
+	}
+}
+
+func TestSettings_Caps(t *testing.T) {
+	var buf bytes.Buffer
+	if (Settings{Unicode: termcaps.UnicodeOff}).Caps(&buf).Unicode {
+		t.Error("Caps() reports unicode with UnicodeOff")
+	}
+	if !(Settings{Unicode: termcaps.UnicodeOn}).Caps(&buf).Unicode {
+		t.Error("Caps() reports no unicode with UnicodeOn")
+	}
+}
+
+func TestSettingsFrom(t *testing.T) {
+	if got := SettingsFrom(context.Background()); got != (Settings{}) {
+		t.Errorf("SettingsFrom() = %+v without settings, want the zero Settings", got)
+	}
+	s := Settings{MaxWidth: 80, NonInteractive: true}
+	if got := SettingsFrom(WithSettings(context.Background(), s)); got != s {
+		t.Errorf("SettingsFrom() = %+v, want %+v", got, s)
+	}
+}
@@ -0,0 +1,140 @@
+// internal/ui/theme.go
+
+package ui
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/peiman/ckeletin-go/pkg/termcaps"
+)
+
+// Theme holds the colors and labels of status output: the timing footer, the
+// error box and progress lines
+type Theme struct {
+	Name                              string
+	Success, Failure, Warning, Accent lipgloss.TerminalColor
+	// PassIcon and FailIcon replace the status symbols of the terminal when set
+	PassIcon, FailIcon string
+	// Labels follows status symbols with a text label, e.g. "[PASS]", so the
+	// status does not depend on telling colors apart
+	Labels bool
+}
+
+// ThemeOverrides replace parts of a theme. Colors are names such as "red",
+// ANSI color numbers from 0 to 255 or "#rrggbb"; empty fields keep the
+// value of the theme.
+type ThemeOverrides struct {
+	Success, Failure, Warning, Accent string
+	PassIcon, FailIcon                string
+}
+
+// With returns t with the overrides of o applied
+func (t Theme) With(o ThemeOverrides) (Theme, error) {
+	colors := []struct {
+		name  string
+		value string
+		dst   *lipgloss.TerminalColor
+	}{
+		{"success", o.Success, &t.Success},
+		{"failure", o.Failure, &t.Failure},
+		{"warning", o.Warning, &t.Warning},
+		{"accent", o.Accent, &t.Accent},
+	}
+	for _, c := range colors {
+		if c.value == "" {
+			continue
+		}
+		color, err := ParseColor(c.value)
+		if err != nil {
+			return Theme{}, fmt.Errorf("%s color: %w", c.name, err)
+		}
+		*c.dst = color
+	}
+	if o.PassIcon != "" {
+		t.PassIcon = o.PassIcon
+	}
+	if o.FailIcon != "" {
+		t.FailIcon = o.FailIcon
+	}
+	return t, nil
+}
+
+// DefaultTheme is the green and red palette of the terminal
+func DefaultTheme() Theme {
+	return Theme{
+		Name:    "default",
+		Success: lipgloss.Color("10"),
+		Failure: lipgloss.Color("9"),
+		Warning: lipgloss.Color("11"),
+		Accent:  lipgloss.Color("6"),
+	}
+}
+
+// AccessibleTheme uses blue and orange from the Okabe-Ito palette, which stay
+// distinct with the common kinds of color blindness, and adds text labels to
+// status symbols
+func AccessibleTheme() Theme {
+	return Theme{
+		Name:    "accessible",
+		Success: lipgloss.CompleteColor{TrueColor: "#0072B2", ANSI256: "25", ANSI: "12"},
+		Failure: lipgloss.CompleteColor{TrueColor: "#E69F00", ANSI256: "214", ANSI: "11"},
+		Warning: lipgloss.CompleteColor{TrueColor: "#CC79A7", ANSI256: "175", ANSI: "13"},
+		Accent:  lipgloss.CompleteColor{TrueColor: "#56B4E9", ANSI256: "74", ANSI: "14"},
+		Labels:  true,
+	}
+}
+
+// themes are the built-in themes by name
+var themes = map[string]func() Theme{
+	"default":    DefaultTheme,
+	"accessible": AccessibleTheme,
+}
+
+// ThemeNames returns the names of the built-in themes, sorted
+func ThemeNames() []string {
+	names := make([]string, 0, len(themes))
+	for name := range themes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ThemeNamed returns the built-in theme name
+func ThemeNamed(name string) (Theme, error) {
+	t, ok := themes[name]
+	if !ok {
+		return Theme{}, fmt.Errorf("unknown theme %q, use one of %v", name, ThemeNames())
+	}
+	return t(), nil
+}
+
+// Pass returns the symbol for a successful step
+func (t Theme) Pass(g termcaps.Glyphs) string {
+	return t.label(t.PassIcon, g.Success, "[PASS]")
+}
+
+// Fail returns the symbol for a failed step
+func (t Theme) Fail(g termcaps.Glyphs) string {
+	return t.label(t.FailIcon, g.Failure, "[FAIL]")
+}
+
+func (t Theme) label(icon, symbol, label string) string {
+	if icon != "" {
+		symbol = icon
+	}
+	if t.Labels {
+		return symbol + " " + label
+	}
+	return symbol
+}
+
+// LimitedColors reports whether output with caps has only the basic terminal
+// colors, e.g. when COLORTERM does not announce more. Their shades of red and
+// green depend on the terminal palette and are often hard to tell apart, so
+// the accessible theme may be the better choice.
+func LimitedColors(caps termcaps.Caps) bool {
+	return caps.TTY && caps.Color == termcaps.BasicColor
+}
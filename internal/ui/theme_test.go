@@ -0,0 +1,73 @@
+// internal/ui/theme_test.go
+
+package ui
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/peiman/ckeletin-go/pkg/termcaps"
+)
+
+func TestThemeNamed(t *testing.T) {
+	for _, name := range ThemeNames() {
+		theme, err := ThemeNamed(name)
+		if err != nil || theme.Name != name {
+			t.Errorf("ThemeNamed(%q) = %+v, %v", name, theme, err)
+		}
+	}
+	if _, err := ThemeNamed("neon"); err == nil {
+		t.Error("ThemeNamed(neon) succeeded, want an error")
+	}
+}
+
+func TestThemeLabels(t *testing.T) {
+	g := termcaps.Caps{Unicode: true}.Glyphs()
+	if got := DefaultTheme().Pass(g); got != g.Success {
+		t.Errorf("DefaultTheme().Pass() = %q, want %q", got, g.Success)
+	}
+	if got := AccessibleTheme().Pass(g); got != g.Success+" [PASS]" {
+		t.Errorf("AccessibleTheme().Pass() = %q, want a label", got)
+	}
+	if got := AccessibleTheme().Fail(g); got != g.Failure+" [FAIL]" {
+		t.Errorf("AccessibleTheme().Fail() = %q, want a label", got)
+	}
+}
+
+func TestLimitedColors(t *testing.T) {
+	tests := []struct {
+		caps termcaps.Caps
+		want bool
+	}{
+		{termcaps.Caps{TTY: true, Color: termcaps.BasicColor}, true},
+		{termcaps.Caps{TTY: true, Color: termcaps.TrueColor}, false},
+		{termcaps.Caps{Color: termcaps.BasicColor}, false},
+	}
+	for _, tt := range tests {
+		if got := LimitedColors(tt.caps); got != tt.want {
+			t.Errorf("LimitedColors(%+v) = %v, want %v", tt.caps, got, tt.want)
+		}
+	}
+}
+
+func TestThemeWith(t *testing.T) {
+	theme, err := DefaultTheme().With(ThemeOverrides{Failure: "208", PassIcon: "OK"})
+	if err != nil {
+		t.Fatalf("With() error = %v", err)
+	}
+	if theme.Failure != lipgloss.Color("208") || theme.Success != DefaultTheme().Success {
+		t.Errorf("With() colors = %v, %v, want only failure replaced", theme.Success, theme.Failure)
+	}
+	g := termcaps.Caps{}.Glyphs()
+	if got := theme.Pass(g); got != "OK" {
+		t.Errorf("Pass() = %q, want the override", got)
+	}
+	if got := theme.Fail(g); got != g.Failure {
+		t.Errorf("Fail() = %q, want the glyph", got)
+	}
+
+	if _, err := DefaultTheme().With(ThemeOverrides{Accent: "nope"}); err == nil || !strings.Contains(err.Error(), "accent color") {
+		t.Errorf("With(invalid accent) error = %v, want an accent color error", err)
+	}
+}
@@ -3,24 +3,39 @@
 package ui
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"sort"
 
-	"github.com/rs/zerolog/log"
+	"github.com/rs/zerolog"
 
+	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 )
 
 // UIRunner defines an interface for running a UI
 type UIRunner interface {
-	RunUI(message, col string) error
+	RunUI(ctx context.Context, message, col string) error
 }
 
+// SaveFunc persists the message and color chosen in the UI. ctx is the
+// context RunUI was called with.
+type SaveFunc func(ctx context.Context, message, col string) error
+
 // DefaultUIRunner is the default implementation of UIRunner
-type DefaultUIRunner struct{}
+type DefaultUIRunner struct {
+	// Save is called when the user confirms saving their edits.
+	// When nil, edits are kept for the session only.
+	Save SaveFunc
+}
 
-// RunUI runs the Bubble Tea UI
-func (d *DefaultUIRunner) RunUI(message, col string) error {
+// RunUI runs the Bubble Tea UI until the user quits or ctx is cancelled.
+// Cancelling ctx stops the program and restores the terminal. It logs to the
+// logger of ctx and does not run when the Settings of ctx are non-interactive.
+func (d *DefaultUIRunner) RunUI(ctx context.Context, message, col string) error {
+	log := zerolog.Ctx(ctx)
 	colorStyle, err := GetLipglossColor(col)
 	if err != nil {
 		log.Error().
@@ -31,13 +46,19 @@ func (d *DefaultUIRunner) RunUI(message, col string) error {
 		return err
 	}
 
-	m := model{
-		message:    message,
-		colorStyle: lipgloss.NewStyle().Foreground(colorStyle).Bold(true),
+	if !SettingsFrom(ctx).Interactive() {
+		return ErrNonInteractive
 	}
 
-	p := tea.NewProgram(m)
-	_, err = p.Run()
+	m := newModel(message, col, colorStyle)
+	m.canSave = d.Save != nil
+
+	// Signals are handled by the caller, which cancels ctx.
+	p := tea.NewProgram(m, tea.WithContext(ctx), tea.WithoutSignalHandler())
+	final, err := p.Run()
+	if errors.Is(err, tea.ErrProgramKilled) && ctx.Err() != nil {
+		return ctx.Err()
+	}
 	if err != nil {
 		log.Error().
 			Err(err).
@@ -47,6 +68,21 @@ func (d *DefaultUIRunner) RunUI(message, col string) error {
 		return err
 	}
 
+	if fm, ok := final.(model); ok && fm.save {
+		if err := d.Save(ctx, fm.message, fm.colorName); err != nil {
+			log.Error().
+				Err(err).
+				Str("message", fm.message).
+				Str("color", fm.colorName).
+				Msg("Failed to save UI changes")
+			return fmt.Errorf("failed to save changes: %w", err)
+		}
+		log.Info().
+			Str("message", fm.message).
+			Str("color", fm.colorName).
+			Msg("UI changes saved")
+	}
+
 	log.Info().
 		Str("message", message).
 		Str("color", col).
@@ -60,19 +96,44 @@ func GetLipglossColor(col string) (lipgloss.Color, error) {
 	if color, ok := ColorMap[col]; ok {
 		return color, nil
 	}
-	err := fmt.Errorf("invalid color: %s", col)
-	log.Error().
-		Err(err).
-		Str("color", col).
-		Msg("Failed to get lipgloss color")
-	return "", err
+	return "", fmt.Errorf("invalid color: %s", col)
+}
+
+// ColorNames returns the names in ColorMap in a stable order
+func ColorNames() []string {
+	names := make([]string, 0, len(ColorMap))
+	for name := range ColorMap {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
 }
 
 // model defines the Bubble Tea model
 type model struct {
 	message    string
+	colorName  string
 	colorStyle lipgloss.Style
 	done       bool
+
+	input      textinput.Model
+	editing    bool
+	dirty      bool
+	canSave    bool
+	confirming bool
+	save       bool
+}
+
+// newModel creates a model showing message in the given color
+func newModel(message, colorName string, col lipgloss.Color) model {
+	input := textinput.New()
+	input.Prompt = "Message: "
+	return model{
+		message:    message,
+		colorName:  colorName,
+		colorStyle: lipgloss.NewStyle().Foreground(col).Bold(true),
+		input:      input,
+	}
 }
 
 // Init initializes the model (no-op)
@@ -82,21 +143,104 @@ func (m model) Init() tea.Cmd {
 
 // Update handles messages and updates the model
 func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
-	switch msg := msg.(type) {
-	case tea.KeyMsg:
-		switch {
-		case msg.Type == tea.KeyCtrlC:
-			return m, tea.Quit
-		case msg.Type == tea.KeyEsc:
-			return m, tea.Quit
-		case msg.String() == "q":
-			return m, tea.Quit
+	keyMsg, ok := msg.(tea.KeyMsg)
+	if !ok {
+		if m.editing {
+			var cmd tea.Cmd
+			m.input, cmd = m.input.Update(msg)
+			return m, cmd
+		}
+		return m, nil
+	}
+
+	// CTRL-C always exits immediately without saving.
+	if keyMsg.Type == tea.KeyCtrlC {
+		return m, tea.Quit
+	}
+
+	switch {
+	case m.editing:
+		return m.updateEditing(keyMsg)
+	case m.confirming:
+		return m.updateConfirming(keyMsg)
+	}
+
+	switch {
+	case keyMsg.Type == tea.KeyEsc, keyMsg.String() == "q":
+		if m.dirty && m.canSave {
+			m.confirming = true
+			return m, nil
 		}
+		return m, tea.Quit
+	case keyMsg.String() == "c":
+		m.cycleColor()
+	case keyMsg.String() == "e":
+		m.editing = true
+		m.input.SetValue(m.message)
+		m.input.CursorEnd()
+		return m, m.input.Focus()
 	}
 	return m, nil
 }
 
+// updateEditing handles keys while the message is being edited
+func (m model) updateEditing(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.Type {
+	case tea.KeyEnter:
+		if value := m.input.Value(); value != m.message {
+			m.message = value
+			m.dirty = true
+		}
+		m.editing = false
+		m.input.Blur()
+		return m, nil
+	case tea.KeyEsc:
+		m.editing = false
+		m.input.Blur()
+		return m, nil
+	}
+
+	var cmd tea.Cmd
+	m.input, cmd = m.input.Update(msg)
+	return m, cmd
+}
+
+// updateConfirming handles the save prompt shown when quitting with unsaved changes
+func (m model) updateConfirming(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "y", "Y":
+		m.save = true
+		return m, tea.Quit
+	case "n", "N", "esc":
+		return m, tea.Quit
+	}
+	return m, nil
+}
+
+// cycleColor switches to the next color in ColorNames
+func (m *model) cycleColor() {
+	names := ColorNames()
+	next := names[0]
+	for i, name := range names {
+		if name == m.colorName {
+			next = names[(i+1)%len(names)]
+			break
+		}
+	}
+	m.colorName = next
+	m.colorStyle = m.colorStyle.Foreground(ColorMap[next])
+	m.dirty = true
+}
+
 // View renders the model's view
 func (m model) View() string {
-	return m.colorStyle.Render(m.message) + "\n\nPress 'q' or 'CTRL-C' to exit."
+	switch {
+	case m.editing:
+		return m.input.View() + "\n\nPress 'enter' to apply or 'esc' to cancel."
+	case m.confirming:
+		return m.colorStyle.Render(m.message) + "\n\nSave changes to the config file? (y/n)"
+	}
+	return m.colorStyle.Render(m.message) +
+		"\n\nPress 'c' to change color, 'e' to edit the message." +
+		"\nPress 'q' or 'CTRL-C' to exit."
 }
@@ -3,6 +3,7 @@
 package ui
 
 import (
+	"context"
 	"errors"
 	"testing"
 
@@ -80,7 +81,7 @@ func TestRunUIWithMock(t *testing.T) {
 				ReturnError: tt.mockError,
 			}
 
-			err := mockRunner.RunUI(tt.message, tt.color)
+			err := mockRunner.RunUI(context.Background(), tt.message, tt.color)
 
 			// Check if RunUI was called
 			if (mockRunner.CalledWithMessage != tt.message || mockRunner.CalledWithColor != tt.color) && tt.wantCalled {
@@ -102,7 +103,7 @@ func TestModelView(t *testing.T) {
 		colorStyle: lipgloss.NewStyle(),
 	}
 
-	expectedOutput := "Test Message\n\nPress 'q' or 'CTRL-C' to exit."
+	expectedOutput := "Test Message\n\nPress 'c' to change color, 'e' to edit the message.\nPress 'q' or 'CTRL-C' to exit."
 
 	if got := m.View(); got != expectedOutput {
 		t.Errorf("View() = %q, want %q", got, expectedOutput)
@@ -161,8 +162,132 @@ func TestRunUI(t *testing.T) {
 	runner := DefaultUIRunner{}
 
 	// Since testing the actual UI is complex, we can test for error handling
-	err := runner.RunUI("Test Message", "invalid-color")
+	err := runner.RunUI(context.Background(), "Test Message", "invalid-color")
 	if err == nil {
 		t.Errorf("Expected error for invalid color, got nil")
 	}
 }
+
+func TestModelCycleColor(t *testing.T) {
+	m := newModel("Test Message", "white", ColorMap["white"])
+
+	updated, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'c'}})
+	got := updated.(model)
+
+	// ColorNames is sorted, so "white" is followed by "yellow".
+	if got.colorName != "yellow" {
+		t.Errorf("colorName = %q, want %q", got.colorName, "yellow")
+	}
+	if !got.dirty {
+		t.Errorf("Expected model to be dirty after changing color")
+	}
+
+	updated, _ = got.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'c'}})
+	if c := updated.(model).colorName; c != ColorNames()[0] {
+		t.Errorf("Expected color to wrap around to %q, got %q", ColorNames()[0], c)
+	}
+}
+
+func TestModelEditMessage(t *testing.T) {
+	m := newModel("Old", "red", ColorMap["red"])
+
+	updated, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'e'}})
+	m = updated.(model)
+	if !m.editing {
+		t.Fatalf("Expected 'e' to start editing")
+	}
+	if m.input.Value() != "Old" {
+		t.Errorf("Expected input to be prefilled with %q, got %q", "Old", m.input.Value())
+	}
+
+	// Typing 'q' while editing must not quit.
+	updated, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'q'}})
+	m = updated.(model)
+	if !m.editing {
+		t.Fatalf("Expected to still be editing after typing 'q'")
+	}
+	if cmd != nil {
+		if _, quit := cmd().(tea.QuitMsg); quit {
+			t.Fatalf("Typing 'q' while editing should not quit")
+		}
+	}
+
+	updated, _ = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m = updated.(model)
+	if m.editing {
+		t.Errorf("Expected enter to finish editing")
+	}
+	if m.message != "Oldq" || !m.dirty {
+		t.Errorf("Expected message %q and dirty model, got %q dirty=%v", "Oldq", m.message, m.dirty)
+	}
+}
+
+func TestModelEditCancel(t *testing.T) {
+	m := newModel("Keep", "red", ColorMap["red"])
+
+	updated, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'e'}})
+	updated, _ = updated.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'x'}})
+	updated, _ = updated.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	m = updated.(model)
+
+	if m.editing || m.message != "Keep" || m.dirty {
+		t.Errorf("Expected edit to be discarded, got message=%q editing=%v dirty=%v", m.message, m.editing, m.dirty)
+	}
+}
+
+func TestModelQuitConfirmation(t *testing.T) {
+	tests := []struct {
+		name     string
+		canSave  bool
+		answer   string
+		wantSave bool
+	}{
+		{"Confirm save", true, "y", true},
+		{"Decline save", true, "n", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := newModel("Test", "red", ColorMap["red"])
+			m.canSave = tt.canSave
+			m.dirty = true
+
+			updated, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'q'}})
+			m = updated.(model)
+			if !m.confirming || cmd != nil {
+				t.Fatalf("Expected a save prompt instead of quitting")
+			}
+
+			updated, cmd = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(tt.answer)})
+			if cmd == nil {
+				t.Fatalf("Expected answering the prompt to quit")
+			}
+			if got := updated.(model).save; got != tt.wantSave {
+				t.Errorf("save = %v, want %v", got, tt.wantSave)
+			}
+		})
+	}
+}
+
+func TestModelQuitWithoutSaveFunc(t *testing.T) {
+	m := newModel("Test", "red", ColorMap["red"])
+	m.dirty = true
+
+	_, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'q'}})
+	if cmd == nil {
+		t.Errorf("Expected quit when there is nowhere to save changes")
+	}
+}
+
+func TestRunUI_NonInteractive(t *testing.T) {
+	s := Settings{NonInteractive: true}
+	ctx := WithSettings(context.Background(), s)
+
+	runner := DefaultUIRunner{}
+	if err := runner.RunUI(ctx, "Test Message", "white"); !errors.Is(err, ErrNonInteractive) {
+		t.Errorf("RunUI() error = %v, want %v", err, ErrNonInteractive)
+	}
+	if s.Interactive() {
+		t.Error("Interactive() = true in non-interactive mode")
+	}
+}
@@ -0,0 +1,27 @@
+// internal/ui/width.go
+
+package ui
+
+import (
+	"github.com/charmbracelet/x/ansi"
+	"github.com/peiman/ckeletin-go/pkg/termcaps"
+)
+
+// lineWidth returns the column output to a stream with caps wraps at with
+// the MaxWidth of s, or 0 for no wrapping
+func (s Settings) lineWidth(caps termcaps.Caps) int {
+	n := s.MaxWidth
+	if n > 0 && caps.TTY && caps.Width < n {
+		return caps.Width
+	}
+	return n
+}
+
+// wrap breaks the lines of s at width columns, between words where possible,
+// or returns s unchanged for a width of 0. Escape sequences do not count.
+func wrap(s string, width int) string {
+	if width <= 0 {
+		return s
+	}
+	return ansi.Wrap(s, width, "")
+}
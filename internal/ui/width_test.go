@@ -0,0 +1,56 @@
+// internal/ui/width_test.go
+
+package ui
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/peiman/ckeletin-go/pkg/termcaps"
+)
+
+func TestLineWidth(t *testing.T) {
+	tests := []struct {
+		max  int
+		caps termcaps.Caps
+		want int
+	}{
+		{0, termcaps.Caps{TTY: true, Width: 200}, 0},
+		{0, termcaps.Caps{Width: 80}, 0},
+		{120, termcaps.Caps{TTY: true, Width: 200}, 120},
+		{120, termcaps.Caps{TTY: true, Width: 100}, 100},
+		{120, termcaps.Caps{Width: 80}, 120},
+	}
+	for _, tt := range tests {
+		if got := (Settings{MaxWidth: tt.max}).lineWidth(tt.caps); got != tt.want {
+			t.Errorf("lineWidth(%+v) with max %d = %d, want %d", tt.caps, tt.max, got, tt.want)
+		}
+	}
+}
+
+func TestPrintErrorBox_MaxWidth(t *testing.T) {
+	e := ErrorBox{Message: strings.Repeat("word ", 20)}
+
+	var buf bytes.Buffer
+	if err := PrintErrorBox(&buf, Settings{}, e); err != nil {
+		t.Fatal(err)
+	}
+	if lines := strings.Split(strings.TrimSpace(buf.String()), "\n"); len(lines) != 1 {
+		t.Errorf("Error wrapped without a max width:\n%s", buf.String())
+	}
+
+	buf.Reset()
+	if err := PrintErrorBox(&buf, Settings{MaxWidth: 30}, e); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) < 3 {
+		t.Errorf("Error not wrapped at 30 columns:\n%s", buf.String())
+	}
+	for _, line := range lines {
+		if len(line) > 30 {
+			t.Errorf("Line %q is longer than 30 columns", line)
+		}
+	}
+}
@@ -0,0 +1,88 @@
+// internal/update/notice.go
+
+package update
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/peiman/ckeletin-go/internal/store"
+	"github.com/rs/zerolog"
+)
+
+// Location of the cached release check in the state store
+const (
+	noticeBucket = "update"
+	noticeKey    = "last_check"
+)
+
+// noticeState is the cached result of the last release check
+type noticeState struct {
+	CheckedAt time.Time `json:"checked_at"`
+	Latest    string    `json:"latest_version"`
+}
+
+// Notifier checks for new releases at most once per Interval and caches the result,
+// so commands can print a notice without hitting the network on every run.
+type Notifier struct {
+	Updater  *Updater
+	Store    *store.Store
+	Interval time.Duration
+	Current  string
+
+	now func() time.Time
+}
+
+func (n *Notifier) clock() time.Time {
+	if n.now != nil {
+		return n.now()
+	}
+	return time.Now()
+}
+
+// Refresh looks up the latest release when the last attempt is older than
+// Interval. A failed lookup counts as an attempt and keeps the cached
+// version, so offline users are not slowed down by a lookup on every run.
+func (n *Notifier) Refresh(ctx context.Context) error {
+	state, err := n.load()
+	if err != nil {
+		zerolog.Ctx(ctx).Debug().Err(err).Str("path", n.Store.Path()).Msg("Ignoring unreadable update state")
+	}
+	if n.clock().Sub(state.CheckedAt) < n.Interval {
+		return nil
+	}
+
+	rel, err := n.Updater.Latest(ctx)
+	if err != nil {
+		if saveErr := n.save(noticeState{CheckedAt: n.clock(), Latest: state.Latest}); saveErr != nil {
+			zerolog.Ctx(ctx).Debug().Err(saveErr).Msg("Failed to record the update check")
+		}
+		return err
+	}
+	return n.save(noticeState{CheckedAt: n.clock(), Latest: rel.Version()})
+}
+
+// Notice returns a one-line message when the cached latest release is newer than Current,
+// or an empty string otherwise.
+func (n *Notifier) Notice() string {
+	state, err := n.load()
+	if err != nil || state.Latest == "" {
+		return ""
+	}
+	if cmp, err := CompareVersions(n.Current, state.Latest); err != nil || cmp >= 0 {
+		return ""
+	}
+	return fmt.Sprintf("A new release of %s is available: %s -> %s. Run '%s update' to install it.",
+		n.Updater.BinaryName, n.Current, state.Latest, n.Updater.BinaryName)
+}
+
+func (n *Notifier) load() (noticeState, error) {
+	var state noticeState
+	_, err := n.Store.GetJSON(noticeBucket, noticeKey, &state)
+	return state, err
+}
+
+func (n *Notifier) save(state noticeState) error {
+	return n.Store.PutJSON(noticeBucket, noticeKey, state)
+}
@@ -0,0 +1,125 @@
+// internal/update/notice_test.go
+
+package update
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/peiman/ckeletin-go/internal/store"
+)
+
+func newNotifier(t *testing.T, current, tag string, calls *int32) (*Notifier, *time.Time) {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(calls, 1)
+		_ = json.NewEncoder(w).Encode(Release{TagName: tag})
+	}))
+	t.Cleanup(srv.Close)
+
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	n := &Notifier{
+		Updater:  &Updater{APIURL: srv.URL, Repo: "acme/mycli", BinaryName: "mycli"},
+		Store:    store.New(filepath.Join(t.TempDir(), "state.db")),
+		Interval: 24 * time.Hour,
+		Current:  current,
+	}
+	n.now = func() time.Time { return now }
+	return n, &now
+}
+
+func TestNotifier_RefreshAtMostOncePerInterval(t *testing.T) {
+	var calls int32
+	n, now := newNotifier(t, "1.0.0", "v1.1.0", &calls)
+
+	for i := 0; i < 3; i++ {
+		if err := n.Refresh(context.Background()); err != nil {
+			t.Fatalf("Refresh() error = %v", err)
+		}
+	}
+	if calls != 1 {
+		t.Errorf("Expected 1 release lookup within the interval, got %d", calls)
+	}
+
+	*now = now.Add(25 * time.Hour)
+	if err := n.Refresh(context.Background()); err != nil {
+		t.Fatalf("Refresh() error = %v", err)
+	}
+	if calls != 2 {
+		t.Errorf("Expected a new lookup after the interval, got %d lookups", calls)
+	}
+}
+
+func TestNotifier_FailedRefreshNotRetriedWithinInterval(t *testing.T) {
+	var calls int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		http.Error(w, "unavailable", http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+	n, now := newNotifier(t, "1.0.0", "v1.1.0", new(int32))
+	n.Updater.APIURL = srv.URL
+	if err := n.save(noticeState{CheckedAt: now.Add(-48 * time.Hour), Latest: "1.0.5"}); err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 3; i++ {
+		if err := n.Refresh(context.Background()); err == nil && i == 0 {
+			t.Error("Refresh() succeeded with an unavailable server")
+		}
+	}
+	if calls != 1 {
+		t.Errorf("Expected 1 failed lookup within the interval, got %d", calls)
+	}
+	if state, _ := n.load(); state.Latest != "1.0.5" {
+		t.Errorf("Cached version = %q after a failed lookup, want 1.0.5 kept", state.Latest)
+	}
+
+	*now = now.Add(25 * time.Hour)
+	_ = n.Refresh(context.Background())
+	if calls != 2 {
+		t.Errorf("Expected a new lookup after the interval, got %d lookups", calls)
+	}
+}
+
+func TestNotifier_Notice(t *testing.T) {
+	tests := []struct {
+		name    string
+		current string
+		tag     string
+		want    string
+	}{
+		{"Newer release", "1.0.0", "v1.1.0", "A new release of mycli is available: 1.0.0 -> 1.1.0."},
+		{"Up to date", "1.1.0", "v1.1.0", ""},
+		{"Development build", "dev", "v1.1.0", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls int32
+			n, _ := newNotifier(t, tt.current, tt.tag, &calls)
+
+			if got := n.Notice(); got != "" {
+				t.Errorf("Notice() before any check = %q, want empty", got)
+			}
+			if err := n.Refresh(context.Background()); err != nil {
+				t.Fatalf("Refresh() error = %v", err)
+			}
+
+			got := n.Notice()
+			if tt.want == "" && got != "" {
+				t.Errorf("Notice() = %q, want empty", got)
+			}
+			if tt.want != "" && !strings.Contains(got, tt.want) {
+				t.Errorf("Notice() = %q, want containing %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,121 @@
+// internal/update/signature.go
+
+package update
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// signatureSuffixes name the signature of a release asset, <asset>.minisig or <asset>.sig
+var signatureSuffixes = []string{".minisig", ".sig"}
+
+// Ed25519 signatures of minisign (legacy mode, minisign -S -l) and signify
+// share one layout: a two-byte algorithm, an eight-byte key ID and the key or
+// signature, base64 encoded.
+const (
+	sigAlgorithm = "Ed"
+	// prehashedAlgorithm marks minisign signatures of a BLAKE2b hash, the
+	// default of minisign 0.11 and later
+	prehashedAlgorithm = "ED"
+	keyIDLen           = 8
+)
+
+// trustedCommentPrefix starts the third line of minisign signatures
+const trustedCommentPrefix = "trusted comment: "
+
+// PublicKey is an Ed25519 key verifying minisign or signify signatures
+type PublicKey struct {
+	id  [keyIDLen]byte
+	key ed25519.PublicKey
+}
+
+// ParsePublicKey parses a minisign or signify public key: the base64 line of a
+// minisign.pub or .pub file, with or without its "untrusted comment" line
+func ParsePublicKey(s string) (*PublicKey, error) {
+	data, err := decodeLine(s, 2+keyIDLen+ed25519.PublicKeySize)
+	if err != nil {
+		return nil, fmt.Errorf("invalid public key: %w", err)
+	}
+	if string(data[:2]) != sigAlgorithm {
+		return nil, fmt.Errorf("invalid public key: unsupported algorithm %q", data[:2])
+	}
+	k := &PublicKey{key: ed25519.PublicKey(data[2+keyIDLen:])}
+	copy(k.id[:], data[2:])
+	return k, nil
+}
+
+// Verify checks that sig, the content of a minisign or signify signature
+// file, is a signature of data by k. The trusted comment of minisign
+// signatures is verified as well.
+func (k *PublicKey) Verify(data, sig []byte) error {
+	lines := signatureLines(sig)
+	if len(lines) < 2 || !strings.HasPrefix(lines[0], "untrusted comment:") {
+		return errors.New("invalid signature: missing untrusted comment")
+	}
+	raw, err := decodeLine(lines[1], 2+keyIDLen+ed25519.SignatureSize)
+	if err != nil {
+		return fmt.Errorf("invalid signature: %w", err)
+	}
+	switch string(raw[:2]) {
+	case sigAlgorithm:
+	case prehashedAlgorithm:
+		return errors.New("prehashed minisign signatures are not supported, sign with 'minisign -S -l'")
+	default:
+		return fmt.Errorf("invalid signature: unsupported algorithm %q", raw[:2])
+	}
+	if !bytes.Equal(raw[2:2+keyIDLen], k.id[:]) {
+		return errors.New("signature was made with another key")
+	}
+	signature := raw[2+keyIDLen:]
+	if !ed25519.Verify(k.key, data, signature) {
+		return errors.New("signature verification failed")
+	}
+
+	if len(lines) < 4 {
+		return nil
+	}
+	comment, ok := strings.CutPrefix(lines[2], trustedCommentPrefix)
+	if !ok {
+		return errors.New("invalid signature: missing trusted comment")
+	}
+	global, err := base64.StdEncoding.DecodeString(lines[3])
+	if err != nil || len(global) != ed25519.SignatureSize {
+		return errors.New("invalid signature: malformed trusted comment signature")
+	}
+	if !ed25519.Verify(k.key, append(append([]byte{}, signature...), comment...), global) {
+		return errors.New("trusted comment verification failed")
+	}
+	return nil
+}
+
+// signatureLines returns the non-empty lines of a signature file
+func signatureLines(sig []byte) []string {
+	var lines []string
+	for _, line := range strings.Split(strings.ReplaceAll(string(sig), "\r\n", "\n"), "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
+
+// decodeLine decodes the last base64 line of s, which must hold size bytes
+func decodeLine(s string, size int) ([]byte, error) {
+	lines := signatureLines([]byte(s))
+	if len(lines) == 0 {
+		return nil, errors.New("empty")
+	}
+	data, err := base64.StdEncoding.DecodeString(lines[len(lines)-1])
+	if err != nil {
+		return nil, err
+	}
+	if len(data) != size {
+		return nil, fmt.Errorf("%d bytes, want %d", len(data), size)
+	}
+	return data, nil
+}
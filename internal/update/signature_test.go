@@ -0,0 +1,90 @@
+// internal/update/signature_test.go
+
+package update
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/base64"
+	"strings"
+	"testing"
+)
+
+// testSigner signs like minisign -S -l, or like signify without a trusted comment
+type testSigner struct {
+	id        [keyIDLen]byte
+	priv      ed25519.PrivateKey
+	publicKey string
+}
+
+func newTestSigner(t *testing.T) *testSigner {
+	t.Helper()
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := &testSigner{priv: priv}
+	if _, err := rand.Read(s.id[:]); err != nil {
+		t.Fatal(err)
+	}
+	key := append(append([]byte(sigAlgorithm), s.id[:]...), pub...)
+	s.publicKey = "untrusted comment: minisign public key\n" + base64.StdEncoding.EncodeToString(key) + "\n"
+	return s
+}
+
+func (s *testSigner) sign(data []byte, trustedComment string) string {
+	sig := ed25519.Sign(s.priv, data)
+	line := base64.StdEncoding.EncodeToString(append(append([]byte(sigAlgorithm), s.id[:]...), sig...))
+	out := "untrusted comment: signature from test key\n" + line + "\n"
+	if trustedComment == "" {
+		return out
+	}
+	global := ed25519.Sign(s.priv, append(sig, trustedComment...))
+	return out + trustedCommentPrefix + trustedComment + "\n" + base64.StdEncoding.EncodeToString(global) + "\n"
+}
+
+func TestPublicKey_Verify(t *testing.T) {
+	signer := newTestSigner(t)
+	key, err := ParsePublicKey(signer.publicKey)
+	if err != nil {
+		t.Fatalf("ParsePublicKey() error = %v", err)
+	}
+	data := []byte("checksums\n")
+
+	if err := key.Verify(data, []byte(signer.sign(data, "timestamp:1 file:checksums.txt"))); err != nil {
+		t.Errorf("Verify() minisign error = %v", err)
+	}
+	if err := key.Verify(data, []byte(signer.sign(data, ""))); err != nil {
+		t.Errorf("Verify() signify error = %v", err)
+	}
+
+	other := newTestSigner(t)
+	tamperedComment := strings.Replace(signer.sign(data, "file:a"), "file:a", "file:b", 1)
+	prehashed := strings.Split(signer.sign(data, ""), "\n")
+	raw, _ := base64.StdEncoding.DecodeString(prehashed[1])
+	copy(raw, prehashedAlgorithm)
+	prehashed[1] = base64.StdEncoding.EncodeToString(raw)
+
+	for _, tt := range []struct {
+		name, sig, data, want string
+	}{
+		{"tampered data", signer.sign(data, ""), "checksums!\n", "verification failed"},
+		{"other key", other.sign(data, ""), string(data), "another key"},
+		{"tampered trusted comment", tamperedComment, string(data), "trusted comment verification failed"},
+		{"prehashed", strings.Join(prehashed, "\n"), string(data), "not supported"},
+		{"garbage", "untrusted comment: x\nnot base64", string(data), "invalid signature"},
+		{"empty", "", string(data), "invalid signature"},
+	} {
+		if err := key.Verify([]byte(tt.data), []byte(tt.sig)); err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("Verify() with %s error = %v, want containing %q", tt.name, err, tt.want)
+		}
+	}
+}
+
+func TestParsePublicKey_Invalid(t *testing.T) {
+	for _, s := range []string{"", "bm90IGEga2V5", "RWQ=", "%%%"} {
+		if _, err := ParsePublicKey(s); err == nil {
+			t.Errorf("ParsePublicKey(%q) succeeded, want an error", s)
+		}
+	}
+}
@@ -0,0 +1,268 @@
+// internal/update/update.go
+
+package update
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/rs/zerolog"
+)
+
+// DefaultAPIURL is the GitHub REST API endpoint used to look up releases
+const DefaultAPIURL = "https://api.github.com"
+
+// checksumsAssetName is the goreleaser-style checksum file name
+const checksumsAssetName = "checksums.txt"
+
+// Asset is a downloadable file attached to a release
+type Asset struct {
+	Name string `json:"name"`
+	URL  string `json:"browser_download_url"`
+}
+
+// Release is a published GitHub release
+type Release struct {
+	TagName string  `json:"tag_name"`
+	Assets  []Asset `json:"assets"`
+}
+
+// Version returns the release tag without a leading "v"
+func (r *Release) Version() string {
+	return strings.TrimPrefix(r.TagName, "v")
+}
+
+// FindAsset returns the binary asset for the given platform.
+// Assets must be named <binary>_<os>_<arch> or <binary>-<os>-<arch>, optionally with .exe.
+func (r *Release) FindAsset(binaryName, goos, goarch string) (*Asset, error) {
+	candidates := []string{
+		fmt.Sprintf("%s_%s_%s", binaryName, goos, goarch),
+		fmt.Sprintf("%s-%s-%s", binaryName, goos, goarch),
+	}
+	for _, a := range r.Assets {
+		name := strings.TrimSuffix(a.Name, ".exe")
+		for _, c := range candidates {
+			if name == c {
+				asset := a
+				return &asset, nil
+			}
+		}
+	}
+	return nil, fmt.Errorf("release %s has no asset for %s/%s", r.TagName, goos, goarch)
+}
+
+func (r *Release) findAssetByName(name string) *Asset {
+	for _, a := range r.Assets {
+		if a.Name == name {
+			asset := a
+			return &asset
+		}
+	}
+	return nil
+}
+
+// Updater checks GitHub Releases for new versions and replaces the running binary
+type Updater struct {
+	APIURL     string
+	Repo       string // owner/name
+	BinaryName string
+	Client     *http.Client
+	// PublicKey is the minisign or signify key the checksums of releases must
+	// be signed with; Apply refuses to install anything without it
+	PublicKey string
+}
+
+// Latest returns the latest published release
+func (u *Updater) Latest(ctx context.Context) (*Release, error) {
+	url := fmt.Sprintf("%s/repos/%s/releases/latest", strings.TrimSuffix(u.APIURL, "/"), u.Repo)
+	body, err := u.get(ctx, url)
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up latest release: %w", err)
+	}
+	defer body.Close()
+
+	var rel Release
+	if err := json.NewDecoder(body).Decode(&rel); err != nil {
+		return nil, fmt.Errorf("failed to decode release: %w", err)
+	}
+	zerolog.Ctx(ctx).Debug().Str("repo", u.Repo).Str("tag", rel.TagName).Msg("Found latest release")
+	return &rel, nil
+}
+
+// Apply downloads the release asset for the running platform, verifies its checksum
+// and atomically replaces the file at target. The checksum file must be signed
+// with PublicKey (<checksums>.minisig or .sig), so a release whose assets were
+// replaced by someone without the private key is refused.
+func (u *Updater) Apply(ctx context.Context, rel *Release, target string) error {
+	if u.PublicKey == "" {
+		return fmt.Errorf("no public key configured to verify release %s, refusing to update", rel.TagName)
+	}
+	key, err := ParsePublicKey(u.PublicKey)
+	if err != nil {
+		return err
+	}
+	asset, err := rel.FindAsset(u.BinaryName, runtime.GOOS, runtime.GOARCH)
+	if err != nil {
+		return err
+	}
+
+	want, err := u.expectedChecksum(ctx, rel, asset.Name, key)
+	if err != nil {
+		return err
+	}
+
+	body, err := u.get(ctx, asset.URL)
+	if err != nil {
+		return fmt.Errorf("failed to download %s: %w", asset.Name, err)
+	}
+	defer body.Close()
+
+	tmp, err := os.CreateTemp(filepath.Dir(target), "."+filepath.Base(target)+".new.*")
+	if err != nil {
+		return fmt.Errorf("failed to create temporary file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	defer tmp.Close()
+
+	hash := sha256.New()
+	if _, err := io.Copy(io.MultiWriter(tmp, hash), body); err != nil {
+		return fmt.Errorf("failed to download %s: %w", asset.Name, err)
+	}
+	if got := hex.EncodeToString(hash.Sum(nil)); got != want {
+		return fmt.Errorf("checksum mismatch for %s: expected %s, got %s", asset.Name, want, got)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write %s: %w", tmp.Name(), err)
+	}
+	if err := os.Chmod(tmp.Name(), 0o755); err != nil {
+		return fmt.Errorf("failed to make binary executable: %w", err)
+	}
+
+	return replaceFile(tmp.Name(), target, runtime.GOOS == "windows")
+}
+
+// expectedChecksum finds the SHA-256 of assetName in checksums.txt or
+// <asset>.sha256, after verifying the signature of that file with key
+func (u *Updater) expectedChecksum(ctx context.Context, rel *Release, assetName string, key *PublicKey) (string, error) {
+	sumAsset := rel.findAssetByName(checksumsAssetName)
+	if sumAsset == nil {
+		sumAsset = rel.findAssetByName(assetName + ".sha256")
+	}
+	if sumAsset == nil {
+		return "", fmt.Errorf("release %s publishes no checksum for %s, refusing to update", rel.TagName, assetName)
+	}
+	var sigAsset *Asset
+	for _, suffix := range signatureSuffixes {
+		if sigAsset = rel.findAssetByName(sumAsset.Name + suffix); sigAsset != nil {
+			break
+		}
+	}
+	if sigAsset == nil {
+		return "", fmt.Errorf("release %s publishes no signature for %s, refusing to update", rel.TagName, sumAsset.Name)
+	}
+
+	sums, err := u.download(ctx, sumAsset)
+	if err != nil {
+		return "", err
+	}
+	sig, err := u.download(ctx, sigAsset)
+	if err != nil {
+		return "", err
+	}
+	if err := key.Verify(sums, sig); err != nil {
+		return "", fmt.Errorf("failed to verify %s: %w", sumAsset.Name, err)
+	}
+
+	scanner := bufio.NewScanner(bytes.NewReader(sums))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		switch {
+		case len(fields) == 1 && sumAsset.Name != checksumsAssetName:
+			return strings.ToLower(fields[0]), nil
+		case len(fields) >= 2 && strings.TrimPrefix(fields[1], "*") == assetName:
+			return strings.ToLower(fields[0]), nil
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return "", fmt.Errorf("failed to read %s: %w", sumAsset.Name, err)
+	}
+	return "", fmt.Errorf("%s has no entry for %s", sumAsset.Name, assetName)
+}
+
+// maxSmallAsset bounds the size of checksum and signature files
+const maxSmallAsset = 1 << 20
+
+// download returns the content of a small asset such as a checksum file
+func (u *Updater) download(ctx context.Context, a *Asset) ([]byte, error) {
+	body, err := u.get(ctx, a.URL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download %s: %w", a.Name, err)
+	}
+	defer body.Close()
+	data, err := io.ReadAll(io.LimitReader(body, maxSmallAsset+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to download %s: %w", a.Name, err)
+	}
+	if len(data) > maxSmallAsset {
+		return nil, fmt.Errorf("%s is larger than %d bytes", a.Name, maxSmallAsset)
+	}
+	return data, nil
+}
+
+func (u *Updater) get(ctx context.Context, url string) (io.ReadCloser, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+
+	client := u.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, fmt.Errorf("unexpected status: %s", resp.Status)
+	}
+	return resp.Body, nil
+}
+
+// replaceFile moves src over dst. Windows cannot overwrite a running executable,
+// so there the old binary is moved aside first, and moved back if src cannot
+// take its place.
+func replaceFile(src, dst string, moveAside bool) error {
+	if !moveAside {
+		if err := os.Rename(src, dst); err != nil {
+			return fmt.Errorf("failed to replace binary: %w", err)
+		}
+		return nil
+	}
+
+	old := dst + ".old"
+	_ = os.Remove(old)
+	if err := os.Rename(dst, old); err != nil {
+		return fmt.Errorf("failed to move old binary aside: %w", err)
+	}
+	if err := os.Rename(src, dst); err != nil {
+		if restoreErr := os.Rename(old, dst); restoreErr != nil {
+			return fmt.Errorf("failed to replace binary: %w; restoring the old binary from %s also failed: %v", err, old, restoreErr)
+		}
+		return fmt.Errorf("failed to replace binary: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,216 @@
+// internal/update/update_test.go
+
+package update
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+const testBinary = "mycli"
+
+// newReleaseServer serves a latest release whose platform asset contains payload.
+// checksums is the content of checksums.txt, or empty to publish no checksum, and
+// sig its signature, or empty to publish none.
+func newReleaseServer(t *testing.T, payload, checksums, sig string) *httptest.Server {
+	t.Helper()
+	assetName := fmt.Sprintf("%s_%s_%s", testBinary, runtime.GOOS, runtime.GOARCH)
+
+	mux := http.NewServeMux()
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+
+	mux.HandleFunc("/repos/acme/mycli/releases/latest", func(w http.ResponseWriter, r *http.Request) {
+		rel := Release{
+			TagName: "v1.2.0",
+			Assets:  []Asset{{Name: assetName, URL: srv.URL + "/download/" + assetName}},
+		}
+		if checksums != "" {
+			rel.Assets = append(rel.Assets, Asset{Name: checksumsAssetName, URL: srv.URL + "/download/" + checksumsAssetName})
+		}
+		if sig != "" {
+			rel.Assets = append(rel.Assets, Asset{Name: checksumsAssetName + ".minisig", URL: srv.URL + "/download/" + checksumsAssetName + ".minisig"})
+		}
+		_ = json.NewEncoder(w).Encode(rel)
+	})
+	mux.HandleFunc("/download/"+assetName, func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(payload))
+	})
+	mux.HandleFunc("/download/"+checksumsAssetName, func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(checksums))
+	})
+	mux.HandleFunc("/download/"+checksumsAssetName+".minisig", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(sig))
+	})
+	return srv
+}
+
+func checksumLine(payload, name string) string {
+	sum := sha256.Sum256([]byte(payload))
+	return hex.EncodeToString(sum[:]) + "  " + name + "\n"
+}
+
+func TestUpdater_LatestAndApply(t *testing.T) {
+	payload := "new binary"
+	assetName := fmt.Sprintf("%s_%s_%s", testBinary, runtime.GOOS, runtime.GOARCH)
+	checksums := checksumLine("other", "other-asset") + checksumLine(payload, assetName)
+	signer := newTestSigner(t)
+	srv := newReleaseServer(t, payload, checksums, signer.sign([]byte(checksums), "file:checksums.txt"))
+
+	u := &Updater{APIURL: srv.URL, Repo: "acme/mycli", BinaryName: testBinary, PublicKey: signer.publicKey}
+	rel, err := u.Latest(context.Background())
+	if err != nil {
+		t.Fatalf("Latest() error = %v", err)
+	}
+	if rel.Version() != "1.2.0" {
+		t.Errorf("Version() = %q, want %q", rel.Version(), "1.2.0")
+	}
+
+	target := filepath.Join(t.TempDir(), testBinary)
+	if err := os.WriteFile(target, []byte("old binary"), 0o755); err != nil {
+		t.Fatalf("Failed to write target: %v", err)
+	}
+	if err := u.Apply(context.Background(), rel, target); err != nil {
+		t.Fatalf("Apply() error = %v", err)
+	}
+
+	data, err := os.ReadFile(target)
+	if err != nil || string(data) != payload {
+		t.Errorf("target content = %q (err %v), want %q", data, err, payload)
+	}
+}
+
+func TestUpdater_ApplyRefusesUnverified(t *testing.T) {
+	signer, other := newTestSigner(t), newTestSigner(t)
+	assetName := fmt.Sprintf("%s_%s_%s", testBinary, runtime.GOOS, runtime.GOARCH)
+	valid := checksumLine("new binary", assetName)
+	tests := []struct {
+		name      string
+		checksums string
+		sig       string
+		publicKey string
+		wantErr   string
+	}{
+		{"No checksum published", "", "", signer.publicKey, "publishes no checksum"},
+		{"Checksum mismatch", checksumLine("tampered", assetName), signer.sign([]byte(checksumLine("tampered", assetName)), ""), signer.publicKey, "checksum mismatch"},
+		{"Checksum missing for asset", checksumLine("x", "other-asset"), signer.sign([]byte(checksumLine("x", "other-asset")), ""), signer.publicKey, "has no entry"},
+		{"No public key", valid, signer.sign([]byte(valid), ""), "", "no public key configured"},
+		{"No signature published", valid, "", signer.publicKey, "publishes no signature"},
+		{"Signed by another key", valid, other.sign([]byte(valid), ""), signer.publicKey, "another key"},
+		{"Signature of other checksums", valid, signer.sign([]byte(checksumLine("evil", assetName)), ""), signer.publicKey, "verification failed"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := newReleaseServer(t, "new binary", tt.checksums, tt.sig)
+			u := &Updater{APIURL: srv.URL, Repo: "acme/mycli", BinaryName: testBinary, PublicKey: tt.publicKey}
+			rel, err := u.Latest(context.Background())
+			if err != nil {
+				t.Fatalf("Latest() error = %v", err)
+			}
+
+			target := filepath.Join(t.TempDir(), testBinary)
+			if err := os.WriteFile(target, []byte("old binary"), 0o755); err != nil {
+				t.Fatalf("Failed to write target: %v", err)
+			}
+
+			err = u.Apply(context.Background(), rel, target)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("Apply() error = %v, want containing %q", err, tt.wantErr)
+			}
+			if data, _ := os.ReadFile(target); string(data) != "old binary" {
+				t.Errorf("Target was modified despite failed verification: %q", data)
+			}
+		})
+	}
+}
+
+func TestReplaceFile_MoveAside(t *testing.T) {
+	dir := t.TempDir()
+	dst := filepath.Join(dir, testBinary)
+	src := filepath.Join(dir, "new")
+	if err := os.WriteFile(dst, []byte("old binary"), 0o755); err != nil {
+		t.Fatalf("Failed to write target: %v", err)
+	}
+	if err := os.WriteFile(src, []byte("new binary"), 0o755); err != nil {
+		t.Fatalf("Failed to write new binary: %v", err)
+	}
+
+	if err := replaceFile(src, dst, true); err != nil {
+		t.Fatalf("replaceFile() error = %v", err)
+	}
+	if data, _ := os.ReadFile(dst); string(data) != "new binary" {
+		t.Errorf("Target = %q, want the new binary", data)
+	}
+	if data, _ := os.ReadFile(dst + ".old"); string(data) != "old binary" {
+		t.Errorf("Moved aside binary = %q, want the old binary", data)
+	}
+}
+
+func TestReplaceFile_RestoresOldBinary(t *testing.T) {
+	dst := filepath.Join(t.TempDir(), testBinary)
+	if err := os.WriteFile(dst, []byte("old binary"), 0o755); err != nil {
+		t.Fatalf("Failed to write target: %v", err)
+	}
+
+	// The new binary is missing, so it cannot take the place of the old one
+	err := replaceFile(dst+".missing", dst, true)
+	if err == nil || !strings.Contains(err.Error(), "failed to replace binary") {
+		t.Fatalf("replaceFile() error = %v, want a failed replace", err)
+	}
+	if data, _ := os.ReadFile(dst); string(data) != "old binary" {
+		t.Errorf("Target = %q, want the old binary restored", data)
+	}
+	if _, err := os.Stat(dst + ".old"); !os.IsNotExist(err) {
+		t.Errorf("Expected the moved aside binary to be gone, got %v", err)
+	}
+}
+
+func TestUpdater_LatestError(t *testing.T) {
+	srv := httptest.NewServer(http.NotFoundHandler())
+	defer srv.Close()
+
+	u := &Updater{APIURL: srv.URL, Repo: "acme/mycli", BinaryName: testBinary}
+	if _, err := u.Latest(context.Background()); err == nil {
+		t.Errorf("Expected error for missing release")
+	}
+}
+
+func TestRelease_FindAsset(t *testing.T) {
+	rel := &Release{TagName: "v1.0.0", Assets: []Asset{
+		{Name: "mycli-linux-amd64"},
+		{Name: "mycli_windows_amd64.exe"},
+		{Name: "checksums.txt"},
+	}}
+
+	tests := []struct {
+		goos, goarch string
+		want         string
+		wantErr      bool
+	}{
+		{"linux", "amd64", "mycli-linux-amd64", false},
+		{"windows", "amd64", "mycli_windows_amd64.exe", false},
+		{"darwin", "arm64", "", true},
+	}
+
+	for _, tt := range tests {
+		asset, err := rel.FindAsset("mycli", tt.goos, tt.goarch)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("FindAsset(%s/%s) error = %v, wantErr %v", tt.goos, tt.goarch, err, tt.wantErr)
+			continue
+		}
+		if err == nil && asset.Name != tt.want {
+			t.Errorf("FindAsset(%s/%s) = %q, want %q", tt.goos, tt.goarch, asset.Name, tt.want)
+		}
+	}
+}
@@ -0,0 +1,155 @@
+// internal/update/version.go
+
+package update
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// semver is a parsed MAJOR.MINOR.PATCH[-PRERELEASE] version
+type semver struct {
+	major, minor, patch int
+	prerelease          string
+}
+
+// parseVersion parses a semantic version with an optional leading "v"
+func parseVersion(s string) (semver, error) {
+	v := strings.TrimPrefix(strings.TrimSpace(s), "v")
+	if i := strings.IndexByte(v, '+'); i >= 0 {
+		v = v[:i] // build metadata does not affect precedence
+	}
+
+	var sv semver
+	if i := strings.IndexByte(v, '-'); i >= 0 {
+		sv.prerelease = v[i+1:]
+		v = v[:i]
+	}
+
+	parts := strings.Split(v, ".")
+	if len(parts) != 3 {
+		return semver{}, fmt.Errorf("invalid version %q: expected MAJOR.MINOR.PATCH", s)
+	}
+	nums := make([]int, 3)
+	for i, p := range parts {
+		n, err := strconv.Atoi(p)
+		if err != nil || n < 0 {
+			return semver{}, fmt.Errorf("invalid version %q: %q is not a number", s, p)
+		}
+		nums[i] = n
+	}
+	sv.major, sv.minor, sv.patch = nums[0], nums[1], nums[2]
+	return sv, nil
+}
+
+// CompareVersions returns -1, 0 or 1 when a is older than, equal to or newer than b.
+// A pre-release sorts before the corresponding release.
+func CompareVersions(a, b string) (int, error) {
+	va, err := parseVersion(a)
+	if err != nil {
+		return 0, err
+	}
+	vb, err := parseVersion(b)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, d := range []int{va.major - vb.major, va.minor - vb.minor, va.patch - vb.patch} {
+		if d != 0 {
+			return sign(d), nil
+		}
+	}
+
+	switch {
+	case va.prerelease == vb.prerelease:
+		return 0, nil
+	case va.prerelease == "":
+		return 1, nil
+	case vb.prerelease == "":
+		return -1, nil
+	}
+	return comparePrerelease(va.prerelease, vb.prerelease), nil
+}
+
+// comparePrerelease compares pre-release versions as SemVer 2.0.0 §11 does:
+// identifier by identifier, numeric ones as numbers and before alphanumeric
+// ones, and a longer list after its prefix
+func comparePrerelease(a, b string) int {
+	as, bs := strings.Split(a, "."), strings.Split(b, ".")
+	for i := 0; i < len(as) && i < len(bs); i++ {
+		if c := compareIdentifier(as[i], bs[i]); c != 0 {
+			return c
+		}
+	}
+	return sign(len(as) - len(bs))
+}
+
+func compareIdentifier(a, b string) int {
+	aNum, bNum := isNumeric(a), isNumeric(b)
+	switch {
+	case aNum && bNum:
+		// Without leading zeros the longer number is larger, at any size
+		if len(a) != len(b) {
+			return sign(len(a) - len(b))
+		}
+	case aNum:
+		return -1
+	case bNum:
+		return 1
+	}
+	return strings.Compare(a, b)
+}
+
+func isNumeric(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+func sign(n int) int {
+	switch {
+	case n < 0:
+		return -1
+	case n > 0:
+		return 1
+	}
+	return 0
+}
+
+// Upgrade kinds returned by UpgradeKind
+const (
+	Major      = "major"
+	Minor      = "minor"
+	Patch      = "patch"
+	Prerelease = "prerelease"
+)
+
+// UpgradeKind returns which part of the version changes from from to to:
+// Major, Minor, Patch, or Prerelease when only the pre-release differs.
+func UpgradeKind(from, to string) (string, error) {
+	a, err := parseVersion(from)
+	if err != nil {
+		return "", err
+	}
+	b, err := parseVersion(to)
+	if err != nil {
+		return "", err
+	}
+
+	switch {
+	case a.major != b.major:
+		return Major, nil
+	case a.minor != b.minor:
+		return Minor, nil
+	case a.patch != b.patch:
+		return Patch, nil
+	}
+	return Prerelease, nil
+}
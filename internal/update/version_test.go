@@ -0,0 +1,83 @@
+// internal/update/version_test.go
+
+package update
+
+import "testing"
+
+func TestCompareVersions(t *testing.T) {
+	tests := []struct {
+		a, b    string
+		want    int
+		wantErr bool
+	}{
+		{"1.0.0", "1.0.0", 0, false},
+		{"v1.2.3", "1.2.3", 0, false},
+		{"1.0.0", "1.0.1", -1, false},
+		{"1.10.0", "1.9.9", 1, false},
+		{"2.0.0", "10.0.0", -1, false},
+		{"1.0.0-rc.1", "1.0.0", -1, false},
+		{"1.0.0", "1.0.0-rc.1", 1, false},
+		{"1.0.0-alpha", "1.0.0-beta", -1, false},
+		{"1.0.0-rc.9", "1.0.0-rc.10", -1, false},
+		{"1.0.0-rc.10", "1.0.0-rc.9", 1, false},
+		{"1.0.0-alpha", "1.0.0-alpha.1", -1, false},
+		{"1.0.0-alpha.1", "1.0.0-alpha.beta", -1, false},
+		{"1.0.0-beta.11", "1.0.0-rc.1", -1, false},
+		{"1.0.0-alpha.1", "1.0.0-alpha.1", 0, false},
+		{"1.0.0+build.5", "1.0.0", 0, false},
+		{"dev", "1.0.0", 0, true},
+		{"1.0", "1.0.0", 0, true},
+		{"1.0.0", "1.x.0", 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.a+"_vs_"+tt.b, func(t *testing.T) {
+			got, err := CompareVersions(tt.a, tt.b)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("CompareVersions() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("CompareVersions(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUpgradeKind(t *testing.T) {
+	tests := []struct {
+		from, to string
+		want     string
+		wantErr  bool
+	}{
+		{"v0.9.1", "v1.0.0", Major, false},
+		{"v1.2.3", "v1.3.0", Minor, false},
+		{"v1.2.3", "v1.2.4", Patch, false},
+		{"v1.2.3-rc.1", "v1.2.3", Prerelease, false},
+		{"v0.0.0-20230905200255-921286631fa9", "v0.1.0", Minor, false},
+		{"dev", "v1.0.0", "", true},
+		{"v1.0.0", "latest", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.from+"_to_"+tt.to, func(t *testing.T) {
+			got, err := UpgradeKind(tt.from, tt.to)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("UpgradeKind() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("UpgradeKind(%q, %q) = %q, want %q", tt.from, tt.to, got, tt.want)
+			}
+		})
+	}
+}
+
+func FuzzCompareVersions(f *testing.F) {
+	for _, s := range []string{"1.2.3", "v1.0.0-rc.1", "1.0.0+build", "1.2", "v-1.2.3", " 1.2.3 "} {
+		f.Add(s)
+	}
+	f.Fuzz(func(t *testing.T, s string) {
+		if got, err := CompareVersions(s, s); err == nil && got != 0 {
+			t.Fatalf("CompareVersions(%q, %q) = %d, want 0", s, s, got)
+		}
+	})
+}
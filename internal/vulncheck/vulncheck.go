@@ -0,0 +1,199 @@
+// internal/vulncheck/vulncheck.go
+
+// Package vulncheck reads the JSON output of govulncheck and keeps only the
+// vulnerabilities whose vulnerable symbols are actually called, together with
+// the version that fixes them. Accepted risks can be ignored until a date.
+package vulncheck
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// stdlib is the module govulncheck reports for vulnerabilities in the standard library
+const stdlib = "stdlib"
+
+// message is one object of the govulncheck -json stream; only the parts used here
+type message struct {
+	OSV     *osvEntry `json:"osv"`
+	Finding *finding  `json:"finding"`
+}
+
+type osvEntry struct {
+	ID      string `json:"id"`
+	Summary string `json:"summary"`
+}
+
+type finding struct {
+	OSV          string  `json:"osv"`
+	FixedVersion string  `json:"fixed_version"`
+	Trace        []frame `json:"trace"`
+}
+
+type frame struct {
+	Module   string `json:"module"`
+	Version  string `json:"version"`
+	Package  string `json:"package"`
+	Function string `json:"function"`
+	Receiver string `json:"receiver"`
+}
+
+// Vuln is a vulnerability reachable from the scanned code
+type Vuln struct {
+	ID           string   `json:"id"`
+	Summary      string   `json:"summary"`
+	Module       string   `json:"module"`
+	Version      string   `json:"version"`
+	FixedVersion string   `json:"fixed_version,omitempty"`
+	Symbols      []string `json:"symbols"`
+}
+
+// Remediation returns the command that upgrades to the fixed version
+func (v Vuln) Remediation() string {
+	switch {
+	case v.FixedVersion == "":
+		return "no fixed version available"
+	case v.Module == stdlib:
+		return "upgrade Go to " + strings.TrimPrefix(v.FixedVersion, "v")
+	}
+	return fmt.Sprintf("go get %s@%s", v.Module, v.FixedVersion)
+}
+
+// Parse reads a govulncheck -json stream and returns the reachable vulnerabilities,
+// i.e. those with a finding at symbol level, sorted by ID. Findings for modules or
+// packages that are imported but whose vulnerable code is never called are dropped.
+func Parse(r io.Reader) ([]Vuln, error) {
+	summaries := map[string]string{}
+	byKey := map[string]*Vuln{}
+
+	dec := json.NewDecoder(r)
+	for {
+		var msg message
+		if err := dec.Decode(&msg); errors.Is(err, io.EOF) {
+			break
+		} else if err != nil {
+			return nil, fmt.Errorf("invalid govulncheck output: %w", err)
+		}
+
+		if msg.OSV != nil {
+			summaries[msg.OSV.ID] = msg.OSV.Summary
+		}
+		f := msg.Finding
+		if f == nil || len(f.Trace) == 0 || f.Trace[0].Function == "" {
+			continue
+		}
+
+		top := f.Trace[0]
+		key := f.OSV + " " + top.Module
+		v, ok := byKey[key]
+		if !ok {
+			v = &Vuln{ID: f.OSV, Module: top.Module, Version: top.Version, FixedVersion: f.FixedVersion}
+			byKey[key] = v
+		}
+		symbol := top.Package + "." + top.Function
+		if top.Receiver != "" {
+			symbol = top.Package + "." + strings.TrimPrefix(top.Receiver, "*") + "." + top.Function
+		}
+		if !contains(v.Symbols, symbol) {
+			v.Symbols = append(v.Symbols, symbol)
+		}
+	}
+
+	vulns := make([]Vuln, 0, len(byKey))
+	for _, v := range byKey {
+		v.Summary = summaries[v.ID]
+		sort.Strings(v.Symbols)
+		vulns = append(vulns, *v)
+	}
+	sort.Slice(vulns, func(i, j int) bool {
+		if vulns[i].ID != vulns[j].ID {
+			return vulns[i].ID < vulns[j].ID
+		}
+		return vulns[i].Module < vulns[j].Module
+	})
+	return vulns, nil
+}
+
+func contains(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
+
+// Ignore accepts the risk of one vulnerability until Expires
+type Ignore struct {
+	ID     string `yaml:"id" json:"id"`
+	Reason string `yaml:"reason" json:"reason"`
+	// Expires is a date (YYYY-MM-DD); the ignore stops applying at the start of that day
+	Expires string `yaml:"expires" json:"expires"`
+}
+
+// expired reports whether the ignore no longer applies at now
+func (i Ignore) expired(now time.Time) bool {
+	t, err := time.Parse(time.DateOnly, i.Expires)
+	return err == nil && !now.Before(t)
+}
+
+// ignoreFile is the layout of the ignore file
+type ignoreFile struct {
+	Ignore []Ignore `yaml:"ignore"`
+}
+
+// LoadIgnores reads the ignore file at path. A missing file means nothing is ignored.
+// Every entry needs an ID, a reason and a valid expiry date, so accepted risks are
+// documented and revisited.
+func LoadIgnores(path string) ([]Ignore, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read ignore file: %w", err)
+	}
+
+	var f ignoreFile
+	if err := yaml.Unmarshal(data, &f); err != nil {
+		return nil, fmt.Errorf("invalid ignore file %s: %w", path, err)
+	}
+	for _, i := range f.Ignore {
+		if i.ID == "" || i.Reason == "" {
+			return nil, fmt.Errorf("invalid ignore file %s: every entry needs an id and a reason", path)
+		}
+		if _, err := time.Parse(time.DateOnly, i.Expires); err != nil {
+			return nil, fmt.Errorf("invalid ignore file %s: %s has invalid expiry %q (want YYYY-MM-DD)", path, i.ID, i.Expires)
+		}
+	}
+	return f.Ignore, nil
+}
+
+// Filter splits vulns into those still to be fixed and those ignored at now,
+// and returns the ignores that have expired.
+func Filter(vulns []Vuln, ignores []Ignore, now time.Time) (active, ignored []Vuln, expired []Ignore) {
+	valid := map[string]bool{}
+	for _, i := range ignores {
+		if i.expired(now) {
+			expired = append(expired, i)
+		} else {
+			valid[i.ID] = true
+		}
+	}
+	for _, v := range vulns {
+		if valid[v.ID] {
+			ignored = append(ignored, v)
+		} else {
+			active = append(active, v)
+		}
+	}
+	return active, ignored, expired
+}
@@ -0,0 +1,162 @@
+// internal/vulncheck/vulncheck_test.go
+
+package vulncheck
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// govulncheckOutput mimics govulncheck -json: pretty-printed objects, one finding
+// per level, and a vulnerability that is imported but never called.
+const govulncheckOutput = `{
+  "config": {"protocol_version": "v1.0.0", "scanner_name": "govulncheck"}
+}
+{
+  "progress": {"message": "Scanning your code..."}
+}
+{
+  "osv": {"id": "GO-2024-0001", "summary": "Panic in YAML parsing"}
+}
+{
+  "osv": {"id": "GO-2024-0002", "summary": "Unused vulnerable package"}
+}
+{
+  "osv": {"id": "GO-2024-0003", "summary": "Path traversal in net/http"}
+}
+{
+  "finding": {"osv": "GO-2024-0001", "fixed_version": "v3.0.2",
+    "trace": [{"module": "gopkg.in/yaml.v3", "version": "v3.0.1"}]}
+}
+{
+  "finding": {"osv": "GO-2024-0001", "fixed_version": "v3.0.2",
+    "trace": [{"module": "gopkg.in/yaml.v3", "version": "v3.0.1", "package": "gopkg.in/yaml.v3", "function": "Unmarshal"},
+              {"module": "example.com/app", "package": "example.com/app", "function": "main"}]}
+}
+{
+  "finding": {"osv": "GO-2024-0001", "fixed_version": "v3.0.2",
+    "trace": [{"module": "gopkg.in/yaml.v3", "version": "v3.0.1", "package": "gopkg.in/yaml.v3", "function": "Decode", "receiver": "*Decoder"}]}
+}
+{
+  "finding": {"osv": "GO-2024-0002", "fixed_version": "v1.1.0",
+    "trace": [{"module": "example.com/lib", "version": "v1.0.0", "package": "example.com/lib"}]}
+}
+{
+  "finding": {"osv": "GO-2024-0003", "fixed_version": "v1.23.4",
+    "trace": [{"module": "stdlib", "version": "v1.23.3", "package": "net/http", "function": "ServeFile"}]}
+}
+`
+
+func TestParse(t *testing.T) {
+	vulns, err := Parse(strings.NewReader(govulncheckOutput))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	want := []Vuln{
+		{ID: "GO-2024-0001", Summary: "Panic in YAML parsing", Module: "gopkg.in/yaml.v3", Version: "v3.0.1",
+			FixedVersion: "v3.0.2", Symbols: []string{"gopkg.in/yaml.v3.Decoder.Decode", "gopkg.in/yaml.v3.Unmarshal"}},
+		{ID: "GO-2024-0003", Summary: "Path traversal in net/http", Module: "stdlib", Version: "v1.23.3",
+			FixedVersion: "v1.23.4", Symbols: []string{"net/http.ServeFile"}},
+	}
+	if !reflect.DeepEqual(vulns, want) {
+		t.Errorf("Parse() = %+v\nwant %+v", vulns, want)
+	}
+}
+
+func TestParse_Invalid(t *testing.T) {
+	if _, err := Parse(strings.NewReader("{not json")); err == nil {
+		t.Error("Expected an error for invalid output")
+	}
+	if vulns, err := Parse(strings.NewReader("")); err != nil || len(vulns) != 0 {
+		t.Errorf("Parse(empty) = %v, %v; want no vulnerabilities", vulns, err)
+	}
+}
+
+func TestRemediation(t *testing.T) {
+	tests := []struct {
+		vuln Vuln
+		want string
+	}{
+		{Vuln{Module: "gopkg.in/yaml.v3", FixedVersion: "v3.0.2"}, "go get gopkg.in/yaml.v3@v3.0.2"},
+		{Vuln{Module: "stdlib", FixedVersion: "v1.23.4"}, "upgrade Go to 1.23.4"},
+		{Vuln{Module: "example.com/lib"}, "no fixed version available"},
+	}
+	for _, tt := range tests {
+		if got := tt.vuln.Remediation(); got != tt.want {
+			t.Errorf("Remediation() = %q, want %q", got, tt.want)
+		}
+	}
+}
+
+func TestLoadIgnores(t *testing.T) {
+	dir := t.TempDir()
+	write := func(content string) string {
+		path := filepath.Join(dir, "ignore.yaml")
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+
+	ignores, err := LoadIgnores(filepath.Join(dir, "missing.yaml"))
+	if err != nil || ignores != nil {
+		t.Errorf("LoadIgnores(missing) = %v, %v; want nothing", ignores, err)
+	}
+
+	ignores, err = LoadIgnores(write("ignore:\n  - id: GO-2024-0001\n    reason: input is trusted\n    expires: 2030-01-01\n"))
+	if err != nil || len(ignores) != 1 || ignores[0].Reason != "input is trusted" {
+		t.Errorf("LoadIgnores() = %+v, %v", ignores, err)
+	}
+
+	for _, bad := range []string{
+		"ignore: [",
+		"ignore:\n  - id: GO-2024-0001\n    expires: 2030-01-01\n",
+		"ignore:\n  - id: GO-2024-0001\n    reason: later\n    expires: soon\n",
+	} {
+		if _, err := LoadIgnores(write(bad)); err == nil {
+			t.Errorf("Expected an error for %q", bad)
+		}
+	}
+}
+
+func TestFilter(t *testing.T) {
+	vulns := []Vuln{{ID: "GO-1"}, {ID: "GO-2"}, {ID: "GO-3"}}
+	ignores := []Ignore{
+		{ID: "GO-1", Reason: "accepted", Expires: "2024-07-01"},
+		{ID: "GO-2", Reason: "accepted", Expires: "2024-06-01"},
+	}
+	now := time.Date(2024, 6, 15, 0, 0, 0, 0, time.UTC)
+
+	active, ignored, expired := Filter(vulns, ignores, now)
+	if len(active) != 2 || active[0].ID != "GO-2" || active[1].ID != "GO-3" {
+		t.Errorf("active = %+v, want GO-2 and GO-3", active)
+	}
+	if len(ignored) != 1 || ignored[0].ID != "GO-1" {
+		t.Errorf("ignored = %+v, want GO-1", ignored)
+	}
+	if len(expired) != 1 || expired[0].ID != "GO-2" {
+		t.Errorf("expired = %+v, want GO-2", expired)
+	}
+}
+
+func FuzzParse(f *testing.F) {
+	f.Add(govulncheckOutput)
+	f.Add(`{"finding": {"osv": "GO-1", "trace": [{"module": "m", "function": "F", "receiver": "*T"}]}}`)
+	f.Add(`{"finding": {"trace": []}}{`)
+	f.Fuzz(func(t *testing.T, s string) {
+		vulns, err := Parse(strings.NewReader(s))
+		if err != nil {
+			return
+		}
+		for _, v := range vulns {
+			if len(v.Symbols) == 0 {
+				t.Fatalf("Parse() returned %+v without symbols", v)
+			}
+		}
+	})
+}
@@ -0,0 +1,204 @@
+// internal/xdg/xdg.go
+
+// Package xdg resolves per-application directories following the XDG Base Directory
+// specification, with the platform conventions of os.UserCacheDir on macOS and Windows.
+package xdg
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"time"
+)
+
+// Dirs resolves the directories of apps. The zero value uses the platform
+// directories; the package-level functions use it.
+type Dirs struct {
+	// Root, when set, holds the directories of every app in subdirectories:
+	// cache, data, state and run. This makes runs hermetic, e.g. in CI or to
+	// reproduce a bug report with a bundled directory.
+	Root string
+}
+
+// CacheDir returns the cache directory for app, see Dirs.CacheDir
+func CacheDir(app string) (string, error) { return Dirs{}.CacheDir(app) }
+
+// CacheFile returns the path of name in the cache directory for app, see Dirs.CacheFile
+func CacheFile(app, name string) (string, error) { return Dirs{}.CacheFile(app, name) }
+
+// DataDir returns the data directory for app, see Dirs.DataDir
+func DataDir(app string) (string, error) { return Dirs{}.DataDir(app) }
+
+// StateDir returns the state directory for app, see Dirs.StateDir
+func StateDir(app string) (string, error) { return Dirs{}.StateDir(app) }
+
+// StateFile returns the path of name in the state directory for app, see Dirs.StateFile
+func StateFile(app, name string) (string, error) { return Dirs{}.StateFile(app, name) }
+
+// RuntimeDir returns the runtime directory for app, see Dirs.RuntimeDir
+func RuntimeDir(app string) (string, error) { return Dirs{}.RuntimeDir(app) }
+
+// RuntimeFile returns the path of name in the runtime directory for app, see Dirs.RuntimeFile
+func RuntimeFile(app, name string) (string, error) { return Dirs{}.RuntimeFile(app, name) }
+
+// Backup copies the file at path into the state directory for app, see Dirs.Backup
+func Backup(app, path string) (string, error) { return Dirs{}.Backup(app, path) }
+
+// CacheDir returns the cache directory for app, e.g. $XDG_CACHE_HOME/app.
+// The directory is not created.
+func (d Dirs) CacheDir(app string) (string, error) {
+	if d.Root != "" {
+		return filepath.Join(d.Root, "cache"), nil
+	}
+	base, err := os.UserCacheDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to determine cache directory: %w", err)
+	}
+	return filepath.Join(base, app), nil
+}
+
+// CacheFile returns the path of name inside the cache directory for app,
+// creating the directory if needed.
+func (d Dirs) CacheFile(app, name string) (string, error) {
+	dir, err := d.CacheDir(app)
+	if err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return "", fmt.Errorf("failed to create cache directory: %w", err)
+	}
+	return filepath.Join(dir, name), nil
+}
+
+// DataDir returns the data directory for app, e.g. $XDG_DATA_HOME/app
+// (~/.local/share/app by default). The directory is not created.
+func (d Dirs) DataDir(app string) (string, error) {
+	if d.Root != "" {
+		return filepath.Join(d.Root, "data"), nil
+	}
+	if dir := os.Getenv("XDG_DATA_HOME"); filepath.IsAbs(dir) {
+		return filepath.Join(dir, app), nil
+	}
+
+	switch runtime.GOOS {
+	case "windows", "darwin":
+		// Same location as os.UserConfigDir on these platforms.
+		base, err := os.UserConfigDir()
+		if err != nil {
+			return "", fmt.Errorf("failed to determine data directory: %w", err)
+		}
+		return filepath.Join(base, app), nil
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to determine data directory: %w", err)
+	}
+	return filepath.Join(home, ".local", "share", app), nil
+}
+
+// StateDir returns the state directory for app, e.g. $XDG_STATE_HOME/app
+// (~/.local/state/app by default). The directory is not created.
+func (d Dirs) StateDir(app string) (string, error) {
+	if d.Root != "" {
+		return filepath.Join(d.Root, "state"), nil
+	}
+	if dir := os.Getenv("XDG_STATE_HOME"); filepath.IsAbs(dir) {
+		return filepath.Join(dir, app), nil
+	}
+
+	switch runtime.GOOS {
+	case "windows":
+		// State is machine-local, so use LocalAppData rather than the roaming profile.
+		base, err := os.UserCacheDir()
+		if err != nil {
+			return "", fmt.Errorf("failed to determine state directory: %w", err)
+		}
+		return filepath.Join(base, app, "state"), nil
+	case "darwin":
+		base, err := os.UserConfigDir()
+		if err != nil {
+			return "", fmt.Errorf("failed to determine state directory: %w", err)
+		}
+		return filepath.Join(base, app, "state"), nil
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to determine state directory: %w", err)
+	}
+	return filepath.Join(home, ".local", "state", app), nil
+}
+
+// StateFile returns the path of name inside the state directory for app,
+// creating the directory if needed.
+func (d Dirs) StateFile(app, name string) (string, error) {
+	dir, err := d.StateDir(app)
+	if err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return "", fmt.Errorf("failed to create state directory: %w", err)
+	}
+	return filepath.Join(dir, name), nil
+}
+
+// RuntimeDir returns the runtime directory for app, $XDG_RUNTIME_DIR/app, for
+// lock files and sockets that must not outlive the session. Without
+// XDG_RUNTIME_DIR (macOS, Windows, cron jobs) it falls back to a "run"
+// directory inside the state directory. The directory is not created.
+func (d Dirs) RuntimeDir(app string) (string, error) {
+	if d.Root != "" {
+		return filepath.Join(d.Root, "run"), nil
+	}
+	if dir := os.Getenv("XDG_RUNTIME_DIR"); filepath.IsAbs(dir) {
+		return filepath.Join(dir, app), nil
+	}
+	state, err := d.StateDir(app)
+	if err != nil {
+		return "", fmt.Errorf("failed to determine runtime directory: %w", err)
+	}
+	return filepath.Join(state, "run"), nil
+}
+
+// RuntimeFile returns the path of name inside the runtime directory for app,
+// creating the directory if needed.
+func (d Dirs) RuntimeFile(app, name string) (string, error) {
+	dir, err := d.RuntimeDir(app)
+	if err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return "", fmt.Errorf("failed to create runtime directory: %w", err)
+	}
+	return filepath.Join(dir, name), nil
+}
+
+// Backup copies the file at path into the backups directory inside the state
+// directory for app, named after the file and the current time, and returns
+// the path of the copy. Backups keep the permissions of the original.
+func (d Dirs) Backup(app, path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return "", err
+	}
+	dir, err := d.StateDir(app)
+	if err != nil {
+		return "", err
+	}
+	dir = filepath.Join(dir, "backups")
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return "", fmt.Errorf("failed to create backup directory: %w", err)
+	}
+	stamp := time.Now().UTC().Format("20060102T150405.000000000Z")
+	backup := filepath.Join(dir, filepath.Base(path)+"."+stamp+".bak")
+	if err := os.WriteFile(backup, data, info.Mode().Perm()); err != nil {
+		return "", fmt.Errorf("failed to write backup: %w", err)
+	}
+	return backup, nil
+}
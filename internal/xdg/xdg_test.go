@@ -0,0 +1,186 @@
+// internal/xdg/xdg_test.go
+
+package xdg
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestCacheFile(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("XDG_CACHE_HOME is only honored on Linux")
+	}
+	base := t.TempDir()
+	t.Setenv("XDG_CACHE_HOME", base)
+
+	dir, err := CacheDir("myapp")
+	if err != nil {
+		t.Fatalf("CacheDir() error = %v", err)
+	}
+	if want := filepath.Join(base, "myapp"); dir != want {
+		t.Errorf("CacheDir() = %q, want %q", dir, want)
+	}
+
+	path, err := CacheFile("myapp", "state.json")
+	if err != nil {
+		t.Fatalf("CacheFile() error = %v", err)
+	}
+	if want := filepath.Join(base, "myapp", "state.json"); path != want {
+		t.Errorf("CacheFile() = %q, want %q", path, want)
+	}
+	if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+		t.Errorf("Expected CacheFile() to create %s", dir)
+	}
+}
+
+func TestDataDir(t *testing.T) {
+	base := t.TempDir()
+	t.Setenv("XDG_DATA_HOME", base)
+
+	dir, err := DataDir("myapp")
+	if err != nil {
+		t.Fatalf("DataDir() error = %v", err)
+	}
+	if want := filepath.Join(base, "myapp"); dir != want {
+		t.Errorf("DataDir() = %q, want %q", dir, want)
+	}
+}
+
+func TestDataDir_Default(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("default data directory is platform specific")
+	}
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_DATA_HOME", "relative/paths/are/ignored")
+
+	dir, err := DataDir("myapp")
+	if err != nil {
+		t.Fatalf("DataDir() error = %v", err)
+	}
+	if want := filepath.Join(home, ".local", "share", "myapp"); dir != want {
+		t.Errorf("DataDir() = %q, want %q", dir, want)
+	}
+}
+
+func TestStateFile(t *testing.T) {
+	base := t.TempDir()
+	t.Setenv("XDG_STATE_HOME", base)
+
+	path, err := StateFile("myapp", "history.json")
+	if err != nil {
+		t.Fatalf("StateFile() error = %v", err)
+	}
+	if want := filepath.Join(base, "myapp", "history.json"); path != want {
+		t.Errorf("StateFile() = %q, want %q", path, want)
+	}
+	if info, err := os.Stat(filepath.Dir(path)); err != nil || !info.IsDir() {
+		t.Errorf("Expected StateFile() to create the state directory")
+	}
+}
+
+func TestStateDir_Default(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("default state directory is platform specific")
+	}
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_STATE_HOME", "")
+
+	dir, err := StateDir("myapp")
+	if err != nil {
+		t.Fatalf("StateDir() error = %v", err)
+	}
+	if want := filepath.Join(home, ".local", "state", "myapp"); dir != want {
+		t.Errorf("StateDir() = %q, want %q", dir, want)
+	}
+}
+
+func TestRuntimeFile(t *testing.T) {
+	base := t.TempDir()
+	t.Setenv("XDG_RUNTIME_DIR", base)
+
+	path, err := RuntimeFile("myapp", "update.lock")
+	if err != nil {
+		t.Fatalf("RuntimeFile() error = %v", err)
+	}
+	if want := filepath.Join(base, "myapp", "update.lock"); path != want {
+		t.Errorf("RuntimeFile() = %q, want %q", path, want)
+	}
+	if info, err := os.Stat(filepath.Dir(path)); err != nil || !info.IsDir() {
+		t.Errorf("Expected RuntimeFile() to create %s", filepath.Dir(path))
+	}
+}
+
+func TestRuntimeDir_Fallback(t *testing.T) {
+	state := t.TempDir()
+	t.Setenv("XDG_RUNTIME_DIR", "")
+	t.Setenv("XDG_STATE_HOME", state)
+
+	dir, err := RuntimeDir("myapp")
+	if err != nil {
+		t.Fatalf("RuntimeDir() error = %v", err)
+	}
+	if want := filepath.Join(state, "myapp", "run"); dir != want {
+		t.Errorf("RuntimeDir() = %q, want %q", dir, want)
+	}
+}
+
+func TestBackup(t *testing.T) {
+	t.Setenv("XDG_STATE_HOME", t.TempDir())
+	src := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(src, []byte("app: {}\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	backup, err := Backup("myapp", src)
+	if err != nil {
+		t.Fatalf("Backup() error = %v", err)
+	}
+	dir, _ := StateDir("myapp")
+	if filepath.Dir(backup) != filepath.Join(dir, "backups") || !strings.HasPrefix(filepath.Base(backup), "config.yaml.") {
+		t.Errorf("Backup() = %q, want config.yaml.<time>.bak in the backups directory", backup)
+	}
+	if data, err := os.ReadFile(backup); err != nil || string(data) != "app: {}\n" {
+		t.Errorf("backup content = %q, %v", data, err)
+	}
+
+	second, err := Backup("myapp", src)
+	if err != nil || second == backup {
+		t.Errorf("second Backup() = %q, %v, want a new file", second, err)
+	}
+	if _, err := Backup("myapp", filepath.Join(t.TempDir(), "missing")); err == nil {
+		t.Error("Expected an error for a missing file")
+	}
+}
+
+func TestDirs_Root(t *testing.T) {
+	root := t.TempDir()
+	t.Setenv("XDG_STATE_HOME", t.TempDir())
+	t.Setenv("XDG_RUNTIME_DIR", t.TempDir())
+	d := Dirs{Root: root}
+
+	dirs := map[string]func(string) (string, error){
+		"cache": d.CacheDir,
+		"data":  d.DataDir,
+		"state": d.StateDir,
+		"run":   d.RuntimeDir,
+	}
+	for name, dirFunc := range dirs {
+		dir, err := dirFunc("myapp")
+		if err != nil {
+			t.Fatalf("%s dir error = %v", name, err)
+		}
+		if want := filepath.Join(root, name); dir != want {
+			t.Errorf("%s dir = %q, want %q", name, dir, want)
+		}
+	}
+
+	if dir, _ := StateDir("myapp"); strings.HasPrefix(dir, root) {
+		t.Errorf("StateDir() = %q without a root, want the platform directory", dir)
+	}
+}
@@ -1,18 +1,16 @@
 package main
 
 import (
-	"fmt"
 	"os"
 
 	"github.com/peiman/ckeletin-go/cmd"
+	"github.com/peiman/ckeletin-go/internal/exitcode"
 )
 
 func run() int {
-	if err := cmd.Execute(); err != nil {
-		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-		return 1
-	}
-	return 0
+	err := cmd.Execute()
+	cmd.PrintError(os.Stderr, err)
+	return exitcode.Code(err)
 }
 
 // main is intentionally not covered by tests because it's the program's entry point.
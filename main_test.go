@@ -3,13 +3,85 @@
 package main
 
 import (
+	"errors"
 	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
 	"testing"
 
 	"github.com/peiman/ckeletin-go/cmd"
+	"github.com/rogpeppe/go-internal/testscript"
 	"github.com/spf13/cobra"
 )
 
+// binaryName is the command the scripts in testdata/script run
+const binaryName = "ckeletin-go"
+
+// TestMain lets the scripts run the test binary as the CLI
+func TestMain(m *testing.M) {
+	testscript.Main(m, map[string]func(){
+		binaryName: func() { os.Exit(run()) },
+	})
+}
+
+// TestScripts runs the behavioral tests in testdata/script, see README.md.
+// Each script runs in a sandbox with its own home and XDG directories below
+// $WORK and none of the environment of the test.
+func TestScripts(t *testing.T) {
+	testscript.Run(t, testscript.Params{
+		Dir:                 filepath.Join("testdata", "script"),
+		RequireExplicitExec: true,
+		Setup: func(env *testscript.Env) error {
+			home := filepath.Join(env.WorkDir, "home")
+			env.Setenv("HOME", home)
+			env.Setenv("USERPROFILE", home)
+			env.Setenv("XDG_CONFIG_HOME", filepath.Join(home, ".config"))
+			env.Setenv("XDG_CACHE_HOME", filepath.Join(home, ".cache"))
+			env.Setenv("XDG_STATE_HOME", filepath.Join(home, ".local", "state"))
+			env.Setenv("XDG_DATA_HOME", filepath.Join(home, ".local", "share"))
+			env.Setenv("XDG_RUNTIME_DIR", filepath.Join(env.WorkDir, "run"))
+			// The tests may run as root in containers
+			env.Setenv("APP_ROOT_GUARD", "off")
+			env.Setenv("APP_UPDATE_NOTIFY", "false")
+			return os.MkdirAll(home, 0o700)
+		},
+		Cmds: map[string]func(ts *testscript.TestScript, neg bool, args []string){
+			"exitcode": cmdExitcode,
+		},
+	})
+}
+
+// cmdExitcode runs the CLI and checks its exit code, so scripts can tell usage
+// errors (2) from config errors (3) and other failures, which exec cannot:
+//
+//	exitcode 2 ping --nope
+//	stderr 'unknown flag'
+func cmdExitcode(ts *testscript.TestScript, neg bool, args []string) {
+	if neg {
+		ts.Fatalf("unsupported: ! exitcode")
+	}
+	if len(args) < 1 {
+		ts.Fatalf("usage: exitcode code [args...]")
+	}
+	want, err := strconv.Atoi(args[0])
+	if err != nil {
+		ts.Fatalf("invalid exit code %q", args[0])
+	}
+
+	got := 0
+	var exitErr *exec.ExitError
+	if err := ts.Exec(binaryName, args[1:]...); errors.As(err, &exitErr) {
+		got = exitErr.ExitCode()
+	} else if err != nil {
+		ts.Fatalf("%v", err)
+	}
+	if got != want {
+		ts.Fatalf("exit code %d, want %d", got, want)
+	}
+}
+
 func TestMainFunction(t *testing.T) {
 	// Save the original RootCmd
 	originalRoot := cmd.RootCmd
@@ -36,14 +108,18 @@ func TestMainFunction(t *testing.T) {
 		},
 	})
 
+	// run reads the arguments of the process
+	origArgs := os.Args
+	defer func() { os.Args = origArgs }()
+
 	// Test success scenario
-	testRoot.SetArgs([]string{"success"})
+	os.Args = []string{"test", "success"}
 	if code := run(); code != 0 {
 		t.Errorf("expected exit code 0, got %d", code)
 	}
 
 	// Test failure scenario
-	testRoot.SetArgs([]string{"fail"})
+	os.Args = []string{"test", "fail"}
 	if code := run(); code != 1 {
 		t.Errorf("expected exit code 1, got %d", code)
 	}
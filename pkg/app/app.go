@@ -0,0 +1,226 @@
+// pkg/app/app.go
+
+// Package app lets downstream binaries embed the CLI as a library and contribute
+// their own commands and configuration without editing the cmd package.
+//
+//	func main() {
+//		a := app.New()
+//		if err := a.RegisterCommand(helloCmd); err != nil {
+//			log.Fatal(err)
+//		}
+//		a.RegisterConfigOptions(app.ConfigOption{
+//			Key:         "app.hello.greeting",
+//			Default:     "Hello",
+//			Description: "Greeting printed by the hello command",
+//		})
+//		os.Exit(a.Run())
+//	}
+//
+// Every App owns a copy of the command tree, its streams, base config and
+// logger, so tests and embedders can create and run many Apps in one process,
+// also in parallel.
+package app
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/peiman/ckeletin-go/cmd"
+	"github.com/peiman/ckeletin-go/internal/config"
+	"github.com/peiman/ckeletin-go/internal/exitcode"
+	"github.com/peiman/ckeletin-go/internal/output"
+	"github.com/peiman/ckeletin-go/internal/runctx"
+	"github.com/rs/zerolog"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// ConfigOption describes a configuration key contributed by an extension
+type ConfigOption struct {
+	// Key is the config key, e.g. "app.hello.greeting", set in the environment
+	// as APP_HELLO_GREETING
+	Key string
+	// Default is the value when neither the config file, the environment nor
+	// a flag sets the key
+	Default interface{}
+	// Description is a one-line explanation shown in the docs
+	Description string
+	// Type is set for values that need parsing
+	Type ConfigType
+}
+
+// ConfigType says how a value from the config file or environment is parsed
+type ConfigType string
+
+// Config types
+const (
+	// ConfigDuration is a Go duration, e.g. "30s"
+	ConfigDuration ConfigType = ConfigType(config.TypeDuration)
+	// ConfigSize is a byte size, e.g. "100MB" or "1GiB"
+	ConfigSize ConfigType = ConfigType(config.TypeSize)
+)
+
+// UsageError returns err as an invalid usage, e.g. of arguments, exiting with 2.
+// Any error not marked with UsageError, ConfigError or CheckFailure exits with 1.
+func UsageError(err error) error {
+	return &exitcode.UsageError{Err: err}
+}
+
+// ConfigError returns err as an unusable configuration, exiting with 3
+func ConfigError(err error) error {
+	return &exitcode.ConfigError{Err: err}
+}
+
+// CheckFailure returns err as a verification that did not pass, exiting with 4
+func CheckFailure(err error) error {
+	return &exitcode.CheckFailure{Err: err}
+}
+
+// RunContext describes the running invocation
+type RunContext struct {
+	// ID identifies the invocation in logs and records
+	ID string
+	// Start is when the invocation started
+	Start time.Time
+	// Version is the version of the binary
+	Version string
+	// Args are the arguments the CLI was invoked with, without the binary name
+	Args []string
+	// DryRun is true when mutating commands should only show what they would do
+	DryRun bool
+	// Config is the effective configuration of the invocation
+	Config *viper.Viper
+	// Printer writes the command's results and messages
+	Printer Printer
+	// Logger logs with the invocation ID attached
+	Logger zerolog.Logger
+}
+
+// Printer writes results in the format chosen with --output and messages for the user
+type Printer struct {
+	Out io.Writer
+	Err io.Writer
+	// Format is text, json or yaml
+	Format string
+}
+
+// Print renders v to Out in the printer's format
+func (p Printer) Print(v interface{}) error {
+	return output.Printer(p).Print(v)
+}
+
+// Message writes a line for the user to Err
+func (p Printer) Message(format string, args ...interface{}) error {
+	return output.Printer(p).Message(format, args...)
+}
+
+// Context returns the RunContext of the running command c. It is available
+// in RunE of every command executed through the root command.
+func Context(c *cobra.Command) (*RunContext, bool) {
+	rc, ok := runctx.From(c.Context())
+	if !ok {
+		return nil, false
+	}
+	return &RunContext{
+		ID:      rc.ID,
+		Start:   rc.Start,
+		Version: rc.Version,
+		Args:    rc.Args,
+		DryRun:  rc.DryRun,
+		Config:  rc.Config,
+		Printer: Printer(rc.Printer),
+		Logger:  rc.Logger,
+	}, true
+}
+
+// App is the embeddable CLI
+type App struct {
+	app *cmd.App
+}
+
+// Option configures an App, see New
+type Option func(*options)
+
+type options struct {
+	app []cmd.AppOption
+}
+
+// WithIO sets the standard input, output and error of the App's commands,
+// by default those of the process
+func WithIO(in io.Reader, out, errOut io.Writer) Option {
+	return func(o *options) { o.app = append(o.app, cmd.WithIO(in, out, errOut)) }
+}
+
+// WithConfig sets the base config of the App's invocations. Its values
+// replace the registered defaults; the config file, environment and flags
+// still override them. Invocations read v but never change it.
+func WithConfig(v *viper.Viper) Option {
+	return func(o *options) { o.app = append(o.app, cmd.WithConfig(v)) }
+}
+
+// WithLogger sets the logger of the App's invocations, by default one
+// writing to their stderr. Its level is still set by app.log_level.
+func WithLogger(l zerolog.Logger) Option {
+	return func(o *options) { o.app = append(o.app, cmd.WithLogger(l)) }
+}
+
+// New returns an App with a copy of the built-in root command and its
+// subcommands, configured by opts
+func New(opts ...Option) *App {
+	var o options
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return &App{app: cmd.NewApp(o.app...)}
+}
+
+// Root returns the root command of the App, e.g. to add persistent flags
+func (a *App) Root() *cobra.Command {
+	return a.app.Root()
+}
+
+// RegisterCommand adds top-level commands. It fails if a command with the same
+// name or alias is already registered.
+func (a *App) RegisterCommand(cmds ...*cobra.Command) error {
+	for _, c := range cmds {
+		for _, name := range append([]string{c.Name()}, c.Aliases...) {
+			if existing, _, err := a.Root().Find([]string{name}); err == nil && existing != a.Root() {
+				return fmt.Errorf("command %q conflicts with existing command %q", c.Name(), existing.Name())
+			}
+		}
+		a.Root().AddCommand(c)
+	}
+	return nil
+}
+
+// RegisterConfigOptions registers configuration keys and their defaults.
+// Values can then be overridden by the config file, environment variables and flags
+// like any built-in setting.
+func (a *App) RegisterConfigOptions(opts ...ConfigOption) {
+	for _, o := range opts {
+		config.Register(config.Option{Key: o.Key, Default: o.Default, Description: o.Description, Type: config.Type(o.Type)})
+	}
+}
+
+// Execute runs the CLI with os.Args until the command finishes or the
+// process gets a shutdown signal, and returns the command error, if any
+func (a *App) Execute() error {
+	return a.app.Main()
+}
+
+// ExecuteContext runs the CLI with args and returns the command error, if
+// any. Signals are not handled; cancel ctx to interrupt the command.
+func (a *App) ExecuteContext(ctx context.Context, args []string) error {
+	return a.app.Execute(ctx, append([]string{}, args...))
+}
+
+// Run executes the CLI, prints any error to the App's stderr and returns the process exit code.
+// Usage errors exit with 2, configuration errors with 3 and interrupted commands with 130;
+// see the root command help for the full list.
+func (a *App) Run() int {
+	err := a.Execute()
+	cmd.PrintError(a.Root().ErrOrStderr(), err)
+	return exitcode.Code(err)
+}
@@ -0,0 +1,191 @@
+// pkg/app/app_test.go
+
+package app
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/peiman/ckeletin-go/internal/config"
+	"github.com/peiman/ckeletin-go/internal/output"
+	"github.com/peiman/ckeletin-go/internal/runctx"
+	"github.com/rs/zerolog"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// isolate gives the Apps of the test an empty home and state, like a new user
+func isolate(t *testing.T) {
+	t.Helper()
+	dir := t.TempDir()
+	for _, name := range []string{"HOME", "XDG_CONFIG_HOME", "XDG_STATE_HOME", "XDG_CACHE_HOME", "XDG_DATA_HOME", "XDG_RUNTIME_DIR"} {
+		t.Setenv(name, dir)
+	}
+	t.Setenv("APP_ROOT_GUARD", "off")
+	t.Setenv("APP_UPDATE_NOTIFY", "false")
+}
+
+// useArgs sets the arguments of the process for the duration of the test
+func useArgs(t *testing.T, args ...string) {
+	t.Helper()
+	orig := os.Args
+	os.Args = append([]string{"test"}, args...)
+	t.Cleanup(func() { os.Args = orig })
+}
+
+// greetCmd prints app.hello.greeting of its invocation
+func greetCmd() *cobra.Command {
+	return &cobra.Command{
+		Use: "hello",
+		RunE: func(c *cobra.Command, args []string) error {
+			rc, ok := Context(c)
+			if !ok {
+				return fmt.Errorf("no RunContext")
+			}
+			rc.Logger.Debug().Msg("Greeting")
+			_, err := fmt.Fprintln(c.OutOrStdout(), rc.Config.GetString("app.hello.greeting"))
+			return err
+		},
+	}
+}
+
+func TestRegisterCommand(t *testing.T) {
+	isolate(t)
+	var stdout, stderr bytes.Buffer
+	a := New(WithIO(strings.NewReader(""), &stdout, &stderr))
+	a.RegisterConfigOptions(ConfigOption{Key: "app.hello.greeting", Default: "Hello", Description: "Greeting"})
+	if err := a.RegisterCommand(greetCmd()); err != nil {
+		t.Fatalf("RegisterCommand() error = %v", err)
+	}
+
+	if err := a.ExecuteContext(context.Background(), []string{"hello"}); err != nil {
+		t.Fatalf("ExecuteContext() error = %v\n%s", err, &stderr)
+	}
+	if stdout.String() != "Hello\n" {
+		t.Errorf("output = %q, want %q", stdout.String(), "Hello\n")
+	}
+	if c, _, err := New().Root().Find([]string{"hello"}); err == nil && c.Name() == "hello" {
+		t.Error("RegisterCommand() added the command to other Apps")
+	}
+}
+
+func TestRegisterCommand_Conflicts(t *testing.T) {
+	a := New()
+	if err := a.RegisterCommand(&cobra.Command{Use: "hello", Aliases: []string{"hi"}}); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		cmd  *cobra.Command
+	}{
+		{"Built-in command", &cobra.Command{Use: "ping"}},
+		{"Same name", &cobra.Command{Use: "hello"}},
+		{"Alias of existing", &cobra.Command{Use: "hi"}},
+		{"Own alias conflicts", &cobra.Command{Use: "other", Aliases: []string{"hello"}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := a.RegisterCommand(tt.cmd)
+			if err == nil || !strings.Contains(err.Error(), "conflicts") {
+				t.Errorf("Expected conflict error, got %v", err)
+			}
+		})
+	}
+}
+
+func TestNew_Options(t *testing.T) {
+	isolate(t)
+	New().RegisterConfigOptions(ConfigOption{Key: "app.hello.greeting", Default: "Hello", Description: "Greeting"})
+
+	// Apps with different options run in parallel without affecting each other
+	for _, greeting := range []string{"Hi", "Hej", "Hola", "Salut"} {
+		t.Run(greeting, func(t *testing.T) {
+			t.Parallel()
+			base := viper.New()
+			base.Set("app.hello.greeting", greeting)
+			var stdout, stderr, logged bytes.Buffer
+			a := New(WithIO(strings.NewReader(""), &stdout, &stderr), WithConfig(base), WithLogger(zerolog.New(&logged)))
+			if err := a.RegisterCommand(greetCmd()); err != nil {
+				t.Fatal(err)
+			}
+
+			if err := a.ExecuteContext(context.Background(), []string{"hello", "--log-level", "debug"}); err != nil {
+				t.Fatalf("ExecuteContext() error = %v\n%s", err, &stderr)
+			}
+			if stdout.String() != greeting+"\n" {
+				t.Errorf("output = %q, want the greeting of the base config %q", stdout.String(), greeting)
+			}
+			if !strings.Contains(logged.String(), "Greeting") || strings.Contains(stderr.String(), "Greeting") {
+				t.Errorf("logger got %q, stderr %q, want the logs in the injected logger", logged.String(), stderr.String())
+			}
+		})
+	}
+}
+
+func TestRun(t *testing.T) {
+	isolate(t)
+	var stderr bytes.Buffer
+	a := New(WithIO(strings.NewReader(""), io.Discard, &stderr))
+	if err := a.RegisterCommand(
+		&cobra.Command{Use: "fail", RunE: func(*cobra.Command, []string) error { return fmt.Errorf("boom") }},
+		&cobra.Command{Use: "verify", RunE: func(*cobra.Command, []string) error { return CheckFailure(fmt.Errorf("mismatch")) }},
+	); err != nil {
+		t.Fatal(err)
+	}
+
+	useArgs(t, "ping")
+	if code := a.Run(); code != 0 {
+		t.Errorf("Run() = %d, want 0\n%s", code, &stderr)
+	}
+	useArgs(t, "fail")
+	if code := a.Run(); code != 1 || !strings.Contains(stderr.String(), "boom") {
+		t.Errorf("Run() = %d with stderr %q, want 1 and the error", code, stderr.String())
+	}
+	useArgs(t, "verify")
+	if code := a.Run(); code != 4 {
+		t.Errorf("Run() = %d, want 4", code)
+	}
+}
+
+func TestContext(t *testing.T) {
+	hello := &cobra.Command{Use: "hello", RunE: func(*cobra.Command, []string) error { return nil }}
+	if _, ok := Context(hello); ok {
+		t.Error("Context() found a RunContext outside a run")
+	}
+
+	out := new(bytes.Buffer)
+	rc := &runctx.RunContext{ID: "abc", Args: []string{"hello"}, Printer: output.Printer{Out: out, Format: output.JSON}}
+	hello.SetContext(runctx.With(context.Background(), rc))
+	got, ok := Context(hello)
+	if !ok || got.ID != "abc" || len(got.Args) != 1 {
+		t.Fatalf("Context() = %+v, %v, want the attached RunContext", got, ok)
+	}
+	if err := got.Printer.Print(map[string]string{"greeting": "hi"}); err != nil {
+		t.Fatalf("Print() error = %v", err)
+	}
+	if !strings.Contains(out.String(), `"greeting": "hi"`) {
+		t.Errorf("Print() wrote %q, want JSON", out.String())
+	}
+}
+
+func TestRegisterConfigOptions_Type(t *testing.T) {
+	a := New()
+	a.RegisterConfigOptions(ConfigOption{Key: "app.hello.max_size", Default: "1MB", Description: "Largest greeting", Type: ConfigSize})
+
+	for _, opt := range config.Options() {
+		if opt.Key == "app.hello.max_size" {
+			if opt.Type != config.TypeSize {
+				t.Errorf("Type = %q, want %q", opt.Type, config.TypeSize)
+			}
+			return
+		}
+	}
+	t.Error("Option was not registered")
+}
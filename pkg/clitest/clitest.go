@@ -0,0 +1,293 @@
+// pkg/clitest/clitest.go
+
+// Package clitest runs a CLI in tests the way users run it, in a sandbox: an
+// empty working directory, its own home and XDG directories, no APP_
+// environment variables from the developer's shell, and captured output.
+//
+// The quickest setup runs the test binary itself as the CLI:
+//
+//	func TestMain(m *testing.M) {
+//		clitest.Main(m, func() int {
+//			err := cmd.Execute()
+//			cmd.PrintError(os.Stderr, err)
+//			return exitcode.Code(err)
+//		})
+//	}
+//
+//	func TestVersion(t *testing.T) {
+//		clitest.RunExpect(t, []string{"version"}, 0, "Version:")
+//	}
+//
+// Use Binary to run a built binary, e.g. from Build, or InProcess to call the
+// CLI in the test process, which is faster but shares its global state.
+package clitest
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// runEnv makes a test binary started by the harness run the CLI instead of the tests
+const runEnv = "CLITEST_RUN_CLI"
+
+// DefaultTimeout is how long a run may take before the test fails
+const DefaultTimeout = time.Minute
+
+// mainRun is the CLI passed to Main
+var mainRun func() int
+
+// Main runs the tests of a package, or the CLI with run when the harness
+// starts the test binary. Call it from TestMain.
+func Main(m *testing.M, run func() int) {
+	if os.Getenv(runEnv) == "1" {
+		os.Exit(run())
+	}
+	mainRun = run
+	os.Exit(m.Run())
+}
+
+// Runner runs the CLI in the test process with args and returns its exit code
+type Runner func(args []string, stdout, stderr io.Writer) int
+
+// Option configures a Harness
+type Option func(*Harness)
+
+// Binary runs the CLI from the executable at path
+func Binary(path string) Option {
+	return func(h *Harness) { h.exe = path }
+}
+
+// InProcess runs the CLI with run in the test process. The sandbox is applied
+// with t.Setenv and by changing the working directory for the duration of
+// each run, so tests using it cannot run in parallel.
+func InProcess(run Runner) Option {
+	return func(h *Harness) { h.inProcess = run }
+}
+
+// Timeout sets how long a run may take, DefaultTimeout by default. It does not
+// apply to InProcess runs.
+func Timeout(d time.Duration) Option {
+	return func(h *Harness) { h.timeout = d }
+}
+
+// Harness runs a CLI in a sandbox
+type Harness struct {
+	// Dir holds the sandbox: home, XDG and working directories
+	Dir string
+	// WorkDir is the working directory of runs, initially empty
+	WorkDir string
+
+	t         testing.TB
+	exe       string
+	self      bool
+	inProcess Runner
+	timeout   time.Duration
+	env       map[string]string
+}
+
+// Result is the outcome of a run
+type Result struct {
+	Code   int
+	Stdout string
+	Stderr string
+}
+
+// Output returns stdout followed by stderr
+func (r Result) Output() string {
+	return r.Stdout + r.Stderr
+}
+
+// New returns a harness with a new sandbox, removed when the test ends.
+// Without Binary or InProcess it runs the test binary, which requires Main.
+func New(t testing.TB, opts ...Option) *Harness {
+	t.Helper()
+	dir := t.TempDir()
+	h := &Harness{
+		Dir:     dir,
+		WorkDir: filepath.Join(dir, "work"),
+		t:       t,
+		timeout: DefaultTimeout,
+	}
+	for _, opt := range opts {
+		opt(h)
+	}
+	if h.exe == "" && h.inProcess == nil {
+		if mainRun == nil {
+			t.Fatal("clitest: call clitest.Main from TestMain, or use clitest.Binary or clitest.InProcess")
+		}
+		exe, err := os.Executable()
+		if err != nil {
+			t.Fatalf("clitest: %v", err)
+		}
+		h.exe = exe
+		h.self = true
+	}
+
+	home := filepath.Join(dir, "home")
+	h.env = map[string]string{
+		"HOME":            home,
+		"USERPROFILE":     home,
+		"APPDATA":         filepath.Join(home, "AppData", "Roaming"),
+		"LOCALAPPDATA":    filepath.Join(home, "AppData", "Local"),
+		"XDG_CONFIG_HOME": filepath.Join(dir, "config"),
+		"XDG_CACHE_HOME":  filepath.Join(dir, "cache"),
+		"XDG_STATE_HOME":  filepath.Join(dir, "state"),
+		"XDG_DATA_HOME":   filepath.Join(dir, "data"),
+		"XDG_RUNTIME_DIR": filepath.Join(dir, "run"),
+	}
+	for _, d := range []string{home, h.WorkDir} {
+		if err := os.MkdirAll(d, 0o700); err != nil {
+			t.Fatalf("clitest: %v", err)
+		}
+	}
+	return h
+}
+
+// Setenv sets an environment variable for the following runs
+func (h *Harness) Setenv(key, value string) {
+	h.env[key] = value
+}
+
+// WriteFile writes content to name, relative to the working directory, and
+// returns its path
+func (h *Harness) WriteFile(name, content string) string {
+	h.t.Helper()
+	path := filepath.Join(h.WorkDir, name)
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		h.t.Fatalf("clitest: %v", err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		h.t.Fatalf("clitest: %v", err)
+	}
+	return path
+}
+
+// Environ returns the environment of runs: the environment of the test
+// without HOME, XDG_ and APP_ variables, plus the sandbox and Setenv variables
+func (h *Harness) Environ() []string {
+	var env []string
+	for _, kv := range os.Environ() {
+		name, _, _ := strings.Cut(kv, "=")
+		if h.sandboxed(name) {
+			continue
+		}
+		env = append(env, kv)
+	}
+	for name, value := range h.env {
+		env = append(env, name+"="+value)
+	}
+	return env
+}
+
+// sandboxed reports whether the sandbox replaces the variable name
+func (h *Harness) sandboxed(name string) bool {
+	if _, ok := h.env[name]; ok {
+		return true
+	}
+	return strings.HasPrefix(name, "APP_") || strings.HasPrefix(name, "XDG_") || name == runEnv
+}
+
+// Run runs the CLI with args and returns the result. Failing to start or
+// timing out fails the test; a non-zero exit code does not.
+func (h *Harness) Run(args ...string) Result {
+	h.t.Helper()
+	if h.inProcess != nil {
+		return h.runInProcess(args)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), h.timeout)
+	defer cancel()
+	var stdout, stderr bytes.Buffer
+	run := exec.CommandContext(ctx, h.exe, args...)
+	run.Dir = h.WorkDir
+	run.Env = h.Environ()
+	if h.self {
+		run.Env = append(run.Env, runEnv+"=1")
+	}
+	run.Stdout = &stdout
+	run.Stderr = &stderr
+	err := run.Run()
+	if ctx.Err() != nil {
+		h.t.Fatalf("clitest: %s timed out after %s\n%s%s", strings.Join(args, " "), h.timeout, &stdout, &stderr)
+	}
+	var exitErr *exec.ExitError
+	if err != nil && !errors.As(err, &exitErr) {
+		h.t.Fatalf("clitest: failed to run %s: %v", h.exe, err)
+	}
+	return Result{Code: run.ProcessState.ExitCode(), Stdout: stdout.String(), Stderr: stderr.String()}
+}
+
+func (h *Harness) runInProcess(args []string) Result {
+	h.t.Helper()
+	for _, kv := range os.Environ() {
+		if name, _, _ := strings.Cut(kv, "="); h.sandboxed(name) {
+			h.t.Setenv(name, "")
+			_ = os.Unsetenv(name)
+		}
+	}
+	for name, value := range h.env {
+		h.t.Setenv(name, value)
+	}
+
+	wd, err := os.Getwd()
+	if err != nil {
+		h.t.Fatalf("clitest: %v", err)
+	}
+	if err := os.Chdir(h.WorkDir); err != nil {
+		h.t.Fatalf("clitest: %v", err)
+	}
+	defer func() {
+		if err := os.Chdir(wd); err != nil {
+			h.t.Fatalf("clitest: %v", err)
+		}
+	}()
+
+	var stdout, stderr bytes.Buffer
+	code := h.inProcess(args, &stdout, &stderr)
+	return Result{Code: code, Stdout: stdout.String(), Stderr: stderr.String()}
+}
+
+// RunExpect runs the CLI with args and fails the test unless it exits with
+// wantCode and its output contains each of wantContains
+func (h *Harness) RunExpect(args []string, wantCode int, wantContains ...string) Result {
+	h.t.Helper()
+	res := h.Run(args...)
+	if res.Code != wantCode {
+		h.t.Errorf("%s: exit code = %d, want %d\n%s", strings.Join(args, " "), res.Code, wantCode, res.Output())
+	}
+	for _, want := range wantContains {
+		if !strings.Contains(res.Output(), want) {
+			h.t.Errorf("%s: output does not contain %q\n%s", strings.Join(args, " "), want, res.Output())
+		}
+	}
+	return res
+}
+
+// RunExpect runs the CLI in a new sandbox, see Harness.RunExpect
+func RunExpect(t testing.TB, args []string, wantCode int, wantContains ...string) Result {
+	t.Helper()
+	return New(t).RunExpect(args, wantCode, wantContains...)
+}
+
+// Build compiles the main package pkg, e.g. "." or a module path, with the go
+// command and returns the path of the binary, removed when the test ends
+func Build(t testing.TB, pkg string) string {
+	t.Helper()
+	exe := filepath.Join(t.TempDir(), "cli")
+	if filepath.Separator == '\\' {
+		exe += ".exe"
+	}
+	out, err := exec.Command("go", "build", "-o", exe, pkg).CombinedOutput()
+	if err != nil {
+		t.Fatalf("clitest: go build %s failed: %v\n%s", pkg, err, out)
+	}
+	return exe
+}
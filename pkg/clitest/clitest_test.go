@@ -0,0 +1,109 @@
+// pkg/clitest/clitest_test.go
+
+package clitest
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestMain(m *testing.M) {
+	Main(m, func() int {
+		return fakeCLI(os.Args[1:], os.Stdout, os.Stderr)
+	})
+}
+
+// fakeCLI prints what the tests check: "env NAME" prints the variable, "pwd"
+// the working directory, and "exit N" exits with N
+func fakeCLI(args []string, stdout, stderr io.Writer) int {
+	switch {
+	case len(args) == 2 && args[0] == "env":
+		fmt.Fprintln(stdout, os.Getenv(args[1]))
+	case len(args) == 1 && args[0] == "pwd":
+		wd, _ := os.Getwd()
+		fmt.Fprintln(stdout, wd)
+	case len(args) == 2 && args[0] == "exit":
+		code, _ := strconv.Atoi(args[1])
+		fmt.Fprintln(stderr, "exiting")
+		return code
+	default:
+		fmt.Fprintln(stderr, "unknown command")
+		return 2
+	}
+	return 0
+}
+
+func TestRun(t *testing.T) {
+	modes := map[string][]Option{
+		"subprocess": nil,
+		"in-process": {InProcess(fakeCLI)},
+	}
+	for name, opts := range modes {
+		t.Run(name, func(t *testing.T) {
+			t.Setenv("APP_LOG_LEVEL", "debug")
+			h := New(t, opts...)
+			h.Setenv("APP_GREETING", "hi")
+
+			expectOutput(t, h, []string{"env", "HOME"}, filepath.Join(h.Dir, "home"))
+			expectOutput(t, h, []string{"env", "XDG_STATE_HOME"}, filepath.Join(h.Dir, "state"))
+			expectOutput(t, h, []string{"env", "APP_LOG_LEVEL"}, "")
+			expectOutput(t, h, []string{"env", "APP_GREETING"}, "hi")
+			expectOutput(t, h, []string{"pwd"}, h.WorkDir)
+
+			res := h.RunExpect([]string{"exit", "3"}, 3, "exiting")
+			if res.Stdout != "" || res.Stderr != "exiting\n" {
+				t.Errorf("Run() = %+v, want the message on stderr", res)
+			}
+		})
+	}
+}
+
+// expectOutput checks that the CLI prints want and succeeds
+func expectOutput(t *testing.T, h *Harness, args []string, want string) {
+	t.Helper()
+	res := h.RunExpect(args, 0)
+	if got := strings.TrimSpace(res.Stdout); got != want {
+		t.Errorf("%v printed %q, want %q", args, got, want)
+	}
+}
+
+// recorder records test failures instead of failing the test
+type recorder struct {
+	testing.TB
+	errors []string
+}
+
+func (r *recorder) Errorf(format string, args ...interface{}) {
+	r.errors = append(r.errors, fmt.Sprintf(format, args...))
+}
+
+func TestRunExpect_Failures(t *testing.T) {
+	rec := &recorder{TB: t}
+	RunExpect(rec, []string{"exit", "1"}, 0, "exiting", "missing")
+
+	if len(rec.errors) != 2 {
+		t.Fatalf("RunExpect() reported %q, want the exit code and the missing output", rec.errors)
+	}
+	if !strings.Contains(rec.errors[0], "exit code = 1, want 0") {
+		t.Errorf("first error = %q, want the exit code", rec.errors[0])
+	}
+	if !strings.Contains(rec.errors[1], `does not contain "missing"`) {
+		t.Errorf("second error = %q, want the missing output", rec.errors[1])
+	}
+}
+
+func TestWriteFile(t *testing.T) {
+	h := New(t)
+	path := h.WriteFile("conf/app.yaml", "app: {}\n")
+	if want := filepath.Join(h.WorkDir, "conf", "app.yaml"); path != want {
+		t.Errorf("WriteFile() = %q, want %q", path, want)
+	}
+	if data, err := os.ReadFile(path); err != nil || string(data) != "app: {}\n" {
+		t.Errorf("file content = %q, %v", data, err)
+	}
+}
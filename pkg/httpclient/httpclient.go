@@ -0,0 +1,213 @@
+// pkg/httpclient/httpclient.go
+
+// Package httpclient builds the HTTP clients used by commands: a timeout per
+// attempt, retries with exponential backoff for idempotent requests, proxy and
+// TLS settings, an optional rate limit, a User-Agent naming the CLI and its
+// version, and a debug log line for every request with the logger of the
+// request context (see zerolog.Ctx).
+package httpclient
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/peiman/ckeletin-go/pkg/retry"
+	"github.com/rs/zerolog"
+)
+
+// Options configure a client
+type Options struct {
+	// Timeout limits each attempt, including reading the response body; 0 means no limit
+	Timeout time.Duration
+	// Retries is how often idempotent requests are retried after network errors
+	// and 5xx or 429 responses
+	Retries int
+	// RetryDelay is the delay before the first retry (default 500ms); it doubles
+	// with every retry, with 20% jitter
+	RetryDelay time.Duration
+	// OnRetry is called before each retry, e.g. to show it as progress. By default
+	// retries are logged as warnings with the logger of the request context.
+	OnRetry func(retry.Event)
+	// RateLimit is the maximum number of requests per second; 0 means no limit
+	RateLimit float64
+	// Proxy is the proxy URL; empty uses HTTP_PROXY, HTTPS_PROXY and NO_PROXY
+	Proxy string
+	// InsecureSkipVerify disables TLS certificate verification. Only for testing.
+	InsecureSkipVerify bool
+	// UserAgent is sent with requests that do not set their own
+	UserAgent string
+	// Transport sends the requests, by default a copy of http.DefaultTransport
+	Transport http.RoundTripper
+}
+
+// New returns a client configured by o. An invalid proxy URL makes every request
+// fail with an error naming it.
+func New(o Options) *http.Client {
+	base := o.Transport
+	if base == nil {
+		t := http.DefaultTransport.(*http.Transport).Clone()
+		if o.Proxy != "" {
+			proxyURL, err := url.Parse(o.Proxy)
+			if err == nil && (proxyURL.Scheme == "" || proxyURL.Host == "") {
+				err = fmt.Errorf("missing scheme or host")
+			}
+			if err != nil {
+				err = fmt.Errorf("invalid proxy URL %q: %w", o.Proxy, err)
+				t.Proxy = func(*http.Request) (*url.URL, error) { return nil, err }
+			} else {
+				t.Proxy = http.ProxyURL(proxyURL)
+			}
+		}
+		if o.InsecureSkipVerify {
+			t.TLSClientConfig = &tls.Config{InsecureSkipVerify: true} //nolint:gosec // opt-in for testing
+		}
+		base = t
+	}
+
+	backoff := retry.DefaultBackoff
+	if o.RetryDelay > 0 {
+		backoff.Initial = o.RetryDelay
+	}
+	return &http.Client{Transport: &retryTransport{
+		next:    &logTransport{next: base, userAgent: o.UserAgent},
+		retries: o.Retries,
+		backoff: backoff,
+		onRetry: o.OnRetry,
+		limiter: retry.NewLimiter(o.RateLimit, 1),
+		timeout: o.Timeout,
+	}}
+}
+
+// logTransport sets the User-Agent and logs every attempt
+type logTransport struct {
+	next      http.RoundTripper
+	userAgent string
+}
+
+func (t *logTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if t.userAgent != "" && req.Header.Get("User-Agent") == "" {
+		req = req.Clone(req.Context())
+		req.Header.Set("User-Agent", t.userAgent)
+	}
+
+	start := time.Now()
+	resp, err := t.next.RoundTrip(req)
+	event := zerolog.Ctx(req.Context()).Debug().Str("method", req.Method).Str("url", req.URL.Redacted()).Dur("duration", time.Since(start))
+	if err != nil {
+		event.Err(err).Msg("HTTP request failed")
+		return nil, err
+	}
+	event.Int("status", resp.StatusCode).Msg("HTTP request")
+	return resp, nil
+}
+
+// retryTransport applies the rate limit and per-attempt timeout and retries
+// transient failures
+type retryTransport struct {
+	next    http.RoundTripper
+	retries int
+	backoff retry.Backoff
+	onRetry func(retry.Event)
+	limiter *retry.Limiter
+	timeout time.Duration
+}
+
+func (t *retryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	p := retry.Policy{Retries: t.retries, Backoff: t.backoff, OnRetry: t.onRetry}
+	if !replayable(req) {
+		p.Retries = 0
+	}
+	if p.OnRetry == nil {
+		p.OnRetry = func(e retry.Event) {
+			zerolog.Ctx(req.Context()).Warn().Err(e.Err).Str("url", req.URL.Redacted()).Int("attempt", e.Retry).Dur("delay", e.Delay).Msg("Retrying request")
+		}
+	}
+
+	var resp *http.Response
+	attempts := 0
+	err := retry.Do(req.Context(), p, func(ctx context.Context) error {
+		if resp != nil {
+			_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+			resp.Body.Close()
+			resp = nil
+		}
+		if err := t.limiter.Wait(ctx); err != nil {
+			return retry.Permanent(err)
+		}
+		r, err := t.attempt(req, attempts)
+		attempts++
+		if err != nil {
+			return err
+		}
+		resp = r
+		if r.StatusCode >= 500 || r.StatusCode == http.StatusTooManyRequests {
+			return fmt.Errorf("unexpected status: %s", r.Status)
+		}
+		return nil
+	})
+
+	// The last response is returned even with a retryable status, as without retries
+	if resp != nil && req.Context().Err() == nil {
+		return resp, nil
+	}
+	if resp != nil {
+		resp.Body.Close()
+	}
+	if attempts > 1 && req.Context().Err() == nil {
+		err = fmt.Errorf("request failed after %d attempts: %w", attempts, err)
+	}
+	return nil, err
+}
+
+// attempt sends req once, with a fresh body on retries and the attempt timeout
+// lasting until the response body is closed
+func (t *retryTransport) attempt(req *http.Request, n int) (*http.Response, error) {
+	ctx, cancel := req.Context(), context.CancelFunc(func() {})
+	if t.timeout > 0 {
+		ctx, cancel = context.WithTimeout(ctx, t.timeout)
+	}
+	r := req.Clone(ctx)
+	if n > 0 && req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			cancel()
+			return nil, err
+		}
+		r.Body = body
+	}
+
+	resp, err := t.next.RoundTrip(r)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	resp.Body = &cancelBody{ReadCloser: resp.Body, cancel: cancel}
+	return resp, nil
+}
+
+// replayable reports whether req can be sent again: an idempotent method and
+// no body, or a body that can be recreated
+func replayable(req *http.Request) bool {
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		return req.Body == nil || req.Body == http.NoBody || req.GetBody != nil
+	}
+	return false
+}
+
+// cancelBody ends the attempt's timeout context once the body is closed
+type cancelBody struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (b *cancelBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.cancel()
+	return err
+}
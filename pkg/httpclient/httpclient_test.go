@@ -0,0 +1,219 @@
+// pkg/httpclient/httpclient_test.go
+
+package httpclient
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/peiman/ckeletin-go/pkg/retry"
+	"github.com/rs/zerolog"
+)
+
+func TestRetries(t *testing.T) {
+	tests := []struct {
+		name         string
+		method       string
+		statuses     []int
+		retries      int
+		wantStatus   int
+		wantAttempts int32
+	}{
+		{"Succeeds first time", http.MethodGet, []int{200}, 3, 200, 1},
+		{"Retries server errors", http.MethodGet, []int{500, 503, 200}, 3, 200, 3},
+		{"Retries rate limiting", http.MethodGet, []int{429, 200}, 3, 200, 2},
+		{"Gives up after retries", http.MethodGet, []int{500, 500, 500}, 2, 500, 3},
+		{"No retry on client error", http.MethodGet, []int{404, 200}, 3, 404, 1},
+		{"No retry for POST", http.MethodPost, []int{500, 200}, 3, 500, 1},
+		{"Replays PUT body", http.MethodPut, []int{500, 200}, 3, 200, 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var attempts int32
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				n := atomic.AddInt32(&attempts, 1)
+				if body, _ := io.ReadAll(r.Body); r.Method != http.MethodGet && string(body) != "payload" {
+					t.Errorf("attempt %d body = %q", n, body)
+				}
+				w.WriteHeader(tt.statuses[n-1])
+			}))
+			defer srv.Close()
+
+			c := New(Options{Retries: tt.retries, RetryDelay: time.Millisecond})
+			var body io.Reader
+			if tt.method != http.MethodGet {
+				body = strings.NewReader("payload")
+			}
+			req, _ := http.NewRequest(tt.method, srv.URL, body)
+			resp, err := c.Do(req)
+			if err != nil {
+				t.Fatalf("Do() error = %v", err)
+			}
+			resp.Body.Close()
+			if resp.StatusCode != tt.wantStatus {
+				t.Errorf("status = %d, want %d", resp.StatusCode, tt.wantStatus)
+			}
+			if got := atomic.LoadInt32(&attempts); got != tt.wantAttempts {
+				t.Errorf("attempts = %d, want %d", got, tt.wantAttempts)
+			}
+		})
+	}
+}
+
+func TestRetries_NetworkError(t *testing.T) {
+	srv := httptest.NewServer(http.NotFoundHandler())
+	url := srv.URL
+	srv.Close()
+
+	c := New(Options{Retries: 2, RetryDelay: time.Millisecond})
+	_, err := c.Get(url)
+	if err == nil || !strings.Contains(err.Error(), "request failed after 3 attempts") {
+		t.Errorf("Expected error after 3 attempts, got %v", err)
+	}
+}
+
+func TestTimeoutPerAttempt(t *testing.T) {
+	var attempts int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&attempts, 1) == 1 {
+			select {
+			case <-r.Context().Done():
+			case <-time.After(time.Second):
+			}
+			return
+		}
+		_, _ = io.WriteString(w, "ok")
+	}))
+	defer srv.Close()
+
+	c := New(Options{Timeout: 50 * time.Millisecond, Retries: 1, RetryDelay: time.Millisecond})
+	resp, err := c.Get(srv.URL)
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	defer resp.Body.Close()
+	// The body stays readable after RoundTrip returned
+	if body, err := io.ReadAll(resp.Body); err != nil || string(body) != "ok" {
+		t.Errorf("body = %q, %v", body, err)
+	}
+}
+
+func TestContextCanceled(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, srv.URL, nil)
+	if _, err := New(Options{Retries: 3}).Do(req); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context.Canceled, got %v", err)
+	}
+}
+
+func TestUserAgentAndLogging(t *testing.T) {
+	var agents []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		agents = append(agents, r.UserAgent())
+	}))
+	defer srv.Close()
+
+	logBuf := new(bytes.Buffer)
+	ctx := zerolog.New(logBuf).Level(zerolog.DebugLevel).WithContext(context.Background())
+
+	c := New(Options{UserAgent: "mycli/1.2.0"})
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, srv.URL+"/path?q=1", nil)
+	resp, err := c.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	req, _ = http.NewRequestWithContext(ctx, http.MethodGet, srv.URL, nil)
+	req.Header.Set("User-Agent", "custom")
+	if resp, err = c.Do(req); err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+
+	if len(agents) != 2 || agents[0] != "mycli/1.2.0" || agents[1] != "custom" {
+		t.Errorf("User-Agents = %v", agents)
+	}
+	if !strings.Contains(logBuf.String(), `"message":"HTTP request"`) || !strings.Contains(logBuf.String(), `"status":200`) ||
+		!strings.Contains(logBuf.String(), "/path?q=1") {
+		t.Errorf("Unexpected log %q", logBuf.String())
+	}
+}
+
+func TestProxy(t *testing.T) {
+	var proxied int32
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&proxied, 1)
+		if r.URL.Host != "example.invalid" {
+			t.Errorf("Proxy got request for %q", r.URL.Host)
+		}
+	}))
+	defer proxy.Close()
+
+	resp, err := New(Options{Proxy: proxy.URL}).Get("http://example.invalid/")
+	if err != nil {
+		t.Fatalf("Get() through proxy error = %v", err)
+	}
+	resp.Body.Close()
+	if atomic.LoadInt32(&proxied) != 1 {
+		t.Error("Request did not go through the proxy")
+	}
+
+	if _, err := New(Options{Proxy: "not a url"}).Get("http://example.invalid/"); err == nil || !strings.Contains(err.Error(), "invalid proxy URL") {
+		t.Errorf("Expected invalid proxy error, got %v", err)
+	}
+}
+
+func TestInsecureSkipVerify(t *testing.T) {
+	srv := httptest.NewTLSServer(http.NotFoundHandler())
+	defer srv.Close()
+
+	if _, err := New(Options{}).Get(srv.URL); err == nil {
+		t.Error("Expected a certificate error")
+	}
+	resp, err := New(Options{InsecureSkipVerify: true}).Get(srv.URL)
+	if err != nil {
+		t.Fatalf("Get() with InsecureSkipVerify error = %v", err)
+	}
+	resp.Body.Close()
+}
+
+func TestOnRetryAndRateLimit(t *testing.T) {
+	var attempts int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&attempts, 1) == 1 {
+			w.WriteHeader(http.StatusBadGateway)
+		}
+	}))
+	defer srv.Close()
+
+	var events []retry.Event
+	c := New(Options{Retries: 2, RetryDelay: time.Millisecond, RateLimit: 20, OnRetry: func(e retry.Event) { events = append(events, e) }})
+	start := time.Now()
+	resp, err := c.Get(srv.URL)
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	resp.Body.Close()
+	if len(events) != 1 || events[0].Retry != 1 || !strings.Contains(events[0].Err.Error(), "502") {
+		t.Errorf("OnRetry events = %+v", events)
+	}
+	// The second request waits for the limiter: 20 per second is one per 50ms
+	if elapsed := time.Since(start); elapsed < 40*time.Millisecond {
+		t.Errorf("Two requests took %v, expected the rate limit to apply", elapsed)
+	}
+}
@@ -0,0 +1,217 @@
+// pkg/prompt/models.go
+
+package prompt
+
+import (
+	"strings"
+	"sync"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/peiman/ckeletin-go/pkg/termcaps"
+)
+
+// theme holds the styles of the questions
+type theme struct {
+	question, selected, hint lipgloss.Style
+}
+
+// styles returns the theme, built when the first question is shown rather
+// than when the package is loaded
+var styles = sync.OnceValue(func() theme {
+	return theme{
+		question: lipgloss.NewStyle().Bold(true),
+		selected: lipgloss.NewStyle().Foreground(lipgloss.Color("6")).Bold(true),
+		hint:     lipgloss.NewStyle().Faint(true),
+	}
+})
+
+// isAbort reports whether msg cancels the question
+func isAbort(msg tea.KeyMsg) bool {
+	return msg.Type == tea.KeyCtrlC || msg.Type == tea.KeyEsc
+}
+
+// confirmModel is a yes/no question answered with y, n or enter
+type confirmModel struct {
+	question string
+	value    bool
+	done     bool
+	abort    bool
+}
+
+func newConfirmModel(question string, def bool) confirmModel {
+	return confirmModel{question: question, value: def}
+}
+
+func (m confirmModel) Init() tea.Cmd { return nil }
+
+func (m confirmModel) aborted() bool { return m.abort }
+
+func (m confirmModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	keyMsg, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return m, nil
+	}
+	if isAbort(keyMsg) {
+		m.abort = true
+		return m, tea.Quit
+	}
+
+	switch keyMsg.String() {
+	case "y", "Y":
+		m.value = true
+	case "n", "N":
+		m.value = false
+	case "enter":
+	case "left", "right", "tab", "h", "l":
+		m.value = !m.value
+		return m, nil
+	default:
+		return m, nil
+	}
+	m.done = true
+	return m, tea.Quit
+}
+
+func (m confirmModel) View() string {
+	if m.done {
+		answer := "no"
+		if m.value {
+			answer = "yes"
+		}
+		return styles().question.Render(m.question) + " " + answer + "\n"
+	}
+	if m.abort {
+		return ""
+	}
+
+	yes, no := "yes", "no"
+	if m.value {
+		yes = styles().selected.Render("[yes]")
+	} else {
+		no = styles().selected.Render("[no]")
+	}
+	return styles().question.Render(m.question) + " " + yes + " / " + no +
+		"\n" + styles().hint.Render("y/n to answer, enter to accept, esc to cancel")
+}
+
+// selectModel is a list of options navigated with the arrow keys
+type selectModel struct {
+	question string
+	options  []string
+	cursor   int
+	glyphs   termcaps.Glyphs
+	done     bool
+	abort    bool
+}
+
+func newSelectModel(question string, options []string, def int, glyphs termcaps.Glyphs) selectModel {
+	if def < 0 {
+		def = 0
+	}
+	return selectModel{question: question, options: options, cursor: def, glyphs: glyphs}
+}
+
+func (m selectModel) Init() tea.Cmd { return nil }
+
+func (m selectModel) aborted() bool { return m.abort }
+
+func (m selectModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	keyMsg, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return m, nil
+	}
+	if isAbort(keyMsg) {
+		m.abort = true
+		return m, tea.Quit
+	}
+
+	switch keyMsg.String() {
+	case "up", "k", "shift+tab":
+		m.cursor = (m.cursor - 1 + len(m.options)) % len(m.options)
+	case "down", "j", "tab":
+		m.cursor = (m.cursor + 1) % len(m.options)
+	case "enter":
+		m.done = true
+		return m, tea.Quit
+	}
+	return m, nil
+}
+
+func (m selectModel) View() string {
+	if m.done {
+		return styles().question.Render(m.question) + " " + m.options[m.cursor] + "\n"
+	}
+	if m.abort {
+		return ""
+	}
+
+	var b strings.Builder
+	b.WriteString(styles().question.Render(m.question) + "\n")
+	for i, option := range m.options {
+		if i == m.cursor {
+			b.WriteString(styles().selected.Render("> "+option) + "\n")
+		} else {
+			b.WriteString("  " + option + "\n")
+		}
+	}
+	b.WriteString(styles().hint.Render(m.glyphs.Up + "/" + m.glyphs.Down + " to move, enter to select, esc to cancel"))
+	return b.String()
+}
+
+// inputModel is a single line text question
+type inputModel struct {
+	question string
+	def      string
+	input    textinput.Model
+	done     bool
+	abort    bool
+}
+
+func newInputModel(question, def string) inputModel {
+	input := textinput.New()
+	input.Prompt = "> "
+	input.Placeholder = def
+	input.Focus()
+	return inputModel{question: question, def: def, input: input}
+}
+
+func (m inputModel) Init() tea.Cmd { return textinput.Blink }
+
+func (m inputModel) aborted() bool { return m.abort }
+
+// result is the typed value, or the default when nothing was typed
+func (m inputModel) result() string {
+	if v := strings.TrimSpace(m.input.Value()); v != "" {
+		return v
+	}
+	return m.def
+}
+
+func (m inputModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if keyMsg, ok := msg.(tea.KeyMsg); ok {
+		if isAbort(keyMsg) {
+			m.abort = true
+			return m, tea.Quit
+		}
+		if keyMsg.Type == tea.KeyEnter {
+			m.done = true
+			return m, tea.Quit
+		}
+	}
+
+	var cmd tea.Cmd
+	m.input, cmd = m.input.Update(msg)
+	return m, cmd
+}
+
+func (m inputModel) View() string {
+	if m.done {
+		return styles().question.Render(m.question) + " " + m.result() + "\n"
+	}
+	if m.abort {
+		return ""
+	}
+	return styles().question.Render(m.question) + "\n" + m.input.View()
+}
@@ -0,0 +1,118 @@
+// pkg/prompt/models_test.go
+
+package prompt
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/peiman/ckeletin-go/pkg/termcaps"
+)
+
+func key(s string) tea.KeyMsg {
+	switch s {
+	case "enter":
+		return tea.KeyMsg{Type: tea.KeyEnter}
+	case "esc":
+		return tea.KeyMsg{Type: tea.KeyEsc}
+	case "ctrl+c":
+		return tea.KeyMsg{Type: tea.KeyCtrlC}
+	case "up":
+		return tea.KeyMsg{Type: tea.KeyUp}
+	case "down":
+		return tea.KeyMsg{Type: tea.KeyDown}
+	case "tab":
+		return tea.KeyMsg{Type: tea.KeyTab}
+	}
+	return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(s)}
+}
+
+func TestConfirmModel(t *testing.T) {
+	tests := []struct {
+		name  string
+		def   bool
+		keys  []string
+		want  bool
+		abort bool
+	}{
+		{name: "Yes", keys: []string{"y"}, want: true},
+		{name: "No", def: true, keys: []string{"n"}, want: false},
+		{name: "Enter keeps default", def: true, keys: []string{"enter"}, want: true},
+		{name: "Toggle then enter", keys: []string{"tab", "enter"}, want: true},
+		{name: "Esc aborts", keys: []string{"esc"}, abort: true},
+		{name: "Ctrl-C aborts", keys: []string{"ctrl+c"}, abort: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var m tea.Model = newConfirmModel("Delete?", tt.def)
+			for _, k := range tt.keys {
+				m, _ = m.Update(key(k))
+			}
+			cm := m.(confirmModel)
+			if cm.aborted() != tt.abort {
+				t.Fatalf("aborted() = %v, want %v", cm.aborted(), tt.abort)
+			}
+			if !tt.abort && (!cm.done || cm.value != tt.want) {
+				t.Errorf("done = %v, value = %v; want done, %v", cm.done, cm.value, tt.want)
+			}
+		})
+	}
+}
+
+func TestConfirmModelView(t *testing.T) {
+	m := newConfirmModel("Delete?", false)
+	if view := m.View(); !strings.Contains(view, "Delete?") || !strings.Contains(view, "[no]") {
+		t.Errorf("View() = %q, want the question with 'no' selected", view)
+	}
+	done, _ := m.Update(key("y"))
+	if view := done.View(); !strings.Contains(view, "yes") {
+		t.Errorf("View() after answering = %q, want the answer", view)
+	}
+}
+
+func TestSelectModel(t *testing.T) {
+	options := []string{"a", "b", "c"}
+	var m tea.Model = newSelectModel("Pick", options, -1, termcaps.Caps{}.Glyphs())
+	for _, k := range []string{"down", "down", "down", "up", "up", "enter"} {
+		m, _ = m.Update(key(k))
+	}
+	sm := m.(selectModel)
+	if !sm.done || sm.cursor != 1 {
+		t.Errorf("done = %v, cursor = %d; want done, 1", sm.done, sm.cursor)
+	}
+	if view := sm.View(); !strings.Contains(view, "Pick b") {
+		t.Errorf("View() = %q, want the chosen option", view)
+	}
+
+	m = newSelectModel("Pick", options, 2, termcaps.Caps{}.Glyphs())
+	if view := m.View(); !strings.Contains(view, "> c") {
+		t.Errorf("View() = %q, want the default highlighted", view)
+	}
+	m, _ = m.Update(key("esc"))
+	if !m.(selectModel).aborted() {
+		t.Error("expected esc to abort")
+	}
+}
+
+func TestInputModel(t *testing.T) {
+	var m tea.Model = newInputModel("Name", "world")
+	m, _ = m.Update(key("enter"))
+	if im := m.(inputModel); !im.done || im.result() != "world" {
+		t.Errorf("done = %v, result = %q; want done, default", im.done, im.result())
+	}
+
+	m = newInputModel("Name", "world")
+	m, _ = m.Update(key("bob"))
+	m, _ = m.Update(key("enter"))
+	if got := m.(inputModel).result(); got != "bob" {
+		t.Errorf("result() = %q, want %q", got, "bob")
+	}
+
+	m = newInputModel("Name", "")
+	m, _ = m.Update(key("ctrl+c"))
+	if !m.(inputModel).aborted() {
+		t.Error("expected ctrl+c to abort")
+	}
+}
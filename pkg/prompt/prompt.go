@@ -0,0 +1,296 @@
+// pkg/prompt/prompt.go
+
+// Package prompt asks the user to confirm, choose or type a value. On a terminal
+// the questions are small Bubble Tea programs; otherwise a line is read from the
+// input, so answers can be piped in. With --yes confirmations are accepted and
+// defaults are used, and with --no-input nothing is read at all: a question that
+// has no default fails with ErrNoInput instead of blocking a script.
+package prompt
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/peiman/ckeletin-go/pkg/termcaps"
+)
+
+// ErrNoInput is returned when a question needs an answer but prompting is disabled
+var ErrNoInput = errors.New("input required but prompts are disabled (use --yes or pass the value as a flag)")
+
+// ErrAborted is returned when the user aborts a question with Ctrl-C or Esc.
+// It wraps context.Canceled so callers treat it like an interrupt.
+var ErrAborted = fmt.Errorf("prompt aborted: %w", context.Canceled)
+
+// maxAttempts bounds how often an invalid line answer is asked again
+const maxAttempts = 3
+
+// Options are the prompt settings, set from the global flags
+type Options struct {
+	// AssumeYes accepts confirmations and uses defaults without asking (--yes)
+	AssumeYes bool
+	// NoInput never reads from the user; questions without a default fail (--no-input)
+	NoInput bool
+	// In is where answers are read from, os.Stdin if nil
+	In io.Reader
+	// Out is where questions are written to, os.Stderr if nil
+	Out io.Writer
+	// Unicode overrides whether the interactive prompts use non-ASCII symbols
+	Unicode termcaps.UnicodeMode
+}
+
+type optionsKey struct{}
+
+// WithOptions returns a copy of ctx carrying o for the package-level helpers,
+// so invocations running in one process can prompt with settings of their own.
+func WithOptions(ctx context.Context, o Options) context.Context {
+	return context.WithValue(ctx, optionsKey{}, o)
+}
+
+// For returns the Prompter the package-level helpers use with ctx: one with
+// the options ctx carries, or the zero Options. Without In and Out, questions
+// go to stderr so they never mix with command output.
+func For(ctx context.Context) *Prompter {
+	o, _ := ctx.Value(optionsKey{}).(Options)
+	return newPrompter(o)
+}
+
+// newPrompter returns a Prompter with the options o
+func newPrompter(o Options) *Prompter {
+	p := &Prompter{
+		In:        o.In,
+		Out:       o.Out,
+		AssumeYes: o.AssumeYes,
+		NoInput:   o.NoInput,
+	}
+	if p.In == nil {
+		p.In = os.Stdin
+	}
+	if p.Out == nil {
+		p.Out = os.Stderr
+	}
+	// The interactive prompts need the terminal itself
+	p.TTY = p.In == io.Reader(os.Stdin) && p.Out == io.Writer(os.Stderr) && termcaps.Stdin().TTY && termcaps.Stderr().TTY
+	p.Glyphs = o.Unicode.Apply(termcaps.Stderr()).Glyphs()
+	return p
+}
+
+// Confirm asks a yes/no question with the Prompter of ctx, see For
+func Confirm(ctx context.Context, question string, def bool) (bool, error) {
+	return For(ctx).Confirm(ctx, question, def)
+}
+
+// Select asks to choose one of options with the Prompter of ctx and returns its index
+func Select(ctx context.Context, question string, options []string, def int) (int, error) {
+	return For(ctx).Select(ctx, question, options, def)
+}
+
+// Input asks for a line of text with the Prompter of ctx
+func Input(ctx context.Context, question, def string) (string, error) {
+	return For(ctx).Input(ctx, question, def)
+}
+
+// Prompter asks questions on In and Out
+type Prompter struct {
+	In        io.Reader
+	Out       io.Writer
+	AssumeYes bool
+	NoInput   bool
+	// TTY selects the interactive Bubble Tea prompts instead of reading lines
+	TTY bool
+	// Glyphs are the symbols the interactive prompts draw with
+	Glyphs termcaps.Glyphs
+
+	reader *bufio.Reader
+}
+
+// Confirm asks a yes/no question. An empty answer selects def; with AssumeYes the
+// answer is yes without asking.
+func (p *Prompter) Confirm(ctx context.Context, question string, def bool) (bool, error) {
+	if p.AssumeYes {
+		return true, nil
+	}
+	if p.NoInput {
+		return false, ErrNoInput
+	}
+	if p.TTY {
+		final, err := p.run(ctx, newConfirmModel(question, def))
+		if err != nil {
+			return false, err
+		}
+		return final.(confirmModel).value, nil
+	}
+
+	hint := "y/N"
+	if def {
+		hint = "Y/n"
+	}
+	for i := 0; i < maxAttempts; i++ {
+		line, err := p.ask(ctx, fmt.Sprintf("%s [%s]: ", question, hint))
+		if err != nil {
+			return false, err
+		}
+		switch strings.ToLower(line) {
+		case "":
+			return def, nil
+		case "y", "yes":
+			return true, nil
+		case "n", "no":
+			return false, nil
+		}
+		fmt.Fprintln(p.Out, "Please answer yes or no.")
+	}
+	return false, fmt.Errorf("no valid answer to %q", question)
+}
+
+// Select asks to choose one of options and returns its index. def is the index used
+// for an empty answer, or -1 when an answer is required.
+func (p *Prompter) Select(ctx context.Context, question string, options []string, def int) (int, error) {
+	if len(options) == 0 {
+		return -1, errors.New("no options to select from")
+	}
+	if def >= len(options) {
+		return -1, fmt.Errorf("invalid default option %d", def)
+	}
+	if p.AssumeYes || p.NoInput {
+		if def < 0 {
+			return -1, ErrNoInput
+		}
+		return def, nil
+	}
+	if p.TTY {
+		final, err := p.run(ctx, newSelectModel(question, options, def, p.Glyphs))
+		if err != nil {
+			return -1, err
+		}
+		return final.(selectModel).cursor, nil
+	}
+
+	fmt.Fprintln(p.Out, question)
+	for i, option := range options {
+		fmt.Fprintf(p.Out, "  %d) %s\n", i+1, option)
+	}
+	label := fmt.Sprintf("Choose 1-%d: ", len(options))
+	if def >= 0 {
+		label = fmt.Sprintf("Choose 1-%d [%d]: ", len(options), def+1)
+	}
+	for i := 0; i < maxAttempts; i++ {
+		line, err := p.ask(ctx, label)
+		if err != nil {
+			return -1, err
+		}
+		if line == "" && def >= 0 {
+			return def, nil
+		}
+		if n, err := strconv.Atoi(line); err == nil && n >= 1 && n <= len(options) {
+			return n - 1, nil
+		}
+		for i, option := range options {
+			if strings.EqualFold(line, option) {
+				return i, nil
+			}
+		}
+		fmt.Fprintf(p.Out, "Please enter a number between 1 and %d.\n", len(options))
+	}
+	return -1, fmt.Errorf("no valid answer to %q", question)
+}
+
+// Input asks for a line of text. An empty answer selects def; with AssumeYes or
+// NoInput def is used without asking, and ErrNoInput is returned when it is empty.
+func (p *Prompter) Input(ctx context.Context, question, def string) (string, error) {
+	if p.AssumeYes || p.NoInput {
+		if def == "" {
+			return "", ErrNoInput
+		}
+		return def, nil
+	}
+	if p.TTY {
+		final, err := p.run(ctx, newInputModel(question, def))
+		if err != nil {
+			return "", err
+		}
+		return final.(inputModel).result(), nil
+	}
+
+	label := question + ": "
+	if def != "" {
+		label = fmt.Sprintf("%s [%s]: ", question, def)
+	}
+	line, err := p.ask(ctx, label)
+	if err != nil {
+		return "", err
+	}
+	if line == "" {
+		return def, nil
+	}
+	return line, nil
+}
+
+// ask writes label and reads one trimmed line from In. Running out of input
+// means nobody can answer, which is reported as ErrNoInput.
+func (p *Prompter) ask(ctx context.Context, label string) (string, error) {
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+	if p.reader == nil {
+		p.reader = bufio.NewReader(p.In)
+	}
+	fmt.Fprint(p.Out, label)
+
+	type result struct {
+		line string
+		err  error
+	}
+	done := make(chan result, 1)
+	go func() {
+		line, err := p.reader.ReadString('\n')
+		done <- result{line, err}
+	}()
+
+	select {
+	case <-ctx.Done():
+		fmt.Fprintln(p.Out)
+		return "", ctx.Err()
+	case r := <-done:
+		if r.err != nil && (r.line == "" || !errors.Is(r.err, io.EOF)) {
+			fmt.Fprintln(p.Out)
+			if errors.Is(r.err, io.EOF) {
+				return "", ErrNoInput
+			}
+			return "", fmt.Errorf("failed to read answer: %w", r.err)
+		}
+		return strings.TrimSpace(r.line), nil
+	}
+}
+
+// aborter is implemented by the prompt models to report Ctrl-C or Esc
+type aborter interface {
+	aborted() bool
+}
+
+// run shows m until it quits and returns the final model
+func (p *Prompter) run(ctx context.Context, m tea.Model) (tea.Model, error) {
+	// Signals are handled by the caller, which cancels ctx.
+	prog := tea.NewProgram(m,
+		tea.WithContext(ctx),
+		tea.WithInput(p.In),
+		tea.WithOutput(p.Out),
+		tea.WithoutSignalHandler())
+	final, err := prog.Run()
+	if errors.Is(err, tea.ErrProgramKilled) && ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to run prompt: %w", err)
+	}
+	if a, ok := final.(aborter); ok && a.aborted() {
+		return nil, ErrAborted
+	}
+	return final, nil
+}
@@ -0,0 +1,167 @@
+// pkg/prompt/prompt_test.go
+
+package prompt
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func linePrompter(input string) (*Prompter, *bytes.Buffer) {
+	out := &bytes.Buffer{}
+	return &Prompter{In: strings.NewReader(input), Out: out}, out
+}
+
+func TestConfirm(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		def     bool
+		want    bool
+		wantErr error
+	}{
+		{name: "Yes", input: "y\n", want: true},
+		{name: "Full word", input: "YES\n", want: true},
+		{name: "No", input: "n\n", def: true, want: false},
+		{name: "Empty uses default", input: "\n", def: true, want: true},
+		{name: "Retry after invalid", input: "maybe\ny\n", want: true},
+		{name: "Answer without newline", input: "y", want: true},
+		{name: "EOF", input: "", wantErr: ErrNoInput},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, out := linePrompter(tt.input)
+			got, err := p.Confirm(context.Background(), "Continue?", tt.def)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Confirm() error = %v, want %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("Confirm() = %v, want %v", got, tt.want)
+			}
+			if !strings.Contains(out.String(), "Continue? [") {
+				t.Errorf("question not written, got %q", out.String())
+			}
+		})
+	}
+}
+
+func TestConfirmGivesUp(t *testing.T) {
+	p, _ := linePrompter("a\nb\nc\ny\n")
+	if _, err := p.Confirm(context.Background(), "Continue?", false); err == nil {
+		t.Fatal("expected an error after repeated invalid answers")
+	}
+}
+
+func TestConfirmFlags(t *testing.T) {
+	p := &Prompter{AssumeYes: true, NoInput: true}
+	if ok, err := p.Confirm(context.Background(), "Delete?", false); err != nil || !ok {
+		t.Errorf("Confirm() with AssumeYes = %v, %v; want true, nil", ok, err)
+	}
+
+	p = &Prompter{NoInput: true}
+	if _, err := p.Confirm(context.Background(), "Delete?", true); !errors.Is(err, ErrNoInput) {
+		t.Errorf("Confirm() with NoInput error = %v, want ErrNoInput", err)
+	}
+}
+
+func TestSelect(t *testing.T) {
+	options := []string{"text", "json", "yaml"}
+	tests := []struct {
+		name  string
+		input string
+		def   int
+		want  int
+	}{
+		{name: "By number", input: "2\n", def: -1, want: 1},
+		{name: "By name", input: "YAML\n", def: -1, want: 2},
+		{name: "Empty uses default", input: "\n", def: 0, want: 0},
+		{name: "Retry after invalid", input: "9\n3\n", def: -1, want: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, out := linePrompter(tt.input)
+			got, err := p.Select(context.Background(), "Format?", options, tt.def)
+			if err != nil {
+				t.Fatalf("Select() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Select() = %d, want %d", got, tt.want)
+			}
+			if !strings.Contains(out.String(), "2) json") {
+				t.Errorf("options not listed, got %q", out.String())
+			}
+		})
+	}
+}
+
+func TestSelectFlags(t *testing.T) {
+	options := []string{"a", "b"}
+	p := &Prompter{NoInput: true}
+	if got, err := p.Select(context.Background(), "Pick", options, 1); err != nil || got != 1 {
+		t.Errorf("Select() with default = %d, %v; want 1, nil", got, err)
+	}
+	if _, err := p.Select(context.Background(), "Pick", options, -1); !errors.Is(err, ErrNoInput) {
+		t.Errorf("Select() without default error = %v, want ErrNoInput", err)
+	}
+	if _, err := p.Select(context.Background(), "Pick", nil, -1); err == nil {
+		t.Error("expected an error without options")
+	}
+	if _, err := p.Select(context.Background(), "Pick", options, 2); err == nil {
+		t.Error("expected an error for an out of range default")
+	}
+}
+
+func TestInput(t *testing.T) {
+	p, _ := linePrompter("  hello  \n\n")
+	got, err := p.Input(context.Background(), "Name", "world")
+	if err != nil || got != "hello" {
+		t.Errorf("Input() = %q, %v; want %q", got, err, "hello")
+	}
+	got, err = p.Input(context.Background(), "Name", "world")
+	if err != nil || got != "world" {
+		t.Errorf("Input() with empty answer = %q, %v; want default", got, err)
+	}
+
+	p = &Prompter{AssumeYes: true}
+	if got, err := p.Input(context.Background(), "Name", "world"); err != nil || got != "world" {
+		t.Errorf("Input() with AssumeYes = %q, %v; want default", got, err)
+	}
+	if _, err := p.Input(context.Background(), "Name", ""); !errors.Is(err, ErrNoInput) {
+		t.Errorf("Input() without default error = %v, want ErrNoInput", err)
+	}
+}
+
+func TestCanceledContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	p, _ := linePrompter("y\n")
+	if _, err := p.Confirm(ctx, "Continue?", false); !errors.Is(err, context.Canceled) {
+		t.Errorf("Confirm() error = %v, want context.Canceled", err)
+	}
+}
+
+func TestWithOptions(t *testing.T) {
+	ctx := WithOptions(context.Background(), Options{AssumeYes: true})
+	if ok, err := Confirm(ctx, "Delete?", false); err != nil || !ok {
+		t.Errorf("Confirm() = %v, %v; want true, nil", ok, err)
+	}
+
+	ctx = WithOptions(context.Background(), Options{NoInput: true})
+	if _, err := Input(ctx, "Name", ""); !errors.Is(err, ErrNoInput) {
+		t.Errorf("Input() error = %v, want ErrNoInput", err)
+	}
+	if got, err := Select(ctx, "Pick", []string{"a", "b"}, 0); err != nil || got != 0 {
+		t.Errorf("Select() = %d, %v; want 0, nil", got, err)
+	}
+}
+
+func TestAbortedWrapsCanceled(t *testing.T) {
+	if !errors.Is(ErrAborted, context.Canceled) {
+		t.Error("ErrAborted should wrap context.Canceled")
+	}
+}
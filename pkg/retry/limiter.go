@@ -0,0 +1,65 @@
+// pkg/retry/limiter.go
+
+package retry
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Limiter allows up to rate operations per second on average, with bursts of
+// up to burst operations (a token bucket). It is safe for concurrent use.
+type Limiter struct {
+	mu       sync.Mutex
+	interval time.Duration
+	burst    int
+	tokens   float64
+	last     time.Time
+	now      func() time.Time
+}
+
+// NewLimiter returns a limiter for rate operations per second. A rate of 0 or
+// less returns nil, which never waits.
+func NewLimiter(rate float64, burst int) *Limiter {
+	if rate <= 0 {
+		return nil
+	}
+	if burst < 1 {
+		burst = 1
+	}
+	return &Limiter{
+		interval: time.Duration(float64(time.Second) / rate),
+		burst:    burst,
+		tokens:   float64(burst),
+		now:      time.Now,
+	}
+}
+
+// Wait blocks until an operation is allowed or ctx is done
+func (l *Limiter) Wait(ctx context.Context) error {
+	if l == nil {
+		return ctx.Err()
+	}
+	return Sleep(ctx, l.reserve())
+}
+
+// reserve takes a token and returns how long to wait until it is available
+func (l *Limiter) reserve() time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	if !l.last.IsZero() {
+		l.tokens += float64(now.Sub(l.last)) / float64(l.interval)
+		if l.tokens > float64(l.burst) {
+			l.tokens = float64(l.burst)
+		}
+	}
+	l.last = now
+	l.tokens--
+	if l.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-l.tokens * float64(l.interval))
+}
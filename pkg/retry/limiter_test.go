@@ -0,0 +1,54 @@
+// pkg/retry/limiter_test.go
+
+package retry
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestLimiterReserve(t *testing.T) {
+	now := time.Unix(0, 0)
+	l := NewLimiter(10, 2) // one token every 100ms
+	l.now = func() time.Time { return now }
+
+	// The burst is available right away
+	for i := 0; i < 2; i++ {
+		if d := l.reserve(); d != 0 {
+			t.Fatalf("reserve() #%d = %v, want 0", i+1, d)
+		}
+	}
+	if d := l.reserve(); d != 100*time.Millisecond {
+		t.Errorf("reserve() beyond burst = %v, want 100ms", d)
+	}
+	if d := l.reserve(); d != 200*time.Millisecond {
+		t.Errorf("reserve() queued = %v, want 200ms", d)
+	}
+
+	// Tokens refill over time, up to the burst
+	now = now.Add(10 * time.Second)
+	if d := l.reserve(); d != 0 {
+		t.Errorf("reserve() after refill = %v, want 0", d)
+	}
+}
+
+func TestLimiterWait(t *testing.T) {
+	var l *Limiter = NewLimiter(0, 1)
+	if l != nil {
+		t.Fatal("NewLimiter(0) should return nil")
+	}
+	if err := l.Wait(context.Background()); err != nil {
+		t.Errorf("nil Limiter Wait() error = %v", err)
+	}
+
+	l = NewLimiter(1, 1)
+	if err := l.Wait(context.Background()); err != nil {
+		t.Fatalf("Wait() error = %v", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := l.Wait(ctx); err == nil {
+		t.Error("Expected Wait() to return the context error")
+	}
+}
@@ -0,0 +1,150 @@
+// pkg/retry/retry.go
+
+// Package retry repeats failing operations with exponential backoff and jitter,
+// and limits the rate of operations. Both stop waiting as soon as the context
+// is canceled. Callers observe retries through the OnRetry hook, e.g. to show
+// them as progress.
+package retry
+
+import (
+	"context"
+	"errors"
+	"math"
+	"math/rand"
+	"time"
+)
+
+// Backoff computes the delay before each retry
+type Backoff struct {
+	// Initial is the delay before the first retry
+	Initial time.Duration
+	// Max caps the delay; 0 caps it at MaxDelay
+	Max time.Duration
+	// Multiplier grows the delay per retry; values below 1 mean 2
+	Multiplier float64
+	// Jitter randomizes each delay by up to this fraction in either direction
+	// (0.2 gives 80% to 120%), so clients do not retry in lockstep
+	Jitter float64
+}
+
+// DefaultBackoff starts at 500ms and doubles up to 30s, with 20% jitter
+var DefaultBackoff = Backoff{Initial: 500 * time.Millisecond, Max: 30 * time.Second, Multiplier: 2, Jitter: 0.2}
+
+// MaxDelay is the longest delay Delay returns, whatever the backoff
+const MaxDelay = time.Duration(math.MaxInt64)
+
+// randFloat returns a number in [0, 1), can be replaced in tests
+var randFloat = rand.Float64
+
+// Delay returns the delay before retry number retry, counting from 1
+func (b Backoff) Delay(retry int) time.Duration {
+	m := b.Multiplier
+	if m < 1 {
+		m = 2
+	}
+	limit := float64(MaxDelay)
+	if b.Max > 0 {
+		limit = float64(b.Max)
+	}
+	d := float64(b.Initial)
+	for i := 1; i < retry && d < limit; i++ {
+		d *= m
+	}
+	if d > limit {
+		d = limit
+	}
+	if b.Jitter > 0 {
+		d *= 1 + b.Jitter*(2*randFloat()-1)
+	}
+	// float64(MaxDelay) rounds up past the largest Duration
+	if d >= float64(MaxDelay) {
+		return MaxDelay
+	}
+	return time.Duration(d)
+}
+
+// Event describes an upcoming retry
+type Event struct {
+	// Retry counts the retries, from 1
+	Retry int
+	// Retries is the maximum number of retries
+	Retries int
+	// Delay is how long Do waits before the retry
+	Delay time.Duration
+	// Err is the error of the failed attempt
+	Err error
+}
+
+// Policy configures Do
+type Policy struct {
+	// Retries is how often a failed operation is retried
+	Retries int
+	Backoff Backoff
+	// Retryable decides whether an error is worth retrying; nil retries all
+	// errors except context cancellation and errors marked with Permanent
+	Retryable func(error) bool
+	// OnRetry is called before waiting for each retry
+	OnRetry func(Event)
+}
+
+// permanentError marks an error that must not be retried
+type permanentError struct{ err error }
+
+func (e *permanentError) Error() string { return e.err.Error() }
+func (e *permanentError) Unwrap() error { return e.err }
+
+// Permanent wraps err so Do returns it without retrying
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &permanentError{err: err}
+}
+
+// Do calls fn until it succeeds, returns a non-retryable error, the retries are
+// used up or ctx is canceled. It returns the last error of fn, or the context error.
+func Do(ctx context.Context, p Policy, fn func(ctx context.Context) error) error {
+	for retry := 0; ; retry++ {
+		err := fn(ctx)
+		if err == nil {
+			return nil
+		}
+		var perm *permanentError
+		if errors.As(err, &perm) {
+			return perm.err
+		}
+		if retry >= p.Retries || ctx.Err() != nil || !p.retryable(err) {
+			return err
+		}
+
+		delay := p.Backoff.Delay(retry + 1)
+		if p.OnRetry != nil {
+			p.OnRetry(Event{Retry: retry + 1, Retries: p.Retries, Delay: delay, Err: err})
+		}
+		if err := Sleep(ctx, delay); err != nil {
+			return err
+		}
+	}
+}
+
+func (p Policy) retryable(err error) bool {
+	if errors.Is(err, context.Canceled) {
+		return false
+	}
+	return p.Retryable == nil || p.Retryable(err)
+}
+
+// Sleep waits for d or until ctx is done, returning the context error in that case
+func Sleep(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
@@ -0,0 +1,112 @@
+// pkg/retry/retry_test.go
+
+package retry
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestBackoffDelay(t *testing.T) {
+	origRand := randFloat
+	defer func() { randFloat = origRand }()
+	randFloat = func() float64 { return 0.5 } // no jitter
+
+	b := Backoff{Initial: 100 * time.Millisecond, Max: time.Second, Multiplier: 2, Jitter: 0.2}
+	want := []time.Duration{100 * time.Millisecond, 200 * time.Millisecond, 400 * time.Millisecond, 800 * time.Millisecond, time.Second, time.Second}
+	for i, w := range want {
+		if got := b.Delay(i + 1); got != w {
+			t.Errorf("Delay(%d) = %v, want %v", i+1, got, w)
+		}
+	}
+
+	randFloat = func() float64 { return 0 }
+	if got := b.Delay(1); got != 80*time.Millisecond {
+		t.Errorf("Delay(1) with minimal jitter = %v, want 80ms", got)
+	}
+	if got := (Backoff{Initial: time.Second}).Delay(3); got != 4*time.Second {
+		t.Errorf("Delay(3) with default multiplier = %v, want 4s", got)
+	}
+}
+
+func TestBackoffDelay_NoMax(t *testing.T) {
+	origRand := randFloat
+	defer func() { randFloat = origRand }()
+	randFloat = func() float64 { return 1 } // largest jitter
+
+	b := Backoff{Initial: time.Second, Multiplier: 10, Jitter: 0.5}
+	for _, retry := range []int{30, 100, 10000} {
+		if got := b.Delay(retry); got != MaxDelay {
+			t.Errorf("Delay(%d) without Max = %v, want MaxDelay", retry, got)
+		}
+	}
+	if got := b.Delay(2); got != 15*time.Second {
+		t.Errorf("Delay(2) = %v, want 15s", got)
+	}
+}
+
+func TestDo(t *testing.T) {
+	errTemp := errors.New("temporary")
+	p := Policy{Retries: 3, Backoff: Backoff{Initial: time.Millisecond}}
+
+	tests := []struct {
+		name      string
+		errs      []error
+		retryable func(error) bool
+		wantCalls int
+		wantErr   error
+	}{
+		{"Succeeds", []error{nil}, nil, 1, nil},
+		{"Succeeds after retries", []error{errTemp, errTemp, nil}, nil, 3, nil},
+		{"Gives up", []error{errTemp, errTemp, errTemp, errTemp}, nil, 4, errTemp},
+		{"Permanent", []error{Permanent(errTemp)}, nil, 1, errTemp},
+		{"Not retryable", []error{errTemp}, func(error) bool { return false }, 1, errTemp},
+		{"Canceled", []error{context.Canceled}, nil, 1, context.Canceled},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var events []Event
+			p := p
+			p.Retryable = tt.retryable
+			p.OnRetry = func(e Event) { events = append(events, e) }
+
+			calls := 0
+			err := Do(context.Background(), p, func(ctx context.Context) error {
+				calls++
+				return tt.errs[calls-1]
+			})
+			if !errors.Is(err, tt.wantErr) || (tt.wantErr == nil && err != nil) {
+				t.Errorf("Do() error = %v, want %v", err, tt.wantErr)
+			}
+			if calls != tt.wantCalls {
+				t.Errorf("calls = %d, want %d", calls, tt.wantCalls)
+			}
+			if len(events) != calls-1 {
+				t.Errorf("OnRetry called %d times, want %d", len(events), calls-1)
+			}
+			for i, e := range events {
+				if e.Retry != i+1 || e.Retries != 3 || e.Err == nil {
+					t.Errorf("Unexpected event %+v", e)
+				}
+			}
+		})
+	}
+}
+
+func TestDo_ContextCanceledWhileWaiting(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	p := Policy{Retries: 3, Backoff: Backoff{Initial: time.Hour}, OnRetry: func(Event) { cancel() }}
+
+	err := Do(ctx, p, func(ctx context.Context) error { return errors.New("fail") })
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("Do() error = %v, want context.Canceled", err)
+	}
+}
+
+func TestPermanent_Nil(t *testing.T) {
+	if Permanent(nil) != nil {
+		t.Error("Permanent(nil) should be nil")
+	}
+}
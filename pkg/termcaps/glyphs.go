@@ -0,0 +1,29 @@
+// pkg/termcaps/glyphs.go
+
+package termcaps
+
+// Glyphs are the symbols output uses for states, progress and navigation
+// hints, with ASCII equivalents for terminals without unicode
+type Glyphs struct {
+	Success, Failure, Warning, Running string
+	Arrow, Up, Down, Ellipsis          string
+}
+
+var (
+	unicodeGlyphs = Glyphs{
+		Success: "✔", Failure: "✘", Warning: "⚠", Running: "▶",
+		Arrow: "→", Up: "↑", Down: "↓", Ellipsis: "…",
+	}
+	asciiGlyphs = Glyphs{
+		Success: "+", Failure: "x", Warning: "!", Running: ">",
+		Arrow: "->", Up: "up", Down: "down", Ellipsis: "...",
+	}
+)
+
+// Glyphs returns the symbols c can display
+func (c Caps) Glyphs() Glyphs {
+	if c.Unicode {
+		return unicodeGlyphs
+	}
+	return asciiGlyphs
+}
@@ -0,0 +1,199 @@
+// pkg/termcaps/termcaps.go
+
+// Package termcaps detects what the terminal behind an output stream can display:
+// color depth, unicode and width. Detection runs once per stream so every output
+// layer (logger, progress, error messages) degrades the same way, e.g. plain
+// ASCII without color when piped or on a legacy Windows console. On Windows,
+// detection also enables ANSI escape sequences in the console where possible.
+package termcaps
+
+import (
+	"io"
+	"os"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/charmbracelet/x/term"
+	"github.com/muesli/termenv"
+)
+
+// DefaultWidth and DefaultHeight are assumed when the terminal size cannot be determined
+const (
+	DefaultWidth  = 80
+	DefaultHeight = 24
+)
+
+// ColorLevel is the color depth an output supports
+type ColorLevel int
+
+// Color levels, from least to most capable
+const (
+	NoColor ColorLevel = iota
+	BasicColor
+	Color256
+	TrueColor
+)
+
+func (l ColorLevel) String() string {
+	switch l {
+	case BasicColor:
+		return "16"
+	case Color256:
+		return "256"
+	case TrueColor:
+		return "truecolor"
+	}
+	return "none"
+}
+
+// Caps describes the capabilities of an output stream
+type Caps struct {
+	// TTY is true when the stream is an interactive terminal
+	TTY bool
+	// Color is the supported color depth; NoColor when not a TTY or NO_COLOR is set
+	Color ColorLevel
+	// Unicode is true when box drawing and other non-ASCII symbols render correctly
+	Unicode bool
+	// Width is the terminal width in columns, or DefaultWidth
+	Width int
+	// Height is the terminal height in lines, or DefaultHeight
+	Height int
+}
+
+var (
+	stdin  = sync.OnceValue(func() Caps { return detectFile(os.Stdin, false) })
+	stdout = sync.OnceValue(func() Caps { return detectFile(os.Stdout, true) })
+	stderr = sync.OnceValue(func() Caps { return detectFile(os.Stderr, true) })
+)
+
+// Stdin returns the capabilities of the terminal behind standard input, detected on first use.
+// Its TTY field tells whether the user can answer prompts.
+func Stdin() Caps { return stdin() }
+
+// Stdout returns the capabilities of standard output, detected on first use
+func Stdout() Caps { return stdout() }
+
+// Stderr returns the capabilities of standard error, detected on first use
+func Stderr() Caps { return stderr() }
+
+// UnicodeMode decides whether output uses non-ASCII symbols
+type UnicodeMode int32
+
+// Unicode modes
+const (
+	// UnicodeAuto detects unicode support from the platform and locale
+	UnicodeAuto UnicodeMode = iota
+	// UnicodeOn always uses unicode symbols
+	UnicodeOn
+	// UnicodeOff always uses ASCII, e.g. for terminals or log aggregators
+	// with broken fonts
+	UnicodeOff
+)
+
+// Apply returns c with its Unicode field overridden by m, unless m is UnicodeAuto
+func (m UnicodeMode) Apply(c Caps) Caps {
+	switch m {
+	case UnicodeOn:
+		c.Unicode = true
+	case UnicodeOff:
+		c.Unicode = false
+	}
+	return c
+}
+
+// For returns the capabilities of w. Standard output and error use the cached
+// detection; writers that are not files (buffers, pipes to other code) get no
+// color and no TTY.
+func For(w io.Writer) Caps {
+	switch w {
+	case os.Stdout:
+		return Stdout()
+	case os.Stderr:
+		return Stderr()
+	}
+	if f, ok := w.(*os.File); ok {
+		return Detect(f)
+	}
+	return Caps{Unicode: unicodeSupported(false, os.Getenv, runtime.GOOS), Width: DefaultWidth, Height: DefaultHeight}
+}
+
+// Detect inspects f and the environment without caching
+func Detect(f *os.File) Caps {
+	return detectFile(f, f != os.Stdin)
+}
+
+// detectFile inspects f. Virtual terminal processing is only enabled for
+// output: on a Windows input handle the same mode bit means ENABLE_ECHO_INPUT.
+func detectFile(f *os.File, output bool) Caps {
+	tty := term.IsTerminal(f.Fd())
+	width, height := 0, 0
+	if tty {
+		width, height, _ = term.GetSize(f.Fd())
+	}
+	profile := termenv.NewOutput(f).EnvColorProfile()
+	c := detect(tty, profile, width, height, os.Getenv, runtime.GOOS)
+	if tty && output && !enableVT(f) {
+		c = legacyConsole(c)
+	}
+	return c
+}
+
+// legacyConsole limits c to what a console without ANSI escape sequences can
+// display: escape sequences would be printed literally and its code page
+// lacks most symbols
+func legacyConsole(c Caps) Caps {
+	c.Color = NoColor
+	c.Unicode = false
+	return c
+}
+
+func detect(tty bool, profile termenv.Profile, width, height int, getenv func(string) string, goos string) Caps {
+	c := Caps{TTY: tty, Unicode: unicodeSupported(tty, getenv, goos), Width: width, Height: height}
+
+	switch profile {
+	case termenv.TrueColor:
+		c.Color = TrueColor
+	case termenv.ANSI256:
+		c.Color = Color256
+	case termenv.ANSI:
+		c.Color = BasicColor
+	}
+
+	if c.Width <= 0 {
+		if cols, err := strconv.Atoi(getenv("COLUMNS")); err == nil && cols > 0 {
+			c.Width = cols
+		} else {
+			c.Width = DefaultWidth
+		}
+	}
+	if c.Height <= 0 {
+		if lines, err := strconv.Atoi(getenv("LINES")); err == nil && lines > 0 {
+			c.Height = lines
+		} else {
+			c.Height = DefaultHeight
+		}
+	}
+	return c
+}
+
+// unicodeSupported guesses from the platform and locale whether non-ASCII symbols render
+func unicodeSupported(tty bool, getenv func(string) string, goos string) bool {
+	if goos == "windows" {
+		// Legacy consoles use a code page without box drawing; Windows Terminal,
+		// VS Code and ConEmu do not. Redirected output is written as UTF-8.
+		return !tty || getenv("WT_SESSION") != "" || getenv("TERM_PROGRAM") != "" || getenv("ConEmuANSI") == "ON"
+	}
+	if tty && getenv("TERM") == "linux" {
+		// The Linux virtual console font lacks most symbols.
+		return false
+	}
+	for _, name := range []string{"LC_ALL", "LC_CTYPE", "LANG"} {
+		if v := getenv(name); v != "" {
+			v = strings.ToLower(v)
+			return strings.Contains(v, "utf-8") || strings.Contains(v, "utf8")
+		}
+	}
+	return true
+}
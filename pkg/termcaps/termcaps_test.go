@@ -0,0 +1,96 @@
+// pkg/termcaps/termcaps_test.go
+
+package termcaps
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/muesli/termenv"
+)
+
+func env(vars map[string]string) func(string) string {
+	return func(name string) string { return vars[name] }
+}
+
+func TestDetect(t *testing.T) {
+	tests := []struct {
+		name    string
+		tty     bool
+		profile termenv.Profile
+		width   int
+		height  int
+		env     map[string]string
+		goos    string
+		want    Caps
+	}{
+		{"Truecolor terminal", true, termenv.TrueColor, 120, 40, map[string]string{"LANG": "en_US.UTF-8"}, "linux",
+			Caps{TTY: true, Color: TrueColor, Unicode: true, Width: 120, Height: 40}},
+		{"256 colors, C locale", true, termenv.ANSI256, 100, 30, map[string]string{"LANG": "C"}, "darwin",
+			Caps{TTY: true, Color: Color256, Unicode: false, Width: 100, Height: 30}},
+		{"Piped output uses COLUMNS", false, termenv.Ascii, 0, 0, map[string]string{"COLUMNS": "132", "LINES": "50"}, "linux",
+			Caps{Color: NoColor, Unicode: true, Width: 132, Height: 50}},
+		{"Linux console", true, termenv.ANSI, 0, 0, map[string]string{"TERM": "linux", "LANG": "en_US.UTF-8"}, "linux",
+			Caps{TTY: true, Color: BasicColor, Unicode: false, Width: DefaultWidth, Height: DefaultHeight}},
+		{"Legacy Windows console", true, termenv.Ascii, 80, 25, nil, "windows",
+			Caps{TTY: true, Color: NoColor, Unicode: false, Width: 80, Height: 25}},
+		{"Windows Terminal", true, termenv.TrueColor, 80, 25, map[string]string{"WT_SESSION": "1"}, "windows",
+			Caps{TTY: true, Color: TrueColor, Unicode: true, Width: 80, Height: 25}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := detect(tt.tty, tt.profile, tt.width, tt.height, env(tt.env), tt.goos); got != tt.want {
+				t.Errorf("detect() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFor_NonFileWriter(t *testing.T) {
+	c := For(new(bytes.Buffer))
+	if c.TTY || c.Color != NoColor || c.Width != DefaultWidth || c.Height != DefaultHeight {
+		t.Errorf("For(buffer) = %+v, want no TTY, no color, default size", c)
+	}
+}
+
+func TestColorLevelString(t *testing.T) {
+	if TrueColor.String() != "truecolor" || NoColor.String() != "none" {
+		t.Errorf("Unexpected ColorLevel strings %q, %q", TrueColor, NoColor)
+	}
+}
+
+func TestGlyphs(t *testing.T) {
+	if g := (Caps{Unicode: true}).Glyphs(); g.Success != "✔" || g.Ellipsis != "…" {
+		t.Errorf("Unicode glyphs = %+v", g)
+	}
+	g := Caps{}.Glyphs()
+	for _, s := range []string{g.Success, g.Failure, g.Warning, g.Running, g.Arrow, g.Up, g.Down, g.Ellipsis} {
+		for _, r := range s {
+			if r > 127 {
+				t.Errorf("ASCII glyph %q contains %q", s, r)
+			}
+		}
+	}
+}
+
+func TestLegacyConsole(t *testing.T) {
+	c := legacyConsole(Caps{TTY: true, Color: TrueColor, Unicode: true, Width: 100, Height: 30})
+	if c != (Caps{TTY: true, Color: NoColor, Unicode: false, Width: 100, Height: 30}) {
+		t.Errorf("legacyConsole() = %+v, want no color and no unicode", c)
+	}
+}
+
+func TestUnicodeMode_Apply(t *testing.T) {
+	detected := For(new(bytes.Buffer))
+
+	if UnicodeOff.Apply(detected).Unicode || UnicodeOff.Apply(Stderr()).Unicode {
+		t.Error("Unicode reported with UnicodeOff")
+	}
+	if !UnicodeOn.Apply(detected).Unicode || !UnicodeOn.Apply(Stderr()).Unicode {
+		t.Error("No unicode reported with UnicodeOn")
+	}
+	if UnicodeAuto.Apply(detected) != detected {
+		t.Error("UnicodeAuto changed the detection")
+	}
+}
@@ -0,0 +1,13 @@
+// pkg/termcaps/termcaps_unix.go
+
+//go:build !windows
+
+package termcaps
+
+import "os"
+
+// enableVT reports whether the terminal behind f interprets ANSI escape
+// sequences, which terminals outside Windows always do
+func enableVT(*os.File) bool {
+	return true
+}
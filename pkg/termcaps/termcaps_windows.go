@@ -0,0 +1,26 @@
+// pkg/termcaps/termcaps_windows.go
+
+//go:build windows
+
+package termcaps
+
+import (
+	"os"
+
+	"golang.org/x/sys/windows"
+)
+
+// enableVT turns on virtual terminal processing for the console output behind
+// f, so it interprets ANSI escape sequences instead of printing them. It fails
+// on consoles older than Windows 10.
+func enableVT(f *os.File) bool {
+	h := windows.Handle(f.Fd())
+	var mode uint32
+	if err := windows.GetConsoleMode(h, &mode); err != nil {
+		return false
+	}
+	if mode&windows.ENABLE_VIRTUAL_TERMINAL_PROCESSING != 0 {
+		return true
+	}
+	return windows.SetConsoleMode(h, mode|windows.ENABLE_VIRTUAL_TERMINAL_PROCESSING) == nil
+}